@@ -1,10 +1,13 @@
+// Package bernstein implements the evaluation and search heuristics used by BERNSTEIN.
 package bernstein
 
 import (
 	"context"
+	"fmt"
 	"github.com/herohde/morlock/pkg/board"
 	"github.com/herohde/morlock/pkg/eval"
 	"github.com/seekerror/stdlib/pkg/util/mathx"
+	"math"
 )
 
 // Eval implements the evaluation heuristic: the value, or score, is measured
@@ -47,6 +50,29 @@ func (e Eval) Evaluate(ctx context.Context, b *board.Board) eval.Pawns {
 	}
 }
 
+// Parameters returns the material multiplier as a tunable parameter.
+func (e *Eval) Parameters() map[string]float64 {
+	return map[string]float64{
+		"material": float64(e.Factor),
+	}
+}
+
+// SetParameters updates the material multiplier from tunable parameters.
+func (e *Eval) SetParameters(params map[string]float64) error {
+	for name, v := range params {
+		switch name {
+		case "material":
+			if v != math.Trunc(v) {
+				return fmt.Errorf("invalid parameter %v: %v is not an integer", name, v)
+			}
+			e.Factor = int(v)
+		default:
+			return fmt.Errorf("unknown parameter: %v", name)
+		}
+	}
+	return nil
+}
+
 func Evaluate(pos *board.Position, factor int, side board.Color) int {
 	mobility := Mobility(pos, side)
 	control := Control(pos, side)
@@ -99,13 +125,7 @@ func Mobility(pos *board.Position, side board.Color) int {
 // Control returns the number of squares defended by the given side, but with no opponent
 // attackers. Populated squares included.
 func Control(pos *board.Position, side board.Color) int {
-	ret := 0
-	for sq := board.ZeroSquare; sq < board.NumSquares; sq++ {
-		if pos.IsDefended(side, sq) && !pos.IsAttacked(side, sq) {
-			ret++
-		}
-	}
-	return ret
+	return eval.Control(pos, side)
 }
 
 // KingDefense returns the number of squares around the king defended by the given side, but
@@ -6,7 +6,8 @@ import (
 )
 
 // TODO(herohde) 11/24/2023: unclear to what extent static exchange evaluation is performed.
-// For now, keep it simple and explore how it predicts the published games.
+// For now, keep it simple and explore how it predicts the published games. SEE is available
+// as an option for rules 2a-2c to help evaluate the reconstruction.
 
 // IsMoveSafe evaluates whether a move is safe, i.e., that the piece is adequately defended
 // at its destination. Assumes legal. Takes into account sliding piece reach post-move.
@@ -30,3 +31,66 @@ func IsSafe(pos *board.Position, side board.Color, piece board.Piece, sq board.S
 	}
 	return eval.NominalValue(attackers[0].Piece) >= eval.NominalValue(piece)
 }
+
+// SEE returns the static exchange evaluation of a move in material value, i.e., the net
+// material gain for the side moving assuming both sides recapture on the destination square
+// with the least valuable piece as long as it is favorable. Assumes legal.
+func SEE(pos *board.Position, side board.Color, move board.Move) int {
+	gain := 0
+	switch {
+	case move.Type == board.EnPassant:
+		gain = MaterialValue(board.Pawn)
+	case move.IsCapture():
+		gain = MaterialValue(move.Capture)
+	}
+	piece := move.Piece
+	if move.IsPromotion() {
+		gain += MaterialValue(move.Promotion) - MaterialValue(board.Pawn)
+		piece = move.Promotion
+	}
+
+	next, ok := pos.Move(move)
+	if !ok {
+		return 0
+	}
+	return gain - exchange(next, side.Opponent(), move.To, MaterialValue(piece))
+}
+
+// Threatened returns the material value the opponent can win by capturing on the
+// occupied square, if any, using static exchange evaluation.
+func Threatened(pos *board.Position, side board.Color, piece board.Piece, sq board.Square) int {
+	return exchange(pos, side.Opponent(), sq, MaterialValue(piece))
+}
+
+// exchange returns the best material gain for side by capturing the target on the square,
+// if favorable. Zero otherwise.
+func exchange(pos *board.Position, side board.Color, sq board.Square, target int) int {
+	for _, attacker := range eval.SortByNominalValue(eval.FindCapture(pos, side, sq)) {
+		move := board.Move{Type: board.Capture, Piece: attacker.Piece, From: attacker.Square, To: sq, Capture: pieceAt(pos, sq)}
+		if attacker.Piece == board.Pawn && sq.Rank() == board.PromotionRank(side) {
+			move.Type = board.CapturePromotion
+			move.Promotion = board.Queen
+		}
+
+		next, ok := pos.Move(move)
+		if !ok {
+			continue // pinned: try next attacker
+		}
+
+		value := MaterialValue(attacker.Piece)
+		if move.IsPromotion() {
+			target += MaterialValue(board.Queen) - MaterialValue(board.Pawn)
+			value = MaterialValue(board.Queen)
+		}
+		if gain := target - exchange(next, side.Opponent(), sq, value); gain > 0 {
+			return gain
+		}
+		return 0
+	}
+	return 0
+}
+
+func pieceAt(pos *board.Position, sq board.Square) board.Piece {
+	_, piece, _ := pos.Square(sq)
+	return piece
+}
@@ -0,0 +1,47 @@
+package bernstein_test
+
+import (
+	"github.com/herohde/morlock/cmd/bernstein/bernstein"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSEE(t *testing.T) {
+	tests := []struct {
+		pos      string
+		move     string
+		expected int
+	}{
+		{"4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 1", "e4d5", 1},      // free pawn
+		{"4k3/8/2p5/3p4/4P3/8/8/4K3 w - - 0 1", "e4d5", 0},    // pawn exchange
+		{"4k3/8/2p5/3p4/8/8/8/3QK3 w - - 0 1", "d1d5", -8},    // queen takes defended pawn
+		{"4k3/8/2p5/3p4/8/5N2/8/3RK3 w - - 0 1", "f3d4", 0},   // knight move
+		{"3rk3/8/8/3p4/8/8/3R4/3RK3 w - - 0 1", "d2d5", 1},    // x-ray support
+		{"3rk3/3r4/8/3p4/8/8/3R4/3RK3 w - - 0 1", "d2d5", -4}, // losing rook exchange
+		{"4k3/8/8/8/8/8/6p1/4K2R b K - 0 1", "g2h1q", 13},     // promotion capture
+		{"1k6/8/8/3n4/8/8/1b6/R3K3 w - - 0 1", "a1a8", -5},    // rook en prise
+		{"1k6/8/2b5/3n4/8/5B2/8/4K3 w - - 0 1", "f3d5", 0},    // bishop exchange
+	}
+
+	for _, tt := range tests {
+		b, err := fen.NewBoard(tt.pos)
+		require.NoError(t, err)
+
+		candidate, err := board.ParseMove(tt.move)
+		require.NoError(t, err)
+
+		var move board.Move
+		for _, m := range b.Position().LegalMoves(b.Turn()) {
+			if m.Equals(candidate) {
+				move = m
+			}
+		}
+		require.False(t, move.IsInvalid(), "move %v not found: %v", tt.move, b)
+
+		actual := bernstein.SEE(b.Position(), b.Turn(), move)
+		assert.Equal(t, tt.expected, actual, "%v: %v", tt.move, b)
+	}
+}
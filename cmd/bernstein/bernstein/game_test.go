@@ -0,0 +1,44 @@
+package bernstein_test
+
+import (
+	"context"
+	"github.com/herohde/morlock/cmd/bernstein/bernstein"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/pgn"
+	"github.com/herohde/morlock/pkg/engine/gametest"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+)
+
+func TestScientificAmericanGame(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping game replay in short mode")
+	}
+
+	f, err := os.Open("../scientific_american_1958.pgn")
+	require.NoError(t, err)
+	defer f.Close()
+
+	games, err := pgn.Read(f)
+	require.NoError(t, err)
+	require.Len(t, games, 1)
+
+	cfg := gametest.Config{
+		Name: "bernstein",
+		Root: search.AlphaBeta{
+			Explore: bernstein.PlausibleMoveTable{Limit: 7}.Explore,
+			Eval:    search.Leaf{Eval: bernstein.Eval{Factor: 20}},
+		},
+		Depth: 4,
+	}
+
+	r, err := gametest.Replay(context.Background(), games[0], board.White, cfg)
+	require.NoError(t, err)
+	t.Log(r)
+
+	assert.Equal(t, 22, r.Moves)
+	assert.GreaterOrEqual(t, r.Matches(), 7) // regression guard
+}
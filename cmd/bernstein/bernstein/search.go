@@ -2,17 +2,88 @@ package bernstein
 
 import (
 	"context"
+	"fmt"
 	"github.com/herohde/morlock/pkg/board"
 	"github.com/herohde/morlock/pkg/search"
+	"github.com/seekerror/logw"
+	"sync/atomic"
 )
 
 type PlausibleMoveTable struct {
 	Limit int
+	// Widening, if set, are the limits by remaining depth starting at depth 1, so that fewer
+	// moves can be explored near the leaves. Greater depths use Limit. The root of shallow
+	// iterative deepening searches is also limited by depth. Experimental.
+	Widening []int
+	// SEE uses static exchange evaluation for rules 2a-2c instead of single-attack checks.
+	SEE bool
+	// Stats, if set, tracks how often SEE changes the candidate set.
+	Stats *SEEStats
 }
 
-func (p PlausibleMoveTable) Explore(ctx context.Context, b *board.Board) (board.MovePriorityFn, board.MovePredicateFn) {
-	pmt := FindPlausibleMoves(b)
-	return search.Selection(truncate(pmt, p.Limit))
+func (p PlausibleMoveTable) Explore(ctx context.Context, b *board.Board, depth int) (board.MovePriorityFn, board.MovePredicateFn) {
+	limit := p.limit(depth)
+	if !p.SEE {
+		pmt := FindPlausibleMoves(b)
+		return search.Selection(truncate(pmt, limit))
+	}
+
+	pmt := truncate(FindPlausibleMovesWithSEE(b), limit)
+	if p.Stats != nil {
+		base := truncate(FindPlausibleMoves(b), limit)
+		if p.Stats.record(base, pmt) {
+			logw.Debugf(ctx, "SEE changed candidates for %v: %v -> %v", b.Position(), board.PrintMoves(base), board.PrintMoves(pmt))
+		}
+	}
+	return search.Selection(pmt)
+}
+
+func (p PlausibleMoveTable) limit(depth int) int {
+	if depth > 0 && depth <= len(p.Widening) {
+		return p.Widening[depth-1]
+	}
+	return p.Limit
+}
+
+// SEEStats tracks how often SEE changes the plausible move candidate set. Thread-safe.
+type SEEStats struct {
+	total, changed atomic.Uint64
+}
+
+// Total returns the number of candidate sets compared.
+func (s *SEEStats) Total() uint64 {
+	return s.total.Load()
+}
+
+// Changed returns the number of candidate sets changed by SEE.
+func (s *SEEStats) Changed() uint64 {
+	return s.changed.Load()
+}
+
+func (s *SEEStats) String() string {
+	return fmt.Sprintf("SEE changed %v/%v candidate sets", s.Changed(), s.Total())
+}
+
+func (s *SEEStats) record(base, see []board.Move) bool {
+	s.total.Add(1)
+
+	changed := len(base) != len(see)
+	if !changed {
+		set := map[board.Move]bool{}
+		for _, m := range base {
+			set[m] = true
+		}
+		for _, m := range see {
+			if !set[m] {
+				changed = true
+				break
+			}
+		}
+	}
+	if changed {
+		s.changed.Add(1)
+	}
+	return changed
 }
 
 func truncate[T any](list []T, limit int) []T {
@@ -49,6 +120,47 @@ func truncate[T any](list []T, limit int) []T {
 // As a special case, the initial position generates center pawn moves even
 // tough all pawn moves are otherwise considered equally.
 func FindPlausibleMoves(b *board.Board) []board.Move {
+	moves, _ := findPlausibleMoves(b, false)
+	return moves
+}
+
+// FindPlausibleMovesWithSEE returns the plausible moves like FindPlausibleMoves, except it
+// uses static exchange evaluation to determine material gain, loss or exchange in rule 2.
+func FindPlausibleMovesWithSEE(b *board.Board) []board.Move {
+	moves, _ := findPlausibleMoves(b, true)
+	return moves
+}
+
+// Rule returns the plausible move rule that selects the given move, such as "2a" for
+// material gain. Returns the empty string if the move is not plausible.
+func (p PlausibleMoveTable) Rule(ctx context.Context, b *board.Board, move board.Move) string {
+	moves, rank := findPlausibleMoves(b, p.SEE)
+	for _, m := range truncate(moves, p.Limit) {
+		if m != move {
+			continue
+		}
+		if rank == nil {
+			return "1"
+		}
+		return rules[rank[m]]
+	}
+	return ""
+}
+
+// rules map rule priority to the plausible move question.
+var rules = map[board.MovePriority]string{
+	23: "2a",
+	22: "2b",
+	21: "2c",
+	20: "3",
+	13: "4",
+	12: "5",
+	11: "6",
+	10: "7",
+	1:  "8",
+}
+
+func findPlausibleMoves(b *board.Board, see bool) ([]board.Move, map[board.Move]board.MovePriority) {
 	pos := b.Position()
 	side := b.Turn()
 
@@ -56,13 +168,18 @@ func FindPlausibleMoves(b *board.Board) []board.Move {
 	// Table 1 presentation, it is not clear whether the program can handle 2 queens at all.
 	// Also no explicit allowance for mating moves.
 
-	moves := board.FindMoves(pos.LegalMoves(side), board.Move.IsNotUnderPromotion)
+	legal, checked := pos.EvasionMoves(side)
+	if !checked {
+		legal = pos.LegalMoves(side)
+	}
+
+	moves := board.FindMoves(legal, board.Move.IsNotUnderPromotion)
 	board.SortByPriority(moves, TA1(side)) // square order
 	board.SortByPriority(moves, Table1)    // center pawn preference
 
 	//	(1) Is the King in check?
 
-	if pos.IsChecked(side) {
+	if checked {
 		// All legal moves necessarily gets out of check. Preference: capture, block then flee.
 
 		fn := func(move board.Move) board.MovePriority {
@@ -76,7 +193,7 @@ func FindPlausibleMoves(b *board.Board) []board.Move {
 			}
 		}
 		board.SortByPriority(moves, fn)
-		return moves
+		return moves, nil
 	}
 
 	//	(2) Can material be gained, lost or exchanged?
@@ -103,6 +220,21 @@ func FindPlausibleMoves(b *board.Board) []board.Move {
 		return move.Type == board.Capture && MaterialValue(move.Capture) == MaterialValue(move.Piece)
 	}
 
+	if see {
+		gain = func(move board.Move) bool {
+			if move.IsPromotion() {
+				return true
+			}
+			return move.IsCaptureOrEnPassant() && SEE(pos, side, move) > 0
+		}
+		loss = func(move board.Move) bool {
+			return Threatened(pos, side, move.Piece, move.From) > 0 && SEE(pos, side, move) >= 0
+		}
+		exchange = func(move board.Move) bool {
+			return move.IsCaptureOrEnPassant() && SEE(pos, side, move) == 0
+		}
+	}
+
 	rank := map[board.Move]board.MovePriority{}
 	castle := false
 
@@ -130,7 +262,7 @@ func FindPlausibleMoves(b *board.Board) []board.Move {
 		board.SortByPriority(moves, func(move board.Move) board.MovePriority {
 			return rank[move]
 		})
-		return moves
+		return moves, rank
 	}
 
 	//	(4) Can minor pieces be developed?
@@ -194,7 +326,7 @@ func FindPlausibleMoves(b *board.Board) []board.Move {
 	board.SortByPriority(moves, func(move board.Move) board.MovePriority {
 		return rank[move]
 	})
-	return moves
+	return moves, rank
 }
 
 // TA1 captures the board representation table (TA1) bias towards the opponent end (from H7
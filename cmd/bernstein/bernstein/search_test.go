@@ -1,6 +1,7 @@
 package bernstein_test
 
 import (
+	"context"
 	"github.com/herohde/morlock/cmd/bernstein/bernstein"
 	"github.com/herohde/morlock/pkg/board"
 	"github.com/herohde/morlock/pkg/board/fen"
@@ -32,3 +33,66 @@ func TestFindPlausibleMoves(t *testing.T) {
 		assert.Equal(t, tt.expected, board.PrintMoves(actual[:tt.limit]), "board: %v", b)
 	}
 }
+
+func TestPlausibleMoveTableRule(t *testing.T) {
+	tests := []struct {
+		pos      string
+		move     string
+		expected string
+	}{
+		{fen.Initial, "b1c3", "4"},
+		{fen.Initial, "e2e4", "7"},
+		{fen.Initial, "a2a3", ""}, // beyond limit
+		{"r1bqk2r/pppp1ppp/2nbpn2/6B1/3P4/2PB1N2/PP3PPP/RN1Q1RK1 b kq - 5 7", "e8g8", "3"},
+		{"4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 1", "e4d5", "2a"},
+		{"4k3/8/8/8/8/8/3q4/3QK3 w - - 0 1", "e1d2", "1"},
+	}
+
+	pmt := bernstein.PlausibleMoveTable{Limit: 7}
+	for _, tt := range tests {
+		b, err := fen.NewBoard(tt.pos)
+		require.NoError(t, err)
+
+		candidate, err := board.ParseMove(tt.move)
+		require.NoError(t, err)
+
+		var move board.Move
+		for _, m := range b.Position().LegalMoves(b.Turn()) {
+			if m.Equals(candidate) {
+				move = m
+			}
+		}
+		assert.Equal(t, tt.expected, pmt.Rule(context.Background(), b, move), "move %v: %v", tt.move, b)
+	}
+}
+
+func TestPlausibleMoveTableWidening(t *testing.T) {
+	ctx := context.Background()
+
+	b, err := fen.NewBoard(fen.Initial)
+	require.NoError(t, err)
+
+	tests := []struct {
+		depth    int
+		expected int
+	}{
+		{0, 7},
+		{1, 3},
+		{2, 5},
+		{3, 7},
+		{4, 7},
+	}
+
+	pmt := bernstein.PlausibleMoveTable{Limit: 7, Widening: []int{3, 5}}
+	for _, tt := range tests {
+		_, pick := pmt.Explore(ctx, b, tt.depth)
+
+		n := 0
+		for _, m := range b.Position().LegalMoves(b.Turn()) {
+			if pick(m) {
+				n++
+			}
+		}
+		assert.Equal(t, tt.expected, n, "depth %v", tt.depth)
+	}
+}
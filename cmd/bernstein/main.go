@@ -5,21 +5,32 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"github.com/herohde/morlock"
 	"github.com/herohde/morlock/cmd/bernstein/bernstein"
 	"github.com/herohde/morlock/pkg/engine"
-	"github.com/herohde/morlock/pkg/engine/console"
+	"github.com/herohde/morlock/pkg/engine/cli"
 	"github.com/herohde/morlock/pkg/engine/uci"
+	"github.com/herohde/morlock/pkg/eval"
 	"github.com/herohde/morlock/pkg/search"
 	"github.com/seekerror/logw"
 	"os"
-	"time"
+	"strconv"
+	"strings"
 )
 
 var (
-	ply      = flag.Uint("ply", 4, "Search depth limit (zero if no limit)")
-	branch   = flag.Int("branch", 7, "Search branch factor limit (zero if no limit)")
-	material = flag.Int("material", 20, "Material evaluation multiplier")
-	noise    = flag.Uint("noise", 0, "Evaluation noise in \"millipawns\" (zero if deterministic)")
+	ply       = flag.Uint("ply", 4, "Search depth limit (zero if no limit)")
+	branch    = flag.Int("branch", 7, "Search branch factor limit (zero if no limit)")
+	widening  = flag.String("widening", "", "Comma-separated branch factor limits by remaining depth from depth 1, such as \"3,5\" (empty if -branch at all depths)")
+	material  = flag.Int("material", 20, "Material evaluation multiplier")
+	noise     = flag.Uint("noise", 0, "Evaluation noise in \"millipawns\" (zero if deterministic)")
+	seed      = flag.Int64("seed", 0, "Random seed for noise and book choices (zero if new seed per game)")
+	see       = flag.Bool("see", false, "Use static exchange evaluation for plausible move rules 2a-2c")
+	swindle   = flag.Uint("swindle", 0, "Swindle when lost, modeling the opponent at the given ply (zero if disabled)")
+	opponent  = flag.String("opponent", "", "Opponent model for -swindle: morlock, turochamp, sargon or bernstein (this engine if empty)")
+	stalemate = flag.Float64("stalemate", 0, "Seek immediate draws, such as stalemate, when this many pawns behind (zero if disabled)")
+	static    = flag.Bool("static", false, "Play the move with the best static evaluation without search, to isolate evaluation from search (not BERNSTEIN)")
+	kingsafe  = flag.Bool("kingsafety", false, "Add king safety evaluation (not BERNSTEIN)")
 )
 
 func init() {
@@ -42,33 +53,72 @@ func main() {
 
 	logw.Infof(ctx, "BERNSTEIN 1957 chess engine (%v ply, %v-branch limit)", *ply, *branch)
 
+	limits, err := parseLimits(*widening)
+	if err != nil {
+		logw.Exitf(ctx, "Invalid widening '%v': %v", *widening, err)
+	}
+
+	stats := &bernstein.SEEStats{}
+	pmt := bernstein.PlausibleMoveTable{Limit: *branch, Widening: limits, SEE: *see, Stats: stats}
+	ev := &bernstein.Eval{Factor: *material}
+	var evaluator eval.Evaluator = ev
+	if *kingsafe {
+		evaluator = eval.Sum{ev, eval.NewKingSafety()}
+	}
+
 	s := search.AlphaBeta{
-		Explore: bernstein.PlausibleMoveTable{Limit: *branch}.Explore,
+		Explore: pmt.Explore,
 		Eval: search.Leaf{
-			Eval: bernstein.Eval{Factor: *material},
+			Eval: evaluator,
 		},
 	}
 
-	e := engine.New(ctx, "BERNSTEIN (1957)", "Alex Bernstein, Michael de V. Roberts, Timothy Arbuckle and Martin Belsky", s,
-		engine.WithOptions(engine.Options{Depth: *ply, Noise: *noise}),
-	)
+	var root search.Search = s
+	if *swindle > 0 {
+		var model search.Search = s
+		if *opponent != "" {
+			e, ok := morlock.New(ctx, *opponent)
+			if !ok {
+				logw.Exitf(ctx, "Invalid opponent '%v'", *opponent)
+			}
+			model = e.Root()
+		}
+		root = search.Swindle{Eval: s, Opponent: model, OpponentDepth: int(*swindle)}
+	}
+	if *stalemate > 0 {
+		root = search.Stalemate{Eval: root, Threshold: eval.Pawns(*stalemate)}
+	}
+	if *static {
+		root = search.Static{Eval: search.Leaf{Eval: evaluator}}
+	}
 
-	in := engine.ReadStdinLines(ctx)
-	switch <-in {
-	case uci.ProtocolName:
-		driver, out := uci.NewDriver(ctx, e, in, uci.UseBook(bernstein.NewBook(), time.Now().UnixNano()))
-		go engine.WriteStdoutLines(ctx, out)
+	e := engine.New(ctx, "BERNSTEIN (1957)", "Alex Bernstein, Michael de V. Roberts, Timothy Arbuckle and Martin Belsky", root,
+		engine.WithOptions(engine.Options{Depth: *ply, Noise: *noise, Seed: *seed}),
+		engine.WithAttribution(pmt.Rule),
+		engine.WithTunable(ev),
+		engine.WithEvaluator(evaluator),
+	)
 
-		<-driver.Closed()
+	cli.Run(ctx, e, cli.WithUCI(uci.UseBook(bernstein.NewBook())))
 
-	case console.ProtocolName:
-		driver, out := console.NewDriver(ctx, e, s, in)
-		go engine.WriteStdoutLines(ctx, out)
+	if *see {
+		logw.Infof(ctx, "%v", stats)
+	}
+}
 
-		<-driver.Closed()
+// parseLimits parses a comma-separated list of branch factor limits.
+func parseLimits(str string) ([]int, error) {
+	if str == "" {
+		return nil, nil
+	}
 
-	default:
-		flag.Usage()
-		logw.Exitf(ctx, "Protocol not supported")
+	var ret []int
+	for _, part := range strings.Split(str, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid limit: '%v'", part)
+		}
+		ret = append(ret, n)
 	}
+	return ret, nil
 }
@@ -0,0 +1,126 @@
+// bookgen is a tool for building opening books from PGN game collections.
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"github.com/herohde/morlock/pkg/board/pgn"
+	"github.com/herohde/morlock/pkg/engine"
+	"github.com/seekerror/logw"
+	"io"
+	"os"
+)
+
+var (
+	depth  = flag.Int("depth", 16, "Maximum number of plies per game (zero if no limit)")
+	games  = flag.Int("games", 2, "Minimum number of games for a move")
+	score  = flag.Float64("score", 0.4, "Minimum average score in [0;1] for a move")
+	format = flag.String("format", "line", "Output format: line or polyglot")
+	keys   = flag.String("keys", "", "File with 781 hexadecimal Polyglot random keys for polyglot (default: standard keys)")
+	output = flag.String("out", "", "Output file (default to stdout)")
+)
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, `usage: bookgen [options] <file.pgn> ...
+
+BOOKGEN builds an opening book from PGN game collections. Games that do not start
+from the initial position or do not have a result are ignored. The "line" format
+prints one opening line per row, such as "e2e4 e7e5 g1f3".
+Options:
+`)
+		flag.PrintDefaults()
+	}
+}
+
+func main() {
+	flag.Parse()
+	ctx := context.Background()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		logw.Exitf(ctx, "No PGN files")
+	}
+
+	opt := engine.BookOptions{MaxDepth: *depth, MinGames: *games, MinScore: *score}
+	tree := engine.NewBookTree(opt)
+
+	added, total := 0, 0
+	for _, filename := range flag.Args() {
+		list, err := readGames(filename)
+		if err != nil {
+			logw.Exitf(ctx, "Failed to read %v: %v", filename, err)
+		}
+		for _, g := range list {
+			ok, err := tree.Add(g)
+			if err != nil {
+				logw.Exitf(ctx, "Invalid game %v in %v: %v", g, filename, err)
+			}
+			if ok {
+				added++
+			}
+			total++
+		}
+	}
+	logw.Infof(ctx, "Added %v of %v games, options=%v: %v positions", added, total, opt, tree.Size())
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			logw.Exitf(ctx, "Failed to create %v: %v", *output, err)
+		}
+		defer f.Close()
+		w = f
+	}
+	bw := bufio.NewWriter(w)
+
+	switch *format {
+	case "line":
+		lines := tree.Lines()
+		for _, l := range lines {
+			_, _ = fmt.Fprintln(bw, l)
+		}
+		logw.Infof(ctx, "Wrote %v lines", len(lines))
+
+	case "polyglot":
+		table := &engine.StandardPolyglotKeys
+		if *keys != "" {
+			f, err := os.Open(*keys)
+			if err != nil {
+				logw.Exitf(ctx, "Failed to open %v: %v", *keys, err)
+			}
+			table, err = engine.ReadPolyglotKeys(f)
+			_ = f.Close()
+			if err != nil {
+				logw.Exitf(ctx, "Invalid keys %v: %v", *keys, err)
+			}
+		}
+
+		entries := tree.PolyglotEntries(table)
+		if err := engine.WritePolyglot(bw, entries); err != nil {
+			logw.Exitf(ctx, "Failed to write book: %v", err)
+		}
+		logw.Infof(ctx, "Wrote %v entries", len(entries))
+
+	default:
+		flag.Usage()
+		logw.Exitf(ctx, "Format not supported: %v", *format)
+	}
+
+	if err := bw.Flush(); err != nil {
+		logw.Exitf(ctx, "Failed to write book: %v", err)
+	}
+}
+
+func readGames(filename string) ([]pgn.Game, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return pgn.Read(f)
+}
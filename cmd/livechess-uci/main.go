@@ -5,24 +5,30 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"github.com/herohde/livechess-go/pkg/livechess"
 	"github.com/herohde/morlock/pkg/board"
 	"github.com/herohde/morlock/pkg/board/fen"
 	"github.com/herohde/morlock/pkg/engine"
-	"github.com/herohde/morlock/pkg/engine/uci"
+	"github.com/herohde/morlock/pkg/engine/cli"
+	"github.com/herohde/morlock/pkg/engine/console"
 	"github.com/herohde/morlock/pkg/eval"
 	"github.com/herohde/morlock/pkg/search"
+	"github.com/herohde/morlock/pkg/search/searchctl"
 	"github.com/seekerror/logw"
+	"github.com/seekerror/stdlib/pkg/lang"
 	"github.com/seekerror/stdlib/pkg/util/iox"
 	"strings"
 	"sync/atomic"
+	"time"
 )
 
 // TODO(herohde) 12/16/2023: change engine to interface. Protocol seems brittle with setup otherwise.
 
 var (
-	serial = flag.String("serial", "auto", "Board selection by serial number (default: auto)")
-	flip   = flag.Bool("flip", false, "Flip board")
+	serial   = flag.String("serial", "auto", "Board selection by serial number (default: auto)")
+	flip     = flag.Bool("flip", false, "Flip board")
+	watchdog = flag.Duration("watchdog", 0, "Log searches that make no progress for this duration, with goroutine stacks (zero if disabled)")
 )
 
 func main() {
@@ -54,29 +60,73 @@ func main() {
 	s := newAdaptor(ctx, client, events)
 
 	e := engine.New(ctx, "livechess-uci", "herohde", s,
-		engine.WithOptions(engine.Options{Depth: 1}))
+		engine.WithOptions(engine.Options{Depth: 1}),
+		engine.WithWatchdog(searchctl.Watchdog{Timeout: *watchdog, Stacks: true}))
 
-	in := engine.ReadStdinLines(ctx)
-	switch <-in {
-	case uci.ProtocolName:
-		driver, out := uci.NewDriver(ctx, e, in)
-		go engine.WriteStdoutLines(ctx, out)
-
-		<-driver.Closed()
-
-	default:
-		flag.Usage()
-		logw.Exitf(ctx, "Protocol not supported")
-	}
+	cli.Run(ctx, e, cli.WithConsole(console.UsePositionSource(s)))
 }
 
 type adaptor struct {
 	client livechess.FeedClient
 
 	last  atomic.Pointer[livechess.EBoardEventResponse] // last with start and move list
+	board atomic.Pointer[string]                        // last board piece placement
+	clock atomic.Pointer[clock]                         // last clock state, if a clock is present
 	pulse *iox.Pulse
 }
 
+// clock is the state of a DGT clock attached to the board.
+type clock struct {
+	white, black time.Duration
+	run          lang.Optional[board.Color] // running side, if any
+	received     time.Time
+}
+
+func newClock(resp livechess.ClockResponse) *clock {
+	ret := &clock{
+		white:    time.Duration(resp.White) * time.Second,
+		black:    time.Duration(resp.Black) * time.Second,
+		received: time.Now(),
+	}
+	if resp.Run != nil {
+		if *resp.Run {
+			ret.run = lang.Some(board.White)
+		} else {
+			ret.run = lang.Some(board.Black)
+		}
+	}
+	return ret
+}
+
+// Remaining returns the remaining time of the given color, including the time elapsed on a
+// running clock since the state was received.
+func (c *clock) Remaining(color board.Color) time.Duration {
+	ret := c.white
+	if color == board.Black {
+		ret = c.black
+	}
+	if run, ok := c.run.V(); ok && run == color {
+		ret -= time.Since(c.received)
+	}
+	if ret < 0 {
+		return 0
+	}
+	return ret
+}
+
+func (c *clock) String() string {
+	ret := fmt.Sprintf("white %v black %v", c.Remaining(board.White).Round(time.Second), c.Remaining(board.Black).Round(time.Second))
+	if run, ok := c.run.V(); ok {
+		return fmt.Sprintf("%v, %v running", ret, run)
+	}
+	return fmt.Sprintf("%v, stopped", ret)
+}
+
+// TimeControl returns the clock state as UCI time fields.
+func (c *clock) TimeControl() searchctl.TimeControl {
+	return searchctl.TimeControl{White: c.Remaining(board.White), Black: c.Remaining(board.Black)}
+}
+
 func newAdaptor(ctx context.Context, client livechess.FeedClient, events <-chan livechess.EBoardEventResponse) *adaptor {
 	ret := &adaptor{
 		client: client,
@@ -86,6 +136,12 @@ func newAdaptor(ctx context.Context, client livechess.FeedClient, events <-chan
 	return ret
 }
 
+// Capabilities declares that the adaptor waits for the move on the board, so it cannot
+// ponder or be cached. The player uses the remaining time on the clock as needed.
+func (a *adaptor) Capabilities() search.Capabilities {
+	return search.Capabilities{NoTT: true, NoPonder: true, OwnTime: true}
+}
+
 func (a *adaptor) Search(ctx context.Context, sctx *search.Context, b *board.Board, depth int) (uint64, eval.Score, []board.Move, error) {
 	// start := fen.Encode(b.Position(), b.Turn(), b.NoProgress(), b.FullMoves())
 
@@ -106,24 +162,73 @@ func (a *adaptor) Search(ctx context.Context, sctx *search.Context, b *board.Boa
 		return 1, eval.ZeroScore, nil, nil
 	}
 
-	// (2) Wait for a board match one of them
+	// (2) Wait for a board match one of them. The player loses on time once the flag falls
+	// on the DGT clock, if running for the player, or the remaining time of the UCI time
+	// fields runs out. Each new clock state is reported to the user, such as to the GUI.
 
+	var reported *clock
 	for {
+		if c := a.clock.Load(); c != nil && c != reported && sctx.Info != nil {
+			sctx.Info(fmt.Sprintf("clock %v", c))
+			reported = c
+		}
 		if last := a.last.Load(); last != nil {
 			if m, ok := candidates[last.Board]; ok {
+				if c := a.clock.Load(); c != nil {
+					logw.Infof(ctx, "Board move %v, clock %v", m, c.TimeControl())
+				}
 				return 1, eval.ZeroScore, []board.Move{m}, nil
 			}
 		}
 
+		flag, stop := a.flag(sctx.Deadline, b.Turn())
+
 		select {
 		case <-a.pulse.Chan():
 			// ok: try again
+			stop()
+		case <-flag:
+			if c := a.clock.Load(); c != nil {
+				logw.Infof(ctx, "Out of time, clock %v", c.TimeControl())
+			} else {
+				logw.Infof(ctx, "Out of time")
+			}
+			return 0, eval.InvalidScore, nil, search.ErrHalted
 		case <-ctx.Done():
+			stop()
 			return 0, eval.InvalidScore, nil, search.ErrHalted
 		}
 	}
 }
 
+// flag returns a channel that fires when the player of the given color runs out of time,
+// if limited, and a function to stop it. The limit is the deadline, if any, or the flag on
+// the DGT clock, if running for the player, whichever is first.
+func (a *adaptor) flag(deadline time.Time, turn board.Color) (<-chan time.Time, func()) {
+	if c := a.clock.Load(); c != nil {
+		if run, ok := c.run.V(); ok && run == turn {
+			if flag := time.Now().Add(c.Remaining(run)); deadline.IsZero() || flag.Before(deadline) {
+				deadline = flag
+			}
+		}
+	}
+	if deadline.IsZero() {
+		return nil, func() {}
+	}
+
+	timer := time.NewTimer(time.Until(deadline))
+	return timer.C, func() { timer.Stop() }
+}
+
+// Position returns the piece placement on the board, such as a diagram set up by hand. The
+// side to move and other fields are not known.
+func (a *adaptor) Position(ctx context.Context, args ...string) (string, error) {
+	if placement := a.board.Load(); placement != nil {
+		return *placement, nil
+	}
+	return "", fmt.Errorf("no board position")
+}
+
 func (a *adaptor) process(ctx context.Context, events <-chan livechess.EBoardEventResponse) {
 	for {
 		select {
@@ -132,6 +237,14 @@ func (a *adaptor) process(ctx context.Context, events <-chan livechess.EBoardEve
 				return
 			}
 
+			if event.Board != "" {
+				placement := event.Board
+				a.board.Store(&placement)
+			}
+			if event.Clock != nil {
+				a.clock.Store(newClock(*event.Clock))
+				a.pulse.Emit()
+			}
 			if len(event.San) > 0 {
 				a.last.Store(&event)
 				a.pulse.Emit()
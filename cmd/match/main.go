@@ -0,0 +1,261 @@
+// match is a tool for playing tournaments between the morlock engines.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/herohde/morlock"
+	"github.com/herohde/morlock/cmd/bernstein/bernstein"
+	"github.com/herohde/morlock/cmd/sargon/sargon"
+	"github.com/herohde/morlock/cmd/turochamp/turochamp"
+	"github.com/herohde/morlock/pkg/board/epd"
+	"github.com/herohde/morlock/pkg/board/pgn"
+	"github.com/herohde/morlock/pkg/engine"
+	"github.com/herohde/morlock/pkg/engine/gametest"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/match"
+	"github.com/seekerror/logw"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var (
+	engines     = flag.String("engines", "morlock,turochamp,sargon,bernstein", "Comma-separated engines: morlock, turochamp, sargon, bernstein or the reference engines random, material, turochamp-static, sargon-static or bernstein-static")
+	white       = flag.String("white", "", "Engine for White of a color-split engine, which plays first against the others (requires -black)")
+	black       = flag.String("black", "", "Engine for Black of a color-split engine (requires -white)")
+	mode        = flag.String("mode", "roundrobin", "Tournament mode: roundrobin or gauntlet (first engine against the others)")
+	rounds      = flag.Int("rounds", 1, "Number of rounds, where each pair plays a game with each color per round")
+	concurrency = flag.Int("concurrency", 1, "Number of games played at the same time")
+	tc          = flag.Duration("time", 0, "Clock time per side, such as 1m (zero if each engine searches to its depth)")
+	inc         = flag.Duration("inc", 0, "Clock increment per move (requires -time)")
+	depth       = flag.Uint("depth", 0, "Search depth limit per move (zero if engine default or no limit with -time)")
+	openings    = flag.String("openings", "", "Opening suite in EPD or PGN format, by file extension (default to the initial position)")
+	plies       = flag.Int("plies", 8, "Maximum number of opening plies from PGN games (zero if no limit)")
+	output      = flag.String("pgn", "", "PGN output file for the games (disabled if empty)")
+	event       = flag.String("event", "morlock match", "PGN event name")
+	resign      = flag.Float64("resign", 0, "Adjudicate a win if both engines agree on a score of at least this many pawns (zero if disabled)")
+	resignMoves = flag.Int("resignmoves", 3, "Consecutive moves per side for win adjudication (requires -resign)")
+	draw        = flag.Float64("draw", 0, "Adjudicate a draw if both engines agree on a score within this many pawns of zero (zero if disabled)")
+	drawMoves   = flag.Int("drawmoves", 8, "Consecutive moves per side for draw adjudication (requires -draw)")
+	drawAfter   = flag.Int("drawafter", 40, "Moves before draw adjudication (requires -draw)")
+	maxMoves    = flag.Int("maxmoves", 200, "Adjudicate a draw after this many moves (zero if no limit)")
+	stats       = flag.String("json", "", "JSON output file for the Elo and LOS statistics (disabled if empty)")
+	results     = flag.String("results", "", "Print statistics for the games in this PGN file instead of playing (disabled if empty)")
+)
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, `usage: match [options]
+
+MATCH plays a tournament between the morlock engines, such as a round robin
+between all engines or a gauntlet for the first engine. Engines play with their
+default configuration. The reference engines are anchors for calibrating strength:
+a random mover, material-only morlock at 4 ply and the static evaluation of each
+historical engine without search. A color-split engine, given by -white and -black,
+plays White with one engine and Black with another, such as to measure the effect
+of a rule interpretation on one side. It is the first engine and alternates colors
+like any other. The Elo difference, error margin and likelihood of
+superiority (LOS) of each engine are printed when all games are played.
+Options:
+`)
+		flag.PrintDefaults()
+	}
+}
+
+func main() {
+	flag.Parse()
+	ctx := context.Background()
+
+	if *results != "" {
+		f, err := os.Open(*results)
+		if err != nil {
+			logw.Exitf(ctx, "Failed to open %v: %v", *results, err)
+		}
+		games, err := pgn.Read(f)
+		_ = f.Close()
+		if err != nil {
+			logw.Exitf(ctx, "Failed to read %v: %v", *results, err)
+		}
+		report(ctx, match.GamesFromPGN(games))
+		return
+	}
+
+	var players []match.Player
+	if *white != "" || *black != "" {
+		w, ok := player(*white)
+		if !ok {
+			flag.Usage()
+			logw.Exitf(ctx, "White engine not supported: %v", *white)
+		}
+		b, ok := player(*black)
+		if !ok {
+			flag.Usage()
+			logw.Exitf(ctx, "Black engine not supported: %v", *black)
+		}
+		players = append(players, match.ByColor(w, b))
+	}
+	for _, name := range strings.Split(*engines, ",") {
+		p, ok := player(strings.TrimSpace(name))
+		if !ok {
+			flag.Usage()
+			logw.Exitf(ctx, "Engine not supported: %v", name)
+		}
+		players = append(players, p)
+	}
+	if len(players) < 2 {
+		logw.Exitf(ctx, "At least 2 engines are needed: %v", *engines)
+	}
+	if *rounds < 1 {
+		logw.Exitf(ctx, "Invalid number of rounds: %v", *rounds)
+	}
+
+	var suite []match.Opening
+	if *openings != "" {
+		list, err := readOpenings(*openings, *plies)
+		if err != nil {
+			logw.Exitf(ctx, "Failed to read openings %v: %v", *openings, err)
+		}
+		if len(list) == 0 {
+			logw.Exitf(ctx, "No openings in %v", *openings)
+		}
+		suite = list
+	}
+
+	var pairings []match.Pairing
+	switch *mode {
+	case "roundrobin":
+		pairings = match.RoundRobin(len(players), *rounds, suite)
+	case "gauntlet":
+		pairings = match.Gauntlet(len(players), *rounds, suite)
+	default:
+		flag.Usage()
+		logw.Exitf(ctx, "Mode not supported: %v", *mode)
+	}
+
+	t := match.Tournament{
+		Players:  players,
+		Pairings: pairings,
+		Limits:   match.Limits{Time: *tc, Increment: *inc, Depth: *depth},
+		Rules: match.Rules{
+			WinScore:     eval.Pawns(*resign),
+			WinMoves:     *resignMoves,
+			DrawScore:    eval.Pawns(*draw),
+			DrawMoves:    *drawMoves,
+			DrawMinMoves: *drawAfter,
+			MaxMoves:     *maxMoves,
+		},
+		Concurrency: *concurrency,
+	}
+
+	var w *bufio.Writer
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			logw.Exitf(ctx, "Failed to create %v: %v", *output, err)
+		}
+		defer f.Close()
+		w = bufio.NewWriter(f)
+	}
+
+	logw.Infof(ctx, "Playing %v games, limits=%v, rules=%v", len(pairings), t.Limits, t.Rules)
+
+	start := time.Now()
+	done := 0
+	games := t.Run(ctx, func(p match.Pairing, g match.Game) {
+		done++
+		logw.Infof(ctx, "Game %v/%v, round %v: %v", done, len(pairings), p.Round, g)
+
+		if w == nil {
+			return
+		}
+		str, err := pgn.Encode(g.PGN(*event, p.Round))
+		if err != nil {
+			logw.Errorf(ctx, "Failed to encode game %v: %v", g, err)
+			return
+		}
+		_, _ = fmt.Fprintln(w, str)
+		_ = w.Flush()
+	})
+
+	logw.Infof(ctx, "Played %v games in %v", len(games), time.Since(start).Round(time.Second))
+	report(ctx, games)
+}
+
+// report prints the statistics of the games and writes them as JSON, if enabled.
+func report(ctx context.Context, games []match.Game) {
+	r := match.NewReport(games)
+	fmt.Print(r)
+
+	if *stats == "" {
+		return
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		logw.Exitf(ctx, "Failed to encode statistics: %v", err)
+	}
+	if err := os.WriteFile(*stats, data, 0644); err != nil {
+		logw.Exitf(ctx, "Failed to write %v: %v", *stats, err)
+	}
+}
+
+// player returns the engine of the given name as a player. The reference engines are
+// calibrated anchors for strength measurements: the random mover, material-only morlock and
+// the evaluations of the historical engines without search.
+func player(name string) (match.Player, bool) {
+	var fn func(ctx context.Context) *engine.Engine
+	switch name {
+	case "morlock", "turochamp", "sargon", "bernstein":
+		fn = func(ctx context.Context) *engine.Engine {
+			e, _ := morlock.New(ctx, name)
+			return e
+		}
+	case "random":
+		fn = func(ctx context.Context) *engine.Engine { return gametest.Random(time.Now().UnixNano()).New(ctx) }
+	case "material":
+		fn = gametest.Material(4).New
+	case "turochamp-static":
+		fn = gametest.Static("turochamp", turochamp.Eval{}).New
+	case "sargon-static":
+		fn = func(ctx context.Context) *engine.Engine {
+			return gametest.Static("sargon", sargon.Static{Points: &sargon.Points{}}).New(ctx)
+		}
+	case "bernstein-static":
+		fn = func(ctx context.Context) *engine.Engine {
+			return gametest.Static("bernstein", &bernstein.Eval{Factor: 20}).New(ctx)
+		}
+	default:
+		return match.Player{}, false
+	}
+	return match.Player{Name: name, New: fn}, true
+}
+
+// readOpenings reads an opening suite in EPD or PGN format, by file extension.
+func readOpenings(filename string, plies int) ([]match.Opening, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".epd":
+		records, err := epd.Read(f)
+		if err != nil {
+			return nil, err
+		}
+		return match.OpeningsFromEPD(records), nil
+	case ".pgn":
+		games, err := pgn.Read(f)
+		if err != nil {
+			return nil, err
+		}
+		return match.OpeningsFromPGN(games, plies), nil
+	default:
+		return nil, fmt.Errorf("format not supported: %v", filename)
+	}
+}
@@ -5,14 +5,39 @@ import (
 	"flag"
 	"fmt"
 	"github.com/herohde/morlock/pkg/engine"
-	"github.com/herohde/morlock/pkg/engine/console"
-	"github.com/herohde/morlock/pkg/engine/uci"
+	"github.com/herohde/morlock/pkg/engine/cli"
 	"github.com/herohde/morlock/pkg/eval"
 	"github.com/herohde/morlock/pkg/search"
+	"github.com/herohde/morlock/pkg/search/searchctl"
 	"github.com/seekerror/logw"
 	"os"
 )
 
+var (
+	evaluation = flag.String("evaluator", "pst", "Static evaluation: material or pst (tapered piece-square tables)")
+	pawns      = flag.Bool("pawns", true, "Evaluate doubled, isolated, backward and passed pawns")
+	pawnhash   = flag.Uint("pawnhash", 1, "Pawn structure hash size in MB (zero if disabled, requires -pawns)")
+	kingsafety = flag.Bool("kingsafety", true, "Evaluate king safety by pawn shield, open files and king zone attacks")
+	quiescence = flag.Bool("quiescence", false, "Use capture quiescence search at leaf nodes")
+	checks     = flag.Int("checks", 0, "Quiescence plies that also explore checking moves (requires -quiescence)")
+	qprobe     = flag.Bool("qprobe", false, "Probe the transposition table in quiescence search (requires -quiescence)")
+	qstore     = flag.Bool("qstore", false, "Store depth-0 quiescence results in the transposition table (requires -qprobe)")
+	standpat   = flag.Bool("standpat", true, "Stand-pat cutoffs with capture generation in quiescence search (requires -quiescence)")
+	see        = flag.Bool("see", true, "Prune losing captures by static exchange evaluation in quiescence search (requires -quiescence)")
+	delta      = flag.Float64("delta", 2, "Delta pruning safety margin in pawns in quiescence search (zero if disabled, requires -quiescence)")
+	memory     = flag.Uint("memory", 0, "Total memory budget in MB for all tables (zero if no budget)")
+	evalcache  = flag.Uint("evalcache", 0, "Evaluation cache size in MB (zero if disabled)")
+	seed       = flag.Int64("seed", 0, "Random seed for noise and book choices (zero if new seed per game)")
+	reuse      = flag.Bool("reuse", false, "Start the next search at a deeper depth if the game followed the last search")
+	brain      = flag.Bool("brain", false, "Think on the opponent's time after an engine move, independent of UCI ponder")
+	contempt   = flag.Int("contempt", 0, "Draw penalty in millipawns for the engine (negative if seeking draws)")
+	nullmove   = flag.Int("nullmove", 0, "Null-move pruning depth reduction (zero if disabled)")
+	watchdog   = flag.Duration("watchdog", 0, "Log searches that make no node progress for this duration (zero if disabled)")
+	stacks     = flag.Bool("stacks", false, "Also log goroutine stacks for stalled searches (requires -watchdog)")
+	mtdf       = flag.Bool("mtdf", false, "Use MTD(f) minimal window searches at the root")
+	aspiration = flag.Float64("aspiration", 0, "Aspiration window half-width in pawns around the previous iteration score (zero if disabled)")
+)
+
 func init() {
 	flag.Usage = func() {
 		fmt.Fprint(os.Stderr, `usage: morlock [options]
@@ -28,29 +53,69 @@ func main() {
 	flag.Parse()
 	ctx := context.Background()
 
-	s := search.AlphaBeta{
-		Eval: search.Leaf{Eval: eval.Material{}},
+	var opts []engine.Option
+
+	var evaluator eval.Evaluator
+	switch *evaluation {
+	case "material":
+		evaluator = eval.Material{}
+	case "pst":
+		evaluator = eval.PST{}
+	default:
+		logw.Exitf(ctx, "Invalid evaluation '%v'", *evaluation)
+	}
+	if *pawns {
+		var table *eval.PawnTable
+		if *pawnhash > 0 {
+			table = eval.NewPawnTable()
+			opts = append(opts, engine.WithAuxTable("pawnhash", table, *pawnhash))
+		}
+		evaluator = eval.Sum{evaluator, eval.NewPawnStructure(table)}
+	}
+	if *kingsafety {
+		evaluator = eval.Sum{evaluator, eval.NewKingSafety()}
+	}
+	if *evalcache > 0 {
+		cache := eval.NewCache(evaluator)
+		opts = append(opts, engine.WithAuxTable("evalcache", cache, *evalcache))
+		evaluator = cache
 	}
-	e := engine.New(ctx, "morlock", "herohde", s,
-		engine.WithOptions(engine.Options{Hash: 64}),
-		engine.WithTable(search.NewMinDepthTranspositionTable(1)))
+	leaf := search.Leaf{Eval: evaluator}
 
-	in := engine.ReadStdinLines(ctx)
-	switch <-in {
-	case uci.ProtocolName:
-		driver, out := uci.NewDriver(ctx, e, in)
-		go engine.WriteStdoutLines(ctx, out)
+	s := search.AlphaBeta{
+		Eval:     leaf,
+		NullMove: *nullmove,
+	}
+	if *quiescence {
+		s.Eval = search.Quiescence{
+			Explore:  search.CaptureExploration,
+			Eval:     leaf,
+			Checks:   *checks,
+			Probe:    *qprobe,
+			Store:    *qstore,
+			StandPat: *standpat,
+			SEE:      *see,
+			Delta:    eval.Pawns(*delta),
+		}
+	}
 
-		<-driver.Closed()
+	var root search.Search = s
+	if *mtdf {
+		root = search.MTDf{Eval: s}
+	}
 
-	case console.ProtocolName:
-		driver, out := console.NewDriver(ctx, e, s, in)
-		go engine.WriteStdoutLines(ctx, out)
+	factory := search.NewMinDepthTranspositionTable(1)
+	if *qstore {
+		factory = search.NewTranspositionTable // quiescence stores depth-0 entries
+	}
 
-		<-driver.Closed()
+	opts = append(opts,
+		engine.WithOptions(engine.Options{Hash: 64, Memory: *memory, Seed: *seed, Reuse: *reuse, Brain: *brain, Contempt: *contempt}),
+		engine.WithTable(factory),
+		engine.WithEvaluator(evaluator),
+		engine.WithAspiration(eval.Pawns(*aspiration)),
+		engine.WithWatchdog(searchctl.Watchdog{Timeout: *watchdog, Stacks: *stacks}))
+	e := engine.New(ctx, "morlock", "herohde", root, opts...)
 
-	default:
-		flag.Usage()
-		logw.Exitf(ctx, "Protocol not supported")
-	}
+	cli.Run(ctx, e)
 }
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"io"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// external is an external UCI engine that supports "go perft", such as Stockfish.
+type external struct {
+	cmd *exec.Cmd
+	in  io.WriteCloser
+	out *bufio.Scanner
+}
+
+// newExternal starts the external engine with the given command line and waits for it
+// to be ready.
+func newExternal(ctx context.Context, command string) (*external, error) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no command")
+	}
+
+	cmd := exec.CommandContext(ctx, fields[0], fields[1:]...)
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("command %v failed: %w", fields[0], err)
+	}
+
+	ret := &external{cmd: cmd, in: in, out: bufio.NewScanner(out)}
+	if err := ret.send("uci"); err != nil {
+		return nil, err
+	}
+	if _, err := ret.await("uciok"); err != nil {
+		return nil, err
+	}
+	if err := ret.send("isready"); err != nil {
+		return nil, err
+	}
+	if _, err := ret.await("readyok"); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Divide returns the perft counts by initial move in UCI notation of the position after
+// the given moves.
+func (e *external) Divide(position string, moves []string, depth int) (map[string]int64, error) {
+	cmd := fmt.Sprintf("position fen %v", position)
+	if len(moves) > 0 {
+		cmd = fmt.Sprintf("%v moves %v", cmd, strings.Join(moves, " "))
+	}
+	if err := e.send(cmd); err != nil {
+		return nil, err
+	}
+	if err := e.send(fmt.Sprintf("go perft %v", depth)); err != nil {
+		return nil, err
+	}
+	lines, err := e.await("Nodes searched")
+	if err != nil {
+		return nil, err
+	}
+
+	ret := map[string]int64{}
+	for _, line := range lines {
+		// Divide lines are of the form "e2e4: 20".
+
+		parts := strings.Split(line, ":")
+		if len(parts) != 2 {
+			continue
+		}
+		if _, err := board.ParseMove(strings.TrimSpace(parts[0])); err != nil {
+			continue
+		}
+		n, err := strconv.ParseInt(strings.TrimSpace(parts[1]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid divide line '%v': %v", line, err)
+		}
+		ret[strings.TrimSpace(parts[0])] = n
+	}
+	return ret, nil
+}
+
+// Close quits the external engine.
+func (e *external) Close() error {
+	_ = e.send("quit")
+	_ = e.in.Close()
+	return e.cmd.Wait()
+}
+
+func (e *external) send(line string) error {
+	_, err := fmt.Fprintln(e.in, line)
+	return err
+}
+
+// await reads lines until one with the given prefix and returns the lines before it.
+func (e *external) await(prefix string) ([]string, error) {
+	var ret []string
+	for e.out.Scan() {
+		line := strings.TrimSpace(e.out.Text())
+		if strings.HasPrefix(line, prefix) {
+			return ret, nil
+		}
+		ret = append(ret, line)
+	}
+	if err := e.out.Err(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("engine exited before '%v'", prefix)
+}
+
+// diff compares the perft divide counts against the external engine and walks down the
+// tree along the first diverging move, until the moves themselves differ or the counts
+// agree. Returns the path of moves to the diverging position, if any.
+func diff(p *perft, e *external, position string, depth int) ([]string, bool, error) {
+	pos, turn, _, _, err := fen.Decode(position)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var path []string
+	for ; depth > 0; depth-- {
+		expected, err := e.Divide(position, path, depth)
+		if err != nil {
+			return nil, false, err
+		}
+		actual := p.divide(pos, turn, depth)
+
+		var keys []string
+		for k := range expected {
+			keys = append(keys, k)
+		}
+		for k := range actual {
+			if _, ok := expected[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		var next string
+		var differ int
+		var moves []string
+		for _, k := range keys {
+			a, aok := actual[k]
+			x, xok := expected[k]
+			switch {
+			case !xok:
+				moves = append(moves, fmt.Sprintf("extra move %v (%v)", k, a))
+			case !aok:
+				moves = append(moves, fmt.Sprintf("missing move %v (%v)", k, x))
+			case a != x:
+				if next == "" {
+					next = k
+					println(fmt.Sprintf("%v: move %v has %v nodes, want %v", printPath(path), k, a, x))
+				}
+				differ++
+			}
+		}
+		if len(moves) > 0 {
+			for _, m := range moves {
+				println(fmt.Sprintf("%v: %v", printPath(path), m))
+			}
+			return path, true, nil // moves differ: diverging position found
+		}
+		if next == "" {
+			return path, len(path) > 0, nil
+		}
+		if differ > 1 {
+			println(fmt.Sprintf("%v: %v other moves have different counts", printPath(path), differ-1))
+		}
+
+		m, ok := findMove(pos, turn, next)
+		if !ok {
+			return nil, false, fmt.Errorf("move %v not found in %v", next, pos)
+		}
+		pos.MakeMove(m)
+		turn = turn.Opponent()
+		path = append(path, next)
+	}
+	return path, true, nil
+}
+
+// divide returns the perft counts by initial move in UCI notation.
+func (p *perft) divide(pos *board.Position, turn board.Color, depth int) map[string]int64 {
+	h := p.zt.Hash(pos, turn)
+
+	ret := map[string]int64{}
+	for _, m := range pos.PseudoLegalMoves(turn) {
+		next := p.zt.Move(h, pos, m)
+		if undo, ok := pos.MakeMove(m); ok {
+			ret[board.PrintUCIMove(m, pos.CastlingLayout(), false)] = p.search(pos, turn.Opponent(), next, depth-1, false)
+			pos.UnmakeMove(m, undo)
+		}
+	}
+	return ret
+}
+
+// findMove returns the legal move in UCI notation, if present.
+func findMove(pos *board.Position, turn board.Color, str string) (board.Move, bool) {
+	for _, m := range pos.LegalMoves(turn) {
+		if board.PrintUCIMove(m, pos.CastlingLayout(), false) == str {
+			return m, true
+		}
+	}
+	return board.Move{}, false
+}
+
+// printPath prints the path of moves from the start position.
+func printPath(path []string) string {
+	if len(path) == 0 {
+		return "root"
+	}
+	return strings.Join(path, " ")
+}
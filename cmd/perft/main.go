@@ -8,6 +8,7 @@ import (
 	"github.com/herohde/morlock/pkg/board"
 	"github.com/herohde/morlock/pkg/board/fen"
 	"github.com/seekerror/logw"
+	"strings"
 	"time"
 )
 
@@ -15,6 +16,9 @@ var (
 	depth    = flag.Int("depth", 4, "Search depth")
 	position = flag.String("fen", "", "Start position (default to standard)")
 	divide   = flag.Bool("divide", false, "Divide counts by initial move")
+	bulk     = flag.Bool("bulk", true, "Count the legal moves at depth 1 directly instead of making each move")
+	hash     = flag.Uint("hash", 0, "Transposition cache size in MB for subtree counts (zero if disabled)")
+	engine   = flag.String("engine", "", "External UCI engine command that supports 'go perft', such as stockfish, to diff divide counts against (disabled if empty)")
 )
 
 func main() {
@@ -30,29 +34,124 @@ func main() {
 		logw.Exitf(ctx, "Invalid fen '%v': %v", *position, err)
 	}
 
+	zt := board.NewZobristTable(0)
+	p := &perft{zt: zt, cache: newCache(*hash << 20), buf: make([][]board.Move, *depth+1)}
+
+	if *engine != "" {
+		e, err := newExternal(ctx, *engine)
+		if err != nil {
+			logw.Exitf(ctx, "Failed to start %v: %v", *engine, err)
+		}
+		defer e.Close()
+
+		path, diverged, err := diff(p, e, *position, *depth)
+		if err != nil {
+			logw.Exitf(ctx, "Failed to diff against %v: %v", *engine, err)
+		}
+		if diverged {
+			println(fmt.Sprintf("diverged: position fen %v moves %v", *position, strings.Join(path, " ")))
+		} else {
+			println(fmt.Sprintf("perft(%v) matches", *depth))
+		}
+		return
+	}
+
 	for i := 1; i <= *depth; i++ {
 		start := time.Now()
-		nodes := search(pos, turn, i, *divide && i == *depth)
+		nodes := p.search(pos, turn, zt.Hash(pos, turn), i, *divide && i == *depth)
 		duration := time.Since(start)
 
 		println(fmt.Sprintf("perft,%v,%v,%v,%v", *position, i, nodes, duration.Microseconds()))
 	}
 }
 
-func search(pos *board.Position, turn board.Color, depth int, d bool) int64 {
+// perft counts the leaf nodes of the move tree.
+type perft struct {
+	zt    *board.ZobristTable
+	cache *cache
+	buf   [][]board.Move // move generation buffers by depth
+}
+
+func (p *perft) search(pos *board.Position, turn board.Color, h board.ZobristHash, depth int, d bool) int64 {
 	if depth == 0 {
 		return 1
 	}
+	if depth == 1 && *bulk && !d {
+		// Bulk counting: the legal move generator uses check and pin masks, so the moves
+		// need not be made to verify legality.
+		p.buf[0] = pos.LegalMovesInto(turn, p.buf[0])
+		return int64(len(p.buf[0]))
+	}
+	if nodes, ok := p.cache.Read(h, depth); ok && !d {
+		return nodes
+	}
+
+	moves := pos.PseudoLegalMovesInto(turn, p.buf[depth])
+	p.buf[depth] = moves
 
 	var nodes int64
-	for _, m := range pos.PseudoLegalMoves(turn) {
-		if next, ok := pos.Move(m); ok {
-			count := search(next, turn.Opponent(), depth-1, false)
+	for _, m := range moves {
+		next := p.zt.Move(h, pos, m)
+		if undo, ok := pos.MakeMove(m); ok {
+			count := p.search(pos, turn.Opponent(), next, depth-1, false)
+			pos.UnmakeMove(m, undo)
 			if d {
 				println(fmt.Sprintf("%v: %v", m, count))
 			}
 			nodes += count
 		}
 	}
+	p.cache.Write(h, depth, nodes)
 	return nodes
 }
+
+// cache is an always-replace hash table of subtree counts keyed by (zobrist, depth). Depth 1
+// counts are not cached, because they are as cheap to compute as to look up.
+type cache struct {
+	entries []entry
+	mask    uint64
+}
+
+type entry struct {
+	hash  board.ZobristHash
+	depth int32
+	nodes int64
+}
+
+// newCache returns a cache of at most the given size in bytes. A nil cache is disabled.
+func newCache(size uint) *cache {
+	n := uint64(1)
+	for 2*n*entrySize <= uint64(size) {
+		n *= 2
+	}
+	if n*entrySize > uint64(size) {
+		return nil
+	}
+	return &cache{entries: make([]entry, n), mask: n - 1}
+}
+
+const entrySize = 24
+
+func (c *cache) Read(h board.ZobristHash, depth int) (int64, bool) {
+	if c == nil || depth < 2 {
+		return 0, false
+	}
+	e := c.entries[c.index(h, depth)]
+	if e.hash != h || int(e.depth) != depth {
+		return 0, false
+	}
+	return e.nodes, true
+}
+
+func (c *cache) Write(h board.ZobristHash, depth int, nodes int64) {
+	if c == nil || depth < 2 {
+		return
+	}
+	c.entries[c.index(h, depth)] = entry{hash: h, depth: int32(depth), nodes: nodes}
+}
+
+// index returns the slot for the hash and depth. The depth is mixed in, so that counts of
+// the same position at different depths do not evict each other.
+func (c *cache) index(h board.ZobristHash, depth int) uint64 {
+	return (uint64(h) ^ uint64(depth)*0x9e3779b97f4a7c15) & c.mask
+}
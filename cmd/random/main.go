@@ -0,0 +1,44 @@
+// random is a reference engine that plays uniformly random legal moves.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/herohde/morlock/pkg/engine"
+	"github.com/herohde/morlock/pkg/engine/cli"
+	"github.com/herohde/morlock/pkg/search"
+	"os"
+)
+
+var (
+	seed = flag.Int64("seed", 0, "Random seed for move choices (zero if new seed per process)")
+)
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, `usage: random [options]
+
+RANDOM is a UCI chess engine that plays uniformly random legal moves. It is a reference
+engine with no chess knowledge to anchor strength measurements of the other engines.
+Options:
+`)
+		flag.PrintDefaults()
+	}
+}
+
+func main() {
+	flag.Parse()
+	ctx := context.Background()
+
+	var s search.Random
+	if *seed != 0 {
+		s = search.NewRandom(*seed)
+	}
+
+	e := engine.New(ctx, "random", "herohde", s,
+		engine.WithOptions(engine.Options{Depth: 1}),
+	)
+
+	cli.Run(ctx, e)
+}
@@ -5,19 +5,31 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"github.com/herohde/morlock"
 	"github.com/herohde/morlock/cmd/sargon/sargon"
 	"github.com/herohde/morlock/pkg/engine"
-	"github.com/herohde/morlock/pkg/engine/console"
+	"github.com/herohde/morlock/pkg/engine/cli"
 	"github.com/herohde/morlock/pkg/engine/uci"
+	"github.com/herohde/morlock/pkg/eval"
 	"github.com/herohde/morlock/pkg/search"
 	"github.com/seekerror/logw"
 	"os"
-	"time"
 )
 
 var (
 	ply   = flag.Uint("ply", 1, "Search depth limit (zero if no limit)")
 	noise = flag.Uint("noise", 10, "Evaluation noise in \"millipawns\" (zero if deterministic)")
+	seed  = flag.Int64("seed", 0, "Random seed for noise and book choices (zero if new seed per game)")
+
+	limit     = flag.Float64("limit", 0, "BRDC delta limit (zero if LIMIT 6, negative if no limit)")
+	factor    = flag.Float64("factor", 0, "MTRL multiplier (zero if 4x)")
+	doubling  = flag.Bool("doubling", true, "Double exchange values in MTRL")
+	relative  = flag.Bool("relative", false, "Use side-relative BRDC baseline")
+	swindle   = flag.Uint("swindle", 0, "Swindle when lost, modeling the opponent at the given ply (zero if disabled)")
+	opponent  = flag.String("opponent", "", "Opponent model for -swindle: morlock, turochamp, sargon or bernstein (this engine if empty)")
+	stalemate = flag.Float64("stalemate", 0, "Seek immediate draws, such as stalemate, when this many pawns behind (zero if disabled)")
+	static    = flag.Bool("static", false, "Play the move with the best static evaluation without search, to isolate evaluation from search (not SARGON)")
+	kingsafe  = flag.Bool("kingsafety", false, "Add king safety evaluation (not SARGON)")
 )
 
 func init() {
@@ -40,37 +52,51 @@ func main() {
 
 	logw.Infof(ctx, "SARGON 1978 chess engine (%v ply)", *ply)
 
-	points := &sargon.Points{}
+	points := &sargon.Points{
+		Limit:      eval.Pawns(*limit),
+		Factor:     eval.Pawns(*factor),
+		NoDoubling: !*doubling,
+		Relative:   *relative,
+	}
+	var leaf, full eval.Evaluator = points, sargon.Static{Points: points}
+	if *kingsafe {
+		leaf, full = eval.Sum{leaf, eval.NewKingSafety()}, eval.Sum{full, eval.NewKingSafety()}
+	}
+
 	s := sargon.Hook{
 		Eval: search.AlphaBeta{
 			Explore: sargon.SkipUnderPromotions,
 			Eval: sargon.OnePlyIfChecked{
-				Leaf: search.Leaf{Eval: points},
+				Leaf: search.Leaf{Eval: leaf},
 			},
 		},
 		Hook: points,
 	}
 
-	e := engine.New(ctx, "SARGON (1978)", "Dan and Kathe Spracklen", s,
-		engine.WithOptions(engine.Options{Depth: *ply, Noise: *noise}),
-	)
-
-	in := engine.ReadStdinLines(ctx)
-	switch <-in {
-	case uci.ProtocolName:
-		driver, out := uci.NewDriver(ctx, e, in, uci.UseBook(sargon.NewBook(), time.Now().UnixNano()))
-		go engine.WriteStdoutLines(ctx, out)
-
-		<-driver.Closed()
-
-	case console.ProtocolName:
-		driver, out := console.NewDriver(ctx, e, s, in)
-		go engine.WriteStdoutLines(ctx, out)
+	var root search.Search = s
+	if *swindle > 0 {
+		var model search.Search = s
+		if *opponent != "" {
+			e, ok := morlock.New(ctx, *opponent)
+			if !ok {
+				logw.Exitf(ctx, "Invalid opponent '%v'", *opponent)
+			}
+			model = e.Root()
+		}
+		root = search.Swindle{Eval: s, Opponent: model, OpponentDepth: int(*swindle)}
+	}
+	if *stalemate > 0 {
+		root = search.Stalemate{Eval: root, Threshold: eval.Pawns(*stalemate)}
+	}
+	if *static {
+		root = search.Static{Eval: search.Leaf{Eval: full}}
+	}
 
-		<-driver.Closed()
+	e := engine.New(ctx, "SARGON (1978)", "Dan and Kathe Spracklen", root,
+		engine.WithOptions(engine.Options{Depth: *ply, Noise: *noise, Seed: *seed}),
+		engine.WithTunable(points),
+		engine.WithEvaluator(full),
+	)
 
-	default:
-		flag.Usage()
-		logw.Exitf(ctx, "Protocol not supported")
-	}
+	cli.Run(ctx, e, cli.WithUCI(uci.UseBook(sargon.NewBook())))
 }
@@ -1,19 +1,87 @@
+// Package sargon implements the evaluation and search heuristics used by SARGON.
 package sargon
 
 import (
 	"context"
+	"fmt"
 	"github.com/herohde/morlock/pkg/board"
 	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/search"
 )
 
 // Points implements the POINTS evaluation. It uses the full score for material and board
 // control, given we do not have a representation size limit. As long as they are disjoint
 // and the LIMIT 6 BRDC value is still blended in, they should reflect the original scheme.
+//
+// The zero value follows the original scheme. The options allow alternative interpretations
+// to be investigated, notably for the anomalies listed in eval_test.go.
 type Points struct {
+	// Limit is the BRDC delta limit. Zero means the original LIMIT 6. Negative disables the limit.
+	Limit eval.Pawns
+	// Factor is the MTRL multiplier. Zero means the original 4x.
+	Factor eval.Pawns
+	// NoDoubling disables the doubling of exchange values in MTRL.
+	NoDoubling bool
+	// Relative uses the root BRDC baseline from the perspective of the side to move. The
+	// original code compares against the unadjusted baseline, even if ply0 is different color.
+	Relative bool
+
 	side0 board.Color
 	brdc0 eval.Pawns
 }
 
+// DefaultPointsLimit is the LIMIT 6 BRDC delta limit used by SARGON.
+const DefaultPointsLimit eval.Pawns = 6
+
+// DefaultPointsFactor is the MTRL multiplier used by SARGON.
+const DefaultPointsFactor eval.Pawns = 4
+
+// Parameters returns the options as tunable parameters. The limit and factor are the
+// effective values, such as the original LIMIT 6 and 4x for zero options.
+func (p *Points) Parameters() map[string]float64 {
+	return map[string]float64{
+		"limit":    float64(p.limit()),
+		"factor":   float64(p.factor()),
+		"doubling": eval.ParameterBool(!p.NoDoubling),
+		"relative": eval.ParameterBool(p.Relative),
+	}
+}
+
+// SetParameters updates the options from tunable parameters.
+func (p *Points) SetParameters(params map[string]float64) error {
+	tmp := *p
+	for name, v := range params {
+		switch name {
+		case "limit":
+			tmp.Limit = eval.Pawns(v)
+		case "factor":
+			tmp.Factor = eval.Pawns(v)
+		case "doubling":
+			doubling, err := eval.ParseParameterBool(name, v)
+			if err != nil {
+				return err
+			}
+			tmp.NoDoubling = !doubling
+		case "relative":
+			relative, err := eval.ParseParameterBool(name, v)
+			if err != nil {
+				return err
+			}
+			tmp.Relative = relative
+		default:
+			return fmt.Errorf("unknown parameter: %v", name)
+		}
+	}
+	*p = tmp
+	return nil
+}
+
+// Capabilities declares that Development depends on castling and that the BRDC baseline is
+// relative to the search root, so positions cannot be cached across searches.
+func (p *Points) Capabilities() search.Capabilities {
+	return search.Capabilities{History: board.KeyOptions{Castled: true}, NoTT: true}
+}
+
 func (p *Points) Reset(ctx context.Context, b *board.Board) {
 	pins := FindKingQueenPins(b.Position())
 
@@ -25,16 +93,87 @@ func (p *Points) Evaluate(ctx context.Context, b *board.Board) eval.Pawns {
 	pins := FindKingQueenPins(b.Position())
 
 	brdc := BoardControl(ctx, b, pins)
-	mtrl, ptschk := Material(ctx, b, pins)
+	mtrl, ptschk := material(ctx, b, pins, !p.NoDoubling)
 	if ptschk {
-		return mtrl*4 + brdc/100
+		return mtrl*p.factor() + brdc/100
+	}
+	return mtrl*p.factor() + p.delta(b, brdc) + brdc/100
+}
+
+// Explain explains the evaluation by MTRL, split into nominal material and the exchange
+// adjustment, the limited BRDC delta to the root baseline and the full BRDC development and
+// mobility tie-breaks. Each term is scaled as in the score.
+func (p *Points) Explain(ctx context.Context, b *board.Board) eval.Explanation {
+	pins := FindKingQueenPins(b.Position())
+
+	dev, mob := Development(ctx, b), Mobility(ctx, b, pins)
+	nominal := eval.Material{}.Evaluate(ctx, b)
+	mtrl, ptschk := material(ctx, b, pins, !p.NoDoubling)
+
+	var delta eval.Pawns
+	if !ptschk {
+		delta = p.delta(b, BoardControl(ctx, b, pins))
+	}
+
+	return eval.Explanation{
+		Score: p.Evaluate(ctx, b),
+		Terms: []eval.Term{
+			{Name: "material", Score: nominal * p.factor()},
+			{Name: "exchange", Score: (mtrl - nominal) * p.factor()},
+			{Name: "brdc-delta", Score: delta},
+			{Name: "development", Score: dev / 100},
+			{Name: "mobility", Score: mob / 100},
+		},
 	}
+}
 
+// delta returns the limited BRDC delta to the root baseline.
+func (p *Points) delta(b *board.Board, brdc eval.Pawns) eval.Pawns {
 	brdc0 := p.brdc0
 	if b.Turn() != p.side0 {
 		brdc0 = -brdc0
 	}
-	return mtrl*4 + eval.Limit(brdc-p.brdc0, 6) + brdc/100
+
+	delta := brdc - p.brdc0
+	if p.Relative {
+		delta = brdc - brdc0
+	}
+	if limit := p.limit(); limit >= 0 {
+		delta = eval.Limit(delta, limit)
+	}
+	return delta
+}
+
+// Static evaluates positions as search roots, i.e., against their own board control baseline,
+// for use outside of search. It does not modify Points.
+type Static struct {
+	Points *Points
+}
+
+func (s Static) Evaluate(ctx context.Context, b *board.Board) eval.Pawns {
+	p := *s.Points
+	p.Reset(ctx, b)
+	return p.Evaluate(ctx, b)
+}
+
+func (s Static) Explain(ctx context.Context, b *board.Board) eval.Explanation {
+	p := *s.Points
+	p.Reset(ctx, b)
+	return p.Explain(ctx, b)
+}
+
+func (p *Points) limit() eval.Pawns {
+	if p.Limit == 0 {
+		return DefaultPointsLimit
+	}
+	return p.Limit
+}
+
+func (p *Points) factor() eval.Pawns {
+	if p.Factor == 0 {
+		return DefaultPointsFactor
+	}
+	return p.Factor
 }
 
 // Notes
@@ -57,6 +196,10 @@ func (p *Points) Evaluate(ctx context.Context, b *board.Board) eval.Pawns {
 
 // Material implements the MTRL heuristic without limit plus the ptschk (= moving into loss).
 func Material(ctx context.Context, b *board.Board, pins Pins) (eval.Pawns, bool) {
+	return material(ctx, b, pins, true)
+}
+
+func material(ctx context.Context, b *board.Board, pins Pins, doubling bool) (eval.Pawns, bool) {
 	pos := b.Position()
 	turn := b.Turn()
 
@@ -98,12 +241,14 @@ func Material(ctx context.Context, b *board.Board, pins Pins) (eval.Pawns, bool)
 	// Instead follow the BYTE article for 3/4 of PTSW2?
 
 	loss := ptsl
-	if loss < 0 {
-		loss = 2*ptsl + 1
-	}
 	win := ptsw2
-	if win > 0 {
-		win = (2*ptsw2 - 1) / 2
+	if doubling {
+		if loss < 0 {
+			loss = 2*ptsl + 1
+		}
+		if win > 0 {
+			win = (2*ptsw2 - 1) / 2
+		}
 	}
 
 	// We swap win/loss, because the evaluation here is from the points of the side to move. Sargon
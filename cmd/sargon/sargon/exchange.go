@@ -112,14 +112,14 @@ func NumAttackers(attackers []*Attacker) int {
 func FindAttackers(pos *board.Position, pins Pins, sq board.Square, side board.Color) []*Attacker {
 	var ret []*Attacker
 	for _, piece := range board.KingQueenRookKnightBishop {
-		attackboard := board.Attackboard(pos.Rotated(), sq, piece)
+		attackboard := board.Attackboard(pos.All(), sq, piece)
 
 		bb := attackboard & pos.Piece(side, piece)
 		for bb != 0 {
 			from := bb.LastPopSquare()
 			bb ^= board.BitMask(from)
 
-			stack, ok := addAttackerStack(pos, pos.Rotated(), pins, side, piece, from, sq)
+			stack, ok := addAttackerStack(pos, pos.All(), pins, side, piece, from, sq)
 			if ok {
 				ret = append(ret, stack)
 			}
@@ -131,7 +131,7 @@ func FindAttackers(pos *board.Position, pins Pins, sq board.Square, side board.C
 		from := bb.LastPopSquare()
 		bb ^= board.BitMask(from)
 
-		stack, ok := addAttackerStack(pos, pos.Rotated(), pins, side, board.Pawn, from, sq)
+		stack, ok := addAttackerStack(pos, pos.All(), pins, side, board.Pawn, from, sq)
 		if ok {
 			ret = append(ret, stack)
 		}
@@ -140,7 +140,7 @@ func FindAttackers(pos *board.Position, pins Pins, sq board.Square, side board.C
 	return ret
 }
 
-func addAttackerStack(pos *board.Position, r board.RotatedBitboard, pins Pins, side board.Color, piece board.Piece, from, target board.Square) (*Attacker, bool) {
+func addAttackerStack(pos *board.Position, all board.Bitboard, pins Pins, side board.Color, piece board.Piece, from, target board.Square) (*Attacker, bool) {
 	if list := pins[from]; len(list) > 1 || (len(list) == 1 && list[0] != target) {
 		return nil, false // skip: attacker is pinned
 	}
@@ -156,14 +156,14 @@ func addAttackerStack(pos *board.Position, r board.RotatedBitboard, pins Pins, s
 		return ret, true // nobody can be behind the King in an exchange
 	}
 
-	next := r.Xor(from)
+	next := all ^ board.BitMask(from)
 
 	bb := board.EmptyBitboard
 	if board.IsSameRankOrFile(from, target) {
-		attackboard := board.RookAttackboard(next, target) &^ board.RookAttackboard(r, target)
+		attackboard := board.RookAttackboard(next, target) &^ board.RookAttackboard(all, target)
 		bb = attackboard & (pos.Piece(side, board.Queen) | pos.Piece(side, board.Rook))
 	} else if board.IsSameDiagonal(from, target) {
-		attackboard := board.BishopAttackboard(next, target) &^ board.BishopAttackboard(r, target)
+		attackboard := board.BishopAttackboard(next, target) &^ board.BishopAttackboard(all, target)
 		bb = attackboard & (pos.Piece(side, board.Queen) | pos.Piece(side, board.Bishop))
 	}
 
@@ -0,0 +1,88 @@
+package sargon_test
+
+import (
+	"context"
+	"github.com/herohde/morlock/cmd/sargon/sargon"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/board/pgn"
+	"github.com/herohde/morlock/pkg/engine"
+	"github.com/herohde/morlock/pkg/engine/gametest"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// TestBookGames replays the book lines as Black: each first move of White with the book
+// reply of SARGON. The book has no published game scores, so the lines of the one-move book
+// are the recorded SARGON moves. The search prefers developing a Knight to the central pawn
+// moves of the book, which is why SARGON needs the book at all.
+func TestBookGames(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping game replay in short mode")
+	}
+	ctx := context.Background()
+
+	games := bookGames(t)
+	require.Len(t, games, 20)
+
+	points := &sargon.Points{}
+	cfg := gametest.Config{
+		Name: "sargon",
+		Root: sargon.Hook{
+			Eval: search.AlphaBeta{
+				Explore: sargon.SkipUnderPromotions,
+				Eval: sargon.OnePlyIfChecked{
+					Leaf: search.Leaf{Eval: points},
+				},
+			},
+			Hook: points,
+		},
+		Depth:   2,
+		Options: []engine.Option{engine.WithTunable(points)},
+	}
+
+	for _, g := range games {
+		r, err := gametest.Replay(ctx, g, board.Black, cfg)
+		require.NoError(t, err)
+		t.Log(r)
+
+		assert.Equal(t, 1, r.Moves)
+		for _, d := range r.Divergences {
+			assert.Less(t, rank(d), 7, "book reply not a top candidate: %v", d) // regression guard
+		}
+	}
+}
+
+// rank returns the index of the recorded move in the candidate list, if present.
+func rank(d gametest.Divergence) int {
+	for i, c := range d.Candidates {
+		if c.Move.Equals(d.Expected) {
+			return i
+		}
+	}
+	return len(d.Candidates)
+}
+
+// bookGames returns the one-move games of the book replies to every first move of White.
+func bookGames(t *testing.T) []pgn.Game {
+	ctx := context.Background()
+	book := sargon.NewBook()
+
+	pos, turn, _, _, err := fen.Decode(fen.Initial)
+	require.NoError(t, err)
+
+	var ret []pgn.Game
+	for _, m := range pos.LegalMoves(turn) {
+		next, ok := pos.Move(m)
+		require.True(t, ok)
+
+		replies, err := book.Find(ctx, fen.Encode(next, turn.Opponent(), 0, 1))
+		require.NoError(t, err)
+		require.Len(t, replies, 1)
+
+		ret = append(ret, pgn.Game{Moves: []board.Move{m, replies[0]}, Result: "*"})
+	}
+	return ret
+}
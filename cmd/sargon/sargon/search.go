@@ -13,6 +13,10 @@ type Hook struct {
 	Hook *Points
 }
 
+func (h Hook) Capabilities() search.Capabilities {
+	return search.CapabilitiesOf(h.Hook).Merge(search.CapabilitiesOf(h.Eval))
+}
+
 func (h Hook) Search(ctx context.Context, sctx *search.Context, b *board.Board, depth int) (uint64, eval.Score, []board.Move, error) {
 	h.Hook.Reset(ctx, b)
 	return h.Eval.Search(ctx, sctx, b, depth)
@@ -23,6 +27,10 @@ type OnePlyIfChecked struct {
 	Leaf search.Leaf
 }
 
+func (q OnePlyIfChecked) Capabilities() search.Capabilities {
+	return q.Leaf.Capabilities()
+}
+
 func (q OnePlyIfChecked) QuietSearch(ctx context.Context, sctx *search.Context, b *board.Board) (uint64, eval.Score) {
 	if !b.Position().IsChecked(b.Turn()) {
 		return 1, eval.HeuristicScore(q.Leaf.Evaluate(ctx, sctx, b))
@@ -37,6 +45,6 @@ func (q OnePlyIfChecked) QuietSearch(ctx context.Context, sctx *search.Context,
 }
 
 // SkipUnderPromotions is an exploration of all moves, except under-promotions, in MVVLVA order.
-func SkipUnderPromotions(ctx context.Context, b *board.Board) (board.MovePriorityFn, board.MovePredicateFn) {
+func SkipUnderPromotions(ctx context.Context, b *board.Board, depth int) (board.MovePriorityFn, board.MovePredicateFn) {
 	return search.MVVLVA, board.Move.IsNotUnderPromotion
 }
@@ -5,18 +5,26 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"github.com/herohde/morlock"
 	"github.com/herohde/morlock/cmd/turochamp/turochamp"
 	"github.com/herohde/morlock/pkg/engine"
-	"github.com/herohde/morlock/pkg/engine/console"
-	"github.com/herohde/morlock/pkg/engine/uci"
+	"github.com/herohde/morlock/pkg/engine/cli"
+	"github.com/herohde/morlock/pkg/eval"
 	"github.com/herohde/morlock/pkg/search"
 	"github.com/seekerror/logw"
 	"os"
 )
 
 var (
-	ply   = flag.Uint("ply", 2, "Search depth limit (zero if no limit)")
-	noise = flag.Uint("noise", 10, "Evaluation noise in \"millipawns\" (zero if deterministic)")
+	ply       = flag.Uint("ply", 2, "Search depth limit (zero if no limit)")
+	noise     = flag.Uint("noise", 10, "Evaluation noise in \"millipawns\" (zero if deterministic)")
+	seed      = flag.Int64("seed", 0, "Random seed for noise and book choices (zero if new seed per game)")
+	swindle   = flag.Uint("swindle", 0, "Swindle when lost, modeling the opponent at the given ply (zero if disabled)")
+	opponent  = flag.String("opponent", "", "Opponent model for -swindle: morlock, turochamp, sargon or bernstein (this engine if empty)")
+	stalemate = flag.Float64("stalemate", 0, "Seek immediate draws, such as stalemate, when this many pawns behind (zero if disabled)")
+	standpat  = flag.Bool("standpat", false, "Stand pat in quiescence with a positional evaluation, where material does not strictly dominate (not TUROCHAMP)")
+	static    = flag.Bool("static", false, "Play the move with the best static evaluation without search, to isolate evaluation from search (not TUROCHAMP)")
+	kingsafe  = flag.Bool("kingsafety", false, "Add king safety evaluation, best with -standpat where material does not strictly dominate (not TUROCHAMP)")
 )
 
 func init() {
@@ -38,33 +46,41 @@ func main() {
 
 	logw.Infof(ctx, "TUROCHAMP 1948 chess engine (%v ply)", *ply)
 
+	var evaluator eval.Evaluator = turochamp.Eval{Positional: *standpat}
+	if *kingsafe {
+		evaluator = eval.Sum{evaluator, eval.NewKingSafety()}
+	}
+
 	s := search.AlphaBeta{
 		Eval: search.Quiescence{
 			Explore: turochamp.ConsiderableMovesOnly,
-			Eval:    search.Leaf{Eval: turochamp.Eval{}},
+			Eval:    search.Leaf{Eval: evaluator},
 		},
 	}
 
-	e := engine.New(ctx, "TUROCHAMP (1948)", "Alan Turing and David Champernowne", s,
-		engine.WithOptions(engine.Options{Depth: *ply, Noise: *noise}),
-	)
-
-	in := engine.ReadStdinLines(ctx)
-	switch <-in {
-	case uci.ProtocolName:
-		driver, out := uci.NewDriver(ctx, e, in)
-		go engine.WriteStdoutLines(ctx, out)
-
-		<-driver.Closed()
-
-	case console.ProtocolName:
-		driver, out := console.NewDriver(ctx, e, s, in)
-		go engine.WriteStdoutLines(ctx, out)
+	var root search.Search = s
+	if *swindle > 0 {
+		var model search.Search = s
+		if *opponent != "" {
+			e, ok := morlock.New(ctx, *opponent)
+			if !ok {
+				logw.Exitf(ctx, "Invalid opponent '%v'", *opponent)
+			}
+			model = e.Root()
+		}
+		root = search.Swindle{Eval: s, Opponent: model, OpponentDepth: int(*swindle)}
+	}
+	if *stalemate > 0 {
+		root = search.Stalemate{Eval: root, Threshold: eval.Pawns(*stalemate)}
+	}
+	if *static {
+		root = search.Static{Eval: search.Leaf{Eval: evaluator}}
+	}
 
-		<-driver.Closed()
+	e := engine.New(ctx, "TUROCHAMP (1948)", "Alan Turing and David Champernowne", root,
+		engine.WithOptions(engine.Options{Depth: *ply, Noise: *noise, Seed: *seed}),
+		engine.WithEvaluator(evaluator),
+	)
 
-	default:
-		flag.Usage()
-		logw.Exitf(ctx, "Protocol not supported")
-	}
+	cli.Run(ctx, e)
 }
@@ -4,6 +4,7 @@ package turochamp
 import (
 	"context"
 	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/search"
 	"math"
 
 	"github.com/herohde/morlock/pkg/board"
@@ -11,15 +12,31 @@ import (
 
 // Eval implements the TUROCHAMP evaluation function. We use the position play symmetrically
 // for a more stable score, similar to Material.
-type Eval struct{}
+type Eval struct {
+	// Positional uses the material balance as a difference and blends in position play at
+	// a tenth of a pawn per point, instead of letting the material ratio strictly dominate.
+	// Quiet consolidation can then outweigh pointless captures in the considerable-move
+	// quiescence search. Not part of TUROCHAMP and off by default for fidelity.
+	Positional bool
+}
 
-func (Eval) Evaluate(ctx context.Context, b *board.Board) eval.Pawns {
-	mat := Material{}.Evaluate(ctx, b)
+// Capabilities declares that the castling points depend on castling.
+func (e Eval) Capabilities() search.Capabilities {
+	return search.Capabilities{History: board.KeyOptions{Castled: true}}
+}
+
+func (e Eval) Evaluate(ctx context.Context, b *board.Board) eval.Pawns {
 	pp := PositionPlay(b, b.Turn()) - PositionPlay(b, b.Turn().Opponent())
+	if e.Positional {
+		pos := b.Position()
+		return material(pos, b.Turn()) - material(pos, b.Turn().Opponent()) + pp/10
+	}
+
+	mat := Material{}.Evaluate(ctx, b)
 
 	// Combine scores to ensure material strictly dominates: MMMMMP.PP.
 
-	m := eval.Pawns(math.Round(float64(mat)*100) * 10)
+	m := dominant(mat)
 	p := eval.Pawns(math.Round(float64(pp)*100) / 1000)
 
 	// println(fmt.Sprintf("POS %v MAT: %v -> %v, PP: %v -> %v => %v", pos, mat, m, pp, p, m+p))
@@ -27,6 +44,32 @@ func (Eval) Evaluate(ctx context.Context, b *board.Board) eval.Pawns {
 	return m + p
 }
 
+// Explain explains the evaluation by material and position play rule, where each rule is the
+// difference in points at a tenth of a pawn per point. The terms sum to the score up to rounding.
+func (e Eval) Explain(ctx context.Context, b *board.Board) eval.Explanation {
+	pos := b.Position()
+	own, opp := positionPlay(b, b.Turn()), positionPlay(b, b.Turn().Opponent())
+
+	mat := dominant(Material{}.Evaluate(ctx, b))
+	if e.Positional {
+		mat = material(pos, b.Turn()) - material(pos, b.Turn().Opponent())
+	}
+
+	ret := eval.Explanation{
+		Score: e.Evaluate(ctx, b),
+		Terms: []eval.Term{{Name: "material", Score: mat}},
+	}
+	for r := MobilityRule; r < NumRules; r++ {
+		ret.Terms = append(ret.Terms, eval.Term{Name: r.String(), Score: (own[r] - opp[r]) / 10})
+	}
+	return ret
+}
+
+// dominant scales the material ratio to strictly dominate position play.
+func dominant(mat eval.Pawns) eval.Pawns {
+	return eval.Pawns(math.Round(float64(mat)*100) * 10)
+}
+
 // Material returns the material advantage balance as a ratio, W/B. Turing and Champernowne
 // used the following piece values: pawn=1, knight=3, bishop=3½, rook=5, queen=10. The ratio
 // in the range of [-226;226]. We use a negative ratio for when behind to let position-play
@@ -106,18 +149,62 @@ func pieceValue(piece board.Piece) eval.Pawns {
 //
 // We score with 1 decimal point precision as described. The range is [-55;55].
 func PositionPlay(b *board.Board, turn board.Color) eval.Pawns {
+	var score eval.Pawns
+	for _, v := range positionPlay(b, turn) {
+		score += v
+	}
+	return score
+}
+
+// Rule is a position play rule.
+type Rule int
+
+const (
+	MobilityRule Rule = iota
+	PieceSafetyRule
+	KingMobilityRule
+	KingSafetyRule
+	CastlingRule
+	PawnCreditRule
+	MatesAndChecksRule
+	NumRules
+)
+
+func (r Rule) String() string {
+	switch r {
+	case MobilityRule:
+		return "mobility"
+	case PieceSafetyRule:
+		return "piece-safety"
+	case KingMobilityRule:
+		return "king-mobility"
+	case KingSafetyRule:
+		return "king-safety"
+	case CastlingRule:
+		return "castling"
+	case PawnCreditRule:
+		return "pawn-credit"
+	case MatesAndChecksRule:
+		return "mates-and-checks"
+	default:
+		return "?"
+	}
+}
+
+// positionPlay returns the position play points by rule.
+func positionPlay(b *board.Board, turn board.Color) [NumRules]eval.Pawns {
 	pos := b.Position()
 
-	var score eval.Pawns
+	var score [NumRules]eval.Pawns
 
 	if pos.Castling()&board.CastlingRights(turn) != 0 {
-		score += 1
+		score[CastlingRule] += 1
 	}
 	if b.HasCastled(turn) {
-		score += 1
+		score[CastlingRule] += 1
 	}
 	if pos.IsChecked(turn.Opponent()) {
-		score += 0.5
+		score[MatesAndChecksRule] += 0.5
 	}
 
 	// (1) Analyze mobility, castling and checks/checkmates.
@@ -133,11 +220,11 @@ func PositionPlay(b *board.Board, turn board.Color) eval.Pawns {
 
 		if !mayCheckMate && next.IsCheckMate(turn.Opponent()) {
 			mayCheckMate = true
-			score += 1
+			score[MatesAndChecksRule] += 1
 		}
 		if !mayCastle && m.IsCastle() {
 			mayCastle = true
-			score += 1
+			score[CastlingRule] += 1
 		}
 
 		if m.Piece != board.Pawn && !m.IsCastle() {
@@ -147,8 +234,12 @@ func PositionPlay(b *board.Board, turn board.Color) eval.Pawns {
 			}
 		}
 	}
-	for _, n := range mobility {
-		score += eval.Pawns(math.Round(10*math.Sqrt(float64(n)))) / 10
+	for sq, n := range mobility {
+		rule := MobilityRule
+		if _, piece, _ := pos.Square(sq); piece == board.King {
+			rule = KingMobilityRule
+		}
+		score[rule] += eval.Pawns(math.Round(10*math.Sqrt(float64(n)))) / 10
 	}
 
 	// (2) Analyze Rook, Knight, Bishop defence.
@@ -160,7 +251,7 @@ func PositionPlay(b *board.Board, turn board.Color) eval.Pawns {
 
 		defenders := 0
 		for _, p := range board.KingQueenRookKnightBishop {
-			if bb := board.Attackboard(pos.Rotated(), from, p) & pos.Piece(turn, p); bb != 0 {
+			if bb := board.Attackboard(pos.All(), from, p) & pos.Piece(turn, p); bb != 0 {
 				defenders += bb.PopCount()
 			}
 		}
@@ -168,21 +259,21 @@ func PositionPlay(b *board.Board, turn board.Color) eval.Pawns {
 			defenders += bb.PopCount()
 		}
 		if defenders > 0 {
-			score += 1
+			score[PieceSafetyRule] += 1
 		}
 		if defenders > 1 {
-			score += 0.5
+			score[PieceSafetyRule] += 0.5
 		}
 	}
 
 	// (3) Analyze King safety.
 
 	if king := pos.Piece(turn, board.King); king != 0 {
-		attackboard := board.QueenAttackboard(pos.Rotated(), king.LastPopSquare())
+		attackboard := board.QueenAttackboard(pos.All(), king.LastPopSquare())
 		safety := (attackboard &^ pos.Color(turn)).PopCount()
 		// safety += (attackboard & pos.Color(turn.Opponent())).PopCount()
 
-		score -= eval.Pawns(math.Round(10*math.Sqrt(float64(safety)))) / 10
+		score[KingSafetyRule] -= eval.Pawns(math.Round(10*math.Sqrt(float64(safety)))) / 10
 	}
 
 	// (4) Analyze Pawn progress and defence.
@@ -192,18 +283,12 @@ func PositionPlay(b *board.Board, turn board.Color) eval.Pawns {
 		from := pawns.LastPopSquare()
 		pawns ^= board.BitMask(from)
 
-		ranks := 0
-		if turn == board.White {
-			ranks += int(from.Rank() - board.Rank2)
-		} else {
-			ranks += int(board.Rank7 - from.Rank())
-		}
-
-		score += 0.2 * eval.Pawns(ranks)
+		ranks := int(board.RelativeRank(turn, from.Rank()) - board.Rank2)
+		score[PawnCreditRule] += 0.2 * eval.Pawns(ranks)
 
 		for _, p := range board.KingQueenRookKnightBishop {
-			if bb := board.Attackboard(pos.Rotated(), from, p) & pos.Piece(turn, p); bb != 0 {
-				score += 0.3
+			if bb := board.Attackboard(pos.All(), from, p) & pos.Piece(turn, p); bb != 0 {
+				score[PawnCreditRule] += 0.3
 				break
 			}
 		}
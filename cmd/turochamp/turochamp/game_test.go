@@ -0,0 +1,54 @@
+package turochamp_test
+
+import (
+	"context"
+	"github.com/herohde/morlock/cmd/turochamp/turochamp"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/pgn"
+	"github.com/herohde/morlock/pkg/engine/gametest"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+)
+
+func TestTuringGlennieGame(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping game replay in short mode")
+	}
+
+	f, err := os.Open("../turing_glennie_1952.pgn")
+	require.NoError(t, err)
+	defer f.Close()
+
+	games, err := pgn.Read(f)
+	require.NoError(t, err)
+	require.Len(t, games, 1)
+
+	cfg := gametest.Config{
+		Name: "turochamp",
+		Root: search.AlphaBeta{
+			Eval: search.Quiescence{
+				Explore: turochamp.ConsiderableMovesOnly,
+				Eval:    search.Leaf{Eval: turochamp.Eval{}},
+			},
+		},
+		Depth: 2,
+	}
+
+	r, err := gametest.Replay(context.Background(), games[0], board.White, cfg)
+	require.NoError(t, err)
+	t.Log(r)
+
+	assert.Equal(t, 29, r.Moves)
+	assert.GreaterOrEqual(t, r.Matches(), 13) // regression guard
+
+	for _, ref := range gametest.References(1, 2) {
+		rr, err := gametest.Replay(context.Background(), games[0], board.White, ref)
+		require.NoError(t, err)
+		t.Logf("Reference %v: %v/%v moves match", ref.Name, rr.Matches(), rr.Moves)
+
+		assert.GreaterOrEqual(t, r.Matches(), rr.Matches()) // calibration: at least as close as references
+	}
+}
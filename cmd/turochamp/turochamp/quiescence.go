@@ -6,7 +6,7 @@ import (
 	"github.com/herohde/morlock/pkg/search"
 )
 
-func ConsiderableMovesOnly(ctx context.Context, b *board.Board) (board.MovePriorityFn, board.MovePredicateFn) {
+func ConsiderableMovesOnly(ctx context.Context, b *board.Board, depth int) (board.MovePriorityFn, board.MovePredicateFn) {
 	return search.MVVLVA, func(move board.Move) bool {
 		return IsConsiderableMove(move, b /* post move when called */)
 	}
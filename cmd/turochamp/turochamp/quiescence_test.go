@@ -40,3 +40,27 @@ func TestQuiescence(t *testing.T) {
 		assert.Equal(t, actual, tt.expected, "failed: %v", tt.fen)
 	}
 }
+
+func TestQuiescencePositional(t *testing.T) {
+	ctx := context.Background()
+
+	// White is ahead and can trade rooks, which improves the material ratio. The positional
+	// evaluation is indifferent to the trade and prefers a quiet move.
+
+	b, err := fen.NewBoard("7k/6p1/1n5p/3r4/8/3R4/2Q3PP/6K1 w - - 0 1")
+	require.NoError(t, err)
+
+	for _, positional := range []bool{false, true} {
+		s := search.AlphaBeta{
+			Eval: search.Quiescence{
+				Explore: turochamp.ConsiderableMovesOnly,
+				Eval:    search.Leaf{Eval: turochamp.Eval{Positional: positional}},
+			},
+		}
+
+		_, _, pv, err := s.Search(ctx, search.EmptyContext, b, 2)
+		require.NoError(t, err)
+		require.NotEmpty(t, pv)
+		assert.Equal(t, !positional, pv[0].IsCapture(), "positional=%v: %v", positional, pv)
+	}
+}
@@ -0,0 +1,61 @@
+package morlock_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/herohde/morlock"
+	"io"
+	"strings"
+)
+
+func ExampleAnalyze() {
+	ctx := context.Background()
+
+	e := morlock.TuroChamp(ctx)
+	pv, err := morlock.Analyze(ctx, e, "7k/8/6K1/8/8/8/8/5Q2 w - - 0 1", 0)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(pv.Moves[0], pv.Score)
+	// Output: Qf1-f8 M1
+}
+
+func ExamplePlay() {
+	ctx := context.Background()
+
+	white, black := morlock.Sargon(ctx), morlock.Bernstein(ctx)
+	game, err := morlock.Play(ctx, white, black, "", 4)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(game.Moves)
+	// Output: [Nb1-c3 e7-e6 Ng1-f3 Bf8-b4 e2-e4 Ng8-f6 Bf1-b5 0-0]
+}
+
+func ExampleServeUCI() {
+	ctx := context.Background()
+
+	// Connect the engine to a GUI over pipes, such as a network connection.
+
+	in, gui := io.Pipe()
+	out, w := io.Pipe()
+	go func() {
+		_ = morlock.ServeUCI(ctx, morlock.TuroChamp(ctx), in, w)
+		_ = w.Close()
+	}()
+
+	fmt.Fprintln(gui, "uci")
+	fmt.Fprintln(gui, "position fen 7k/8/6K1/8/8/8/8/5Q2 w - - 0 1")
+	fmt.Fprintln(gui, "go depth 2")
+
+	scanner := bufio.NewScanner(out)
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.HasPrefix(line, "bestmove") {
+			fmt.Println(line)
+			break
+		}
+	}
+	fmt.Fprintln(gui, "quit")
+	// Output: bestmove f1f8
+}
@@ -0,0 +1,177 @@
+// Package morlock is a facade for using the morlock chess engines as a library. It constructs
+// the engines with fixed, deterministic defaults for library use, analyzes positions, plays
+// games between engines and runs an engine over custom I/O without the command-line harness.
+// The defaults are not the flag defaults of the binaries, which may add noise or leave the
+// search depth unlimited.
+package morlock
+
+import (
+	"context"
+	"fmt"
+	"github.com/herohde/morlock/cmd/bernstein/bernstein"
+	"github.com/herohde/morlock/cmd/sargon/sargon"
+	"github.com/herohde/morlock/cmd/turochamp/turochamp"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/engine"
+	"github.com/herohde/morlock/pkg/engine/uci"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/match"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/herohde/morlock/pkg/search/searchctl"
+	"github.com/seekerror/stdlib/pkg/lang"
+	"io"
+)
+
+// Morlock returns the morlock engine with capture quiescence search with stand-pat cutoffs, SEE
+// and delta pruning and tapered piece-square-table, pawn structure and king safety evaluation
+// at 4 ply. Unlike the defaults of cmd/morlock, the search is depth-limited and uses quiescence,
+// so that it terminates and plays sound moves. Options are applied after the defaults.
+func Morlock(ctx context.Context, opts ...engine.Option) *engine.Engine {
+	pawns := eval.NewPawnTable()
+	leaf := search.Leaf{Eval: eval.Sum{eval.PST{}, eval.NewPawnStructure(pawns), eval.NewKingSafety()}}
+	root := search.AlphaBeta{
+		Eval: search.Quiescence{Explore: search.CaptureExploration, Eval: leaf, StandPat: true, SEE: true, Delta: 2},
+	}
+
+	opts = append([]engine.Option{
+		engine.WithOptions(engine.Options{Depth: 4, Hash: 64}),
+		engine.WithTable(search.NewMinDepthTranspositionTable(1)),
+		engine.WithAuxTable("pawnhash", pawns, 1),
+		engine.WithEvaluator(leaf.Eval),
+	}, opts...)
+	return engine.New(ctx, "morlock", "herohde", root, opts...)
+}
+
+// TuroChamp returns the TUROCHAMP (1948) engine at 2 ply without noise. Options are applied
+// after the defaults.
+func TuroChamp(ctx context.Context, opts ...engine.Option) *engine.Engine {
+	evaluator := turochamp.Eval{}
+	root := search.AlphaBeta{
+		Eval: search.Quiescence{
+			Explore: turochamp.ConsiderableMovesOnly,
+			Eval:    search.Leaf{Eval: evaluator},
+		},
+	}
+
+	opts = append([]engine.Option{
+		engine.WithOptions(engine.Options{Depth: 2}),
+		engine.WithEvaluator(evaluator),
+	}, opts...)
+	return engine.New(ctx, "TUROCHAMP (1948)", "Alan Turing and David Champernowne", root, opts...)
+}
+
+// Sargon returns the SARGON (1978) engine at 1 ply without noise. Options are applied after
+// the defaults.
+func Sargon(ctx context.Context, opts ...engine.Option) *engine.Engine {
+	points := &sargon.Points{}
+	root := sargon.Hook{
+		Eval: search.AlphaBeta{
+			Explore: sargon.SkipUnderPromotions,
+			Eval: sargon.OnePlyIfChecked{
+				Leaf: search.Leaf{Eval: points},
+			},
+		},
+		Hook: points,
+	}
+
+	opts = append([]engine.Option{
+		engine.WithOptions(engine.Options{Depth: 1}),
+		engine.WithTunable(points),
+		engine.WithEvaluator(sargon.Static{Points: points}),
+	}, opts...)
+	return engine.New(ctx, "SARGON (1978)", "Dan and Kathe Spracklen", root, opts...)
+}
+
+// Bernstein returns the BERNSTEIN (1957) engine at 4 ply with a 7-branch limit without noise.
+// Options are applied after the defaults.
+func Bernstein(ctx context.Context, opts ...engine.Option) *engine.Engine {
+	pmt := bernstein.PlausibleMoveTable{Limit: 7}
+	ev := &bernstein.Eval{Factor: 20}
+	root := search.AlphaBeta{
+		Explore: pmt.Explore,
+		Eval:    search.Leaf{Eval: ev},
+	}
+
+	opts = append([]engine.Option{
+		engine.WithOptions(engine.Options{Depth: 4}),
+		engine.WithAttribution(pmt.Rule),
+		engine.WithTunable(ev),
+		engine.WithEvaluator(ev),
+	}, opts...)
+	return engine.New(ctx, "BERNSTEIN (1957)", "Alex Bernstein, Michael de V. Roberts, Timothy Arbuckle and Martin Belsky", root, opts...)
+}
+
+// New returns the engine of the given name: morlock, turochamp, sargon or bernstein. Options
+// are applied after the defaults.
+func New(ctx context.Context, name string, opts ...engine.Option) (*engine.Engine, bool) {
+	switch name {
+	case "morlock":
+		return Morlock(ctx, opts...), true
+	case "turochamp":
+		return TuroChamp(ctx, opts...), true
+	case "sargon":
+		return Sargon(ctx, opts...), true
+	case "bernstein":
+		return Bernstein(ctx, opts...), true
+	default:
+		return nil, false
+	}
+}
+
+// Analyze searches the position in FEN format to the given depth, or the engine default if
+// zero, and returns the principal variation. The engine is reset to the position. A search
+// without any depth limit is rejected, because it would not terminate.
+func Analyze(ctx context.Context, e *engine.Engine, position string, depth uint) (search.PV, error) {
+	if err := e.Reset(ctx, position); err != nil {
+		return search.PV{}, err
+	}
+
+	if depth == 0 {
+		depth = e.Options().Depth
+	}
+	if depth == 0 {
+		return search.PV{}, fmt.Errorf("%w: %v", engine.ErrNoDepthLimit, e.Name())
+	}
+
+	out, err := e.Analyze(ctx, searchctl.Options{DepthLimit: lang.Some(depth), Analysis: true})
+	if err != nil {
+		return search.PV{}, err
+	}
+	var last search.PV
+	for pv := range out {
+		last = pv
+	}
+	_, _ = e.Halt(ctx)
+	return last, nil
+}
+
+// Game is a game played between two engines.
+type Game = match.Game
+
+// Play plays a game between the given engines from the position in FEN format, or the
+// initial position if empty. Each engine searches to its default depth, which must be set,
+// and plays its move as a game move, such as a blunder. The game ends when decided or is
+// adjudicated a draw after the given number of full moves, if positive. The engines are reset
+// to the position and must be distinct.
+func Play(ctx context.Context, white, black *engine.Engine, position string, moves int) (Game, error) {
+	if position == "" {
+		position = fen.Initial
+	}
+	return match.Play(ctx, white, black, match.Opening{Start: position}, match.Limits{}, match.Rules{MaxMoves: moves})
+}
+
+// ServeUCI runs the engine under the UCI protocol over the given reader and writer, such as to
+// embed it with custom I/O. The input must start with "uci" as sent by a GUI. It blocks until
+// the driver is closed, notably on "quit" or end of input, and all output is written.
+func ServeUCI(ctx context.Context, e *engine.Engine, r io.Reader, w io.Writer, opts ...uci.Option) error {
+	in := engine.ReadLines(ctx, r)
+	if protocol := <-in; protocol != uci.ProtocolName {
+		return fmt.Errorf("protocol not supported: '%v'", protocol)
+	}
+
+	driver, out := uci.NewDriver(ctx, e, in, opts...)
+	engine.WriteLines(ctx, w, out)
+
+	<-driver.Closed()
+	return nil
+}
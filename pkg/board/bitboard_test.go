@@ -100,7 +100,7 @@ func TestAttackboard(t *testing.T) {
 		}
 
 		for _, tt := range tests {
-			assert.Equal(t, board.RookAttackboard(board.NewRotatedBitboard(tt.bb), tt.sq).String(), tt.expected)
+			assert.Equal(t, board.RookAttackboard(tt.bb, tt.sq).String(), tt.expected)
 		}
 	})
 
@@ -123,7 +123,7 @@ func TestAttackboard(t *testing.T) {
 		}
 
 		for _, tt := range tests {
-			assert.Equal(t, board.BishopAttackboard(board.NewRotatedBitboard(tt.bb), tt.sq).String(), tt.expected)
+			assert.Equal(t, board.BishopAttackboard(tt.bb, tt.sq).String(), tt.expected)
 		}
 	})
 }
@@ -11,31 +11,38 @@ const (
 	noprogressPlyLimit = 100
 )
 
+// node represents a position in the game history. Nodes are immutable once created, so that
+// forks can share them. Past positions are not kept, but can be recovered by undoing moves.
 type node struct {
-	pos        *Position
 	hash       ZobristHash
 	noprogress int
 
-	next Move // if not current
+	move Move // move from prev, if any
+	undo Undo // undo information for move
 	prev *node
 }
 
 // Board represents a chess board, metadata and history of positions to correctly handle game
-// results, notably various draw conditions. Not thread-safe.
+// results, notably various draw conditions. The current position is updated in place.
+// Not thread-safe.
 type Board struct {
 	zt          *ZobristTable
+	keys        KeyOptions
 	repetitions map[ZobristHash]int
 
 	hasCastled [NumColors]bool
 	ply, moves int
 	turn       Color
 	result     Result
+	pos        *Position
 	current    *node
 }
 
+// NewBoard returns a new board from the given position. The position is copied.
 func NewBoard(zt *ZobristTable, pos *Position, turn Color, noprogress, fullmoves int) *Board {
+	cp := *pos
+
 	current := &node{
-		pos:        pos,
 		noprogress: noprogress,
 		hash:       zt.Hash(pos, turn),
 	}
@@ -50,27 +57,27 @@ func NewBoard(zt *ZobristTable, pos *Position, turn Color, noprogress, fullmoves
 		ply:         1,
 		moves:       fullmoves,
 		turn:        turn,
+		pos:         &cp,
 		current:     current,
 	}
 }
 
-// Fork branches off a new board, sharing the node history for past positions. If forked, the shared
-// history should not be mutated (via PopMove) as the forward moves in node might then become stale.
+// Fork branches off a new board with a copy of the current position, sharing the node history
+// for past positions.
 func (b *Board) Fork() *Board {
+	pos := *b.pos
+
 	fork := &Board{
 		zt:          b.zt,
+		keys:        b.keys,
 		repetitions: map[ZobristHash]int{},
 		hasCastled:  b.hasCastled,
 		ply:         b.ply,
 		moves:       b.moves,
 		turn:        b.turn,
 		result:      b.result,
-		current: &node{
-			pos:        b.current.pos,
-			hash:       b.current.hash,
-			noprogress: b.current.noprogress,
-			prev:       b.current.prev,
-		},
+		pos:         &pos,
+		current:     b.current,
 	}
 	for k, v := range b.repetitions {
 		fork.repetitions[k] = v
@@ -79,9 +86,10 @@ func (b *Board) Fork() *Board {
 	return fork
 }
 
-// Position returns the current position.
+// Position returns the current position. It is updated in place by PushMove and PopMove, so
+// it must be copied if retained.
 func (b *Board) Position() *Position {
-	return b.current.pos
+	return b.pos
 }
 
 // Turn returns the color whose turn it is to move.
@@ -94,6 +102,30 @@ func (b *Board) Hash() ZobristHash {
 	return b.current.hash
 }
 
+// PawnHash returns the Zobrist hashcode of the pawns only for the current position.
+func (b *Board) PawnHash() ZobristHash {
+	return b.zt.PawnHash(b.pos)
+}
+
+// Key returns the search key for the current position, which is the Zobrist hashcode with
+// any extra history-dependent components. Intended for transposition tables and caches.
+func (b *Board) Key() ZobristHash {
+	if b.keys == (KeyOptions{}) {
+		return b.current.hash
+	}
+	return b.current.hash ^ b.zt.Key(b.keys, b.hasCastled, b.moves)
+}
+
+// KeyOptions returns the extra search key components.
+func (b *Board) KeyOptions() KeyOptions {
+	return b.keys
+}
+
+// SetKeyOptions sets the extra search key components. The position hash is unaffected.
+func (b *Board) SetKeyOptions(opts KeyOptions) {
+	b.keys = opts
+}
+
 // NoProgress returns the ply count since last irreversible move, i.e, pawn move, castling or capture. Used
 // solely to track the 50 move draw rule.
 func (b *Board) NoProgress() int {
@@ -122,23 +154,22 @@ func (b *Board) PushMove(m Move) bool {
 		return false // there are no legal moves
 	} // else: ignore draws that are not always called correctly.
 
-	next, ok := b.current.pos.Move(m)
+	hash := b.zt.Move(b.current.hash, b.pos, m)
+	undo, ok := b.pos.MakeMove(m)
 	if !ok {
 		return false
 	}
 
 	// (1) Move is legal. Create new node.
 
-	n := &node{
-		pos:        next,
-		hash:       b.zt.Move(b.current.hash, b.current.pos, m),
+	b.current = &node{
+		hash:       hash,
 		noprogress: updateNoProgress(b.current.noprogress, m),
+		move:       m,
+		undo:       undo,
 		prev:       b.current,
 	}
 
-	b.current.next = m
-	b.current = n
-
 	// (2) Update board-level metadata.
 
 	if m.IsCastle() {
@@ -154,7 +185,7 @@ func (b *Board) PushMove(m Move) bool {
 	// (3) Determine if draw condition applies.
 
 	if b.repetitions[b.current.hash] >= repetition3Limit {
-		actual := b.identicalPositionCount(b.current, b.turn, b.current.noprogress)
+		actual := b.identicalPositionCount(b.current.noprogress)
 		switch {
 		case actual >= repetition5Limit:
 			b.result.Outcome = Draw
@@ -173,7 +204,7 @@ func (b *Board) PushMove(m Move) bool {
 	}
 
 	if m.Type == Capture || ((m.Type == CapturePromotion || m.Type == Promotion) && (m.Promotion == Bishop || m.Promotion == Knight)) {
-		if b.current.pos.HasInsufficientMaterial() {
+		if b.pos.HasInsufficientMaterial() {
 			b.result.Outcome = Draw
 			b.result.Reason = InsufficientMaterial
 		}
@@ -182,14 +213,18 @@ func (b *Board) PushMove(m Move) bool {
 	return true
 }
 
+// PopMove undoes the last move. Returns false if there is no move to undo or if the last move
+// is a null move, which must be undone with PopNullMove.
 func (b *Board) PopMove() (Move, bool) {
-	if b.current.prev == nil {
+	if b.current.prev == nil || b.current.move.IsInvalid() {
 		return Move{}, false
 	}
 
+	m := b.current.move
+
 	// (1) Update board-level metadata.
 
-	if b.current.prev.next.IsCastle() {
+	if m.IsCastle() {
 		b.hasCastled[b.turn.Opponent()] = false
 	}
 	b.turn = b.turn.Opponent()
@@ -200,14 +235,74 @@ func (b *Board) PopMove() (Move, bool) {
 		b.moves--
 	}
 
-	// (2) Pop current node.
+	// (2) Undo move and pop current node.
 
+	b.pos.UnmakeMove(m, b.current.undo)
 	b.current = b.current.prev
-	m := b.current.next
-	b.current.next = Move{}
 	return m, true
 }
 
+// PushNullMove passes the turn to the opponent without moving, for null-move pruning in
+// search. The hash flips the side to move and en passant is cleared. Null moves are not
+// counted for repetitions and are not allowed in check or in a terminal position. Returns
+// true iff made.
+func (b *Board) PushNullMove() bool {
+	if b.result.Reason == Checkmate || b.result.Reason == Stalemate || b.pos.IsChecked(b.turn) {
+		return false
+	}
+
+	hash := b.current.hash ^ b.zt.turn[b.turn] ^ b.zt.turn[b.turn.Opponent()]
+	if ep, ok := b.pos.EnPassant(); ok {
+		hash ^= b.zt.enpassant[ep]
+	}
+
+	b.current = &node{
+		hash:       hash,
+		noprogress: b.current.noprogress + 1,
+		undo:       Undo{turn: b.turn, enpassant: b.pos.enpassant},
+		prev:       b.current,
+	}
+	b.pos.enpassant = ZeroSquare
+
+	b.turn = b.turn.Opponent()
+	b.ply++
+	if b.turn == White {
+		b.moves++
+	}
+	return true
+}
+
+// PopNullMove undoes a null move made by PushNullMove. Returns false if the last move is not
+// a null move.
+func (b *Board) PopNullMove() bool {
+	if b.current.prev == nil || !b.current.move.IsInvalid() {
+		return false
+	}
+
+	b.turn = b.turn.Opponent()
+	b.result = Result{Outcome: Undecided}
+	b.ply--
+	if b.turn == Black {
+		b.moves--
+	}
+
+	b.pos.enpassant = b.current.undo.enpassant
+	b.current = b.current.prev
+	return true
+}
+
+// HasRepeated returns true iff the current position occurred before within the given number of
+// plies, such as since the root of a search. Unlike Result, a single repetition suffices.
+func (b *Board) HasRepeated(plies int) bool {
+	if b.repetitions[b.current.hash] < 2 {
+		return false
+	}
+	if plies > b.current.noprogress {
+		plies = b.current.noprogress
+	}
+	return b.identicalPositionCount(plies) > 1
+}
+
 // AdjudicateNoLegalMoves adjudicates the position assuming no legal moves exist.
 // The result is then either Mate or Stalemate.
 func (b *Board) AdjudicateNoLegalMoves() Result {
@@ -224,33 +319,39 @@ func (b *Board) Adjudicate(result Result) {
 	b.result = result
 }
 
-func (b *Board) identicalPositionCount(n *node, turn Color, limit int) int {
+// identicalPositionCount returns the number of times the current position has occurred within
+// the given number of plies back. Past positions are recovered by undoing moves on a copy and
+// positions before a null move are not considered.
+func (b *Board) identicalPositionCount(limit int) int {
 	ret := 1
-	tmp := n.prev
-	t := b.turn.Opponent()
+	pos := *b.pos
+	tmp := b.current
+	t := b.turn
 
-	for i := 1; i < limit && tmp != nil; i++ {
-		if tmp.hash == n.hash && turn == t && *tmp.pos == *n.pos {
-			ret++
-		}
+	for i := 1; i <= limit && tmp.prev != nil && !tmp.move.IsInvalid(); i++ {
+		pos.UnmakeMove(tmp.move, tmp.undo)
 		tmp = tmp.prev
 		t = t.Opponent()
+
+		if tmp.hash == b.current.hash && t == b.turn && pos == *b.pos {
+			ret++
+		}
 	}
 	return ret
 }
 
-// LastMove returns the last move, if any.
+// LastMove returns the last move, if any. A null move is not a move.
 func (b *Board) LastMove() (Move, bool) {
-	if b.current.prev != nil {
-		return b.current.prev.next, true
+	if b.current.prev != nil && !b.current.move.IsInvalid() {
+		return b.current.move, true
 	}
 	return Move{}, false
 }
 
 // SecondToLastMove returns the second-to-last move, if any.
 func (b *Board) SecondToLastMove() (Move, bool) {
-	if b.current.prev != nil && b.current.prev.prev != nil {
-		return b.current.prev.prev.next, true
+	if b.current.prev != nil && b.current.prev.prev != nil && !b.current.prev.move.IsInvalid() {
+		return b.current.prev.move, true
 	}
 	return Move{}, false
 }
@@ -264,18 +365,20 @@ func (b *Board) HasCastled(c Color) bool {
 func (b *Board) HasMoved(limit int) Bitboard {
 	var ret Bitboard
 
-	cur := b.current.prev
-	for cur != nil && limit > 0 {
-		ret |= BitMask(cur.next.To)
+	cur := b.current
+	for cur.prev != nil && limit > 0 {
+		if !cur.move.IsInvalid() {
+			ret |= BitMask(cur.move.To)
+		}
 		cur = cur.prev
 		limit--
 	}
 
-	return ret & b.current.pos.All()
+	return ret & b.pos.All()
 }
 
 func (b *Board) String() string {
-	return fmt.Sprintf("board{pos=%v, turn=%v, hash=%x (%v) noprogress=%v, ply=%v, moves=%v, result=%v}", b.current.pos, b.turn, b.current.hash, b.repetitions[b.current.hash], b.current.noprogress, b.ply, b.moves, b.result)
+	return fmt.Sprintf("board{pos=%v, turn=%v, hash=%x (%v) noprogress=%v, ply=%v, moves=%v, result=%v}", b.pos, b.turn, b.current.hash, b.repetitions[b.current.hash], b.current.noprogress, b.ply, b.moves, b.result)
 }
 
 func updateNoProgress(old int, m Move) int {
@@ -0,0 +1,100 @@
+package board_test
+
+import (
+	"testing"
+
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoard(t *testing.T) {
+	t.Run("pushpop", func(t *testing.T) {
+		b, err := fen.NewBoard("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+		require.NoError(t, err)
+
+		start := fen.Encode(b.Position(), b.Turn(), b.NoProgress(), b.FullMoves())
+		hash := b.Hash()
+
+		for _, m := range b.Position().LegalMoves(b.Turn()) {
+			fork := b.Fork()
+			require.True(t, b.PushMove(m))
+			require.True(t, fork.PushMove(m))
+			assert.Equal(t, fork.Hash(), b.Hash())
+
+			last, ok := b.LastMove()
+			assert.True(t, ok)
+			assert.Equal(t, m, last)
+
+			actual, ok := b.PopMove()
+			require.True(t, ok)
+			assert.Equal(t, m, actual)
+			assert.Equal(t, start, fen.Encode(b.Position(), b.Turn(), b.NoProgress(), b.FullMoves()))
+			assert.Equal(t, hash, b.Hash())
+			assert.NotEqual(t, start, fen.Encode(fork.Position(), fork.Turn(), fork.NoProgress(), fork.FullMoves()))
+		}
+	})
+
+	t.Run("repetition", func(t *testing.T) {
+		shuffle := []string{"g1f3", "g8f6", "f3g1", "f6g8"}
+
+		b, err := fen.NewBoard(fen.Initial, append(shuffle, shuffle[:3]...)...)
+		require.NoError(t, err)
+		assert.False(t, b.Result().IsTerminal())
+
+		m, err := board.ParseMove(shuffle[3])
+		require.NoError(t, err)
+		for _, candidate := range b.Position().LegalMoves(b.Turn()) {
+			if candidate.Equals(m) {
+				require.True(t, b.PushMove(candidate))
+			}
+		}
+		assert.Equal(t, board.Result{Outcome: board.Draw, Reason: board.Repetition3}, b.Result())
+
+		_, ok := b.PopMove()
+		require.True(t, ok)
+		assert.False(t, b.Result().IsTerminal())
+	})
+
+	t.Run("hasrepeated", func(t *testing.T) {
+		b, err := fen.NewBoard(fen.Initial, "g1f3", "g8f6", "f3g1", "f6g8")
+		require.NoError(t, err)
+		assert.False(t, b.Result().IsTerminal())
+		assert.True(t, b.HasRepeated(4))
+		assert.False(t, b.HasRepeated(3)) // repetition is before the limit
+
+		b, err = fen.NewBoard(fen.Initial, "e2e4", "g8f6", "g1f3", "f6g8")
+		require.NoError(t, err)
+		assert.False(t, b.HasRepeated(4))
+	})
+
+	t.Run("nullmove", func(t *testing.T) {
+		zt := board.NewZobristTable(0)
+
+		b, err := fen.NewBoard("rnbqkbnr/ppp1pppp/8/8/3pP3/5N2/PPPP1PPP/RNBQKB1R b KQkq e3 0 3")
+		require.NoError(t, err)
+
+		start := fen.Encode(b.Position(), b.Turn(), b.NoProgress(), b.FullMoves())
+		hash := b.Hash()
+
+		require.True(t, b.PushNullMove())
+		assert.Equal(t, board.White, b.Turn())
+		assert.Equal(t, "rnbqkbnr/ppp1pppp/8/8/3pP3/5N2/PPPP1PPP/RNBQKB1R w KQkq - 1 4", fen.Encode(b.Position(), b.Turn(), b.NoProgress(), b.FullMoves()))
+		assert.Equal(t, zt.Hash(b.Position(), b.Turn()), b.Hash())
+
+		_, ok := b.LastMove()
+		assert.False(t, ok)
+		_, ok = b.PopMove()
+		assert.False(t, ok)
+
+		require.True(t, b.PopNullMove())
+		assert.Equal(t, start, fen.Encode(b.Position(), b.Turn(), b.NoProgress(), b.FullMoves()))
+		assert.Equal(t, hash, b.Hash())
+		assert.False(t, b.PopNullMove())
+
+		checked, err := fen.NewBoard("4k3/8/8/8/8/8/8/3KR3 b - - 0 1")
+		require.NoError(t, err)
+		require.False(t, checked.PushNullMove())
+	})
+}
@@ -1,6 +1,9 @@
 package board
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+)
 
 // Castling represents the set of castling rights. 4 bits.
 type Castling uint8
@@ -55,3 +58,56 @@ func CastlingRights(c Color) Castling {
 	}
 	return BlackCastlingRights
 }
+
+// CastlingRight returns the castling right for the given color and castling move type.
+func CastlingRight(c Color, t MoveType) Castling {
+	switch {
+	case t == KingSideCastle && c == White:
+		return WhiteKingSideCastle
+	case t == QueenSideCastle && c == White:
+		return WhiteQueenSideCastle
+	case t == KingSideCastle && c == Black:
+		return BlackKingSideCastle
+	case t == QueenSideCastle && c == Black:
+		return BlackQueenSideCastle
+	default:
+		return NoCastlingRights
+	}
+}
+
+// CastlingLayout represents the initial King and Rook files used for castling. Both colors
+// use the same files. In standard chess, the King starts on the E file and the Rooks on the
+// H and A files. In Chess960 (Fischer Random), the files depend on the start position, but
+// the King and Rook always end up on the standard squares after castling.
+type CastlingLayout struct {
+	King, KingSideRook, QueenSideRook File
+}
+
+// StandardCastlingLayout is the castling layout of standard chess.
+var StandardCastlingLayout = CastlingLayout{King: FileE, KingSideRook: FileH, QueenSideRook: FileA}
+
+// IsStandard returns true iff the layout is the standard chess layout.
+func (l CastlingLayout) IsStandard() bool {
+	return l == StandardCastlingLayout
+}
+
+// Rook returns the initial Rook file for the given castling move type.
+func (l CastlingLayout) Rook(t MoveType) File {
+	if t == QueenSideCastle {
+		return l.QueenSideRook
+	}
+	return l.KingSideRook
+}
+
+func (l CastlingLayout) String() string {
+	return fmt.Sprintf("%v%v%v", l.QueenSideRook, l.King, l.KingSideRook)
+}
+
+// CastlingTarget returns the King and Rook target files for the given castling move type.
+// They are the same for all layouts.
+func CastlingTarget(t MoveType) (File, File) {
+	if t == QueenSideCastle {
+		return FileC, FileD
+	}
+	return FileG, FileF
+}
@@ -0,0 +1,264 @@
+// Package epd contains utilities for reading and writing positions in Extended Position
+// Description (EPD), which is used by test suites, perft suites and analysis tools.
+package epd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/board/san"
+)
+
+// Standard opcodes.
+const (
+	BestMove    = "bm"
+	AvoidMove   = "am"
+	ID          = "id"
+	DirectMate  = "dm"
+	PredictedPV = "pv"
+	// HalfMoveClock and FullMoveNumber hold the FEN fields omitted by EPD, if present.
+	HalfMoveClock  = "hmvc"
+	FullMoveNumber = "fmvn"
+)
+
+// Operation is an EPD operation, such as: bm Nf3 Nc3;
+type Operation struct {
+	Opcode   string
+	Operands []string
+}
+
+func (o Operation) String() string {
+	var sb strings.Builder
+	sb.WriteString(o.Opcode)
+	for _, op := range o.Operands {
+		sb.WriteString(" ")
+		if strings.ContainsAny(op, " ;\"") {
+			sb.WriteString(strconv.Quote(op))
+		} else {
+			sb.WriteString(op)
+		}
+	}
+	sb.WriteString(";")
+	return sb.String()
+}
+
+// Record is an EPD record: a position without move counters followed by operations.
+type Record struct {
+	Position *board.Position
+	Turn     board.Color
+	Ops      []Operation
+}
+
+// Op returns the operands of the given opcode, if present.
+func (r Record) Op(opcode string) ([]string, bool) {
+	for _, o := range r.Ops {
+		if o.Opcode == opcode {
+			return o.Operands, true
+		}
+	}
+	return nil, false
+}
+
+// ID returns the "id" operand, if present.
+func (r Record) ID() string {
+	if v, ok := r.Op(ID); ok && len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// BestMoves returns the "bm" moves, if any.
+func (r Record) BestMoves() ([]board.Move, error) {
+	return r.moves(BestMove)
+}
+
+// AvoidMoves returns the "am" moves, if any.
+func (r Record) AvoidMoves() ([]board.Move, error) {
+	return r.moves(AvoidMove)
+}
+
+// Mate returns the "dm" number of moves to a direct mate, if present.
+func (r Record) Mate() (int, bool) {
+	v, ok := r.Op(DirectMate)
+	if !ok || len(v) == 0 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(v[0])
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// PV returns the "pv" predicted variation, if any. The moves are played in sequence.
+func (r Record) PV() ([]board.Move, error) {
+	v, _ := r.Op(PredictedPV)
+	if len(v) == 0 {
+		return nil, nil
+	}
+
+	b, err := fen.NewBoard(r.FEN())
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []board.Move
+	for _, str := range v {
+		m, err := san.Parse(b.Position(), b.Turn(), str)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pv: %w", err)
+		}
+		if !b.PushMove(m) {
+			return nil, fmt.Errorf("invalid pv: %w: %v", board.ErrIllegalMove, str)
+		}
+		ret = append(ret, m)
+	}
+	return ret, nil
+}
+
+// FEN returns the position in FEN notation. The move counters are taken from the "hmvc"
+// and "fmvn" opcodes, if present.
+func (r Record) FEN() string {
+	noprogress, fullmoves := 0, 1
+	if v, ok := r.Op(HalfMoveClock); ok && len(v) > 0 {
+		if n, err := strconv.Atoi(v[0]); err == nil {
+			noprogress = n
+		}
+	}
+	if v, ok := r.Op(FullMoveNumber); ok && len(v) > 0 {
+		if n, err := strconv.Atoi(v[0]); err == nil {
+			fullmoves = n
+		}
+	}
+	return fen.Encode(r.Position, r.Turn, noprogress, fullmoves)
+}
+
+func (r Record) moves(opcode string) ([]board.Move, error) {
+	v, _ := r.Op(opcode)
+
+	var ret []board.Move
+	for _, str := range v {
+		m, err := san.Parse(r.Position, r.Turn, str)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %v: %w", opcode, err)
+		}
+		ret = append(ret, m)
+	}
+	return ret, nil
+}
+
+func (r Record) String() string {
+	parts := strings.Split(fen.Encode(r.Position, r.Turn, 0, 1), " ")
+
+	var sb strings.Builder
+	sb.WriteString(strings.Join(parts[:4], " "))
+	for _, o := range r.Ops {
+		sb.WriteString(" ")
+		sb.WriteString(o.String())
+	}
+	return sb.String()
+}
+
+// Read reads all records from the given reader. Empty lines and lines starting with '#'
+// are ignored.
+func Read(r io.Reader) ([]Record, error) {
+	var ret []Record
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		str := strings.TrimSpace(scanner.Text())
+		if str == "" || strings.HasPrefix(str, "#") {
+			continue
+		}
+		rec, err := Parse(str)
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %w", line, err)
+		}
+		ret = append(ret, rec)
+	}
+	return ret, scanner.Err()
+}
+
+// Parse parses a single EPD record.
+//
+// Example:
+//
+//	"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - bm e4; id \"start\";"
+func Parse(str string) (Record, error) {
+	// An EPD record contains the first four FEN fields, followed by zero or more
+	// operations. Perft suites often use the form ";D1 20 ;D2 400", which is accepted.
+
+	fields := strings.Fields(str)
+	if len(fields) < 4 {
+		return Record{}, fmt.Errorf("invalid number of sections in EPD: '%v'", str)
+	}
+	pos, turn, _, _, err := fen.Decode(strings.Join(fields[:4], " ") + " 0 1")
+	if err != nil {
+		return Record{}, err
+	}
+
+	rest := str
+	for i := 0; i < 4; i++ {
+		rest = strings.TrimLeft(rest, " \t")
+		rest = rest[strings.IndexAny(rest+" ", " \t"):]
+	}
+
+	ops, err := parseOperations(rest)
+	if err != nil {
+		return Record{}, fmt.Errorf("invalid operations in EPD: '%v': %w", str, err)
+	}
+	return Record{Position: pos, Turn: turn, Ops: ops}, nil
+}
+
+func parseOperations(str string) ([]Operation, error) {
+	var ret []Operation
+
+	var cur []string
+	var sb strings.Builder
+	quoted, token := false, false
+
+	flush := func() {
+		if token {
+			cur = append(cur, sb.String())
+		}
+		sb.Reset()
+		token = false
+	}
+
+	for _, r := range str {
+		switch {
+		case quoted:
+			if r == '"' {
+				quoted = false
+			} else {
+				sb.WriteRune(r)
+			}
+		case r == '"':
+			quoted, token = true, true
+		case r == ';':
+			flush()
+			if len(cur) > 0 {
+				ret = append(ret, Operation{Opcode: cur[0], Operands: cur[1:]})
+			}
+			cur = nil
+		case r == ' ' || r == '\t':
+			flush()
+		default:
+			sb.WriteRune(r)
+			token = true
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated string")
+	}
+	flush()
+	if len(cur) > 0 {
+		ret = append(ret, Operation{Opcode: cur[0], Operands: cur[1:]}) // ok: last ';' missing
+	}
+	return ret, nil
+}
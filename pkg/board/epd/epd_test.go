@@ -0,0 +1,84 @@
+package epd_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/epd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	r, err := epd.Parse(`1k1r4/pp1b1R2/3q2pp/4p3/2B5/4Q3/PPP2B2/2K5 b - - bm Qd1+; am Qxa3; id "BK.01 test";`)
+	require.NoError(t, err)
+
+	assert.Equal(t, board.Black, r.Turn)
+	assert.Equal(t, "BK.01 test", r.ID())
+	assert.Equal(t, "1k1r4/pp1b1R2/3q2pp/4p3/2B5/4Q3/PPP2B2/2K5 b - - 0 1", r.FEN())
+
+	bm, err := r.BestMoves()
+	require.NoError(t, err)
+	assert.Equal(t, "Qd6-d1", board.PrintMoves(bm))
+
+	_, ok := r.Mate()
+	assert.False(t, ok)
+
+	assert.Equal(t, `1k1r4/pp1b1R2/3q2pp/4p3/2B5/4Q3/PPP2B2/2K5 b - - bm Qd1+; am Qxa3; id "BK.01 test";`, r.String())
+}
+
+func TestParseOpcodes(t *testing.T) {
+	tests := []struct {
+		epd      string
+		fen      string
+		mate     int
+		bm, pv   string
+		expected []string
+	}{
+		{"7k/8/6K1/8/8/8/8/R7 w - - dm 1; bm Ra8#; pv Ra8#;", "7k/8/6K1/8/8/8/8/R7 w - - 0 1", 1, "Ra1-a8", "Ra1-a8", nil},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - hmvc 3; fmvn 12; pv e4 e5 Nf3;", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 3 12", 0, "", "e2-e4 e7-e5 Ng1-f3", nil},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - ;D1 20 ;D2 400", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", 0, "", "", []string{"20", "400"}},
+	}
+
+	for _, tt := range tests {
+		r, err := epd.Parse(tt.epd)
+		require.NoError(t, err)
+
+		assert.Equal(t, tt.fen, r.FEN())
+
+		mate, _ := r.Mate()
+		assert.Equal(t, tt.mate, mate)
+
+		bm, err := r.BestMoves()
+		require.NoError(t, err)
+		assert.Equal(t, tt.bm, board.PrintMoves(bm))
+
+		pv, err := r.PV()
+		require.NoError(t, err)
+		assert.Equal(t, tt.pv, board.PrintMoves(pv))
+
+		if tt.expected != nil {
+			d1, _ := r.Op("D1")
+			d2, _ := r.Op("D2")
+			assert.Equal(t, tt.expected, append(d1, d2...))
+		}
+	}
+}
+
+func TestRead(t *testing.T) {
+	records, err := epd.Read(strings.NewReader(`# comment
+4k3/8/8/8/8/8/8/4K3 w - - id "a";
+
+4k3/8/8/8/8/8/8/4K3 b - - id "b";
+`))
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "a", records[0].ID())
+	assert.Equal(t, "b", records[1].ID())
+
+	_, err = epd.Read(strings.NewReader("4k3/8/8/8/8/8/8/4K3 w -"))
+	assert.Error(t, err)
+	_, err = epd.Parse(`4k3/8/8/8/8/8/8/4K3 w - - id "a;`)
+	assert.Error(t, err)
+}
@@ -14,6 +14,25 @@ const (
 	Initial = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
 )
 
+// ErrInvalidFEN is an error indicating that a FEN description is invalid. The reason names
+// the invalid part, such as "castling".
+type ErrInvalidFEN struct {
+	FEN    string
+	Reason string
+	Err    error // underlying error, if any
+}
+
+func (e ErrInvalidFEN) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("invalid %v in FEN: '%v': %v", e.Reason, e.FEN, e.Err)
+	}
+	return fmt.Sprintf("invalid %v in FEN: '%v'", e.Reason, e.FEN)
+}
+
+func (e ErrInvalidFEN) Unwrap() error {
+	return e.Err
+}
+
 // NewBoard returns a Board for the position with moves. Convenience function.
 func NewBoard(startpos string, moves ...string) (*board.Board, error) {
 	pos, turn, np, fm, err := Decode(startpos)
@@ -23,18 +42,30 @@ func NewBoard(startpos string, moves ...string) (*board.Board, error) {
 
 	b := board.NewBoard(board.NewZobristTable(0), pos, turn, np, fm)
 	for _, m := range moves {
-		move, err := board.ParseMove(m)
+		candidate, err := board.ParseMove(m)
 		if err != nil {
-			return nil, fmt.Errorf("invalid move: %v", m)
+			return nil, fmt.Errorf("invalid move: %v: %w", m, err)
 		}
-		if !b.PushMove(move) {
-			return nil, fmt.Errorf("illegal move: %v", m)
+		move, ok := findMove(b, candidate)
+		if !ok || !b.PushMove(move) {
+			return nil, fmt.Errorf("%w: %v", board.ErrIllegalMove, m)
 		}
 	}
 
 	return b, nil
 }
 
+// findMove returns the pseudo-legal move that matches the parsed candidate, which lacks the
+// move type, piece and capture.
+func findMove(b *board.Board, candidate board.Move) (board.Move, bool) {
+	for _, m := range b.Position().PseudoLegalMoves(b.Turn()) {
+		if m.Equals(candidate) {
+			return m, true
+		}
+	}
+	return board.Move{}, false
+}
+
 // Decode returns a new position and game status from a FEN description.
 //
 // Example:
@@ -45,7 +76,7 @@ func Decode(fen string) (*board.Position, board.Color, int, int, error) {
 
 	parts := strings.Split(strings.TrimSpace(fen), " ")
 	if len(parts) != 6 {
-		return nil, 0, 0, 0, fmt.Errorf("invalid number of sections in FEN: '%v'", fen)
+		return nil, 0, 0, 0, ErrInvalidFEN{FEN: fen, Reason: "number of sections"}
 	}
 
 	// (1) Piece placement (from white's perspective). Each rank is described,
@@ -74,34 +105,41 @@ func Decode(fen string) (*board.Position, board.Color, int, int, error) {
 
 			color, piece, ok := parsePiece(r)
 			if !ok {
-				return nil, 0, 0, 0, fmt.Errorf("invalid piece '%v' in FEN: '%v'", r, fen)
+				return nil, 0, 0, 0, ErrInvalidFEN{FEN: fen, Reason: fmt.Sprintf("piece '%c'", r)}
 			}
 			pieces = append(pieces, board.Placement{Square: sq, Color: color, Piece: piece})
 			sq--
 
 		default:
-			return nil, 0, 0, 0, fmt.Errorf("invalid character in FEN: '%v'", fen)
+			return nil, 0, 0, 0, ErrInvalidFEN{FEN: fen, Reason: "character"}
 		}
 	}
 	if sq+1 != board.H1 {
-		return nil, 0, 0, 0, fmt.Errorf("invalid number of squares in FEN: '%v'", fen)
+		return nil, 0, 0, 0, ErrInvalidFEN{FEN: fen, Reason: "number of squares"}
 	}
 
 	// (2) Active color. "w" means white moves next, "b" means black.
 
 	active, ok := parseColor(parts[1])
 	if !ok {
-		return nil, 0, 0, 0, fmt.Errorf("invalid active color in FEN: '%v'", fen)
+		return nil, 0, 0, 0, ErrInvalidFEN{FEN: fen, Reason: "active color"}
 	}
 
 	// (3) Castling availability. If neither side can castle, this is
 	// "-". Otherwise, this has one or more letters: "K" (White can castle
 	// kingside), "Q" (White can castle queenside), "k" (Black can castle
 	// kingside), and/or "q" (Black can castle queenside).
+	//
+	// For Chess960, the X-FEN and Shredder-FEN extensions are also accepted, where the castling
+	// Rook may be identified by its file, such as "HAha". "KQkq" then refer to the outermost Rook.
 
-	castling, ok := parseCastling(parts[2])
+	placement, err := board.NewPosition(pieces, board.NoCastlingRights, board.ZeroSquare)
+	if err != nil {
+		return nil, 0, 0, 0, ErrInvalidFEN{FEN: fen, Reason: "piece placement", Err: err}
+	}
+	castling, layout, ok := parseCastling(parts[2], placement)
 	if !ok {
-		return nil, 0, 0, 0, fmt.Errorf("invalid castling in FEN: '%v'", fen)
+		return nil, 0, 0, 0, ErrInvalidFEN{FEN: fen, Reason: "castling"}
 	}
 
 	// (4) En passant target square in algebraic notation. If there's no en
@@ -112,7 +150,7 @@ func Decode(fen string) (*board.Position, board.Color, int, int, error) {
 	if parts[3] != "-" {
 		sq, err := board.ParseSquareStr(parts[3])
 		if err != nil {
-			return nil, 0, 0, 0, fmt.Errorf("invalid en passant in FEN: '%v'", fen)
+			return nil, 0, 0, 0, ErrInvalidFEN{FEN: fen, Reason: "en passant", Err: err}
 		}
 		ep = sq
 	}
@@ -123,7 +161,7 @@ func Decode(fen string) (*board.Position, board.Color, int, int, error) {
 
 	np, err := strconv.Atoi(parts[4])
 	if err != nil || np < 0 {
-		return nil, 0, 0, 0, fmt.Errorf("invalid halfmove in FEN: '%v'", fen)
+		return nil, 0, 0, 0, ErrInvalidFEN{FEN: fen, Reason: "halfmove"}
 	}
 
 	// (6) Fullmove number: The number of the full move. It starts at 1, and is
@@ -131,10 +169,10 @@ func Decode(fen string) (*board.Position, board.Color, int, int, error) {
 
 	fm, err := strconv.Atoi(parts[5])
 	if err != nil || fm < 0 {
-		return nil, 0, 0, 0, fmt.Errorf("invalid full moves in FEN: '%v'", fen)
+		return nil, 0, 0, 0, ErrInvalidFEN{FEN: fen, Reason: "full moves"}
 	}
 
-	pos, _ := board.NewPosition(pieces, castling, ep)
+	pos, _ := board.NewPositionWithLayout(pieces, castling, layout, ep)
 	return pos, active, np, fm, nil
 }
 
@@ -169,7 +207,7 @@ func Encode(pos *board.Position, c board.Color, noprogress, fullmoves int) strin
 	}
 
 	turn := printColor(c)
-	castling := printCastling(pos.Castling())
+	castling := printCastling(pos)
 
 	ep := "-"
 	if sq, ok := pos.EnPassant(); ok {
@@ -185,48 +223,118 @@ func Strip(pos string) string {
 	return strings.Join(parts[:4], " ")
 }
 
-func parseCastling(str string) (board.Castling, bool) {
+func parseCastling(str string, pos *board.Position) (board.Castling, board.CastlingLayout, bool) {
 	var ret board.Castling
+	layout := board.StandardCastlingLayout
 
 	if str == "-" {
-		return ret, true
+		return ret, layout, true
 	}
 	for _, r := range []rune(str) {
+		c := board.White
+		if unicode.IsLower(r) {
+			c = board.Black
+		}
+		rank := board.RelativeRank(c, board.Rank1)
+
+		// The King file is known from the position, if present on its home rank. Otherwise,
+		// the standard layout is assumed.
+
+		king, hasKing := findKing(pos, c, rank)
+		if hasKing {
+			layout.King = king
+		}
+
+		var t board.MoveType
 		switch r {
-		case 'K':
-			ret |= board.WhiteKingSideCastle
-		case 'Q':
-			ret |= board.WhiteQueenSideCastle
-		case 'k':
-			ret |= board.BlackKingSideCastle
-		case 'q':
-			ret |= board.BlackQueenSideCastle
+		case 'K', 'k':
+			t = board.KingSideCastle
+			if f, ok := outermostRook(pos, c, rank, layout.King, t); ok {
+				layout.KingSideRook = f
+			}
+		case 'Q', 'q':
+			t = board.QueenSideCastle
+			if f, ok := outermostRook(pos, c, rank, layout.King, t); ok {
+				layout.QueenSideRook = f
+			}
 		default:
-			return 0, false
+			f, ok := board.ParseFile(r)
+			if !ok || !hasKing || f == king {
+				return 0, layout, false
+			}
+			if f < king {
+				t = board.KingSideCastle
+				layout.KingSideRook = f
+			} else {
+				t = board.QueenSideCastle
+				layout.QueenSideRook = f
+			}
 		}
+		ret |= board.CastlingRight(c, t)
 	}
-	return ret, true
+	return ret, layout, true
 }
 
-func printCastling(c board.Castling) string {
+func printCastling(pos *board.Position) string {
+	c := pos.Castling()
 	if c == 0 {
 		return "-"
 	}
 
-	ret := ""
-	if c.IsAllowed(board.WhiteKingSideCastle) {
-		ret += "K"
+	// Use X-FEN for Chess960, where the Rook file is given only if not the outermost Rook.
+
+	layout := pos.CastlingLayout()
+	right := func(color board.Color, t board.MoveType, std string) string {
+		if !c.IsAllowed(board.CastlingRight(color, t)) {
+			return ""
+		}
+		if layout.IsStandard() {
+			return std
+		}
+		if f, ok := outermostRook(pos, color, board.RelativeRank(color, board.Rank1), layout.King, t); ok && f == layout.Rook(t) {
+			return std
+		}
+		if color == board.White {
+			return strings.ToUpper(layout.Rook(t).String())
+		}
+		return layout.Rook(t).String()
 	}
-	if c.IsAllowed(board.WhiteQueenSideCastle) {
-		ret += "Q"
+
+	ret := ""
+	ret += right(board.White, board.KingSideCastle, "K")
+	ret += right(board.White, board.QueenSideCastle, "Q")
+	ret += right(board.Black, board.KingSideCastle, "k")
+	ret += right(board.Black, board.QueenSideCastle, "q")
+	return ret
+}
+
+// findKing returns the file of the King of the given color, if on the given rank.
+func findKing(pos *board.Position, c board.Color, rank board.Rank) (board.File, bool) {
+	for f := board.ZeroFile; f < board.NumFiles; f++ {
+		if color, piece, ok := pos.Square(board.NewSquare(f, rank)); ok && color == c && piece == board.King {
+			return f, true
+		}
 	}
-	if c.IsAllowed(board.BlackKingSideCastle) {
-		ret += "k"
+	return 0, false
+}
+
+// outermostRook returns the file of the outermost Rook of the given color on the given side
+// of the King, if any.
+func outermostRook(pos *board.Position, c board.Color, rank board.Rank, king board.File, t board.MoveType) (board.File, bool) {
+	if t == board.KingSideCastle {
+		for f := board.FileH; f < king; f++ {
+			if color, piece, ok := pos.Square(board.NewSquare(f, rank)); ok && color == c && piece == board.Rook {
+				return f, true
+			}
+		}
+		return 0, false
 	}
-	if c.IsAllowed(board.BlackQueenSideCastle) {
-		ret += "q"
+	for f := board.FileA; f > king; f-- {
+		if color, piece, ok := pos.Square(board.NewSquare(f, rank)); ok && color == c && piece == board.Rook {
+			return f, true
+		}
 	}
-	return ret
+	return 0, false
 }
 
 func parseColor(str string) (board.Color, bool) {
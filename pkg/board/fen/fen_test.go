@@ -1,8 +1,10 @@
 package fen_test
 
 import (
+	"errors"
 	"testing"
 
+	"github.com/herohde/morlock/pkg/board"
 	"github.com/herohde/morlock/pkg/board/fen"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -13,6 +15,8 @@ func TestDecode(t *testing.T) {
 		fen.Initial,
 		"4k3/2pppp2/8/4P1K1/4PP2/3P4/8/8 w - - 0 1",
 		"rnbqkbnr/pppppppp/8/8/8/5P2/PPPPP1PP/RNBQKBNR w KQkq - 0 1",
+		"bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w KQkq - 2 9", // Chess960
+		"1k2r2r/8/8/8/8/8/8/1K2R2R w Ee - 0 1",                              // Chess960: inner rook
 	}
 
 	for _, tt := range tests {
@@ -21,5 +25,50 @@ func TestDecode(t *testing.T) {
 
 		assert.Equal(t, tt, fen.Encode(p, c, np, fm))
 	}
+}
+
+func TestDecodeChess960(t *testing.T) {
+	tests := []struct {
+		fen              string
+		layout, expected string
+	}{
+		{fen.Initial, "aeh", fen.Initial},
+		{"bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9", "fgh", "bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w KQkq - 2 9"},
+		{"1k2r2r/8/8/8/8/8/8/1K2R2R w Ee - 0 1", "abe", "1k2r2r/8/8/8/8/8/8/1K2R2R w Ee - 0 1"},
+		{"rk5r/8/8/8/8/8/8/RK5R b Kk - 0 1", "abh", "rk5r/8/8/8/8/8/8/RK5R b Kk - 0 1"},
+	}
+
+	for _, tt := range tests {
+		p, c, np, fm, err := fen.Decode(tt.fen)
+		require.NoError(t, err)
+
+		assert.Equal(t, tt.layout, p.CastlingLayout().String())
+		assert.Equal(t, tt.expected, fen.Encode(p, c, np, fm))
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	tests := []struct {
+		fen    string
+		reason string
+	}{
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0", "number of sections"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNX w KQkq - 0 1", "piece 'X'"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBN w KQkq - 0 1", "number of squares"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR x KQkq - 0 1", "active color"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq z9 0 1", "en passant"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - x 1", "halfmove"},
+	}
+
+	for _, tt := range tests {
+		_, _, _, _, err := fen.Decode(tt.fen)
+
+		var actual fen.ErrInvalidFEN
+		require.True(t, errors.As(err, &actual), "failed: %v", tt.fen)
+		assert.Equal(t, tt.fen, actual.FEN)
+		assert.Equal(t, tt.reason, actual.Reason)
+	}
 
+	_, err := fen.NewBoard(fen.Initial, "e2e4", "e7e5", "e4e5")
+	assert.True(t, errors.Is(err, board.ErrIllegalMove))
 }
@@ -0,0 +1,169 @@
+package board
+
+// Geometry helpers for squares, such as distances and directions. Evaluation terms that
+// depend on proximity, such as king tropism, should use these helpers instead of re-deriving
+// them from ranks and files.
+
+// IsSameRankOrFile returns true if the squares are on the same rank or same file.
+func IsSameRankOrFile(a, b Square) bool {
+	return a.File() == b.File() || a.Rank() == b.Rank()
+}
+
+// IsSameDiagonal returns true if the squares are on the same diagonal.
+func IsSameDiagonal(a, b Square) bool {
+	x, y := a.File().V()-b.File().V(), a.Rank().V()-b.Rank().V()
+	return x == y || x == -y
+}
+
+// Distance returns the Chebyshev distance between two squares, i.e., the number of King
+// moves needed to go from one to the other on an empty board. Range [0;7].
+func Distance(a, b Square) int {
+	return int(distance[a][b])
+}
+
+// ManhattanDistance returns the sum of the rank and file distances between two squares.
+// Range [0;14].
+func ManhattanDistance(a, b Square) int {
+	return int(manhattan[a][b])
+}
+
+// KingTropism returns the closeness of a square to a King square as 14 minus the Manhattan
+// distance. Higher values mean closer. Range [0;14].
+func KingTropism(sq, king Square) int {
+	return 14 - int(manhattan[sq][king])
+}
+
+var (
+	distance  [NumSquares][NumSquares]uint8
+	manhattan [NumSquares][NumSquares]uint8
+)
+
+func init() {
+	for a := ZeroSquare; a < NumSquares; a++ {
+		for b := ZeroSquare; b < NumSquares; b++ {
+			x, y := abs(a.File().V()-b.File().V()), abs(a.Rank().V()-b.Rank().V())
+
+			distance[a][b] = uint8(max(x, y))
+			manhattan[a][b] = uint8(x + y)
+		}
+	}
+}
+
+// Direction represents one of the 8 directions on the board, as seen from White. 3 bits.
+type Direction uint8
+
+const (
+	North Direction = iota
+	NorthEast
+	East
+	SouthEast
+	South
+	SouthWest
+	West
+	NorthWest
+)
+
+// Iteration helpers to enable "for d := ZeroDirection; d<NumDirections; d++".
+const (
+	ZeroDirection Direction = 0
+	NumDirections Direction = 8
+)
+
+var (
+	// RookDirections are the directions a Rook moves in.
+	RookDirections = []Direction{North, East, South, West}
+	// BishopDirections are the directions a Bishop moves in.
+	BishopDirections = []Direction{NorthEast, SouthEast, SouthWest, NorthWest}
+)
+
+// offsets holds the (file, rank) offset of each direction. Note that files are numbered
+// from FileH=0, so East decreases the file.
+var offsets = [NumDirections][2]int{
+	{0, 1}, {-1, 1}, {-1, 0}, {-1, -1}, {0, -1}, {1, -1}, {1, 0}, {1, 1},
+}
+
+// IsDiagonal returns true iff the direction is diagonal.
+func (d Direction) IsDiagonal() bool {
+	return d&1 == 1
+}
+
+// Opposite returns the opposite direction.
+func (d Direction) Opposite() Direction {
+	return (d + 4) % NumDirections
+}
+
+// Step returns the adjacent square in the given direction, if on the board.
+func (d Direction) Step(sq Square) (Square, bool) {
+	f, r := sq.File().V()+offsets[d][0], sq.Rank().V()+offsets[d][1]
+	if f < 0 || f >= int(NumFiles) || r < 0 || r >= int(NumRanks) {
+		return 0, false
+	}
+	return NewSquare(File(f), Rank(r)), true
+}
+
+// Ray returns the squares from the given square, exclusive, to the edge of the board in
+// the given direction.
+func (d Direction) Ray(sq Square) []Square {
+	var ret []Square
+	for next, ok := d.Step(sq); ok; next, ok = d.Step(next) {
+		ret = append(ret, next)
+	}
+	return ret
+}
+
+func (d Direction) String() string {
+	switch d {
+	case North:
+		return "N"
+	case NorthEast:
+		return "NE"
+	case East:
+		return "E"
+	case SouthEast:
+		return "SE"
+	case South:
+		return "S"
+	case SouthWest:
+		return "SW"
+	case West:
+		return "W"
+	case NorthWest:
+		return "NW"
+	default:
+		return "?"
+	}
+}
+
+// DirectionOf returns the direction from one square to another, if they are on the same
+// rank, file or diagonal and not the same square.
+func DirectionOf(from, to Square) (Direction, bool) {
+	if from == to || !(IsSameRankOrFile(from, to) || IsSameDiagonal(from, to)) {
+		return 0, false
+	}
+
+	x, y := sign(to.File().V()-from.File().V()), sign(to.Rank().V()-from.Rank().V())
+	for d := ZeroDirection; d < NumDirections; d++ {
+		if offsets[d][0] == x && offsets[d][1] == y {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func sign(x int) int {
+	switch {
+	case x < 0:
+		return -1
+	case x > 0:
+		return 1
+	default:
+		return 0
+	}
+}
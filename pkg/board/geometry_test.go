@@ -0,0 +1,64 @@
+package board_test
+
+import (
+	"testing"
+
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistance(t *testing.T) {
+	assert.Equal(t, 0, board.Distance(board.E4, board.E4))
+	assert.Equal(t, 1, board.Distance(board.E4, board.F5))
+	assert.Equal(t, 7, board.Distance(board.A1, board.H8))
+	assert.Equal(t, 7, board.Distance(board.A1, board.B8))
+
+	assert.Equal(t, 2, board.ManhattanDistance(board.E4, board.F5))
+	assert.Equal(t, 14, board.ManhattanDistance(board.A1, board.H8))
+	assert.Equal(t, 8, board.ManhattanDistance(board.A1, board.B8))
+
+	assert.Equal(t, 14, board.KingTropism(board.G1, board.G1))
+	assert.Equal(t, 0, board.KingTropism(board.A8, board.H1))
+}
+
+func TestDirection(t *testing.T) {
+	next, ok := board.North.Step(board.E4)
+	assert.True(t, ok)
+	assert.Equal(t, board.E5, next)
+
+	next, ok = board.East.Step(board.E4)
+	assert.True(t, ok)
+	assert.Equal(t, board.F4, next)
+
+	_, ok = board.SouthWest.Step(board.A4)
+	assert.False(t, ok)
+
+	assert.Equal(t, []board.Square{board.F2, board.G3, board.H4}, board.NorthEast.Ray(board.E1))
+	assert.Empty(t, board.West.Ray(board.A5))
+
+	assert.Equal(t, board.South, board.North.Opposite())
+	assert.Equal(t, board.SouthWest, board.NorthEast.Opposite())
+	assert.True(t, board.NorthWest.IsDiagonal())
+	assert.False(t, board.West.IsDiagonal())
+
+	tests := []struct {
+		from, to board.Square
+		expected board.Direction
+		ok       bool
+	}{
+		{board.E1, board.E8, board.North, true},
+		{board.E1, board.A5, board.NorthWest, true},
+		{board.H8, board.A1, board.SouthWest, true},
+		{board.C3, board.H3, board.East, true},
+		{board.B1, board.C3, 0, false},
+		{board.D4, board.D4, 0, false},
+	}
+
+	for _, tt := range tests {
+		actual, ok := board.DirectionOf(tt.from, tt.to)
+		assert.Equal(t, tt.ok, ok, "%v->%v", tt.from, tt.to)
+		if ok {
+			assert.Equal(t, tt.expected, actual, "%v->%v", tt.from, tt.to)
+		}
+	}
+}
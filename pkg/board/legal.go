@@ -0,0 +1,228 @@
+package board
+
+// LegalMoves returns a list of all legal moves. The moves are generated directly using check
+// and pin masks, so that only en passant and castling moves need to be tried. The order is the
+// same as for PseudoLegalMoves.
+func (p *Position) LegalMoves(turn Color) []Move {
+	return p.LegalMovesInto(turn, make([]Move, 0, 50))
+}
+
+// LegalMovesInto returns the legal moves like LegalMoves, but generated into the given buffer
+// to avoid allocation. Convenient for counting legal moves, such as at perft leaves.
+func (p *Position) LegalMovesInto(turn Color, buf []Move) []Move {
+	king := p.pieces[turn][King]
+	if king.PopCount() != 1 {
+		return p.legalMovesByTrial(turn, buf) // no unique king: no pins or checks
+	}
+	k := king.LastPopSquare()
+	return p.legalMoves(turn, k, p.attackers(turn.Opponent(), k, p.All()), buf)
+}
+
+// EvasionMoves returns a list of all legal moves, if the side is in check. The moves are then
+// captures of the checking piece, blocks or King moves. Cheaper than trying all pseudo-legal
+// moves. The order is the same as for PseudoLegalMoves. Returns false if not in check.
+func (p *Position) EvasionMoves(turn Color) ([]Move, bool) {
+	return p.EvasionMovesInto(turn, make([]Move, 0, 16))
+}
+
+// EvasionMovesInto returns the legal moves if in check like EvasionMoves, but generated into
+// the given buffer to avoid allocation. The buffer is not used if not in check.
+func (p *Position) EvasionMovesInto(turn Color, buf []Move) ([]Move, bool) {
+	king := p.pieces[turn][King]
+	if king.PopCount() != 1 {
+		return nil, false
+	}
+	k := king.LastPopSquare()
+
+	checkers := p.attackers(turn.Opponent(), k, p.All())
+	if checkers == EmptyBitboard {
+		return nil, false
+	}
+	return p.legalMoves(turn, k, checkers, buf), true
+}
+
+// legalMoves returns the legal moves for the side with the King at the given square and
+// the given checking pieces, generated into the given buffer.
+func (p *Position) legalMoves(turn Color, k Square, checkers Bitboard, buf []Move) []Move {
+	king := BitMask(k)
+	opp := turn.Opponent()
+	own := p.pieces[turn][NoPiece]
+	captures := p.pieces[opp][NoPiece]
+	moves := ^captures
+	all := p.All()
+	jumps := PawnJumpRank(turn)
+	promos := PawnPromotionRank(turn)
+
+	// (1) Determine pins. If in check, other pieces must capture the checking piece or
+	// block the check. If in double check, only the King can move.
+
+	mask := ^own
+	switch checkers.PopCount() {
+	case 0:
+		// ok
+	case 1:
+		c := checkers.LastPopSquare()
+		mask &= checkers | between[k][c]
+	default:
+		mask = EmptyBitboard
+	}
+	pinned := p.pinned(turn, k)
+
+	ret := buf[:0]
+
+	// (2) Emit moves for pieces other than the King. A pinned piece must stay on the line
+	// through the King and the pinning piece.
+
+	if mask != EmptyBitboard {
+		for _, piece := range QueenRookKnightBishop {
+			pieces := p.pieces[turn][piece]
+			for pieces != EmptyBitboard {
+				from := pieces.LastPopSquare()
+				pieces ^= BitMask(from)
+
+				allowed := mask
+				if pinned.IsSet(from) {
+					allowed &= line[k][from]
+				}
+
+				attackboard := Attackboard(all, from, piece) & allowed
+				p.emitMove(turn, Normal, piece, from, attackboard&moves, &ret)
+				p.emitMove(turn, Capture, piece, from, attackboard&captures, &ret)
+			}
+		}
+
+		pawns := p.pieces[turn][Pawn]
+		for pawns != EmptyBitboard {
+			from := pawns.LastPopSquare()
+			origin := BitMask(from)
+			pawns ^= origin
+
+			allowed := mask
+			if pinned.IsSet(from) {
+				allowed &= line[k][from]
+			}
+
+			captureboard := PawnCaptureboard(turn, origin)
+			pushboard := PawnMoveboard(all, turn, origin)
+			jumpboard := PawnMoveboard(all, turn, pushboard) & jumps & allowed
+			pushboard &= allowed
+
+			p.emitMove(turn, Capture, Pawn, from, captureboard&allowed&captures&^promos, &ret)
+			p.emitMove(turn, Push, Pawn, from, pushboard&^promos, &ret)
+			p.emitMove(turn, Jump, Pawn, from, jumpboard, &ret)
+
+			p.emitPromo(turn, CapturePromotion, Pawn, from, captureboard&allowed&captures&promos, &ret)
+			p.emitPromo(turn, Promotion, Pawn, from, pushboard&promos, &ret)
+
+			if p.enpassant != ZeroSquare && captureboard.IsSet(p.enpassant) {
+				// En passant removes two pieces from the rank of the King, so
+				// try the move instead of using the masks.
+
+				m := Move{Type: EnPassant, Piece: Pawn, From: from, To: p.enpassant}
+				if p.IsLegal(m) {
+					ret = append(ret, m)
+				}
+			}
+		}
+	}
+
+	// (3) Emit King moves. The King cannot move to an attacked square, incl. away from a
+	// checking slider along its line.
+
+	without := all ^ king
+	targets := KingAttackboard(k) &^ own
+	safe := EmptyBitboard
+	for targets != EmptyBitboard {
+		to := targets.LastPopSquare()
+		targets ^= BitMask(to)
+
+		if p.attackers(opp, to, without) == EmptyBitboard {
+			safe |= BitMask(to)
+		}
+	}
+	p.emitMove(turn, Normal, King, k, safe&moves, &ret)
+	p.emitMove(turn, Capture, King, k, safe&captures, &ret)
+
+	if checkers == EmptyBitboard {
+		for _, t := range []MoveType{KingSideCastle, QueenSideCastle} {
+			if m, ok := p.castlingMove(turn, t, k); ok && p.isCastlingLegal(turn, m) {
+				ret = append(ret, m)
+			}
+		}
+	}
+
+	return ret
+}
+
+// legalMovesByTrial returns a list of all legal moves by trying each pseudo-legal move,
+// generated into the given buffer.
+func (p *Position) legalMovesByTrial(turn Color, buf []Move) []Move {
+	ret := buf[:0]
+	for _, m := range p.PseudoLegalMoves(turn) {
+		if p.IsLegal(m) {
+			ret = append(ret, m)
+		}
+	}
+	return ret
+}
+
+// attackers returns the pieces of the given color that attack the square, given the
+// population of the board. Does not include en passant.
+func (p *Position) attackers(c Color, sq Square, all Bitboard) Bitboard {
+	pieces := &p.pieces[c]
+
+	ret := PawnCaptureboard(c.Opponent(), BitMask(sq)) & pieces[Pawn]
+	ret |= KnightAttackboard(sq) & pieces[Knight]
+	ret |= KingAttackboard(sq) & pieces[King]
+	ret |= RookAttackboard(all, sq) & (pieces[Rook] | pieces[Queen])
+	ret |= BishopAttackboard(all, sq) & (pieces[Bishop] | pieces[Queen])
+	return ret
+}
+
+// pinned returns the pieces of the given color that are pinned to the King at the square.
+func (p *Position) pinned(c Color, k Square) Bitboard {
+	opp := &p.pieces[c.Opponent()]
+	all := p.All()
+
+	snipers := RookAttackboard(opp[NoPiece], k) & (opp[Rook] | opp[Queen])
+	snipers |= BishopAttackboard(opp[NoPiece], k) & (opp[Bishop] | opp[Queen])
+
+	var ret Bitboard
+	for snipers != EmptyBitboard {
+		sq := snipers.LastPopSquare()
+		snipers ^= BitMask(sq)
+
+		if b := between[k][sq] & all; b.PopCount() == 1 && b&p.pieces[c][NoPiece] != 0 {
+			ret |= b
+		}
+	}
+	return ret
+}
+
+var (
+	// between holds the squares strictly between two squares on the same rank, file or
+	// diagonal. Empty if not aligned.
+	between [NumSquares][NumSquares]Bitboard
+	// line holds the full rank, file or diagonal through two aligned squares. Empty if
+	// not aligned.
+	line [NumSquares][NumSquares]Bitboard
+)
+
+func init() {
+	for sq := ZeroSquare; sq < NumSquares; sq++ {
+		for _, d := range append(rookDirections, bishopDirections...) {
+			full := BitMask(sq) | raytrace(sq, []direction{d}, EmptyBitboard, false) | raytrace(sq, []direction{{-d.rank, -d.file}}, EmptyBitboard, false)
+
+			ray := EmptyBitboard
+			rank, file := sq.Rank().V()+d.rank, sq.File().V()+d.file
+			for 0 <= rank && rank < 8 && 0 <= file && file < 8 {
+				to := NewSquare(File(file), Rank(rank))
+				between[sq][to] = ray
+				line[sq][to] = full
+
+				ray |= BitMask(to)
+				rank, file = rank+d.rank, file+d.file
+			}
+		}
+	}
+}
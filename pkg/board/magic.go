@@ -0,0 +1,129 @@
+package board
+
+import (
+	"math/rand"
+)
+
+// RookAttackboard returns all potential moves/attacks for a Rook at the given square, given
+// the population of the board.
+func RookAttackboard(all Bitboard, sq Square) Bitboard {
+	return rook[sq].attackboard(all)
+}
+
+// BishopAttackboard returns all potential moves/attacks for a Bishop at the given square,
+// given the population of the board.
+func BishopAttackboard(all Bitboard, sq Square) Bitboard {
+	return bishop[sq].attackboard(all)
+}
+
+// magic represents a "magic bitboard" lookup for a sliding piece on a given square. The
+// relevant blockers -- the rays from the square, excluding the edges -- are multiplied by a
+// magic number, such that the top bits form a perfect hash of the attacks. The magic numbers
+// are found by trial and error on startup with a fixed seed.
+//
+// See: https://www.chessprogramming.org/Magic_Bitboards.
+type magic struct {
+	mask    Bitboard
+	magic   uint64
+	shift   uint8
+	attacks []Bitboard
+}
+
+func (m *magic) attackboard(all Bitboard) Bitboard {
+	return m.attacks[(uint64(all&m.mask)*m.magic)>>m.shift]
+}
+
+var (
+	rook, bishop [NumSquares]magic
+)
+
+// direction is a (rank, file) ray direction.
+type direction struct {
+	rank, file int
+}
+
+var (
+	rookDirections   = []direction{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+	bishopDirections = []direction{{1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+)
+
+func init() {
+	r := rand.New(rand.NewSource(1))
+	for sq := ZeroSquare; sq < NumSquares; sq++ {
+		rook[sq] = findMagic(r, sq, rookDirections)
+		bishop[sq] = findMagic(r, sq, bishopDirections)
+	}
+}
+
+// findMagic finds a magic number for the given square and directions.
+func findMagic(r *rand.Rand, sq Square, dirs []direction) magic {
+	mask := raytrace(sq, dirs, EmptyBitboard, true)
+	bits := mask.PopCount()
+
+	// Enumerate all subsets of the mask with the Carry-Rippler trick.
+
+	var blockers, attacks []Bitboard
+	subset := EmptyBitboard
+	for {
+		blockers = append(blockers, subset)
+		attacks = append(attacks, raytrace(sq, dirs, subset, false))
+
+		subset = (subset - mask) & mask
+		if subset == EmptyBitboard {
+			break
+		}
+	}
+
+	ret := magic{mask: mask, shift: uint8(64 - bits), attacks: make([]Bitboard, 1<<bits)}
+	used := make([]bool, 1<<bits)
+	for {
+		candidate := r.Uint64() & r.Uint64() & r.Uint64() // sparse
+		if Bitboard((uint64(mask)*candidate)>>56).PopCount() < 6 {
+			continue
+		}
+		ret.magic = candidate
+
+		for i := range used {
+			used[i] = false
+		}
+
+		ok := true
+		for i, b := range blockers {
+			index := (uint64(b) * candidate) >> ret.shift
+			if used[index] && ret.attacks[index] != attacks[i] {
+				ok = false
+				break
+			}
+			used[index] = true
+			ret.attacks[index] = attacks[i]
+		}
+		if ok {
+			return ret
+		}
+	}
+}
+
+// raytrace returns the squares reachable from the given square in the given directions,
+// stopping at the first blocker. If mask, the edge squares are excluded as they never block.
+func raytrace(sq Square, dirs []direction, blockers Bitboard, mask bool) Bitboard {
+	ret := EmptyBitboard
+	for _, d := range dirs {
+		rank, file := sq.Rank().V()+d.rank, sq.File().V()+d.file
+		for 0 <= rank && rank < 8 && 0 <= file && file < 8 {
+			if mask {
+				next, nextFile := rank+d.rank, file+d.file
+				if next < 0 || next > 7 || nextFile < 0 || nextFile > 7 {
+					break
+				}
+			}
+
+			to := NewSquare(File(file), Rank(rank))
+			ret |= BitMask(to)
+			if blockers.IsSet(to) {
+				break
+			}
+			rank, file = rank+d.rank, file+d.file
+		}
+	}
+	return ret
+}
@@ -0,0 +1,344 @@
+package board_test
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMailboxDifferential cross-checks legal move generation against a simple, independent
+// mailbox generator over positions reached by random playouts. The mailbox generator does
+// not use bitboards, so subtle bitboard bugs show up as move set differences.
+func TestMailboxDifferential(t *testing.T) {
+	positions := []string{
+		fen.Initial,
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+		"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+		"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+		"r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10",
+		"bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9", // Chess960
+		"rk2r3/pppppppp/8/8/8/8/PPPPPPPP/RK2R3 w KQkq - 0 1",                // Chess960
+	}
+
+	games, plies := 40, 80
+	if testing.Short() {
+		games = 5
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for _, pos := range positions {
+		for i := 0; i < games; i++ {
+			b, err := fen.NewBoard(pos)
+			require.NoError(t, err)
+
+			for j := 0; j < plies; j++ {
+				moves := b.Position().LegalMoves(b.Turn())
+				expected := mailboxLegalMoves(b.Position(), b.Turn())
+				require.Equal(t, expected, moveKeys(moves), "move mismatch: %v", b)
+
+				if len(moves) == 0 {
+					break
+				}
+				b.PushMove(moves[r.Intn(len(moves))])
+				if b.Result().IsTerminal() {
+					break
+				}
+			}
+		}
+	}
+}
+
+// mailbox is a simple 8x8 board representation indexed by [file][rank], where files are
+// numbered as board.File, i.e., FileH=0.
+type mailbox [8][8]struct {
+	color board.Color
+	piece board.Piece
+	ok    bool
+}
+
+var (
+	knightOffsets = [][2]int{{1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}, {-2, 1}, {-1, 2}}
+	kingOffsets   = [][2]int{{0, 1}, {1, 1}, {1, 0}, {1, -1}, {0, -1}, {-1, -1}, {-1, 0}, {-1, 1}}
+	rookOffsets   = [][2]int{{0, 1}, {1, 0}, {0, -1}, {-1, 0}}
+	bishopOffsets = [][2]int{{1, 1}, {1, -1}, {-1, -1}, {-1, 1}}
+)
+
+func newMailbox(pos *board.Position) mailbox {
+	var m mailbox
+	for sq := board.ZeroSquare; sq < board.NumSquares; sq++ {
+		if c, p, ok := pos.Square(sq); ok {
+			m[sq.File()][sq.Rank()].color, m[sq.File()][sq.Rank()].piece, m[sq.File()][sq.Rank()].ok = c, p, true
+		}
+	}
+	return m
+}
+
+func onBoard(f, r int) bool {
+	return 0 <= f && f < 8 && 0 <= r && r < 8
+}
+
+func square(f, r int) board.Square {
+	return board.NewSquare(board.File(f), board.Rank(r))
+}
+
+// attacked returns true iff the square is attacked by the given color.
+func (m *mailbox) attacked(f, r int, by board.Color) bool {
+	is := func(f, r int, pieces ...board.Piece) bool {
+		if !onBoard(f, r) || !m[f][r].ok || m[f][r].color != by {
+			return false
+		}
+		for _, p := range pieces {
+			if m[f][r].piece == p {
+				return true
+			}
+		}
+		return false
+	}
+
+	dir := 1
+	if by == board.White {
+		dir = -1 // white pawns attack from below
+	}
+	if is(f+1, r+dir, board.Pawn) || is(f-1, r+dir, board.Pawn) {
+		return true
+	}
+	for _, o := range knightOffsets {
+		if is(f+o[0], r+o[1], board.Knight) {
+			return true
+		}
+	}
+	for _, o := range kingOffsets {
+		if is(f+o[0], r+o[1], board.King) {
+			return true
+		}
+	}
+	slide := func(offsets [][2]int, pieces ...board.Piece) bool {
+		for _, o := range offsets {
+			for x, y := f+o[0], r+o[1]; onBoard(x, y); x, y = x+o[0], y+o[1] {
+				if m[x][y].ok {
+					if is(x, y, pieces...) {
+						return true
+					}
+					break
+				}
+			}
+		}
+		return false
+	}
+	return slide(rookOffsets, board.Rook, board.Queen) || slide(bishopOffsets, board.Bishop, board.Queen)
+}
+
+func (m *mailbox) inCheck(c board.Color) bool {
+	for f := 0; f < 8; f++ {
+		for r := 0; r < 8; r++ {
+			if m[f][r].ok && m[f][r].color == c && m[f][r].piece == board.King {
+				return m.attacked(f, r, c.Opponent())
+			}
+		}
+	}
+	return false
+}
+
+// mailboxLegalMoves returns the sorted keys of all legal moves.
+func mailboxLegalMoves(pos *board.Position, turn board.Color) []string {
+	m := newMailbox(pos)
+	opp := turn.Opponent()
+
+	var ret []board.Move
+	add := func(mv board.Move) {
+		// Make the move on a copy and verify the King is not left in check.
+
+		next := m
+		from, to := mv.From, mv.To
+		next[to.File()][to.Rank()] = next[from.File()][from.Rank()]
+		next[from.File()][from.Rank()].ok = false
+		if mv.IsPromotion() {
+			next[to.File()][to.Rank()].piece = mv.Promotion
+		}
+		if mv.Type == board.EnPassant {
+			next[to.File()][from.Rank()].ok = false
+		}
+		if !next.inCheck(turn) {
+			ret = append(ret, mv)
+		}
+	}
+
+	for f := 0; f < 8; f++ {
+		for r := 0; r < 8; r++ {
+			if !m[f][r].ok || m[f][r].color != turn {
+				continue
+			}
+			from := square(f, r)
+			piece := m[f][r].piece
+
+			step := func(x, y int) bool { // returns true if empty and on board
+				if !onBoard(x, y) {
+					return false
+				}
+				if !m[x][y].ok {
+					add(board.Move{Type: board.Normal, Piece: piece, From: from, To: square(x, y)})
+					return true
+				}
+				if m[x][y].color == opp {
+					add(board.Move{Type: board.Capture, Piece: piece, From: from, To: square(x, y), Capture: m[x][y].piece})
+				}
+				return false
+			}
+
+			switch piece {
+			case board.Pawn:
+				dir, start, last := 1, 1, 7
+				if turn == board.Black {
+					dir, start, last = -1, 6, 0
+				}
+
+				promote := func(t board.MoveType, to board.Square, capture board.Piece) {
+					for _, p := range []board.Piece{board.Queen, board.Rook, board.Knight, board.Bishop} {
+						add(board.Move{Type: t, Piece: board.Pawn, From: from, To: to, Capture: capture, Promotion: p})
+					}
+				}
+
+				if y := r + dir; onBoard(f, y) && !m[f][y].ok {
+					if y == last {
+						promote(board.Promotion, square(f, y), board.NoPiece)
+					} else {
+						add(board.Move{Type: board.Push, Piece: board.Pawn, From: from, To: square(f, y)})
+						if r == start && !m[f][y+dir].ok {
+							add(board.Move{Type: board.Jump, Piece: board.Pawn, From: from, To: square(f, y+dir)})
+						}
+					}
+				}
+				for _, x := range []int{f - 1, f + 1} {
+					y := r + dir
+					if !onBoard(x, y) {
+						continue
+					}
+					if m[x][y].ok && m[x][y].color == opp {
+						if y == last {
+							promote(board.CapturePromotion, square(x, y), m[x][y].piece)
+						} else {
+							add(board.Move{Type: board.Capture, Piece: board.Pawn, From: from, To: square(x, y), Capture: m[x][y].piece})
+						}
+					}
+					if ep, ok := pos.EnPassant(); ok && ep == square(x, y) {
+						add(board.Move{Type: board.EnPassant, Piece: board.Pawn, From: from, To: ep})
+					}
+				}
+
+			case board.Knight:
+				for _, o := range knightOffsets {
+					step(f+o[0], r+o[1])
+				}
+
+			case board.King:
+				for _, o := range kingOffsets {
+					step(f+o[0], r+o[1])
+				}
+
+				// Castling: rights, rook present, empty between and King not passing through check.
+				// The layout gives the initial King and Rook files, which vary in Chess960. After
+				// castling, the King and Rook are on the standard squares. Files are numbered from
+				// FileH=0: F=2, G=1, D=4, C=5.
+
+				home := 0
+				if turn == board.Black {
+					home = 7
+				}
+				layout := pos.CastlingLayout()
+
+				castle := func(t board.MoveType, right board.Castling, rook, kingTo, rookTo int) {
+					if !pos.Castling().IsAllowed(right) || f != int(layout.King) || r != home {
+						return
+					}
+					if !m[rook][home].ok || m[rook][home].color != turn || m[rook][home].piece != board.Rook {
+						return
+					}
+					for x := 0; x < 8; x++ {
+						if x != f && x != rook && m[x][home].ok && (between(x, f, kingTo) || between(x, rook, rookTo)) {
+							return
+						}
+					}
+					for x := f; ; x += sign(kingTo - f) {
+						if x != kingTo || x == f {
+							if m.attacked(x, home, opp) {
+								return
+							}
+						}
+						if x == kingTo {
+							break
+						}
+					}
+
+					// Make the castling move directly, as add does not know about the Rook.
+
+					next := m
+					king := next[f][home]
+					next[f][home].ok, next[rook][home].ok = false, false
+					next[kingTo][home] = king
+					next[rookTo][home].color, next[rookTo][home].piece, next[rookTo][home].ok = turn, board.Rook, true
+					if !next.inCheck(turn) {
+						ret = append(ret, board.Move{Type: t, Piece: board.King, From: from, To: square(kingTo, home)})
+					}
+				}
+
+				if turn == board.White {
+					castle(board.KingSideCastle, board.WhiteKingSideCastle, int(layout.KingSideRook), 1, 2)
+					castle(board.QueenSideCastle, board.WhiteQueenSideCastle, int(layout.QueenSideRook), 5, 4)
+				} else {
+					castle(board.KingSideCastle, board.BlackKingSideCastle, int(layout.KingSideRook), 1, 2)
+					castle(board.QueenSideCastle, board.BlackQueenSideCastle, int(layout.QueenSideRook), 5, 4)
+				}
+
+			default:
+				var offsets [][2]int
+				switch piece {
+				case board.Rook:
+					offsets = rookOffsets
+				case board.Bishop:
+					offsets = bishopOffsets
+				case board.Queen:
+					offsets = append(append(offsets, rookOffsets...), bishopOffsets...)
+				}
+				for _, o := range offsets {
+					for x, y := f+o[0], r+o[1]; step(x, y); x, y = x+o[0], y+o[1] {
+					}
+				}
+			}
+		}
+	}
+	return moveKeys(ret)
+}
+
+func between(x, a, b int) bool {
+	if a > b {
+		a, b = b, a
+	}
+	return a <= x && x <= b
+}
+
+func sign(x int) int {
+	switch {
+	case x < 0:
+		return -1
+	case x > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// moveKeys returns sorted keys of the moves, including all metadata.
+func moveKeys(moves []board.Move) []string {
+	ret := make([]string, 0, len(moves))
+	for _, m := range moves {
+		ret = append(ret, fmt.Sprintf("%v:%v%v:%v:%v:%v", m.Type, m.From, m.To, m.Piece, m.Capture, m.Promotion))
+	}
+	sort.Strings(ret)
+	return ret
+}
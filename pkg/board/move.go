@@ -1,6 +1,7 @@
 package board
 
 import (
+	"errors"
 	"fmt"
 	"github.com/seekerror/stdlib/pkg/util/slicex"
 	"strings"
@@ -57,6 +58,9 @@ type Move struct {
 	Capture   Piece // captured piece, if any. Not set if EnPassant.
 }
 
+// ErrIllegalMove is an error indicating that a move is not legal in the position.
+var ErrIllegalMove = errors.New("illegal move")
+
 // ParseMove parses a move in pure algebraic coordinate notation, such as "a2a4" or "a7a8q".
 // The parsed move does not contain contextual information like castling or en passant.
 func ParseMove(str string) (Move, error) {
@@ -127,11 +131,7 @@ func (m Move) EnPassantTarget() (Square, bool) {
 		return 0, false
 	}
 
-	if m.To.Rank() == Rank4 { // White
-		return NewSquare(m.To.File(), Rank3), true
-	} else {
-		return NewSquare(m.To.File(), Rank6), true
-	}
+	return NewSquare(m.To.File(), (m.From.Rank()+m.To.Rank())/2), true // midpoint
 }
 
 // EnPassantCapture return the e.p capture square, if a EnPassant move. For d4*e3 e.p, it turns e4.
@@ -140,48 +140,7 @@ func (m Move) EnPassantCapture() (Square, bool) {
 		return 0, false
 	}
 
-	if m.To.Rank() == Rank3 { // Black
-		return NewSquare(m.To.File(), Rank4), true
-	} else {
-		return NewSquare(m.To.File(), Rank5), true
-	}
-}
-
-// CastlingRookMove returns the implicit rook move (from, to), if a KingSideCastle or QueenSideCastle move.
-func (m Move) CastlingRookMove() (Square, Square, bool) {
-	switch {
-	case m.Type == KingSideCastle && m.From == E1:
-		return H1, F1, true
-	case m.Type == QueenSideCastle && m.From == E1:
-		return A1, D1, true
-	case m.Type == KingSideCastle && m.From == E8:
-		return H8, F8, true
-	case m.Type == QueenSideCastle && m.From == E8:
-		return A8, D8, true
-	default:
-		return 0, 0, false
-	}
-}
-
-// CastlingRightsLost returns the castling rights that are definitely not present after this move.
-// If king moves, rights are lost. Ditto if rook moves or is captured.
-func (m Move) CastlingRightsLost() Castling {
-	switch {
-	case m.From == E1:
-		return WhiteKingSideCastle | WhiteQueenSideCastle
-	case m.From == A1 || m.To == A1:
-		return WhiteQueenSideCastle
-	case m.From == H1 || m.To == H1:
-		return WhiteKingSideCastle
-	case m.From == E8:
-		return BlackKingSideCastle | BlackQueenSideCastle
-	case m.From == A8 || m.To == A8:
-		return BlackQueenSideCastle
-	case m.From == H8 || m.To == H8:
-		return BlackKingSideCastle
-	default:
-		return NoCastlingRights
-	}
+	return NewSquare(m.To.File(), m.From.Rank()), true // beside the capturing pawn
 }
 
 func (m Move) Equals(o Move) bool {
@@ -211,6 +170,20 @@ func (m Move) String() string {
 	}
 }
 
+// PrintUCIMove prints the move in pure algebraic coordinate notation as used by UCI, such as
+// "e2e4" or "e7e8q". In Chess960 mode, castling moves are written as the King capturing its
+// own Rook, such as "e1h1", with the Rook files given by the layout. A non-standard layout
+// implies Chess960 mode, because the King move alone may then be ambiguous.
+func PrintUCIMove(m Move, layout CastlingLayout, chess960 bool) string {
+	if (chess960 || !layout.IsStandard()) && m.IsCastle() {
+		return fmt.Sprintf("%v%v", m.From, NewSquare(layout.Rook(m.Type), m.From.Rank()))
+	}
+	if m.IsPromotion() {
+		return fmt.Sprintf("%v%v%v", m.From, m.To, strings.ToLower(m.Promotion.String()))
+	}
+	return fmt.Sprintf("%v%v", m.From, m.To)
+}
+
 // MovePredicateFn is a move predicate.
 type MovePredicateFn func(move Move) bool
 
@@ -0,0 +1,32 @@
+package board_test
+
+import (
+	"testing"
+
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintUCIMove(t *testing.T) {
+	std := board.StandardCastlingLayout
+	shuffle := board.CastlingLayout{King: board.FileF, KingSideRook: board.FileG, QueenSideRook: board.FileB}
+
+	tests := []struct {
+		m        board.Move
+		layout   board.CastlingLayout
+		chess960 bool
+		expected string
+	}{
+		{board.Move{Type: board.Normal, Piece: board.Pawn, From: board.E2, To: board.E4}, std, false, "e2e4"},
+		{board.Move{Type: board.CapturePromotion, Piece: board.Pawn, From: board.E7, To: board.D8, Promotion: board.Knight, Capture: board.Rook}, std, false, "e7d8n"},
+		{board.Move{Type: board.KingSideCastle, Piece: board.King, From: board.E1, To: board.G1}, std, false, "e1g1"},
+		{board.Move{Type: board.KingSideCastle, Piece: board.King, From: board.E1, To: board.G1}, std, true, "e1h1"},
+		{board.Move{Type: board.QueenSideCastle, Piece: board.King, From: board.E8, To: board.C8}, std, true, "e8a8"},
+		{board.Move{Type: board.KingSideCastle, Piece: board.King, From: board.F1, To: board.G1}, shuffle, false, "f1g1"},
+		{board.Move{Type: board.QueenSideCastle, Piece: board.King, From: board.F8, To: board.C8}, shuffle, false, "f8b8"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, board.PrintUCIMove(tt.m, tt.layout, tt.chess960))
+	}
+}
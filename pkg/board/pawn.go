@@ -0,0 +1,74 @@
+package board
+
+// Color-generic pawn helpers. Pawn logic should be expressed in terms of relative ranks and
+// the push direction, so that it need not duplicate White and Black cases.
+
+// RelativeRank returns the rank as seen from the given color, i.e., Rank1 is the home rank
+// of the color. The rank is unchanged for White and mirrored for Black.
+func RelativeRank(c Color, r Rank) Rank {
+	if c == White {
+		return r
+	}
+	return Rank8 - r
+}
+
+// PawnPushDirection returns the direction pawns of the given color move in.
+func PawnPushDirection(c Color) Direction {
+	if c == White {
+		return North
+	}
+	return South
+}
+
+// PawnStartRank returns the starting rank of pawns of the given color, i.e., Rank2 for
+// White or Rank7 for Black.
+func PawnStartRank(c Color) Rank {
+	return RelativeRank(c, Rank2)
+}
+
+// JumpRank returns the target rank for pawn jump moves of the given color, i.e., Rank4 for
+// White or Rank5 for Black.
+func JumpRank(c Color) Rank {
+	return RelativeRank(c, Rank4)
+}
+
+// EnPassantRank returns the rank of en passant target squares for captures by the given
+// color, i.e., Rank6 for White or Rank3 for Black.
+func EnPassantRank(c Color) Rank {
+	return RelativeRank(c, Rank6)
+}
+
+// PromotionRank returns the promotion rank of the given color.
+func PromotionRank(c Color) Rank {
+	return RelativeRank(c, Rank8)
+}
+
+// PawnCaptureboard returns all potential pawn captures for the given color.
+func PawnCaptureboard(c Color, pawns Bitboard) Bitboard {
+	if c == White {
+		return ((pawns << 9) &^ BitFile(FileH)) | ((pawns << 7) &^ BitFile(FileA))
+	} else {
+		return ((pawns >> 9) &^ BitFile(FileA)) | ((pawns >> 7) &^ BitFile(FileH))
+	}
+}
+
+// PawnMoveboard returns all potential pawn sigle-step moves for the given color.
+func PawnMoveboard(all Bitboard, c Color, pawns Bitboard) Bitboard {
+	if c == White {
+		return (pawns << 8) & ^all
+	} else {
+		return (pawns >> 8) & ^all
+	}
+}
+
+// PawnPromotionRank returns the mask of the promotion rank for the given color, i.e.,
+// Rank8 for White or Rank1 for Black.
+func PawnPromotionRank(c Color) Bitboard {
+	return BitRank(PromotionRank(c))
+}
+
+// PawnJumpRank returns the mask of the target rank for jump moves for the given color,
+// i.e., Rank4 for White or Rank5 for Black.
+func PawnJumpRank(c Color) Bitboard {
+	return BitRank(JumpRank(c))
+}
@@ -0,0 +1,44 @@
+package board_test
+
+import (
+	"testing"
+
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelativeRank(t *testing.T) {
+	assert.Equal(t, board.Rank2, board.RelativeRank(board.White, board.Rank2))
+	assert.Equal(t, board.Rank2, board.RelativeRank(board.Black, board.Rank7))
+	assert.Equal(t, board.Rank8, board.RelativeRank(board.Black, board.Rank1))
+
+	assert.Equal(t, board.Rank2, board.PawnStartRank(board.White))
+	assert.Equal(t, board.Rank7, board.PawnStartRank(board.Black))
+	assert.Equal(t, board.Rank4, board.JumpRank(board.White))
+	assert.Equal(t, board.Rank5, board.JumpRank(board.Black))
+	assert.Equal(t, board.Rank6, board.EnPassantRank(board.White))
+	assert.Equal(t, board.Rank3, board.EnPassantRank(board.Black))
+	assert.Equal(t, board.Rank8, board.PromotionRank(board.White))
+	assert.Equal(t, board.Rank1, board.PromotionRank(board.Black))
+
+	assert.Equal(t, board.North, board.PawnPushDirection(board.White))
+	assert.Equal(t, board.South, board.PawnPushDirection(board.Black))
+}
+
+func TestEnPassantSquares(t *testing.T) {
+	target, ok := board.Move{Type: board.Jump, Piece: board.Pawn, From: board.E2, To: board.E4}.EnPassantTarget()
+	assert.True(t, ok)
+	assert.Equal(t, board.E3, target)
+
+	target, ok = board.Move{Type: board.Jump, Piece: board.Pawn, From: board.D7, To: board.D5}.EnPassantTarget()
+	assert.True(t, ok)
+	assert.Equal(t, board.D6, target)
+
+	capture, ok := board.Move{Type: board.EnPassant, Piece: board.Pawn, From: board.D4, To: board.E3}.EnPassantCapture()
+	assert.True(t, ok)
+	assert.Equal(t, board.E4, capture)
+
+	capture, ok = board.Move{Type: board.EnPassant, Piece: board.Pawn, From: board.F5, To: board.G6}.EnPassantCapture()
+	assert.True(t, ok)
+	assert.Equal(t, board.G5, capture)
+}
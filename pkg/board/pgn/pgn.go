@@ -0,0 +1,321 @@
+// Package pgn contains utilities for reading and writing games in Portable Game Notation (PGN).
+package pgn
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/board/san"
+)
+
+// Tag is a PGN tag pair, such as [White "Turing"].
+type Tag struct {
+	Name, Value string
+}
+
+// Game represents a game in PGN. The moves are validated and resolved against the starting
+// position, which is given by the FEN tag, if present.
+type Game struct {
+	Tags  []Tag
+	Moves []board.Move
+	// Comments holds the comment following a move, if any, indexed by move.
+	Comments map[int]string
+	Result   string
+}
+
+// Tag returns the value of the given tag, if present.
+func (g Game) Tag(name string) (string, bool) {
+	for _, t := range g.Tags {
+		if t.Name == name {
+			return t.Value, true
+		}
+	}
+	return "", false
+}
+
+// Start returns the starting position in FEN.
+func (g Game) Start() string {
+	if v, ok := g.Tag("FEN"); ok {
+		return v
+	}
+	return fen.Initial
+}
+
+// Board returns a new board for the starting position of the game.
+func (g Game) Board() (*board.Board, error) {
+	return fen.NewBoard(g.Start())
+}
+
+func (g Game) String() string {
+	w, _ := g.Tag("White")
+	b, _ := g.Tag("Black")
+	return fmt.Sprintf("%v - %v (%v moves) %v", w, b, len(g.Moves), g.Result)
+}
+
+// Encode returns the game in PGN with the moves in SAN, such as for saving it to a file. The
+// tags are written in order, followed by the movetext wrapped at 80 characters and the result.
+// The moves must be legal from the starting position.
+func Encode(g Game) (string, error) {
+	pos, turn, _, fullmoves, err := fen.Decode(g.Start())
+	if err != nil {
+		return "", fmt.Errorf("invalid start: %w", err)
+	}
+
+	var sb strings.Builder
+	for _, t := range g.Tags {
+		sb.WriteString(fmt.Sprintf("[%v %q]\n", t.Name, t.Value))
+	}
+	if len(g.Tags) > 0 {
+		sb.WriteString("\n")
+	}
+
+	var tokens []string
+	for i, m := range g.Moves {
+		if turn == board.White {
+			tokens = append(tokens, fmt.Sprintf("%v.", fullmoves))
+		} else if i == 0 {
+			tokens = append(tokens, fmt.Sprintf("%v...", fullmoves))
+		}
+
+		next, ok := pos.Move(m)
+		if !ok {
+			return "", fmt.Errorf("illegal move %v: %v", i+1, m)
+		}
+		tokens = append(tokens, san.Print(pos, turn, m))
+		if c, ok := g.Comments[i]; ok {
+			tokens = append(tokens, c)
+		}
+
+		if turn == board.Black {
+			fullmoves++
+		}
+		pos, turn = next, turn.Opponent()
+	}
+	result := g.Result
+	if result == "" {
+		result = "*"
+	}
+	tokens = append(tokens, result)
+
+	n := 0
+	for i, t := range tokens {
+		if i > 0 {
+			if n+1+len(t) > 80 {
+				sb.WriteString("\n")
+				n = 0
+			} else {
+				sb.WriteString(" ")
+				n++
+			}
+		}
+		sb.WriteString(t)
+		n += len(t)
+	}
+	sb.WriteString("\n")
+	return sb.String(), nil
+}
+
+// Read reads all games from the given reader.
+func Read(r io.Reader) ([]Game, error) {
+	data, err := io.ReadAll(bufio.NewReader(r))
+	if err != nil {
+		return nil, err
+	}
+	return Parse(string(data))
+}
+
+// Parse parses all games in the given PGN text. Variations and NAGs are ignored.
+func Parse(str string) ([]Game, error) {
+	var ret []Game
+
+	p := &parser{data: []rune(str)}
+	for {
+		g, ok, err := p.game()
+		if err != nil {
+			return nil, fmt.Errorf("game %v: %w", len(ret)+1, err)
+		}
+		if !ok {
+			return ret, nil
+		}
+		ret = append(ret, g)
+	}
+}
+
+type parser struct {
+	data []rune
+	pos  int
+}
+
+func (p *parser) game() (Game, bool, error) {
+	var g Game
+
+	// (1) Tag pairs.
+
+	for {
+		p.skipSpace()
+		if p.eof() {
+			return g, false, nil
+		}
+		if p.peek() != '[' {
+			break
+		}
+		tag, err := p.tag()
+		if err != nil {
+			return g, false, err
+		}
+		g.Tags = append(g.Tags, tag)
+	}
+
+	b, err := g.Board()
+	if err != nil {
+		return g, false, fmt.Errorf("invalid starting position: %w", err)
+	}
+
+	// (2) Movetext, up to and including the result.
+
+	for {
+		p.skipSpace()
+		if p.eof() {
+			return g, true, nil // ok: missing result
+		}
+
+		switch r := p.peek(); {
+		case r == '{':
+			comment, err := p.until('}')
+			if err != nil {
+				return g, false, err
+			}
+			if len(g.Moves) > 0 {
+				if g.Comments == nil {
+					g.Comments = map[int]string{}
+				}
+				g.Comments[len(g.Moves)-1] = strings.TrimSpace(comment)
+			}
+		case r == ';':
+			_, _ = p.until('\n')
+		case r == '(':
+			if err := p.variation(); err != nil {
+				return g, false, err
+			}
+		case r == '[':
+			return g, true, nil // ok: next game without result
+		default:
+			token := p.token()
+			switch {
+			case isResult(token):
+				g.Result = token
+				return g, true, nil
+			case strings.HasPrefix(token, "$"):
+				// NAG: ignore
+			case unicode.IsDigit(rune(token[0])) && strings.HasSuffix(token, "."):
+				// Move number: ignore
+			default:
+				if i := strings.LastIndex(token, "."); i >= 0 {
+					token = token[i+1:] // move number without space, such as "1.e4"
+				}
+				m, err := san.Parse(b.Position(), b.Turn(), token)
+				if err != nil {
+					return g, false, fmt.Errorf("move %v: %w", len(g.Moves)+1, err)
+				}
+				if !b.PushMove(m) {
+					return g, false, fmt.Errorf("move %v: %w: %v", len(g.Moves)+1, board.ErrIllegalMove, token)
+				}
+				g.Moves = append(g.Moves, m)
+			}
+		}
+	}
+}
+
+func (p *parser) tag() (Tag, error) {
+	str, err := p.until(']')
+	if err != nil {
+		return Tag{}, err
+	}
+	str = strings.TrimSpace(str[1 : len(str)-1])
+
+	i := strings.IndexFunc(str, unicode.IsSpace)
+	if i < 0 {
+		return Tag{}, fmt.Errorf("invalid tag: %v", str)
+	}
+	value := strings.TrimSpace(str[i:])
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return Tag{}, fmt.Errorf("invalid tag value: %v", str)
+	}
+	return Tag{Name: str[:i], Value: strings.ReplaceAll(value[1:len(value)-1], `\"`, `"`)}, nil
+}
+
+func (p *parser) variation() error {
+	depth := 0
+	for !p.eof() {
+		switch p.data[p.pos] {
+		case '{':
+			if _, err := p.until('}'); err != nil {
+				return err
+			}
+			continue
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+		p.pos++
+		if depth == 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("unterminated variation")
+}
+
+// until returns the text up to and including the given rune.
+func (p *parser) until(r rune) (string, error) {
+	start := p.pos
+	for !p.eof() {
+		p.pos++
+		if p.data[p.pos-1] == r {
+			return string(p.data[start:p.pos]), nil
+		}
+	}
+	if r == '\n' {
+		return string(p.data[start:]), nil
+	}
+	return "", fmt.Errorf("missing '%c'", r)
+}
+
+func (p *parser) token() string {
+	start := p.pos
+	for !p.eof() && !unicode.IsSpace(p.peek()) && !strings.ContainsRune("{}()[];", p.peek()) {
+		p.pos++
+	}
+	if start == p.pos {
+		p.pos++ // skip stray delimiter
+	}
+	return string(p.data[start:p.pos])
+}
+
+func (p *parser) skipSpace() {
+	for !p.eof() && unicode.IsSpace(p.peek()) {
+		p.pos++
+	}
+}
+
+func (p *parser) peek() rune {
+	return p.data[p.pos]
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.data)
+}
+
+func isResult(token string) bool {
+	switch token {
+	case "1-0", "0-1", "1/2-1/2", "*":
+		return true
+	default:
+		return false
+	}
+}
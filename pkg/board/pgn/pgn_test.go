@@ -0,0 +1,97 @@
+package pgn_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/pgn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	games, err := pgn.Parse(`[Event "?"]
+[White "A"]
+[Black "B"]
+[Result "1-0"]
+
+1. e4 {book} e5 2. Nf3 (2. f4 exf4 (2... d5) 3. Nf3) Nc6 3.Bb5 $1 a6 ; Ruy Lopez
+4. Bxc6 dxc6 5. O-O f6 6. d4 exd4 7. Nxd4 c5 8. Nb3 Qxd1 9. Rxd1 1-0
+
+[White "C"]
+[Black "D"]
+[FEN "4k3/1P6/8/8/8/8/8/4K3 w - - 0 1"]
+
+1. b8=Q+ Kd7 2. Qb7+ *
+`)
+	require.NoError(t, err)
+	require.Len(t, games, 2)
+
+	g := games[0]
+	assert.Equal(t, "1-0", g.Result)
+	w, _ := g.Tag("White")
+	assert.Equal(t, "A", w)
+	assert.Equal(t, "e2-e4 e7-e5 Ng1-f3 Nb8-c6 Bf1-b5 a7-a6 Bb5*c6 d7*c6 0-0 f7-f6 d2-d4 e5*d4 Nf3*d4 c6-c5 Nd4-b3 Qd8*d1 Rf1*d1", board.PrintMoves(g.Moves))
+	assert.Equal(t, map[int]string{0: "{book}"}, g.Comments)
+
+	g = games[1]
+	assert.Equal(t, "*", g.Result)
+	assert.Equal(t, "4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", g.Start())
+	assert.Equal(t, "b7-b8=Q Ke8-d7 Qb8-b7", board.PrintMoves(g.Moves))
+}
+
+func TestReadFiles(t *testing.T) {
+	files := []string{
+		"../../../cmd/bernstein/scientific_american_1958.pgn",
+		"../../../cmd/bernstein/chess_review_1958.pgn",
+		"../../../cmd/turochamp/turing_glennie_1952.pgn",
+		"../../../data/tournaments/tournament1_turo2_sargon134_apr2021.pgn",
+	}
+
+	for _, file := range files {
+		f, err := os.Open(file)
+		require.NoError(t, err)
+
+		games, err := pgn.Read(f)
+		_ = f.Close()
+		require.NoError(t, err, file)
+		assert.NotEmpty(t, games, file)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	games, err := pgn.Parse(`[White "A"]
+[Black "B"]
+[Result "1-0"]
+
+1. e4 {book} e5 2. Nf3 Nc6 3. Bb5 a6 4. Bxc6 dxc6 5. O-O 1-0
+
+[FEN "4k3/1P6/8/8/8/8/8/4K3 b - - 0 7"]
+
+7... Kd7 8. b8=Q Kc6 *
+`)
+	require.NoError(t, err)
+	require.Len(t, games, 2)
+
+	str, err := pgn.Encode(games[0])
+	require.NoError(t, err)
+	assert.Equal(t, `[White "A"]
+[Black "B"]
+[Result "1-0"]
+
+1. e4 {book} e5 2. Nf3 Nc6 3. Bb5 a6 4. Bxc6 dxc6 5. O-O 1-0
+`, str)
+
+	str, err = pgn.Encode(games[1])
+	require.NoError(t, err)
+	assert.Contains(t, str, "7... Kd7 8. b8=Q Kc6 *\n")
+
+	actual, err := pgn.Parse(str)
+	require.NoError(t, err)
+	require.Len(t, actual, 1)
+	assert.Equal(t, games[1].Moves, actual[0].Moves)
+
+	_, err = pgn.Encode(pgn.Game{Moves: games[1].Moves})
+	assert.Error(t, err)
+}
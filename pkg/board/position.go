@@ -19,15 +19,22 @@ func (p Placement) String() string {
 // Position represents a board position suitable for move generation. It includes castling and
 // en passant, but not game metadata to determine various Draw conditions.
 type Position struct {
-	pieces  [NumColors][NumPieces]Bitboard // Zero piece contains all pieces for color.
-	rotated RotatedBitboard
+	pieces [NumColors][NumPieces]Bitboard // Zero piece contains all pieces for color.
 
 	castling  Castling
+	layout    CastlingLayout
 	enpassant Square // zero if last move was not a Jump
 }
 
+// NewPosition returns a new position with the standard castling layout.
 func NewPosition(pieces []Placement, castling Castling, ep Square) (*Position, error) {
-	ret := &Position{castling: castling, enpassant: ep}
+	return NewPositionWithLayout(pieces, castling, StandardCastlingLayout, ep)
+}
+
+// NewPositionWithLayout returns a new position with the given castling layout, such as
+// for Chess960 positions.
+func NewPositionWithLayout(pieces []Placement, castling Castling, layout CastlingLayout, ep Square) (*Position, error) {
+	ret := &Position{castling: castling, layout: layout, enpassant: ep}
 
 	for _, p := range pieces {
 		if !ret.IsEmpty(p.Square) {
@@ -40,22 +47,81 @@ func NewPosition(pieces []Placement, castling Castling, ep Square) (*Position, e
 }
 
 // Move attempts to make a pseudo-legal move. The attempted move is assumed to be
-// pseudo-legal and generated from the position. Returns false if not legal.
+// pseudo-legal and generated from the position. Returns false if not legal. The position
+// is not modified: the new position is a copy. See MakeMove for an in-place alternative.
 func (p *Position) Move(m Move) (*Position, bool) {
 	ret := *p
+	if _, ok := ret.MakeMove(m); !ok {
+		return nil, false
+	}
+	return &ret, true
+}
 
-	// (1) Remove piece from "from" square.
+// Undo holds the information needed to undo a move made in place.
+type Undo struct {
+	turn      Color
+	piece     Piece
+	castling  Castling
+	enpassant Square
+}
 
+// MakeMove attempts to make a pseudo-legal move in place. The attempted move is assumed to be
+// pseudo-legal and generated from the position. Returns false if not legal, in which case the
+// position is unchanged. Otherwise, the move can be undone with UnmakeMove.
+func (p *Position) MakeMove(m Move) (Undo, bool) {
 	turn, piece, ok := p.Square(m.From)
 	if !ok {
-		return nil, false
+		return Undo{}, false
+	}
+	if m.IsCastle() && !p.isCastlingSafe(turn, m) {
+		return Undo{}, false
+	}
+
+	u := Undo{turn: turn, piece: piece, castling: p.castling, enpassant: p.enpassant}
+	p.apply(m, turn, piece)
+
+	// Update EnPassant and castling status.
+
+	p.enpassant, _ = m.EnPassantTarget()
+	p.castling &^= p.CastlingRightsLost(m)
+
+	// Validate that move does not leave own king in check.
+
+	if p.IsChecked(turn) {
+		p.UnmakeMove(m, u)
+		return Undo{}, false
+	}
+	return u, true
+}
+
+// IsLegal returns true iff the pseudo-legal move is legal. The move is tried in place and
+// undone, which avoids the copy of Move when only legality matters.
+func (p *Position) IsLegal(m Move) bool {
+	u, ok := p.MakeMove(m)
+	if ok {
+		p.UnmakeMove(m, u)
 	}
-	ret.xor(m.From, turn, piece)
+	return ok
+}
+
+// UnmakeMove undoes a move made in place by MakeMove. The move and undo information must be
+// from the most recent MakeMove not yet undone.
+func (p *Position) UnmakeMove(m Move, u Undo) {
+	p.apply(m, u.turn, u.piece)
+	p.castling = u.castling
+	p.enpassant = u.enpassant
+}
+
+// apply toggles the pieces of the move. It is its own inverse, because all updates are xor.
+func (p *Position) apply(m Move, turn Color, piece Piece) {
+	// (1) Remove piece from "from" square.
+
+	p.xor(m.From, turn, piece)
 
 	// (2) Remove any captured piece.
 
 	if m.IsCapture() {
-		ret.xor(m.To, turn.Opponent(), m.Capture)
+		p.xor(m.To, turn.Opponent(), m.Capture)
 	}
 
 	// (3) Add piece to "to" square.
@@ -63,43 +129,60 @@ func (p *Position) Move(m Move) (*Position, bool) {
 	if m.IsPromotion() {
 		piece = m.Promotion
 	}
-	ret.xor(m.To, turn, piece)
+	p.xor(m.To, turn, piece)
 
 	// (4) Handle special moves/captures.
 
 	switch m.Type {
 	case EnPassant:
 		capture, _ := m.EnPassantCapture()
-		ret.xor(capture, turn.Opponent(), Pawn)
+		p.xor(capture, turn.Opponent(), Pawn)
 
 	case KingSideCastle, QueenSideCastle:
-		for _, sq := range safeCastlingSquares(turn, m.Type) {
-			if p.IsAttacked(turn, sq) {
-				return nil, false
-			}
-		}
+		// In Chess960, the King or Rook may not move or may move onto the square
+		// of the other piece. The xor updates are independent, so the result is correct.
 
-		from, to, _ := m.CastlingRookMove()
-		ret.xor(from, turn, Rook)
-		ret.xor(to, turn, Rook)
+		from, to, _ := p.CastlingRookMove(m)
+		p.xor(from, turn, Rook)
+		p.xor(to, turn, Rook)
 	}
+}
 
-	// (5) Update EnPassant and castling status.
-
-	ret.enpassant, _ = m.EnPassantTarget()
-	ret.castling &^= m.CastlingRightsLost()
+// Castling returns the castling rights.
+func (p *Position) Castling() Castling {
+	return p.castling
+}
 
-	// (7) Validate that move does not leave own king in check.
+// CastlingLayout returns the castling layout.
+func (p *Position) CastlingLayout() CastlingLayout {
+	return p.layout
+}
 
-	if ret.IsChecked(turn) {
-		return nil, false
+// CastlingRookMove returns the implicit rook move (from, to), if a KingSideCastle or QueenSideCastle move.
+func (p *Position) CastlingRookMove(m Move) (Square, Square, bool) {
+	if !m.IsCastle() {
+		return 0, 0, false
 	}
-	return &ret, true
+	_, to := CastlingTarget(m.Type)
+	return NewSquare(p.layout.Rook(m.Type), m.From.Rank()), NewSquare(to, m.From.Rank()), true
 }
 
-// Castling returns the castling rights.
-func (p *Position) Castling() Castling {
-	return p.castling
+// CastlingRightsLost returns the castling rights that are definitely not present after this move.
+// If king moves, rights are lost. Ditto if rook moves or is captured.
+func (p *Position) CastlingRightsLost(m Move) Castling {
+	ret := NoCastlingRights
+	for c := ZeroColor; c < NumColors; c++ {
+		rank := RelativeRank(c, Rank1)
+		if m.From == NewSquare(p.layout.King, rank) {
+			ret |= CastlingRights(c)
+		}
+		for _, t := range []MoveType{KingSideCastle, QueenSideCastle} {
+			if rook := NewSquare(p.layout.Rook(t), rank); m.From == rook || m.To == rook {
+				ret |= CastlingRight(c, t)
+			}
+		}
+	}
+	return ret
 }
 
 // EnPassant return the target en passant square, if previous move was a Jump. For example,
@@ -108,14 +191,9 @@ func (p *Position) EnPassant() (Square, bool) {
 	return p.enpassant, p.enpassant != ZeroSquare
 }
 
-// Rotated returns the rotated bitboard.
-func (p *Position) Rotated() RotatedBitboard {
-	return p.rotated
-}
-
 // All returns a bitboard contains all pirces.
 func (p *Position) All() Bitboard {
-	return p.rotated.rot
+	return p.pieces[White][NoPiece] | p.pieces[Black][NoPiece]
 }
 
 // Color returns the bitboard for a given color.
@@ -159,7 +237,7 @@ func (p *Position) Square(sq Square) (Color, Piece, bool) {
 
 // IsEmpty returns true iff the square is empty.
 func (p *Position) IsEmpty(sq Square) bool {
-	return !p.rotated.Mask().IsSet(sq)
+	return !p.All().IsSet(sq)
 }
 
 // IsDefended returns true iff the square is defended by the color.
@@ -180,6 +258,7 @@ func (p *Position) IsAttacked(c Color, sq Square) bool {
 // IsAttackedBy returns true iff the square is attacked by the given pieces of the opposing color. Does not include en passant.
 func (p *Position) IsAttackedBy(c Color, sq Square, list []Piece) bool {
 	opp := c.Opponent()
+	all := p.All()
 
 	for _, piece := range list {
 		if piece == Pawn {
@@ -188,13 +267,39 @@ func (p *Position) IsAttackedBy(c Color, sq Square, list []Piece) bool {
 			}
 			continue
 		}
-		if pieces := p.pieces[opp][piece]; pieces != 0 && Attackboard(p.rotated, sq, piece)&pieces != 0 {
+		if pieces := p.pieces[opp][piece]; pieces != 0 && Attackboard(all, sq, piece)&pieces != 0 {
 			return true
 		}
 	}
 	return false
 }
 
+// AttackedSquares returns the squares attacked by the opposing color. Does not include en passant.
+func (p *Position) AttackedSquares(c Color) Bitboard {
+	return p.attackedSquares(c, p.All())
+}
+
+// attackedSquares returns the squares attacked by the opposing color, given the population of
+// the board.
+func (p *Position) attackedSquares(c Color, all Bitboard) Bitboard {
+	opp := c.Opponent()
+
+	ret := PawnCaptureboard(opp, p.pieces[opp][Pawn])
+	for _, piece := range QueenRookKnightBishop {
+		pieces := p.pieces[opp][piece]
+		for pieces != EmptyBitboard {
+			sq := pieces.LastPopSquare()
+			pieces ^= BitMask(sq)
+
+			ret |= Attackboard(all, sq, piece)
+		}
+	}
+	if king := p.pieces[opp][King]; king != EmptyBitboard {
+		ret |= KingAttackboard(king.LastPopSquare())
+	}
+	return ret
+}
+
 // IsChecked returns true iff the color is in check. Convenient for IsAttacked(King).
 func (p *Position) IsChecked(c Color) bool {
 	if pos := p.pieces[c][King].LastPopSquare(); pos != NumSquares {
@@ -208,6 +313,17 @@ func (p *Position) IsCheckMate(c Color) bool {
 	return p.IsChecked(c) && len(p.LegalMoves(c)) == 0
 }
 
+// GivesCheck returns true iff the pseudo-legal move is legal and checks the opponent king,
+// directly or by discovery.
+func (p *Position) GivesCheck(m Move) bool {
+	turn, _, ok := p.Square(m.From)
+	if !ok {
+		return false
+	}
+	next, ok := p.Move(m)
+	return ok && next.IsChecked(turn.Opponent())
+}
+
 var (
 	whiteSquareMask = Bitboard(0xaaaaaaaaaaaaaaaa)
 )
@@ -215,7 +331,7 @@ var (
 // HasInsufficientMaterial returns true iff there is not sufficient material for either side to win.
 // The cases are: K v K, KN v K, KB v KB (or KBB v K) w/ Bishops on same square color. Assumes 2 kings.
 func (p *Position) HasInsufficientMaterial() bool {
-	switch p.rotated.rot.PopCount() {
+	switch p.All().PopCount() {
 	case 2:
 		return true
 	case 3:
@@ -231,35 +347,46 @@ func (p *Position) HasInsufficientMaterial() bool {
 	}
 }
 
-var (
-	whiteKingSideCastlingMask  = BitMask(G1) | BitMask(F1)
-	whiteQueenSideCastlingMask = BitMask(B1) | BitMask(C1) | BitMask(D1)
-	blackKingSideCastlingMask  = BitMask(G8) | BitMask(F8)
-	blackQueenSideCastlingMask = BitMask(B8) | BitMask(C8) | BitMask(D8)
-)
-
-// LegalMoves returns a list of all legal moves. Convenience function.
-func (p *Position) LegalMoves(turn Color) []Move {
-	var ret []Move
-	for _, m := range p.PseudoLegalMoves(turn) {
-		if _, ok := p.Move(m); ok {
-			ret = append(ret, m)
-		}
-	}
-	return ret
+// GenOptions are move generation options.
+type GenOptions struct {
+	// LegalCastling generates only legal castling moves, where the King is not in check and
+	// neither passes through nor lands on an attacked square. Otherwise, castling moves are
+	// pseudo-legal like other moves and only validated when made. Useful for selective
+	// engines that reason about candidate moves without making each of them.
+	LegalCastling bool
 }
 
 // PseudoLegalMoves returns a list of all pseudo-legal moves. The move may not respect
 // either side being in check, which must be validated subsequently.
 func (p *Position) PseudoLegalMoves(turn Color) []Move {
+	return p.PseudoLegalMovesInto(turn, make([]Move, 0, 50))
+}
+
+// PseudoLegalMovesInto returns the pseudo-legal moves like PseudoLegalMoves, but generated
+// into the given buffer to avoid allocation. The buffer is overwritten and may be reused for
+// the next call, if the returned moves are no longer needed.
+func (p *Position) PseudoLegalMovesInto(turn Color, buf []Move) []Move {
+	return p.GenerateMovesInto(turn, GenOptions{}, buf)
+}
+
+// GenerateMoves returns a list of all pseudo-legal moves like PseudoLegalMoves, except as
+// modified by the given options.
+func (p *Position) GenerateMoves(turn Color, opts GenOptions) []Move {
+	return p.GenerateMovesInto(turn, opts, make([]Move, 0, 50))
+}
+
+// GenerateMovesInto returns the moves like GenerateMoves, but generated into the given buffer
+// to avoid allocation.
+func (p *Position) GenerateMovesInto(turn Color, opts GenOptions, buf []Move) []Move {
 	mask := ^p.pieces[turn][NoPiece] // cannot capture own pieces
 
 	captures := p.pieces[turn.Opponent()][NoPiece]
 	moves := ^captures
+	all := p.All()
 	jumps := PawnJumpRank(turn)
 	promos := PawnPromotionRank(turn)
 
-	ret := make([]Move, 0, 50)
+	ret := buf[:0]
 
 	for _, piece := range QueenRookKnightBishop {
 		pieces := p.pieces[turn][piece]
@@ -267,7 +394,7 @@ func (p *Position) PseudoLegalMoves(turn Color) []Move {
 			from := pieces.LastPopSquare()
 			pieces ^= BitMask(from)
 
-			attackboard := Attackboard(p.rotated, from, piece) & mask
+			attackboard := Attackboard(all, from, piece) & mask
 			p.emitMove(turn, Normal, piece, from, attackboard&moves, &ret)
 			p.emitMove(turn, Capture, piece, from, attackboard&captures, &ret)
 		}
@@ -280,8 +407,8 @@ func (p *Position) PseudoLegalMoves(turn Color) []Move {
 		pawns ^= origin
 
 		captureboard := PawnCaptureboard(turn, origin) & mask
-		pushboard := PawnMoveboard(p.rotated.rot, turn, origin)
-		jumpboard := PawnMoveboard(p.rotated.rot, turn, pushboard) & jumps
+		pushboard := PawnMoveboard(all, turn, origin)
+		jumpboard := PawnMoveboard(all, turn, pushboard) & jumps
 
 		p.emitMove(turn, Capture, Pawn, from, captureboard&captures&^promos, &ret)
 		p.emitMove(turn, Push, Pawn, from, pushboard&^promos, &ret)
@@ -302,19 +429,9 @@ func (p *Position) PseudoLegalMoves(turn Color) []Move {
 		p.emitMove(turn, Normal, King, from, attackboard&moves, &ret)
 		p.emitMove(turn, Capture, King, from, attackboard&captures, &ret)
 
-		if turn == White {
-			if p.castling.IsAllowed(WhiteKingSideCastle) && (whiteKingSideCastlingMask&p.rotated.rot) == 0 && p.pieces[turn][Rook]&BitMask(H1) != 0 {
-				p.emitMove(turn, KingSideCastle, King, from, BitMask(G1), &ret)
-			}
-			if p.castling.IsAllowed(WhiteQueenSideCastle) && (whiteQueenSideCastlingMask&p.rotated.rot) == 0 && p.pieces[turn][Rook]&BitMask(A1) != 0 {
-				p.emitMove(turn, QueenSideCastle, King, from, BitMask(C1), &ret)
-			}
-		} else {
-			if p.castling.IsAllowed(BlackKingSideCastle) && (blackKingSideCastlingMask&p.rotated.rot) == 0 && p.pieces[turn][Rook]&BitMask(H8) != 0 {
-				p.emitMove(turn, KingSideCastle, King, from, BitMask(G8), &ret)
-			}
-			if p.castling.IsAllowed(BlackQueenSideCastle) && (blackQueenSideCastlingMask&p.rotated.rot) == 0 && p.pieces[turn][Rook]&BitMask(A8) != 0 {
-				p.emitMove(turn, QueenSideCastle, King, from, BitMask(C8), &ret)
+		for _, t := range []MoveType{KingSideCastle, QueenSideCastle} {
+			if m, ok := p.castlingMove(turn, t, from); ok && (!opts.LegalCastling || p.isCastlingLegal(turn, m)) {
+				ret = append(ret, m)
 			}
 		}
 	}
@@ -322,6 +439,57 @@ func (p *Position) PseudoLegalMoves(turn Color) []Move {
 	return ret
 }
 
+// PseudoLegalCaptures returns a list of all pseudo-legal captures, incl. en passant, and
+// promotions. The order is the same as for PseudoLegalMoves. Suitable for quiescence search.
+func (p *Position) PseudoLegalCaptures(turn Color) []Move {
+	return p.PseudoLegalCapturesInto(turn, make([]Move, 0, 16))
+}
+
+// PseudoLegalCapturesInto returns the pseudo-legal captures and promotions like
+// PseudoLegalCaptures, but generated into the given buffer to avoid allocation.
+func (p *Position) PseudoLegalCapturesInto(turn Color, buf []Move) []Move {
+	captures := p.pieces[turn.Opponent()][NoPiece]
+	all := p.All()
+	promos := PawnPromotionRank(turn)
+
+	ret := buf[:0]
+
+	for _, piece := range QueenRookKnightBishop {
+		pieces := p.pieces[turn][piece]
+		for pieces != EmptyBitboard {
+			from := pieces.LastPopSquare()
+			pieces ^= BitMask(from)
+
+			p.emitMove(turn, Capture, piece, from, Attackboard(all, from, piece)&captures, &ret)
+		}
+	}
+
+	pawns := p.pieces[turn][Pawn]
+	for pawns != EmptyBitboard {
+		from := pawns.LastPopSquare()
+		origin := BitMask(from)
+		pawns ^= origin
+
+		captureboard := PawnCaptureboard(turn, origin)
+		pushboard := PawnMoveboard(all, turn, origin)
+
+		p.emitMove(turn, Capture, Pawn, from, captureboard&captures&^promos, &ret)
+		p.emitPromo(turn, CapturePromotion, Pawn, from, captureboard&captures&promos, &ret)
+		p.emitPromo(turn, Promotion, Pawn, from, pushboard&promos, &ret)
+
+		if p.enpassant != ZeroSquare {
+			p.emitMove(turn, EnPassant, Pawn, from, captureboard&BitMask(p.enpassant), &ret)
+		}
+	}
+
+	if king := p.pieces[turn][King]; king != EmptyBitboard {
+		from := king.LastPopSquare()
+		p.emitMove(turn, Capture, King, from, KingAttackboard(from)&captures, &ret)
+	}
+
+	return ret
+}
+
 func (p *Position) emitMove(turn Color, t MoveType, piece Piece, from Square, attackboard Bitboard, out *[]Move) {
 	for attackboard != EmptyBitboard {
 		to := attackboard.LastPopSquare()
@@ -384,7 +552,6 @@ func (p *Position) String() string {
 }
 
 func (p *Position) xor(sq Square, color Color, piece Piece) {
-	p.rotated = p.rotated.Xor(sq)
 	p.pieces[color][NoPiece] ^= BitMask(sq)
 	p.pieces[color][piece] ^= BitMask(sq)
 }
@@ -396,26 +563,63 @@ func printPiece(c Color, p Piece) string {
 	return strings.ToLower(p.String())
 }
 
-// safeCastlingSquares returns the squares that must not be in check to castle.
-// Does not include the king to square.
-func safeCastlingSquares(c Color, t MoveType) []Square {
-	if c == White {
-		switch t {
-		case KingSideCastle:
-			return []Square{E1, F1}
-		case QueenSideCastle:
-			return []Square{E1, D1}
-		default:
-			return nil
-		}
-	} else {
-		switch t {
-		case KingSideCastle:
-			return []Square{E8, F8}
-		case QueenSideCastle:
-			return []Square{E8, D8}
-		default:
-			return nil
+// castlingMove returns the castling move of the given type, if the castling right is present,
+// the King and Rook are on their initial squares and all squares between their initial and
+// target squares are empty, except for the King and Rook themselves.
+func (p *Position) castlingMove(turn Color, t MoveType, king Square) (Move, bool) {
+	if !p.castling.IsAllowed(CastlingRight(turn, t)) {
+		return Move{}, false
+	}
+
+	rank := RelativeRank(turn, Rank1)
+	from, rook := NewSquare(p.layout.King, rank), NewSquare(p.layout.Rook(t), rank)
+	if king != from || !p.pieces[turn][Rook].IsSet(rook) {
+		return Move{}, false
+	}
+
+	kingTo, rookTo := CastlingTarget(t)
+	to := NewSquare(kingTo, rank)
+
+	span := (rankSpan(from, to) | rankSpan(rook, NewSquare(rookTo, rank))) &^ (BitMask(from) | BitMask(rook))
+	if span&p.All() != 0 {
+		return Move{}, false
+	}
+	return Move{Type: t, Piece: King, From: from, To: to}, true
+}
+
+// isCastlingSafe returns true iff the King is not in check and does not pass through an
+// attacked square. Does not include the king to square, unless the King does not move.
+func (p *Position) isCastlingSafe(turn Color, m Move) bool {
+	if p.IsAttacked(turn, m.From) {
+		return false
+	}
+	d := sign(int(m.To) - int(m.From))
+	for sq := Square(int(m.From) + d); sq != m.To; sq = Square(int(sq) + d) {
+		if p.IsAttacked(turn, sq) {
+			return false
 		}
 	}
+	return true
+}
+
+// isCastlingLegal returns true iff the King is not in check and neither passes through nor
+// lands on an attacked square. The King and Rook are removed from the board for the attacks,
+// so that the King cannot hide behind itself or the Rook it castles with.
+func (p *Position) isCastlingLegal(turn Color, m Move) bool {
+	rook, _, _ := p.CastlingRookMove(m)
+	all := p.All() &^ (BitMask(m.From) | BitMask(rook))
+	return p.attackedSquares(turn, all)&rankSpan(m.From, m.To) == 0
+}
+
+// rankSpan returns the squares between two squares on the same rank, inclusive.
+func rankSpan(a, b Square) Bitboard {
+	if a > b {
+		a, b = b, a
+	}
+
+	var ret Bitboard
+	for sq := a; sq <= b; sq++ {
+		ret |= BitMask(sq)
+	}
+	return ret
 }
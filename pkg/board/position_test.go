@@ -314,6 +314,265 @@ func TestPerft1(t *testing.T) {
 	}
 }
 
+func TestChess960(t *testing.T) {
+	t.Run("perft", func(t *testing.T) {
+		tests := []struct {
+			fen      string
+			expected []int
+		}{
+			// FEN: https://www.chessprogramming.org/Chess960_Perft_Results.
+			{"bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9", []int{21, 528, 12189}},
+			{"2nnrbkr/p1qppppp/8/1ppb4/6PP/3PP3/PPP2P2/BQNNRBKR w HEhe - 1 9", []int{21, 807, 18002}},
+		}
+
+		for _, tt := range tests {
+			pos, turn, _, _, err := fen.Decode(tt.fen)
+			require.NoError(t, err)
+
+			for i, expected := range tt.expected {
+				assert.Equal(t, expected, perft(pos, turn, i+1), "depth %v: %v", i+1, tt.fen)
+			}
+		}
+	})
+
+	t.Run("castling", func(t *testing.T) {
+		tests := []struct {
+			fen      string
+			move     board.MoveType
+			expected string
+		}{
+			{"1r2k1r1/pppppppp/8/8/8/8/PPPPPPPP/1R2K1R1 w KQkq - 0 1", board.KingSideCastle, "1r2k1r1/pppppppp/8/8/8/8/PPPPPPPP/1R3RK1 b kq - 0 1"},
+			{"1r2k1r1/pppppppp/8/8/8/8/PPPPPPPP/1R2K1R1 w KQkq - 0 1", board.QueenSideCastle, "1r2k1r1/pppppppp/8/8/8/8/PPPPPPPP/2KR2R1 b kq - 0 1"},
+			{"1r4k1/8/8/8/8/8/8/1R4K1 b Bb - 0 1", board.QueenSideCastle, "2kr4/8/8/8/8/8/8/1R4K1 w Q - 0 2"},
+		}
+
+		for _, tt := range tests {
+			b, err := fen.NewBoard(tt.fen)
+			require.NoError(t, err)
+
+			moves := filterMoves(b.Position().LegalMoves(b.Turn()), func(m board.Move) bool {
+				return m.Type == tt.move
+			})
+			require.Len(t, moves, 1, "pos: %v", tt.fen)
+			require.True(t, b.PushMove(moves[0]))
+			assert.Equal(t, tt.expected, fen.Encode(b.Position(), b.Turn(), b.NoProgress(), b.FullMoves()))
+		}
+	})
+}
+
+func TestLegalMoves(t *testing.T) {
+	tests := []struct {
+		fen      string
+		expected []int
+	}{
+		// FEN: https://www.chessprogramming.org/Perft_Results.
+		{fen.Initial, []int{20, 400, 8902}},
+		{"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", []int{48, 2039, 97862}},
+		{"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", []int{14, 191, 2812, 43238}},
+		{"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1", []int{6, 264, 9467}},
+		{"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8", []int{44, 1486, 62379}},
+		{"bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9", []int{21, 528, 12189}},
+	}
+
+	for _, tt := range tests {
+		pos, turn, _, _, err := fen.Decode(tt.fen)
+		require.NoError(t, err)
+
+		for i, expected := range tt.expected {
+			assert.Equal(t, expected, legalPerft(t, pos, turn, i+1), "depth %v: %v", i+1, tt.fen)
+		}
+	}
+}
+
+func TestEvasionMoves(t *testing.T) {
+	tests := []struct {
+		fen      string
+		expected string
+	}{
+		{fen.Initial, ""},
+		{"4k3/8/8/8/8/8/3q4/R3K2R w KQ - 0 1", "Ke1-f1 Ke1*d2"},                                                     // capture or flee
+		{"4k3/8/8/8/8/8/5N2/r3K3 w - - 0 1", "Nf2-d1 Ke1-e2 Ke1-d2"},                                                // block or flee
+		{"4k3/8/8/8/8/5n2/8/r3K2R w K - 0 1", "Ke1-f2 Ke1-e2"},                                                      // double check
+		{"8/8/8/2k5/3Pp3/8/8/4K3 b - d3 0 1", "e4*d3 e.p. Kc5-c4 Kc5-b4 Kc5-d5 Kc5-b5 Kc5-d6 Kc5-c6 Kc5-b6 Kc5*d4"}, // en passant
+	}
+
+	for _, tt := range tests {
+		pos, turn, _, _, err := fen.Decode(tt.fen)
+		require.NoError(t, err)
+
+		moves, ok := pos.EvasionMoves(turn)
+		assert.Equal(t, tt.expected != "", ok, "pos: %v", tt.fen)
+		assert.Equal(t, tt.expected, board.PrintMoves(moves), "pos: %v", tt.fen)
+	}
+}
+
+func TestGenerateMoves(t *testing.T) {
+	tests := []struct {
+		fen              string
+		pseudo, castling string
+	}{
+		{"4k3/8/8/8/8/8/8/R3K2R w KQ - 0 1", "0-0 0-0-0", "0-0 0-0-0"},
+		{"4k3/8/8/8/8/8/5r2/R3K2R w KQ - 0 1", "0-0 0-0-0", "0-0-0"},     // passes through attacked square
+		{"4k3/8/8/8/8/8/6r1/R3K2R w KQ - 0 1", "0-0 0-0-0", "0-0-0"},     // lands on attacked square
+		{"4k3/8/8/8/8/8/4r3/R3K2R w KQ - 0 1", "0-0 0-0-0", ""},          // in check
+		{"4k3/8/8/8/8/8/1r6/R3K2R w KQ - 0 1", "0-0 0-0-0", "0-0 0-0-0"}, // Rook passes attacked square
+		{"1r4k1/8/8/8/8/8/8/1R4K1 b Bb - 0 1", "0-0-0", "0-0-0"},         // Chess960
+	}
+
+	for _, tt := range tests {
+		pos, turn, _, _, err := fen.Decode(tt.fen)
+		require.NoError(t, err)
+
+		castles := func(list []board.Move) string {
+			return board.PrintMoves(filterMoves(list, board.Move.IsCastle))
+		}
+
+		assert.Equal(t, tt.pseudo, castles(pos.PseudoLegalMoves(turn)), "pos: %v", tt.fen)
+		assert.Equal(t, tt.castling, castles(pos.GenerateMoves(turn, board.GenOptions{LegalCastling: true})), "pos: %v", tt.fen)
+	}
+}
+
+// legalPerft counts the leaf nodes using LegalMoves and validates that the moves are exactly
+// the pseudo-legal moves that are legal, in the same order. Also validates EvasionMoves.
+func legalPerft(t *testing.T, pos *board.Position, turn board.Color, depth int) int {
+	moves := pos.LegalMoves(turn)
+	expected := filterMoves(pos.PseudoLegalMoves(turn), func(m board.Move) bool {
+		_, ok := pos.Move(m)
+		return ok
+	})
+	require.Equal(t, board.PrintMoves(expected), board.PrintMoves(moves), "pos: %v", pos)
+
+	legal := filterMoves(pos.PseudoLegalMoves(turn), pos.IsLegal)
+	require.Equal(t, board.PrintMoves(expected), board.PrintMoves(legal), "pos: %v", pos)
+	require.Equal(t, board.PrintMoves(moves), board.PrintMoves(pos.LegalMovesInto(turn, make([]board.Move, 5))), "pos: %v", pos)
+
+	castles := filterMoves(pos.GenerateMoves(turn, board.GenOptions{LegalCastling: true}), board.Move.IsCastle)
+	require.Equal(t, board.PrintMoves(filterMoves(expected, board.Move.IsCastle)), board.PrintMoves(castles), "pos: %v", pos)
+
+	evasions, ok := pos.EvasionMoves(turn)
+	require.Equal(t, pos.IsChecked(turn), ok, "pos: %v", pos)
+	if ok {
+		require.Equal(t, board.PrintMoves(expected), board.PrintMoves(evasions), "pos: %v", pos)
+	}
+
+	if depth == 1 {
+		return len(moves)
+	}
+
+	ret := 0
+	for _, m := range moves {
+		next, _ := pos.Move(m)
+		ret += legalPerft(t, next, turn.Opponent(), depth-1)
+	}
+	return ret
+}
+
+func TestPseudoLegalMovesInto(t *testing.T) {
+	tests := []string{
+		fen.Initial,
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+		"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+		"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+	}
+
+	// A shared buffer with stale moves must not affect the result.
+
+	var buf []board.Move
+
+	var check func(pos *board.Position, turn board.Color, depth int)
+	check = func(pos *board.Position, turn board.Color, depth int) {
+		moves := pos.PseudoLegalMoves(turn)
+
+		buf = pos.PseudoLegalMovesInto(turn, buf)
+		require.Equal(t, board.PrintMoves(moves), board.PrintMoves(buf), "pos: %v", pos)
+
+		expected := filterMoves(moves, func(m board.Move) bool {
+			return m.IsCaptureOrEnPassant() || m.IsPromotion()
+		})
+		require.Equal(t, board.PrintMoves(expected), board.PrintMoves(pos.PseudoLegalCaptures(turn)), "pos: %v", pos)
+		buf = pos.PseudoLegalCapturesInto(turn, buf)
+		require.Equal(t, board.PrintMoves(expected), board.PrintMoves(buf), "pos: %v", pos)
+
+		if evasions, ok := pos.EvasionMoves(turn); ok {
+			buf, _ = pos.EvasionMovesInto(turn, buf)
+			require.Equal(t, board.PrintMoves(evasions), board.PrintMoves(buf), "pos: %v", pos)
+		}
+
+		if depth == 0 {
+			return
+		}
+		for _, m := range moves {
+			if next, ok := pos.Move(m); ok {
+				check(next, turn.Opponent(), depth-1)
+			}
+		}
+	}
+
+	for _, tt := range tests {
+		pos, turn, _, _, err := fen.Decode(tt)
+		require.NoError(t, err)
+
+		check(pos, turn, 2)
+	}
+}
+
+func TestMakeMove(t *testing.T) {
+	tests := []string{
+		fen.Initial,
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+		"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+		"bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9",
+	}
+
+	for _, tt := range tests {
+		pos, turn, _, _, err := fen.Decode(tt)
+		require.NoError(t, err)
+
+		// MakeMove must agree with Move and UnmakeMove must restore the position.
+
+		var check func(pos *board.Position, turn board.Color, depth int)
+		check = func(pos *board.Position, turn board.Color, depth int) {
+			if depth == 0 {
+				return
+			}
+			for _, m := range pos.PseudoLegalMoves(turn) {
+				before := *pos
+				next, ok := pos.Move(m)
+
+				undo, actual := pos.MakeMove(m)
+				require.Equal(t, ok, actual, "move %v: %v", m, before)
+				if !actual {
+					require.Equal(t, before, *pos, "illegal move %v modified position", m)
+					continue
+				}
+				require.Equal(t, *next, *pos, "move %v: %v", m, before)
+
+				check(pos, turn.Opponent(), depth-1)
+
+				pos.UnmakeMove(m, undo)
+				require.Equal(t, before, *pos, "unmake %v: %v", m, before)
+			}
+		}
+		check(pos, turn, 3)
+	}
+}
+
+func perft(pos *board.Position, turn board.Color, depth int) int {
+	if depth == 0 {
+		return 1
+	}
+
+	ret := 0
+	for _, m := range pos.PseudoLegalMoves(turn) {
+		if next, ok := pos.Move(m); ok {
+			ret += perft(next, turn.Opponent(), depth-1)
+		}
+	}
+	return ret
+}
+
 func BenchmarkPseudoLegalMoves1(b *testing.B) {
 	pos, _ := fen.NewBoard(fen.Initial)
 	for i := 0; i < b.N; i++ {
@@ -335,6 +594,23 @@ func BenchmarkPseudoLegalMoves3(b *testing.B) {
 	}
 }
 
+func BenchmarkPseudoLegalMovesInto(b *testing.B) {
+	pos, _ := fen.NewBoard("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	buf := make([]board.Move, 0, 64)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf = pos.Position().PseudoLegalMovesInto(pos.Turn(), buf)
+	}
+}
+
+func BenchmarkLegalMoves(b *testing.B) {
+	pos, _ := fen.NewBoard("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	for i := 0; i < b.N; i++ {
+		pos.Position().LegalMoves(pos.Turn())
+	}
+}
+
 func filterMoves(ms []board.Move, fn func(move board.Move) bool) []board.Move {
 	var list []board.Move
 	for _, m := range ms {
@@ -356,3 +632,38 @@ func printMoves(ms []board.Move) string {
 	}
 	return strings.Join(list, "\n")
 }
+
+func TestGivesCheck(t *testing.T) {
+	tests := []struct {
+		fen      string
+		move     string
+		expected bool
+	}{
+		{fen.Initial, "e2e4", false},
+		{"6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", "a1a8", true},  // direct
+		{"6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", "a1a7", false}, // quiet
+		{"6k1/8/8/8/8/8/6N1/6RK w - - 0 1", "g2e3", true},    // discovered
+		{"6k1/8/8/8/8/8/6N1/6RK w - - 0 1", "h1h2", false},   // ..
+		{"k7/8/8/8/8/8/6p1/K7 b - - 0 1", "g2g1q", true},     // promotion
+		{"4k3/8/8/8/8/8/8/4K2R w K - 0 1", "e1g1", false},    // castling
+		{"5k2/8/8/8/8/8/8/4K2R w K - 0 1", "e1g1", true},     // .. w/ rook check
+		{"4k3/4r3/8/8/8/8/4B3/4K3 w - - 0 1", "e2d3", false}, // illegal: pinned
+	}
+
+	for _, tt := range tests {
+		pos, turn, _, _, err := fen.Decode(tt.fen)
+		require.NoError(t, err)
+
+		m, err := board.ParseMove(tt.move)
+		require.NoError(t, err)
+
+		var found bool
+		for _, candidate := range pos.PseudoLegalMoves(turn) {
+			if candidate.Equals(m) {
+				assert.Equal(t, tt.expected, pos.GivesCheck(candidate), "failed: %v %v", tt.fen, tt.move)
+				found = true
+			}
+		}
+		assert.True(t, found, "move not found: %v %v", tt.fen, tt.move)
+	}
+}
@@ -0,0 +1,176 @@
+// Package san contains utilities for reading and writing moves in Standard Algebraic Notation (SAN).
+package san
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/herohde/morlock/pkg/board"
+)
+
+// Parse parses a move in Standard Algebraic Notation (SAN), such as "Nxe5+" or "O-O", in the
+// given position. The move must be legal. Check and annotation suffixes are ignored.
+func Parse(pos *board.Position, turn board.Color, str string) (board.Move, error) {
+	san := strings.TrimRight(str, "+#!?")
+
+	switch san {
+	case "O-O", "0-0":
+		return findMove(pos, turn, str, func(m board.Move) bool { return m.Type == board.KingSideCastle })
+	case "O-O-O", "0-0-0":
+		return findMove(pos, turn, str, func(m board.Move) bool { return m.Type == board.QueenSideCastle })
+	}
+
+	// (1) Promotion suffix, such as "=Q" or "Q".
+
+	promo := board.NoPiece
+	if n := len(san); n > 2 && unicode.IsUpper(rune(san[n-1])) {
+		p, ok := board.ParsePiece(rune(san[n-1]))
+		if !ok {
+			return board.Move{}, fmt.Errorf("invalid promotion: %v", str)
+		}
+		promo = p
+		san = strings.TrimSuffix(san[:n-1], "=")
+	}
+
+	// (2) Piece prefix, if not a pawn.
+
+	piece := board.Pawn
+	if len(san) > 0 && unicode.IsUpper(rune(san[0])) {
+		p, ok := board.ParsePiece(rune(san[0]))
+		if !ok {
+			return board.Move{}, fmt.Errorf("invalid piece: %v", str)
+		}
+		piece = p
+		san = san[1:]
+	}
+
+	// (3) Destination square and optional disambiguation.
+
+	if len(san) < 2 {
+		return board.Move{}, fmt.Errorf("invalid move: %v", str)
+	}
+	to, err := board.ParseSquareStr(san[len(san)-2:])
+	if err != nil {
+		return board.Move{}, fmt.Errorf("invalid move: %v", str)
+	}
+	from := strings.TrimSuffix(strings.TrimSuffix(san[:len(san)-2], "x"), ":")
+
+	return findMove(pos, turn, str, func(m board.Move) bool {
+		if m.Piece != piece || m.To != to || m.Promotion != promo {
+			return false
+		}
+		for _, r := range from {
+			if f, ok := board.ParseFile(r); ok && m.From.File() != f {
+				return false
+			}
+			if rk, ok := board.ParseRank(r); ok && m.From.Rank() != rk {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// Print returns the move in Standard Algebraic Notation (SAN), such as "Nbd7", "exd6", "e8=Q+"
+// or "O-O-O#". The move must be legal in the given position. The origin square is disambiguated
+// by file, rank or both, if needed.
+func Print(pos *board.Position, turn board.Color, m board.Move) string {
+	var sb strings.Builder
+
+	switch m.Type {
+	case board.KingSideCastle:
+		sb.WriteString("O-O")
+	case board.QueenSideCastle:
+		sb.WriteString("O-O-O")
+	default:
+		if m.Piece == board.Pawn {
+			if m.IsCaptureOrEnPassant() {
+				sb.WriteString(m.From.File().String())
+			}
+		} else {
+			sb.WriteString(m.Piece.String())
+			sb.WriteString(disambiguate(pos, turn, m))
+		}
+		if m.IsCaptureOrEnPassant() {
+			sb.WriteString("x")
+		}
+		sb.WriteString(m.To.String())
+		if m.IsPromotion() {
+			sb.WriteString("=")
+			sb.WriteString(m.Promotion.String())
+		}
+	}
+
+	if next, ok := pos.Move(m); ok && next.IsChecked(turn.Opponent()) {
+		if len(next.LegalMoves(turn.Opponent())) == 0 {
+			sb.WriteString("#")
+		} else {
+			sb.WriteString("+")
+		}
+	}
+	return sb.String()
+}
+
+// PrintMoves returns the moves in SAN separated by space. The moves are played in sequence
+// from the given position and must be legal.
+func PrintMoves(pos *board.Position, turn board.Color, moves []board.Move) string {
+	var list []string
+	for _, m := range moves {
+		list = append(list, Print(pos, turn, m))
+
+		next, ok := pos.Move(m)
+		if !ok {
+			break
+		}
+		pos, turn = next, turn.Opponent()
+	}
+	return strings.Join(list, " ")
+}
+
+// disambiguate returns the origin file, rank or square needed to distinguish the move from
+// other legal moves of the same piece type to the same square.
+func disambiguate(pos *board.Position, turn board.Color, m board.Move) string {
+	var ambiguous, file, rank bool
+	for _, o := range pos.LegalMoves(turn) {
+		if o.Piece != m.Piece || o.To != m.To || o.From == m.From {
+			continue
+		}
+		ambiguous = true
+		file = file || o.From.File() == m.From.File()
+		rank = rank || o.From.Rank() == m.From.Rank()
+	}
+
+	switch {
+	case !ambiguous:
+		return ""
+	case !file:
+		return m.From.File().String()
+	case !rank:
+		return m.From.Rank().String()
+	default:
+		return m.From.String()
+	}
+}
+
+func findMove(pos *board.Position, turn board.Color, str string, fn board.MovePredicateFn) (board.Move, error) {
+	var ret []board.Move
+	for _, m := range pos.LegalMoves(turn) {
+		if fn(m) {
+			ret = append(ret, m)
+		}
+	}
+
+	switch len(ret) {
+	case 0:
+		return board.Move{}, fmt.Errorf("%w: %v", board.ErrIllegalMove, str)
+	case 1:
+		return ret[0], nil
+	default:
+		var list []string
+		for _, m := range ret {
+			list = append(list, Print(pos, turn, m))
+		}
+		return board.Move{}, fmt.Errorf("ambiguous move: %v (%v)", str, strings.Join(list, ", "))
+	}
+}
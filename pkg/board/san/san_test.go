@@ -0,0 +1,109 @@
+package san_test
+
+import (
+	"testing"
+
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/board/san"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		fen      string
+		san      string
+		expected string
+	}{
+		{fen.Initial, "e4", "e2-e4"},
+		{fen.Initial, "Nf3", "Ng1-f3"},
+		{"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "O-O-O", "0-0-0"},
+		{"r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1", "0-0", "0-0"},
+		{"4k3/8/8/8/8/8/8/R3K2R w - - 0 1", "Rad1", "Ra1-d1"},
+		{"4k3/8/8/8/8/N7/8/N3K3 w - - 0 1", "N1c2", "Na1-c2"},
+		{"4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", "exd6", "e5*d6 e.p."},
+		{"4k3/8/8/8/8/8/p7/1N2K3 b - - 0 1", "axb1=N+", "a2*b1=N"},
+	}
+
+	for _, tt := range tests {
+		pos, turn, _, _, err := fen.Decode(tt.fen)
+		require.NoError(t, err)
+
+		m, err := san.Parse(pos, turn, tt.san)
+		require.NoError(t, err, tt.san)
+		assert.Equal(t, tt.expected, m.String())
+	}
+
+	pos, turn, _, _, _ := fen.Decode("4k3/8/8/8/8/N7/8/N3K3 w - - 0 1")
+	_, err := san.Parse(pos, turn, "Nc2")
+	assert.EqualError(t, err, "ambiguous move: Nc2 (N1c2, N3c2)")
+	_, err = san.Parse(pos, turn, "Nb4")
+	assert.Error(t, err)
+}
+
+func TestPrint(t *testing.T) {
+	tests := []struct {
+		fen      string
+		move     string
+		expected string
+	}{
+		{fen.Initial, "e2e4", "e4"},
+		{fen.Initial, "g1f3", "Nf3"},
+		{"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "e1c1", "O-O-O"},
+		{"r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1", "e8g8", "O-O"},
+		{"4k3/8/8/8/8/8/4K3/R6R w - - 0 1", "a1d1", "Rad1"},
+		{"4k3/8/8/8/8/N7/8/N3K3 w - - 0 1", "a1c2", "N1c2"},
+		{"4k3/8/8/8/8/4N3/8/N3K3 w - - 0 1", "a1c2", "Nac2"},
+		{"4k3/8/8/Q5Q1/8/8/Q7/4K3 w - - 0 1", "a5d2", "Qa5d2"},
+		{"4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", "e5d6", "exd6"},
+		{"4k3/8/8/8/8/8/p7/1N2K3 b - - 0 1", "a2b1n", "axb1=N"},
+		{"4k3/8/8/8/8/8/p7/3K4 b - - 0 1", "a2a1q", "a1=Q+"},
+		{"6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", "a1a8", "Ra8#"},
+		{"6k1/5ppp/8/8/3b4/8/8/R6K b - - 0 1", "d4a1", "Bxa1"},
+	}
+
+	for _, tt := range tests {
+		pos, turn, _, _, err := fen.Decode(tt.fen)
+		require.NoError(t, err)
+
+		m := findMove(t, pos, turn, tt.move)
+		actual := san.Print(pos, turn, m)
+		assert.Equal(t, tt.expected, actual, "pos: %v", tt.fen)
+
+		parsed, err := san.Parse(pos, turn, actual)
+		require.NoError(t, err)
+		assert.Equal(t, m, parsed) // round trip
+	}
+}
+
+func TestPrintMoves(t *testing.T) {
+	pos, turn, _, _, err := fen.Decode(fen.Initial)
+	require.NoError(t, err)
+
+	var moves []board.Move
+	for _, str := range []string{"f2f3", "e7e5", "g2g4", "d8h4"} {
+		m := findMove(t, pos, turn, str)
+		moves = append(moves, m)
+
+		next, ok := pos.Move(m)
+		require.True(t, ok)
+		pos, turn = next, turn.Opponent()
+	}
+
+	pos, turn, _, _, _ = fen.Decode(fen.Initial)
+	assert.Equal(t, "f3 e5 g4 Qh4#", san.PrintMoves(pos, turn, moves))
+}
+
+func findMove(t *testing.T, pos *board.Position, turn board.Color, str string) board.Move {
+	candidate, err := board.ParseMove(str)
+	require.NoError(t, err)
+
+	for _, m := range pos.LegalMoves(turn) {
+		if candidate.Equals(m) {
+			return m
+		}
+	}
+	require.Failf(t, "illegal move", "%v", str)
+	return board.Move{}
+}
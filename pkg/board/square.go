@@ -137,17 +137,6 @@ func (s Square) String() string {
 	return fmt.Sprintf("%v%v", s.File(), s.Rank())
 }
 
-// IsSameRankOrFile returns true if the squares are on the same rank or same file.
-func IsSameRankOrFile(a, b Square) bool {
-	return a.File() == b.File() || a.Rank() == b.Rank()
-}
-
-// IsSameDiagonal returns true if the squares are on the same diagonal.
-func IsSameDiagonal(a, b Square) bool {
-	x, y := a.File().V()-b.File().V(), a.Rank().V()-b.Rank().V()
-	return x == y || x == -y
-}
-
 // Rank represents a chess board rank from Rank1=0, ..Rank8=7. 3bits.
 type Rank uint8
 
@@ -221,15 +210,6 @@ func (r Rank) String() string {
 	}
 }
 
-// PromotionRank returns the promotion rank of the given color.
-func PromotionRank(c Color) Rank {
-	if c == White {
-		return Rank8
-	} else {
-		return Rank1
-	}
-}
-
 // File represents a chess board file from FileH=0, ..FileA=7. The numbering is reversed
 // to match Square. 3bits.
 type File uint8
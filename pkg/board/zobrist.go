@@ -15,6 +15,22 @@ type ZobristTable struct {
 	castling  [NumCastling]ZobristHash
 	enpassant [NumSquares]ZobristHash
 	turn      [NumColors]ZobristHash
+
+	castled [NumColors]ZobristHash  // extra key component
+	buckets [numBuckets]ZobristHash // extra key component
+}
+
+// numBuckets is the number of distinct move buckets. Later moves share the last bucket.
+const numBuckets = 64
+
+// KeyOptions configure optional, history-dependent components of the search key. Evaluations
+// that depend on history not captured by the position, such as whether a side has castled,
+// are otherwise unsound to cache by position hash alone.
+type KeyOptions struct {
+	// Castled includes whether each side has castled.
+	Castled bool
+	// MoveBucket, if positive, includes the full move number divided by the bucket size.
+	MoveBucket int
 }
 
 func NewZobristTable(seed int64) *ZobristTable {
@@ -38,6 +54,15 @@ func NewZobristTable(seed int64) *ZobristTable {
 			ret.enpassant[sq] = ZobristHash(r.Uint64())
 		}
 	}
+
+	// Extra key components are generated last to keep the position hashes stable.
+
+	for c := ZeroColor; c < NumColors; c++ {
+		ret.castled[c] = ZobristHash(r.Uint64())
+	}
+	for i := 0; i < numBuckets; i++ {
+		ret.buckets[i] = ZobristHash(r.Uint64())
+	}
 	return ret
 }
 
@@ -59,6 +84,42 @@ func (z *ZobristTable) Hash(pos *Position, turn Color) ZobristHash {
 	return hash
 }
 
+// PawnHash computes the zobrist hash of the pawns only for the given position. Intended for
+// pawn structure caches, where positions with the same pawns share the evaluation.
+func (z *ZobristTable) PawnHash(pos *Position) ZobristHash {
+	var hash ZobristHash
+
+	for c := ZeroColor; c < NumColors; c++ {
+		for bb := pos.Piece(c, Pawn); bb != 0; {
+			sq := bb.LastPopSquare()
+			bb ^= BitMask(sq)
+			hash ^= z.pieces[c][Pawn][sq]
+		}
+	}
+	return hash
+}
+
+// Key computes the extra key components for the given history-dependent state.
+func (z *ZobristTable) Key(opts KeyOptions, hasCastled [NumColors]bool, fullmoves int) ZobristHash {
+	var hash ZobristHash
+
+	if opts.Castled {
+		for c := ZeroColor; c < NumColors; c++ {
+			if hasCastled[c] {
+				hash ^= z.castled[c]
+			}
+		}
+	}
+	if opts.MoveBucket > 0 {
+		bucket := fullmoves / opts.MoveBucket
+		if bucket >= numBuckets {
+			bucket = numBuckets - 1
+		}
+		hash ^= z.buckets[bucket]
+	}
+	return hash
+}
+
 // Move computes a hash for the position after the (legal) move incrementally. Cheaper than
 // computing it for the new position directly.
 func (z *ZobristTable) Move(h ZobristHash, pos *Position, m Move) ZobristHash {
@@ -97,7 +158,7 @@ func (z *ZobristTable) Move(h ZobristHash, pos *Position, m Move) ZobristHash {
 
 	case KingSideCastle, QueenSideCastle:
 		hash ^= z.pieces[turn][m.Piece][m.To]
-		from, to, _ := m.CastlingRookMove()
+		from, to, _ := pos.CastlingRookMove(m)
 		hash ^= z.pieces[turn][Rook][from]
 		hash ^= z.pieces[turn][Rook][to]
 
@@ -105,7 +166,7 @@ func (z *ZobristTable) Move(h ZobristHash, pos *Position, m Move) ZobristHash {
 		hash ^= z.pieces[turn][m.Piece][m.To]
 	}
 
-	hash ^= z.castling[pos.Castling()&m.CastlingRightsLost()]
+	hash ^= z.castling[pos.Castling()&^pos.CastlingRightsLost(m)]
 	ept, _ := m.EnPassantTarget()
 	hash ^= z.enpassant[ept]
 	hash ^= z.turn[turn.Opponent()]
@@ -0,0 +1,87 @@
+package board_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKey(t *testing.T) {
+	// (1) Same position, reached by castling or set up directly.
+
+	castled, err := fen.NewBoard("4k3/8/8/8/8/8/8/4K2R w K - 0 1")
+	require.NoError(t, err)
+	for _, m := range castled.Position().LegalMoves(castled.Turn()) {
+		if m.Type == board.KingSideCastle {
+			require.True(t, castled.PushMove(m))
+		}
+	}
+	require.True(t, castled.HasCastled(board.White))
+
+	direct, err := fen.NewBoard("4k3/8/8/8/8/8/8/5RK1 b - - 0 1")
+	require.NoError(t, err)
+
+	assert.Equal(t, direct.Hash(), castled.Hash())
+	assert.Equal(t, direct.Key(), castled.Key())
+
+	opts := board.KeyOptions{Castled: true}
+	castled.SetKeyOptions(opts)
+	direct.SetKeyOptions(opts)
+
+	assert.Equal(t, direct.Hash(), castled.Hash())
+	assert.NotEqual(t, direct.Key(), castled.Key())
+	assert.Equal(t, castled.Key(), castled.Fork().Key())
+
+	// (2) Same position, different move buckets.
+
+	early, err := fen.NewBoard("4k3/8/8/8/8/8/8/5RK1 b - - 0 5")
+	require.NoError(t, err)
+	late, err := fen.NewBoard("4k3/8/8/8/8/8/8/5RK1 b - - 0 30")
+	require.NoError(t, err)
+
+	assert.Equal(t, early.Key(), late.Key())
+
+	opts = board.KeyOptions{MoveBucket: 10}
+	early.SetKeyOptions(opts)
+	late.SetKeyOptions(opts)
+
+	assert.NotEqual(t, early.Key(), late.Key())
+}
+
+func TestIncrementalHash(t *testing.T) {
+	zt := board.NewZobristTable(0)
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		b, err := fen.NewBoard("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+		require.NoError(t, err)
+
+		for j := 0; j < 60 && !b.Result().IsTerminal(); j++ {
+			moves := b.Position().LegalMoves(b.Turn())
+			if len(moves) == 0 {
+				break
+			}
+			m := moves[r.Intn(len(moves))]
+			b.PushMove(m)
+
+			require.Equal(t, zt.Hash(b.Position(), b.Turn()), b.Hash(), "hash mismatch after %v: %v", m, b)
+		}
+	}
+}
+
+func TestPawnHash(t *testing.T) {
+	a, err := fen.NewBoard("4k3/pp6/8/8/8/8/PP6/R3K3 w - - 0 1")
+	require.NoError(t, err)
+	b, err := fen.NewBoard("r3k3/pp6/8/8/8/8/PP6/4K3 b - - 0 1")
+	require.NoError(t, err)
+	c, err := fen.NewBoard("4k3/pp6/8/8/8/P7/1P6/R3K3 w - - 0 1")
+	require.NoError(t, err)
+
+	assert.NotEqual(t, a.Hash(), b.Hash())
+	assert.Equal(t, a.PawnHash(), b.PawnHash())
+	assert.NotEqual(t, a.PawnHash(), c.PawnHash())
+}
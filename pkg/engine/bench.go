@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/herohde/morlock/pkg/search/pvfmt"
+	"github.com/seekerror/logw"
+	"time"
+)
+
+// BenchPositions are the positions searched by Bench. They cover the opening, middlegame and
+// endgame with some tactics, so that search changes can be measured on a fixed workload.
+var BenchPositions = []string{
+	fen.Initial,
+	"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+	"r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10",
+	"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+	"6k1/5ppp/8/8/3b4/8/8/R6K w - - 0 1",
+	"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+}
+
+// BenchResult holds the aggregate result of a benchmark.
+type BenchResult struct {
+	Depth     uint
+	Positions int
+	Nodes     uint64
+	Time      time.Duration
+	Hash      float64 // hash table used [0;1] at the end
+}
+
+// NPS returns the nodes searched per second.
+func (r BenchResult) NPS() uint64 {
+	return pvfmt.NPS(r.Nodes, r.Time)
+}
+
+func (r BenchResult) String() string {
+	return fmt.Sprintf("depth=%v positions=%v nodes=%v time=%v nps=%v hash=%v%%", r.Depth, r.Positions, r.Nodes, r.Time, r.NPS(), int(100*r.Hash))
+}
+
+// Bench searches each benchmark position to the given depth with iterative deepening and
+// returns the aggregate node count and time. It uses a fresh transposition table of the
+// configured size and does not affect the current game. Noise is not used.
+func (e *Engine) Bench(ctx context.Context, depth uint) (BenchResult, error) {
+	e.mu.Lock()
+	_, _ = e.haltSearchIfActive(ctx)
+
+	root, zt, keys := e.root, e.zt, e.keys
+	hash, _ := allocate(e.opts.Memory, e.opts.Hash, e.aux)
+	var tt search.TranspositionTable = search.NoTranspositionTable{}
+	if hash > 0 {
+		tt = e.factory(ctx, hash)
+	}
+	e.mu.Unlock()
+
+	ret := BenchResult{Depth: depth}
+	for _, position := range BenchPositions {
+		pos, turn, np, fm, err := fen.Decode(position)
+		if err != nil {
+			return BenchResult{}, err
+		}
+		b := board.NewBoard(zt, pos, turn, np, fm)
+		b.SetKeyOptions(keys)
+
+		sctx := &search.Context{ID: search.NewID(), Alpha: eval.NegInfScore, Beta: eval.InfScore, TT: tt, Order: search.NewRootOrder()}
+
+		start := time.Now()
+		for d := 1; d <= int(depth); d++ {
+			nodes, _, _, err := root.Search(ctx, sctx, b, d)
+			if err != nil {
+				return BenchResult{}, err
+			}
+			ret.Nodes += nodes
+		}
+		ret.Time += time.Since(start)
+		ret.Positions++
+	}
+	ret.Hash = tt.Used()
+
+	logw.Infof(ctx, "Bench: %v", ret)
+	return ret, nil
+}
@@ -0,0 +1,30 @@
+package engine_test
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/engine"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestBench(t *testing.T) {
+	ctx := context.Background()
+
+	root := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+	e := engine.New(ctx, "test", "test", root, engine.WithOptions(engine.Options{Hash: 1}))
+	require.NoError(t, e.Move(ctx, "e2e4"))
+	before := e.Position()
+
+	r, err := e.Bench(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, len(engine.BenchPositions), r.Positions)
+	assert.Greater(t, r.Nodes, uint64(0))
+
+	again, err := e.Bench(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, r.Nodes, again.Nodes) // deterministic
+	assert.Equal(t, before, e.Position()) // game unaffected
+}
@@ -1,10 +1,14 @@
 package engine
 
 import (
+	"bufio"
 	"context"
 	"fmt"
 	"github.com/herohde/morlock/pkg/board"
 	"github.com/herohde/morlock/pkg/board/fen"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -82,3 +86,46 @@ type book struct {
 func (b *book) Find(ctx context.Context, pos string) ([]board.Move, error) {
 	return b.moves[fen.Strip(pos)], nil
 }
+
+// ReadBook reads an opening book in text line format: one line of moves per line, such as
+// "e2e4 d7d5". Blank lines and lines starting with '#' are ignored.
+func ReadBook(r io.Reader) (Book, error) {
+	var lines []Line
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, strings.Fields(line))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewBook(lines)
+}
+
+// LoadBook loads an opening book from the given file. Files with a ".bin" extension are
+// read in the binary Polyglot format with the given Polyglot keys, or the standard keys if
+// nil. Otherwise, the file is read in text line format.
+func LoadBook(filename string, keys *PolyglotKeys) (Book, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if !strings.EqualFold(filepath.Ext(filename), ".bin") {
+		return ReadBook(f)
+	}
+
+	if keys == nil {
+		keys = &StandardPolyglotKeys
+	}
+	entries, err := ReadPolyglot(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid polyglot book %v: %v", filename, err)
+	}
+	return NewPolyglotBook(keys, entries), nil
+}
@@ -1,12 +1,15 @@
 package engine_test
 
 import (
+	"bytes"
 	"context"
 	"github.com/herohde/morlock/pkg/board"
 	"github.com/herohde/morlock/pkg/board/fen"
 	"github.com/herohde/morlock/pkg/engine"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"testing"
@@ -39,3 +42,42 @@ func TestBook(t *testing.T) {
 		assert.Equal(t, strings.Join(sorted, " "), tt.moves)
 	}
 }
+
+func TestLoadBook(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	// (1) Text line format.
+
+	lines := filepath.Join(dir, "book.txt")
+	require.NoError(t, os.WriteFile(lines, []byte("# comment\ne2e4 d7d5\n\nd2d4\n"), 0644))
+
+	book, err := engine.LoadBook(lines, nil)
+	require.NoError(t, err)
+	list, err := book.Find(ctx, fen.Initial)
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+
+	_, err = engine.ReadBook(strings.NewReader("e2e5\n"))
+	assert.Error(t, err)
+
+	// (2) Polyglot format uses the standard keys by default.
+
+	pos, turn, _, _, err := fen.Decode(fen.Initial)
+	require.NoError(t, err)
+	key := engine.StandardPolyglotKeys.Key(pos, turn)
+
+	var buf bytes.Buffer
+	require.NoError(t, engine.WritePolyglot(&buf, []engine.PolyglotEntry{{Key: key, Move: 3<<6 | 1<<9 | 3 | 3<<3, Weight: 1}}))
+
+	bin := filepath.Join(dir, "book.bin")
+	require.NoError(t, os.WriteFile(bin, buf.Bytes(), 0644))
+	book, err = engine.LoadBook(bin, nil)
+	require.NoError(t, err)
+	list, err = book.Find(ctx, fen.Initial)
+	require.NoError(t, err)
+	assert.Equal(t, "d2-d4", board.PrintMoves(list))
+
+	_, err = engine.LoadBook(filepath.Join(dir, "missing.txt"), nil)
+	assert.Error(t, err)
+}
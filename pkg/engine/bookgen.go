@@ -0,0 +1,199 @@
+package engine
+
+import (
+	"fmt"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/board/pgn"
+	"sort"
+	"strings"
+)
+
+// BookOptions are options for building an opening book from games.
+type BookOptions struct {
+	// MaxDepth is the maximum number of plies of each game to include. If zero, all plies
+	// are included.
+	MaxDepth int
+	// MinGames is the minimum number of games a move must have been played in.
+	MinGames int
+	// MinScore is the minimum average score in [0;1] of a move for the side that played it,
+	// where a win is 1 and a draw is 1/2.
+	MinScore float64
+}
+
+func (o BookOptions) String() string {
+	return fmt.Sprintf("{depth=%v, games=%v, score=%v}", o.MaxDepth, o.MinGames, o.MinScore)
+}
+
+// BookEntry holds the statistics of a move in a position.
+type BookEntry struct {
+	Move  board.Move
+	Games int
+	// Points is the total score for the side that played the move: 1 for a win, 1/2 for a draw.
+	Points float64
+}
+
+// Score returns the average score in [0;1] of the move.
+func (e BookEntry) Score() float64 {
+	if e.Games == 0 {
+		return 0
+	}
+	return e.Points / float64(e.Games)
+}
+
+func (e BookEntry) String() string {
+	return fmt.Sprintf("%v (games=%v, score=%.2f)", e.Move, e.Games, e.Score())
+}
+
+// BookTree holds move statistics by position from a collection of games. Transpositions are
+// merged. It is used to build opening books.
+type BookTree struct {
+	opt   BookOptions
+	nodes map[string]*bookNode // cropped fen -> node
+}
+
+type bookNode struct {
+	pos   *board.Position
+	turn  board.Color
+	moves map[board.Move]*BookEntry
+}
+
+// NewBookTree returns a new, empty book tree with the given options.
+func NewBookTree(opt BookOptions) *BookTree {
+	return &BookTree{opt: opt, nodes: map[string]*bookNode{}}
+}
+
+// Add adds the moves of the given game up to the maximum depth. Games that do not start from
+// the initial position or do not have a result are ignored. Returns true iff added.
+func (t *BookTree) Add(g pgn.Game) (bool, error) {
+	if fen.Strip(g.Start()) != fen.Strip(fen.Initial) {
+		return false, nil
+	}
+
+	var white float64
+	switch g.Result {
+	case "1-0":
+		white = 1
+	case "0-1":
+		white = 0
+	case "1/2-1/2":
+		white = 0.5
+	default:
+		return false, nil
+	}
+
+	b, err := g.Board()
+	if err != nil {
+		return false, err
+	}
+	for i, m := range g.Moves {
+		if t.opt.MaxDepth > 0 && i >= t.opt.MaxDepth {
+			break
+		}
+
+		key := fen.Strip(fen.Encode(b.Position(), b.Turn(), 0, 1))
+		node, ok := t.nodes[key]
+		if !ok {
+			pos := *b.Position()
+			node = &bookNode{pos: &pos, turn: b.Turn(), moves: map[board.Move]*BookEntry{}}
+			t.nodes[key] = node
+		}
+		entry, ok := node.moves[m]
+		if !ok {
+			entry = &BookEntry{Move: m}
+			node.moves[m] = entry
+		}
+		entry.Games++
+		if b.Turn() == board.White {
+			entry.Points += white
+		} else {
+			entry.Points += 1 - white
+		}
+
+		if !b.PushMove(m) {
+			return false, fmt.Errorf("%w: %v", board.ErrIllegalMove, m)
+		}
+	}
+	return true, nil
+}
+
+// Find returns the moves in the given position in FEN that satisfy the options, ordered by
+// number of games.
+func (t *BookTree) Find(pos string) []BookEntry {
+	node, ok := t.nodes[fen.Strip(pos)]
+	if !ok {
+		return nil
+	}
+	return t.entries(node)
+}
+
+// Size returns the number of positions with at least one move that satisfies the options.
+func (t *BookTree) Size() int {
+	ret := 0
+	for _, node := range t.nodes {
+		if len(t.entries(node)) > 0 {
+			ret++
+		}
+	}
+	return ret
+}
+
+// Lines returns all opening lines from the initial position that satisfy the options. Each
+// line ends when no move satisfies the options.
+func (t *BookTree) Lines() []Line {
+	var ret []Line
+	t.lines(fen.Initial, nil, map[string]bool{}, &ret)
+	return ret
+}
+
+func (t *BookTree) lines(pos string, prefix Line, visited map[string]bool, out *[]Line) {
+	key := fen.Strip(pos)
+	node, ok := t.nodes[key]
+	if !ok || visited[key] || len(t.entries(node)) == 0 {
+		if len(prefix) > 0 {
+			*out = append(*out, append(Line{}, prefix...))
+		}
+		return
+	}
+
+	visited[key] = true
+	defer delete(visited, key)
+
+	for _, e := range t.entries(node) {
+		next, ok := node.pos.Move(e.Move)
+		if !ok {
+			continue
+		}
+		t.lines(fen.Encode(next, node.turn.Opponent(), 0, 1), append(prefix, printBookMove(e.Move)), visited, out)
+	}
+}
+
+// Book returns an opening book of the lines.
+func (t *BookTree) Book() (Book, error) {
+	return NewBook(t.Lines())
+}
+
+func (t *BookTree) entries(node *bookNode) []BookEntry {
+	var ret []BookEntry
+	for _, e := range node.moves {
+		if e.Games < t.opt.MinGames || e.Score() < t.opt.MinScore {
+			continue
+		}
+		ret = append(ret, *e)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Games != ret[j].Games {
+			return ret[i].Games > ret[j].Games
+		}
+		return ret[i].Move.String() < ret[j].Move.String()
+	})
+	return ret
+}
+
+// printBookMove prints the move in the Line format, such as "e2e4" or "e7e8q".
+func printBookMove(m board.Move) string {
+	if m.Promotion != board.NoPiece {
+		return fmt.Sprintf("%v%v%v", m.From, m.To, strings.ToLower(m.Promotion.String()))
+	}
+	return fmt.Sprintf("%v%v", m.From, m.To)
+}
@@ -0,0 +1,75 @@
+package engine_test
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/board/pgn"
+	"github.com/herohde/morlock/pkg/engine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestBookTree(t *testing.T) {
+	ctx := context.Background()
+
+	games, err := pgn.Parse(`[Result "1-0"]
+1. e4 e5 2. Nf3 Nc6 1-0
+
+[Result "1/2-1/2"]
+1. e4 e5 2. Nf3 Nf6 1/2-1/2
+
+[Result "0-1"]
+1. e4 c5 2. Nf3 0-1
+
+[Result "1-0"]
+1. d4 d5 1-0
+
+[Result "*"]
+1. e4 e5 *
+
+[FEN "4k3/8/8/8/8/8/8/4K3 w - - 0 1"]
+[Result "1-0"]
+1. Kd2 1-0
+`)
+	require.NoError(t, err)
+
+	build := func(opt engine.BookOptions) *engine.BookTree {
+		tree := engine.NewBookTree(opt)
+		added := 0
+		for _, g := range games {
+			ok, err := tree.Add(g)
+			require.NoError(t, err)
+			if ok {
+				added++
+			}
+		}
+		assert.Equal(t, 4, added)
+		return tree
+	}
+
+	tree := build(engine.BookOptions{MaxDepth: 3, MinGames: 2, MinScore: 0.25})
+
+	list := tree.Find(fen.Initial)
+	require.Len(t, list, 1) // d4: single game
+	assert.Equal(t, "e2-e4", list[0].Move.String())
+	assert.Equal(t, 3, list[0].Games)
+	assert.InDelta(t, 1.5/3, list[0].Score(), 0.001)
+
+	lines := tree.Lines()
+	require.Len(t, lines, 1) // Nc6 and Nf6 excluded by depth
+	assert.Equal(t, "e2e4 e7e5 g1f3", lines[0].String())
+
+	book, err := tree.Book()
+	require.NoError(t, err)
+	moves, err := book.Find(ctx, "rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/RNBQKBNR w KQkq e6 0 2")
+	require.NoError(t, err)
+	assert.Equal(t, "Ng1-f3", board.PrintMoves(moves))
+
+	// e5 scores 1/4 for Black.
+
+	lines = build(engine.BookOptions{MinGames: 2, MinScore: 0.3}).Lines()
+	require.Len(t, lines, 1)
+	assert.Equal(t, "e2e4", lines[0].String())
+}
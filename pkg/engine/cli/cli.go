@@ -0,0 +1,290 @@
+// Package cli contains a shared command-line harness for running an engine over stdin and
+// stdout under the protocol selected by the first line of input.
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/engine"
+	"github.com/herohde/morlock/pkg/engine/console"
+	"github.com/herohde/morlock/pkg/engine/uci"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/herohde/morlock/pkg/search/searchctl"
+	"github.com/seekerror/logw"
+	"github.com/seekerror/stdlib/pkg/lang"
+	"net/http"
+	_ "net/http/pprof"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	source   = flag.String("source", "", "External command that prints a FEN for the console 'setup' command, such as a board-recognition tool (disabled if empty)")
+	pprof    = flag.String("pprof", "", "Serve pprof profiles and metrics on the given address, such as :6060 (disabled if empty)")
+	selftest = flag.Bool("selftest", false, "Run a fast self-test on startup and exit if it fails")
+	params   = flag.String("params", "", "JSON file with tunable evaluation parameters to load on startup (disabled if empty)")
+	export   = flag.Bool("exportparams", false, "Print the tunable evaluation parameters as JSON and exit")
+	evalfen  = flag.String("eval", "", "Print the static evaluation of the given FEN for the side to move and exit (disabled if empty)")
+	explain  = flag.String("explain", "", "Print the static evaluation of the given FEN for the side to move by term and exit (disabled if empty)")
+	bestmove = flag.String("bestmove", "", "Print the best move of the given FEN and exit (disabled if empty)")
+	depth    = flag.Uint("depth", 0, "Search depth limit for -bestmove (zero if engine default)")
+	bench    = flag.Uint("bench", 0, "Search the benchmark positions to the given depth, print the total nodes and nps and exit (disabled if zero)")
+	book     = flag.String("book", "", "Opening book file in text line format or, if '.bin', Polyglot format to use instead of any built-in book (disabled if empty)")
+	bookkeys = flag.String("bookkeys", "", "File with 781 hexadecimal Polyglot random keys for Polyglot books (default: standard keys)")
+)
+
+// benchDepth is the benchmark depth if run as "<engine> bench" without a depth, such as by
+// OpenBench-style tooling.
+const benchDepth = 4
+
+// Option is a harness option.
+type Option func(*options)
+
+type options struct {
+	uci     []uci.Option
+	console []console.Option
+}
+
+// WithUCI adds driver options for the UCI protocol.
+func WithUCI(opts ...uci.Option) Option {
+	return func(o *options) {
+		o.uci = append(o.uci, opts...)
+	}
+}
+
+// WithConsole adds driver options for the console protocol.
+func WithConsole(opts ...console.Option) Option {
+	return func(o *options) {
+		o.console = append(o.console, opts...)
+	}
+}
+
+// Run runs the engine under the protocol selected by the first line of input and blocks
+// until the driver is closed. Exits if the protocol is not supported. Flags must be parsed.
+func Run(ctx context.Context, e *engine.Engine, opts ...Option) {
+	var opt options
+	if fields := strings.Fields(*source); len(fields) > 0 {
+		src := engine.CommandSource{Name: fields[0], Args: fields[1:]}
+		opt.console = append(opt.console, console.UsePositionSource(src))
+	}
+	for _, fn := range opts {
+		fn(&opt)
+	}
+
+	if *params != "" {
+		if err := importParameters(ctx, e, *params); err != nil {
+			logw.Exitf(ctx, "Failed to load parameters %v: %v", *params, err)
+		}
+	}
+	if err := loadBook(ctx, &opt); err != nil {
+		logw.Exitf(ctx, "Failed to load book: %v", err)
+	}
+	if *export {
+		if err := e.ExportParameters(os.Stdout); err != nil {
+			logw.Exitf(ctx, "Failed to export parameters: %v", err)
+		}
+		return
+	}
+	if d, ok := benchArgs(); ok {
+		if err := printBench(ctx, e, d); err != nil {
+			logw.Exitf(ctx, "Failed to run benchmark: %v", err)
+		}
+		return
+	}
+	if *evalfen != "" {
+		if err := printEval(ctx, e, *evalfen); err != nil {
+			logw.Exitf(ctx, "Failed to evaluate %v: %v", *evalfen, err)
+		}
+		return
+	}
+	if *explain != "" {
+		if err := printExplain(ctx, e, *explain); err != nil {
+			logw.Exitf(ctx, "Failed to explain %v: %v", *explain, err)
+		}
+		return
+	}
+	if *bestmove != "" {
+		if err := printBestMove(ctx, e, *bestmove, *depth); err != nil {
+			logw.Exitf(ctx, "Failed to search %v: %v", *bestmove, err)
+		}
+		return
+	}
+	if *pprof != "" {
+		go servePprof(ctx, *pprof)
+	}
+	if *selftest {
+		for _, c := range e.SelfTest(ctx) {
+			if !c.Passed() {
+				logw.Exitf(ctx, "Self-test failed: %v", c)
+			}
+			logw.Infof(ctx, "Self-test: %v", c)
+		}
+	}
+
+	in := engine.ReadStdinLines(ctx)
+	switch <-in {
+	case uci.ProtocolName:
+		driver, out := uci.NewDriver(ctx, e, in, opt.uci...)
+		go engine.WriteStdoutLines(ctx, out)
+
+		<-driver.Closed()
+
+	case console.ProtocolName:
+		driver, out := console.NewDriver(ctx, e, in, opt.console...)
+		go engine.WriteStdoutLines(ctx, out)
+
+		<-driver.Closed()
+
+	default:
+		flag.Usage()
+		logw.Exitf(ctx, "Protocol not supported")
+	}
+}
+
+// loadBook adds the UCI options for the -book and -bookkeys flags, if set.
+func loadBook(ctx context.Context, opt *options) error {
+	var keys *engine.PolyglotKeys
+	if *bookkeys != "" {
+		f, err := os.Open(*bookkeys)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		keys, err = engine.ReadPolyglotKeys(f)
+		if err != nil {
+			return fmt.Errorf("invalid keys %v: %v", *bookkeys, err)
+		}
+		opt.uci = append(opt.uci, uci.UsePolyglotKeys(keys))
+	}
+
+	if *book != "" {
+		b, err := engine.LoadBook(*book, keys)
+		if err != nil {
+			return err
+		}
+		opt.uci = append(opt.uci, uci.UseBookFile(*book, b))
+		logw.Infof(ctx, "Loaded book %v", *book)
+	}
+	return nil
+}
+
+func importParameters(ctx context.Context, e *engine.Engine, filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return e.ImportParameters(ctx, f)
+}
+
+// printEval prints the static evaluation of the position in pawns for the side to move.
+func printEval(ctx context.Context, e *engine.Engine, position string) error {
+	if err := e.Reset(ctx, position); err != nil {
+		return err
+	}
+	score, err := e.Evaluate(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Println(score)
+	return nil
+}
+
+// printExplain prints the static evaluation of the position in pawns for the side to move
+// with a per-term breakdown, if supported by the evaluator.
+func printExplain(ctx context.Context, e *engine.Engine, position string) error {
+	if err := e.Reset(ctx, position); err != nil {
+		return err
+	}
+	explanation, err := e.ExplainEvaluation(ctx)
+	if err != nil {
+		return err
+	}
+	for _, t := range explanation.Terms {
+		fmt.Printf("%-20v %v\n", t.Name, t.Score)
+	}
+	fmt.Println(explanation.Score)
+	return nil
+}
+
+// printBestMove searches the position to the given depth, or the engine default if zero,
+// and prints the best move in UCI notation, such as "bestmove e2e4". A search without any
+// depth limit is rejected, because it would not terminate in reasonable time.
+func printBestMove(ctx context.Context, e *engine.Engine, position string, depth uint) error {
+	if depth == 0 {
+		depth = e.Options().Depth
+	}
+	if depth == 0 {
+		return fmt.Errorf("%w: %v", engine.ErrNoDepthLimit, e.Name())
+	}
+	if err := e.Reset(ctx, position); err != nil {
+		return err
+	}
+
+	out, err := e.Analyze(ctx, searchctl.Options{DepthLimit: lang.Some(depth)})
+	if err != nil {
+		return err
+	}
+	var last search.PV
+	for pv := range out {
+		last = pv
+	}
+	_, _ = e.Halt(ctx)
+
+	logw.Infof(ctx, "Searched %v: %v", position, last)
+
+	if len(last.Moves) == 0 {
+		fmt.Println("bestmove 0000") // checkmate or stalemate
+		return nil
+	}
+	fmt.Printf("bestmove %v\n", board.PrintUCIMove(last.Moves[0], e.Board().Position().CastlingLayout(), false))
+	return nil
+}
+
+// benchArgs returns the benchmark depth, if requested by the -bench flag or by the "bench
+// [<depth>]" command-line arguments.
+func benchArgs() (uint, bool) {
+	if *bench > 0 {
+		return *bench, true
+	}
+	if flag.Arg(0) != "bench" {
+		return 0, false
+	}
+	if n, err := strconv.Atoi(flag.Arg(1)); err == nil && n > 0 {
+		return uint(n), true
+	}
+	return benchDepth, true
+}
+
+// printBench searches the benchmark positions to the given depth and prints the result. The
+// last line is "<nodes> nodes <nps> nps", which OpenBench-style tooling parses. Noise, book
+// and contempt are not used and the hash table is fresh, so the node count is deterministic
+// for a given engine configuration and changes only if the search does.
+func printBench(ctx context.Context, e *engine.Engine, depth uint) error {
+	r, err := e.Bench(ctx, depth)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Positions searched: %v\n", r.Positions)
+	fmt.Printf("Depth:              %v\n", r.Depth)
+	fmt.Printf("Time:               %v\n", r.Time.Round(time.Millisecond))
+	fmt.Printf("%v nodes %v nps\n", r.Nodes, r.NPS())
+	return nil
+}
+
+// servePprof serves the standard pprof handlers. Search iterations are labeled with the
+// search depth, which allows filtering with "go tool pprof -tagfocus depth=12". It also
+// serves search metrics as expvar variables on /debug/vars and in the Prometheus text
+// format on /metrics, for monitoring long-running deployments.
+func servePprof(ctx context.Context, addr string) {
+	logw.Infof(ctx, "Serving pprof profiles on %v/debug/pprof and metrics on %v/metrics", addr, addr)
+	if err := http.ListenAndServe(addr, nil); err != nil {
+		logw.Errorf(ctx, "Failed to serve pprof on %v: %v", addr, err)
+	}
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"expvar"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+func init() {
+	http.HandleFunc("/metrics", serveMetrics)
+}
+
+var invalidMetricChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// serveMetrics exports numeric expvar variables in the Prometheus text format. Maps are
+// flattened with their keys, such as "morlock_search_nodes" for the "nodes" key of "search".
+func serveMetrics(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	var lines []string
+	expvar.Do(func(kv expvar.KeyValue) {
+		lines = append(lines, metricLines(kv.Key, kv.Value)...)
+	})
+	sort.Strings(lines)
+
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+}
+
+func metricLines(name string, v expvar.Var) []string {
+	switch t := v.(type) {
+	case *expvar.Int:
+		return []string{fmt.Sprintf("%v %v", metricName(name), t.Value())}
+	case *expvar.Float:
+		return []string{fmt.Sprintf("%v %v", metricName(name), t.Value())}
+	case *expvar.Map:
+		var ret []string
+		t.Do(func(kv expvar.KeyValue) {
+			ret = append(ret, metricLines(name+"_"+kv.Key, kv.Value)...)
+		})
+		return ret
+	default:
+		return nil // not numeric
+	}
+}
+
+func metricName(name string) string {
+	return "morlock_" + invalidMetricChars.ReplaceAllString(strings.ToLower(name), "_")
+}
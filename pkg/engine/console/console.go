@@ -1,3 +1,4 @@
+// Package console contains a line-based debugging protocol for the engine.
 package console
 
 import (
@@ -5,40 +6,75 @@ import (
 	"fmt"
 	"github.com/herohde/morlock/pkg/board"
 	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/board/san"
 	"github.com/herohde/morlock/pkg/engine"
 	"github.com/herohde/morlock/pkg/eval"
 	"github.com/herohde/morlock/pkg/search"
+	"github.com/herohde/morlock/pkg/search/pvfmt"
 	"github.com/herohde/morlock/pkg/search/searchctl"
 	"github.com/seekerror/logw"
 	"github.com/seekerror/stdlib/pkg/lang"
 	"github.com/seekerror/stdlib/pkg/util/iox"
-	"sort"
+	"github.com/seekerror/stdlib/pkg/util/mathx"
+	"os"
 	"strconv"
 	"strings"
 	"sync/atomic"
+	"time"
 )
 
 const ProtocolName = "console"
 
+// Option is a console driver option.
+type Option func(*options)
+
+type options struct {
+	source engine.PositionSource
+}
+
+// UsePositionSource instructs the driver to use the given position source for "setup".
+func UsePositionSource(src engine.PositionSource) Option {
+	return func(opt *options) {
+		opt.source = src
+	}
+}
+
 // Driver implements a console driver for debugging.
 type Driver struct {
 	iox.AsyncCloser
 
-	e *engine.Engine
+	e   *engine.Engine
+	opt options
 
 	out chan<- string
 
-	root   search.Search
-	active atomic.Bool // user is waiting for engine to move
+	active  atomic.Bool // user is waiting for engine to move
+	verbose atomic.Bool // print time and nodes per root move
+
+	game     *game                            // play mode, if active
+	played   chan search.PV                   // completed play searches
+	comments map[board.ZobristHash]annotation // PGN comments of moves by position
+	undone   []undone                         // moves taken back, latest last
+	editor   *editor                          // position editor, if active
+	view     view                             // board rendering options
+	level    level                            // time control for play mode
 }
 
-func NewDriver(ctx context.Context, e *engine.Engine, root search.Search, in <-chan string) (*Driver, <-chan string) {
+func NewDriver(ctx context.Context, e *engine.Engine, in <-chan string, opts ...Option) (*Driver, <-chan string) {
+	var opt options
+	for _, fn := range opts {
+		fn(&opt)
+	}
+
 	out := make(chan string, 100)
 	d := &Driver{
 		AsyncCloser: iox.NewAsyncCloser(),
 		e:           e,
-		root:        root,
+		opt:         opt,
 		out:         out,
+		played:      make(chan search.PV, 10),
+		comments:    map[board.ZobristHash]annotation{},
+		level:       level{limit: DefaultPlayTime},
 	}
 	go d.process(ctx, in)
 
@@ -70,6 +106,11 @@ func (d *Driver) process(ctx context.Context, in <-chan string) {
 			cmd := parts[0]
 			args := parts[1:]
 
+			if d.editor != nil {
+				d.edit(ctx, cmd, args)
+				break
+			}
+
 			switch strings.ToLower(cmd) {
 			case "reset", "r":
 				// reset [<fenstring>] moves ...
@@ -80,10 +121,7 @@ func (d *Driver) process(ctx context.Context, in <-chan string) {
 				if len(args) > 0 && args[0] != "moves" {
 					pos = strings.Join(args[0:6], " ")
 				}
-				if err := d.e.Reset(ctx, pos); err != nil {
-					logw.Errorf(ctx, "Invalid position: %v", line)
-					return
-				}
+				var moves []board.Move
 				move := false
 				for _, arg := range args {
 					if arg == "moves" {
@@ -94,30 +132,112 @@ func (d *Driver) process(ctx context.Context, in <-chan string) {
 						continue
 					}
 
-					if err := d.e.Move(ctx, arg); err != nil {
+					m, err := board.ParseMove(arg)
+					if err != nil {
 						logw.Errorf(ctx, "Invalid position move '%v': %v: %v", arg, line, err)
 						return
 					}
+					moves = append(moves, m)
+				}
+				if err := d.e.SetGame(ctx, pos, moves); err != nil {
+					logw.Errorf(ctx, "Invalid position: %v: %v", line, err)
+					return
 				}
 				d.printBoard(ctx)
 
+			case "setup", "s":
+				// setup [<source args>]
+
+				if d.opt.source == nil {
+					d.out <- "no position source"
+					break
+				}
+
+				d.ensureInactive(ctx)
+
+				if err := engine.Setup(ctx, d.e, d.opt.source, args...); err != nil {
+					d.out <- fmt.Sprintf("setup failed: %v", err)
+					break
+				}
+				d.printBoard(ctx)
+
+			case "save": // save <file>: the current game as PGN
+				if err := d.save(args); err != nil {
+					d.out <- fmt.Sprintf("save failed: %v", err)
+				}
+
+			case "load": // load <file> [<n>]: a game from PGN
+				d.ensureInactive(ctx)
+
+				if err := d.load(ctx, args); err != nil {
+					d.out <- fmt.Sprintf("load failed: %v", err)
+					break
+				}
+				d.printBoard(ctx)
+
+			case "edit": // enter the position editor
+				d.ensureInactive(ctx)
+
+				d.editor = newEditor(d.e.Board())
+				d.out <- "edit: <piece><square> to place, x<square> to remove, clear, white|black, castle <rights>, ep <square>, print, done, cancel"
+				d.printEditor()
+
 			case "undo", "u":
 				d.ensureInactive(ctx)
 
-				_ = d.e.TakeBack(ctx)
+				_ = d.undo(ctx)
+				d.printBoard(ctx)
+
+			case "redo":
+				d.ensureInactive(ctx)
+
+				if !d.redo(ctx) {
+					d.out <- "no move to redo"
+					break
+				}
+				d.printBoard(ctx)
+
+			case "goto": // goto <ply>: 0 is the start position
+				d.ensureInactive(ctx)
+
+				if err := d.seek(ctx, args); err != nil {
+					d.out <- fmt.Sprintf("goto failed: %v", err)
+				}
+				d.printBoard(ctx)
+
+			case "first":
+				d.ensureInactive(ctx)
+
+				for d.undo(ctx) {
+				}
+				d.printBoard(ctx)
+
+			case "last":
+				d.ensureInactive(ctx)
+
+				for d.redo(ctx) {
+				}
 				d.printBoard(ctx)
 
 			case "print", "p":
 				d.printBoard(ctx)
 
 			case "analyze", "a":
+				// analyze [<depth> [<move> ...]]
+
 				d.ensureInactive(ctx)
 
-				var opt searchctl.Options
+				opt := searchctl.Options{Analysis: true}
 				if len(args) > 0 {
 					depth, _ := strconv.Atoi(args[0])
 					opt.DepthLimit = lang.Some(uint(depth))
 				}
+				moves, err := parseMoves(args[mathx.Min(len(args), 1):])
+				if err != nil {
+					d.out <- err.Error()
+					break
+				}
+				opt.SearchMoves = moves
 
 				out, err := d.e.Analyze(ctx, opt)
 				if err != nil {
@@ -130,11 +250,90 @@ func (d *Driver) process(ctx context.Context, in <-chan string) {
 					var last search.PV
 					for pv := range out {
 						last = pv
-						d.out <- pv.String()
+						d.out <- pvfmt.Summary(pv)
+						for i, alt := range pv.Alt {
+							d.out <- fmt.Sprintf(" multipv %v: score=%v pv=%v", i+2, alt.Score, board.PrintMoves(alt.Moves))
+						}
+						if d.verbose.Load() {
+							for _, rm := range pv.Root {
+								d.out <- fmt.Sprintf(" time %v", rm)
+							}
+						}
 					}
 					d.searchCompleted(ctx, last)
 				}()
 
+			case "bench": // bench [<depth>]
+				d.ensureInactive(ctx)
+
+				depth := uint(4)
+				if len(args) > 0 {
+					n, _ := strconv.Atoi(args[0])
+					depth = uint(mathx.Max(n, 1))
+				}
+
+				r, err := d.e.Bench(ctx, depth)
+				if err != nil {
+					d.out <- fmt.Sprintf("bench failed: %v", err)
+					break
+				}
+				d.out <- fmt.Sprintf("bench %v", r)
+
+			case "perft": // perft <depth> [divide]: count the leaf nodes of the move tree
+				d.ensureInactive(ctx)
+
+				depth := 1
+				if len(args) > 0 {
+					n, _ := strconv.Atoi(args[0])
+					depth = mathx.Max(n, 1)
+				}
+
+				start := time.Now()
+				list := d.e.Perft(ctx, depth)
+				duration := time.Since(start)
+
+				var nodes uint64
+				for _, dv := range list {
+					if len(args) > 1 && args[1] == "divide" {
+						d.out <- fmt.Sprintf(" %v: %v", dv.Move, dv.Nodes)
+					}
+					nodes += dv.Nodes
+				}
+				d.out <- fmt.Sprintf("perft %v: nodes=%v time=%v nps=%v", depth, nodes, duration.Round(time.Millisecond), pvfmt.NPS(nodes, duration))
+
+			case "selftest":
+				d.ensureInactive(ctx)
+
+				passed := true
+				for _, c := range d.e.SelfTest(ctx) {
+					d.out <- c.String()
+					passed = passed && c.Passed()
+				}
+				if passed {
+					d.out <- "selftest passed"
+				} else {
+					d.out <- "selftest FAILED"
+				}
+
+			case "moves": // moves [scores]: legal moves, optionally with one-ply scores
+				if err := d.moves(ctx, args); err != nil {
+					d.out <- fmt.Sprintf("moves failed: %v", err)
+				}
+
+			case "threats": // captures and checks available to the opponent
+				d.threats()
+
+			case "eval", "e": // static evaluation with a per-term breakdown, if supported
+				e, err := d.e.ExplainEvaluation(ctx)
+				if err != nil {
+					d.out <- fmt.Sprintf("eval failed: %v", err)
+					break
+				}
+				for _, t := range e.Terms {
+					d.out <- fmt.Sprintf(" %v", t)
+				}
+				d.out <- fmt.Sprintf("eval %v", e.Score)
+
 			case "depth", "d":
 				if len(args) > 0 {
 					depth, _ := strconv.Atoi(args[0])
@@ -150,18 +349,155 @@ func (d *Driver) process(ctx context.Context, in <-chan string) {
 			case "nohash":
 				d.e.SetHash(0)
 
-			case "noise": // evaluation randomness in milli-pawns
+			case "memory": // total budget in MB (zero if no budget)
+				if len(args) > 0 {
+					memory, _ := strconv.Atoi(args[0])
+					d.e.SetMemory(uint(memory))
+				}
+				d.out <- fmt.Sprintf("memory %v", d.e.Memory())
+
+			case "noise": // noise <millipawns> [uniform|gaussian] [move|game]: evaluation randomness
 				if len(args) > 0 {
 					noise, _ := strconv.Atoi(args[0])
 					d.e.SetNoise(uint(noise))
 				}
+				for _, arg := range args[1:] {
+					switch arg {
+					case "move", "game":
+						d.e.SetNoisePerGame(arg == "game")
+					default:
+						if dist, ok := eval.ParseDistribution(arg); ok {
+							d.e.SetNoiseDistribution(dist)
+						} else {
+							d.out <- fmt.Sprintf("invalid noise option: %v", arg)
+						}
+					}
+				}
 
 			case "nonoise":
 				d.e.SetNoise(0)
 
-			case "halt", "stop":
-				pv, err := d.e.Halt(ctx)
+			case "contempt": // draw penalty in millipawns for the engine (negative if seeking draws)
+				if len(args) > 0 {
+					contempt, _ := strconv.Atoi(args[0])
+					d.e.SetContempt(contempt)
+				}
+				d.out <- fmt.Sprintf("contempt %v", eval.Pawns(d.e.Options().Contempt)/1000)
+
+			case "blunder": // blunder <percent> [<margin millipawns>]: play a weaker root move at times
+				if len(args) > 0 {
+					percent, _ := strconv.Atoi(args[0])
+					margin := int(d.e.Options().BlunderMargin)
+					if len(args) > 1 {
+						margin, _ = strconv.Atoi(args[1])
+					}
+					d.e.SetBlunder(uint(mathx.Max(percent, 0)), uint(mathx.Max(margin, 0)))
+				}
+				opts := d.e.Options()
+				d.out <- fmt.Sprintf("blunder %v%% within %v", opts.Blunder, eval.Pawns(opts.BlunderMargin)/1000)
+
+			case "noblunder":
+				d.e.SetBlunder(0, d.e.Options().BlunderMargin)
+
+			case "multipv": // number of best lines to search for analysis
+				if len(args) > 0 {
+					lines, _ := strconv.Atoi(args[0])
+					d.e.SetMultiPV(uint(lines))
+				}
+
+			case "flip": // view the board from the other side
+				d.view.flip = !d.view.flip
+				d.printBoard(ctx)
+
+			case "unicode": // chess symbols for pieces
+				d.view.unicode = true
+				d.printBoard(ctx)
+
+			case "nounicode":
+				d.view.unicode = false
+				d.printBoard(ctx)
+
+			case "coords": // rank and file coordinates
+				d.view.nocoords = false
+				d.printBoard(ctx)
+
+			case "nocoords":
+				d.view.nocoords = true
+				d.printBoard(ctx)
+
+			case "color": // ANSI colors for the board
+				d.view.color = true
+				d.printBoard(ctx)
+
+			case "nocolor":
+				d.view.color = false
+				d.printBoard(ctx)
+
+			case "verbose": // print time and nodes per root move in analysis
+				d.verbose.Store(true)
+
+			case "noverbose":
+				d.verbose.Store(false)
+
+			case "reuse": // start at a deeper depth if the game followed the last search
+				d.e.SetReuse(true)
+
+			case "noreuse":
+				d.e.SetReuse(false)
+
+			case "brain": // think on the opponent's time after an engine move
+				d.e.SetBrain(true)
+
+			case "nobrain":
+				d.e.SetBrain(false)
+
+			case "params": // params [save <file> | load <file>]: tunable evaluation parameters as JSON
+				d.ensureInactive(ctx)
+
+				if err := d.params(ctx, args); err != nil {
+					d.out <- fmt.Sprintf("params failed: %v", err)
+				}
+
+			case "seed": // random seed for the next game (zero if new seed per game)
+				if len(args) > 0 {
+					seed, _ := strconv.ParseInt(args[0], 10, 64)
+					d.e.SetSeed(seed)
+				}
+				d.out <- fmt.Sprintf("seed %v", d.e.Seed())
+
+			case "play": // play [white|black [<minutes> [<increment seconds>]]]: engine plays a side
+				d.ensureInactive(ctx)
+
+				if err := d.play(ctx, args); err != nil {
+					d.out <- fmt.Sprintf("play failed: %v", err)
+				}
+
+			case "level": // level <minutes>[+<increment seconds>]: time control for play
+				l, err := parseLevel(args)
 				if err != nil {
+					d.out <- err.Error()
+					break
+				}
+				d.level = l
+				d.out <- fmt.Sprintf("level %v", l)
+
+			case "clock": // remaining time in play mode
+				if d.game == nil {
+					d.out <- "no game"
+					break
+				}
+				d.out <- d.game.clock.String()
+
+			case "selfplay": // selfplay [n] [depth] [<file>]: engine plays both sides
+				d.ensureInactive(ctx)
+
+				if err := d.selfplay(ctx, args); err != nil {
+					d.out <- fmt.Sprintf("selfplay failed: %v", err)
+				}
+
+			case "halt", "stop": // in play mode, the engine moves now
+				pv, err := d.e.Halt(ctx)
+				if err == nil {
 					d.searchCompleted(ctx, pv)
 				}
 
@@ -175,14 +511,37 @@ func (d *Driver) process(ctx context.Context, in <-chan string) {
 			default:
 				// Assume move if not a recognized command.
 
+				if d.game != nil {
+					d.playMove(ctx, cmd)
+					break
+				}
+
 				d.ensureInactive(ctx)
-				if err := d.e.Move(ctx, cmd); err != nil {
+				if result := d.e.Result(); result.IsTerminal() {
+					d.out <- fmt.Sprintf("game over: %v (reset, undo or load to continue)", result)
+					break
+				}
+				m, err := d.parseMove(cmd)
+				if err != nil {
+					d.out <- err.Error()
+					break
+				}
+				if err := d.move(ctx, m); err != nil {
 					d.out <- fmt.Sprintf("invalid move: '%v'", cmd)
-				} else {
-					d.printBoard(ctx)
+					break
+				}
+				d.printBoard(ctx)
+				if result := d.e.Result(); result.IsTerminal() {
+					d.out <- fmt.Sprintf("game over: %v", result)
 				}
 			}
 
+		case pv := <-d.played:
+			d.searched(ctx, pv)
+
+		case <-d.flagged():
+			d.lostOnTime(ctx)
+
 		case <-d.Closed():
 			d.ensureInactive(ctx)
 
@@ -192,7 +551,85 @@ func (d *Driver) process(ctx context.Context, in <-chan string) {
 	}
 }
 
+// params prints, saves or loads the tunable evaluation parameters.
+func (d *Driver) params(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		var sb strings.Builder
+		if err := d.e.ExportParameters(&sb); err != nil {
+			return err
+		}
+		d.out <- strings.TrimSpace(sb.String())
+		return nil
+	}
+	if len(args) != 2 {
+		return fmt.Errorf("usage: params [save <file> | load <file>]")
+	}
+
+	switch args[0] {
+	case "save":
+		f, err := os.Create(args[1])
+		if err != nil {
+			return err
+		}
+		if err := d.e.ExportParameters(f); err != nil {
+			_ = f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		d.out <- fmt.Sprintf("params saved to %v", args[1])
+
+	case "load":
+		f, err := os.Open(args[1])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		if err := d.e.ImportParameters(ctx, f); err != nil {
+			return err
+		}
+		d.out <- fmt.Sprintf("params loaded from %v", args[1])
+
+	default:
+		return fmt.Errorf("usage: params [save <file> | load <file>]")
+	}
+	return nil
+}
+
+// parseMove parses a move in coordinate notation, such as "g1f3", or in SAN for the current
+// position, such as "Nf3", "exd5" or "O-O".
+func (d *Driver) parseMove(str string) (board.Move, error) {
+	if m, err := board.ParseMove(str); err == nil {
+		return m, nil
+	}
+
+	b := d.e.Board()
+	return san.Parse(b.Position(), b.Turn(), str)
+}
+
+// parseMoves parses a list of moves in coordinate notation.
+func parseMoves(args []string) ([]board.Move, error) {
+	var ret []board.Move
+	for _, arg := range args {
+		m, err := board.ParseMove(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid move: '%v'", arg)
+		}
+		ret = append(ret, m)
+	}
+	return ret, nil
+}
+
+// move makes the move in the current position.
+func (d *Driver) move(ctx context.Context, m board.Move) error {
+	layout := d.e.Board().Position().CastlingLayout()
+	return d.e.Move(ctx, board.PrintUCIMove(m, layout, false))
+}
+
 func (d *Driver) ensureInactive(ctx context.Context) {
+	d.stopPlay(ctx)
 	d.active.Store(false)
 	_, _ = d.e.Halt(ctx)
 }
@@ -204,66 +641,44 @@ func (d *Driver) searchCompleted(ctx context.Context, pv search.PV) {
 		if len(pv.Moves) > 0 {
 			d.out <- fmt.Sprintf("bestmove %v", pv.Moves[0])
 		}
+		if pv.Stats.Nodes > 0 {
+			d.out <- fmt.Sprintf("stats: %v", pvfmt.Stats(pv.Stats))
+		}
 
-		// Ponder each move for score breakdown. No TT. No noise.
-
-		b := d.e.Board()
+		// Explain each move for score breakdown.
 
-		var sub []result
-		for _, move := range b.Position().LegalMoves(b.Turn()) {
-			nodes, score, moves, _ := d.root.Search(ctx, &search.Context{TT: search.NoTranspositionTable{}, Ponder: []board.Move{move}}, b, pv.Depth)
-			if len(moves) > 0 {
-				moves = moves[1:] // skip ponder move in pv breakdown
-			}
-			sub = append(sub, result{m: move, s: score, n: nodes - 1, pv: moves})
+		list, err := d.e.Explain(ctx, uint(pv.Depth))
+		if err != nil {
+			logw.Errorf(ctx, "Explain failed: %v", err)
+			return
 		}
-		sort.Sort(byScore(sub))
 
 		d.out <- fmt.Sprintf("Search, depth=%v", pv.Depth)
-		for i := 0; i < len(sub); i++ {
-			d.out <- fmt.Sprintf(" %2d. %v\t%v\t\t(%v nodes\tpv %v)", i+1, sub[i].m, sub[i].s, sub[i].n, board.PrintMoves(sub[i].pv))
+		for i, r := range list {
+			d.out <- fmt.Sprintf(" %2d. %v", i+1, r)
 		}
 	} // else: stale or duplicate result
 }
 
-const (
-	files      = "    a   b   c   d   e   f   g   h"
-	horizontal = "  ---------------------------------"
-	vertical   = " | "
-)
-
 func (d *Driver) printBoard(ctx context.Context) {
 	b := d.e.Board()
+	if d.game != nil && d.game.expects {
+		_, _ = b.PopMove() // hide the expected move while pondering
+	}
 	p := b.Position()
 
+	d.printPosition(p)
+	d.out <- fmt.Sprintf("fen:    %v", fen.Encode(p, b.Turn(), b.NoProgress(), b.FullMoves()))
+	d.out <- fmt.Sprintf("result: %v, ply: %v, hash: 0x%x", b.Result(), b.Ply(), b.Hash())
 	d.out <- ""
-	d.out <- files
-	d.out <- horizontal
-	var sb strings.Builder
-	sb.WriteString("8" + vertical)
-	for i := board.ZeroSquare; i < board.NumSquares; i++ {
-		if i != 0 && i%8 == 0 {
-			d.out <- sb.String()
-			d.out <- horizontal
-
-			sb.Reset()
-			sb.WriteString((7 - i.Rank()).String())
-			sb.WriteString(vertical)
-		}
+}
 
-		if color, piece, ok := p.Square(board.NumSquares - i - 1); ok {
-			sb.WriteString(printPiece(color, piece))
-		} else {
-			sb.WriteString(" ")
-		}
-		sb.WriteString(vertical)
-	}
-	d.out <- sb.String()
-	d.out <- horizontal
-	d.out <- files
+// printPosition prints the pieces of the position as a diagram.
+func (d *Driver) printPosition(p *board.Position) {
 	d.out <- ""
-	d.out <- fmt.Sprintf("fen:    %v", d.e.Position())
-	d.out <- fmt.Sprintf("result: %v, ply: %v, hash: 0x%x", b.Result(), b.Ply(), b.Hash())
+	for _, line := range d.view.render(p) {
+		d.out <- line
+	}
 	d.out <- ""
 }
 
@@ -273,25 +688,3 @@ func printPiece(c board.Color, p board.Piece) string {
 	}
 	return strings.ToLower(p.String())
 }
-
-type result struct {
-	m  board.Move
-	s  eval.Score
-	n  uint64
-	pv []board.Move
-}
-
-// byScore is a sort order by score.
-type byScore []result
-
-func (b byScore) Len() int {
-	return len(b)
-}
-
-func (b byScore) Less(i, j int) bool {
-	return b[j].s.Less(b[i].s)
-}
-
-func (b byScore) Swap(i, j int) {
-	b[i], b[j] = b[j], b[i]
-}
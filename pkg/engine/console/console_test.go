@@ -0,0 +1,18 @@
+package console_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAnalyzeInvalidMove(t *testing.T) {
+	_, in, out := newDriver(t)
+
+	in <- "analyze 2 e2e4 x9"
+	assert.Equal(t, "invalid move: 'x9'", expect(t, out, "invalid"))
+
+	// No search is started: the first best move is from the following analysis.
+
+	in <- "analyze 1 d2d4"
+	assert.Contains(t, expect(t, out, "bestmove"), "d2-d4")
+}
@@ -0,0 +1,198 @@
+package console
+
+import (
+	"context"
+	"fmt"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"strings"
+	"unicode"
+)
+
+// editor holds the state of the position editor, where pieces are placed and removed and
+// the side to move, castling rights and en passant square are set interactively. Only
+// accessed by the driver process.
+type editor struct {
+	pieces   map[board.Square]board.Placement
+	turn     board.Color
+	castling string // in FEN, such as "KQkq" or "-"
+	ep       string // in FEN, such as "e3" or "-"
+}
+
+// newEditor returns an editor for the position of the board.
+func newEditor(b *board.Board) *editor {
+	pos := b.Position()
+	parts := strings.Split(fen.Encode(pos, b.Turn(), 0, 1), " ")
+
+	ret := &editor{pieces: map[board.Square]board.Placement{}, turn: b.Turn(), castling: parts[2], ep: parts[3]}
+	for sq := board.ZeroSquare; sq < board.NumSquares; sq++ {
+		if c, p, ok := pos.Square(sq); ok {
+			ret.pieces[sq] = board.Placement{Square: sq, Color: c, Piece: p}
+		}
+	}
+	return ret
+}
+
+// Position returns the edited pieces without castling rights or en passant.
+func (e *editor) Position() *board.Position {
+	var list []board.Placement
+	for _, p := range e.pieces {
+		list = append(list, p)
+	}
+	pos, _ := board.NewPosition(list, board.NoCastlingRights, board.ZeroSquare)
+	return pos
+}
+
+// FEN returns the edited position in FEN. It may not be valid.
+func (e *editor) FEN() string {
+	parts := strings.Split(fen.Encode(e.Position(), e.turn, 0, 1), " ")
+	parts[2], parts[3] = e.castling, e.ep
+	return strings.Join(parts, " ")
+}
+
+// Validate returns the edited position in FEN, if valid. Each side must have a single King,
+// pawns cannot be on the first or last rank and the side not to move cannot be in check.
+// Castling rights require the King and Rook on their home squares and the en passant square
+// requires a pawn that just jumped past it.
+func (e *editor) Validate() (string, error) {
+	str := e.FEN()
+	pos, turn, _, _, err := fen.Decode(str)
+	if err != nil {
+		return "", err
+	}
+
+	for _, c := range []board.Color{board.White, board.Black} {
+		if n := pos.Piece(c, board.King).PopCount(); n != 1 {
+			return "", fmt.Errorf("%v has %v kings", printColor(c), n)
+		}
+	}
+	if pawns := pos.Piece(board.White, board.Pawn) | pos.Piece(board.Black, board.Pawn); pawns&(board.BitRank(board.Rank1)|board.BitRank(board.Rank8)) != 0 {
+		return "", fmt.Errorf("pawn on first or last rank")
+	}
+	if pos.IsChecked(turn.Opponent()) {
+		return "", fmt.Errorf("%v is in check, but not to move", printColor(turn.Opponent()))
+	}
+	layout := pos.CastlingLayout()
+	for _, c := range []board.Color{board.White, board.Black} {
+		rank := board.RelativeRank(c, board.Rank1)
+		for _, t := range []board.MoveType{board.KingSideCastle, board.QueenSideCastle} {
+			if !pos.Castling().IsAllowed(board.CastlingRight(c, t)) {
+				continue
+			}
+			king := pos.Piece(c, board.King).IsSet(board.NewSquare(layout.King, rank))
+			rook := pos.Piece(c, board.Rook).IsSet(board.NewSquare(layout.Rook(t), rank))
+			if !king || !rook {
+				return "", fmt.Errorf("invalid castling rights: %v", pos.Castling())
+			}
+		}
+	}
+	if ep, ok := pos.EnPassant(); ok {
+		rank, pawn := board.Rank6, board.NewSquare(ep.File(), board.Rank5)
+		if turn == board.Black {
+			rank, pawn = board.Rank3, board.NewSquare(ep.File(), board.Rank4)
+		}
+		if ep.Rank() != rank || !pos.Piece(turn.Opponent(), board.Pawn).IsSet(pawn) || !pos.IsEmpty(ep) {
+			return "", fmt.Errorf("invalid en passant square: %v", ep)
+		}
+	}
+	return str, nil
+}
+
+// edit processes a command in the position editor.
+func (d *Driver) edit(ctx context.Context, cmd string, args []string) {
+	e := d.editor
+
+	switch strings.ToLower(cmd) {
+	case "clear":
+		e.pieces = map[board.Square]board.Placement{}
+		e.castling, e.ep = "-", "-"
+
+	case "white", "w":
+		e.turn = board.White
+
+	case "black", "b":
+		e.turn = board.Black
+
+	case "castle": // castle <rights>, such as KQkq or -
+		if len(args) != 1 {
+			d.out <- "usage: castle <rights>"
+			return
+		}
+		e.castling = args[0]
+
+	case "ep": // ep <square> or -
+		if len(args) != 1 {
+			d.out <- "usage: ep <square>"
+			return
+		}
+		e.ep = args[0]
+
+	case "print", "p":
+		// print below
+
+	case "done":
+		str, err := e.Validate()
+		if err != nil {
+			d.out <- fmt.Sprintf("invalid position: %v", err)
+			return
+		}
+		if err := d.e.Reset(ctx, str); err != nil {
+			d.out <- fmt.Sprintf("invalid position: %v", err)
+			return
+		}
+		d.editor = nil
+		d.printBoard(ctx)
+		return
+
+	case "cancel":
+		d.editor = nil
+		d.out <- "edit cancelled"
+		return
+
+	case "":
+		return
+
+	default:
+		if err := e.Place(cmd); err != nil {
+			d.out <- err.Error()
+			return
+		}
+	}
+	d.printEditor()
+}
+
+// Place places a piece, such as "Ke1" or "pd7", or removes a piece, such as "xd7". The case
+// of the piece is the color, as in FEN.
+func (e *editor) Place(str string) error {
+	runes := []rune(str)
+	if len(runes) != 3 {
+		return fmt.Errorf("invalid placement: '%v'", str)
+	}
+	sq, err := board.ParseSquare(runes[1], runes[2])
+	if err != nil {
+		return fmt.Errorf("invalid square: '%v'", str)
+	}
+
+	if runes[0] == 'x' {
+		delete(e.pieces, sq)
+		return nil
+	}
+
+	piece, ok := board.ParsePiece(runes[0])
+	if !ok {
+		return fmt.Errorf("invalid piece: '%v'", str)
+	}
+	color := board.Black
+	if unicode.IsUpper(runes[0]) {
+		color = board.White
+	}
+	e.pieces[sq] = board.Placement{Square: sq, Color: color, Piece: piece}
+	return nil
+}
+
+// printEditor prints the edited position.
+func (d *Driver) printEditor() {
+	d.printPosition(d.editor.Position())
+	d.out <- fmt.Sprintf("edit:   %v", d.editor.FEN())
+	d.out <- ""
+}
@@ -0,0 +1,47 @@
+package console_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEdit(t *testing.T) {
+	e, in, out := newDriver(t)
+
+	in <- "edit"
+	expect(t, out, "edit:   rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
+
+	in <- "clear"
+	in <- "Ke1"
+	in <- "done"
+	assert.Equal(t, "invalid position: black has 0 kings", expect(t, out, "invalid"))
+
+	in <- "ke8"
+	in <- "Pa8"
+	in <- "done"
+	assert.Equal(t, "invalid position: pawn on first or last rank", expect(t, out, "invalid"))
+
+	in <- "xa8"
+	in <- "Qe7"
+	in <- "done"
+	assert.Equal(t, "invalid position: black is in check, but not to move", expect(t, out, "invalid"))
+
+	in <- "b"
+	in <- "xe7"
+	in <- "Pe4"
+	in <- "ep e3"
+	in <- "castle K"
+	in <- "done"
+	expect(t, out, "invalid position")
+
+	in <- "castle -"
+	in <- "done"
+	expect(t, out, "fen:")
+	assert.Equal(t, "4k3/8/8/8/4P3/8/8/4K3 b - e3 0 1", e.Position())
+
+	in <- "edit"
+	in <- "xe4"
+	in <- "cancel"
+	expect(t, out, "edit cancelled")
+	assert.Equal(t, "4k3/8/8/8/4P3/8/8/4K3 b - e3 0 1", e.Position())
+}
@@ -0,0 +1,73 @@
+package console
+
+import (
+	"context"
+	"fmt"
+	"github.com/herohde/morlock/pkg/board"
+	"strconv"
+)
+
+// undone is a move taken back, for redo. The hash identifies the position it was played from,
+// so that redo is only possible if the game has not changed since.
+type undone struct {
+	hash board.ZobristHash
+	move board.Move
+}
+
+// undo takes back the latest move. Returns false if none.
+func (d *Driver) undo(ctx context.Context) bool {
+	m, ok := d.e.Board().LastMove()
+	if !ok || d.e.TakeBack(ctx) != nil {
+		return false
+	}
+	d.undone = append(d.undone, undone{hash: d.e.Board().Hash(), move: m})
+	return true
+}
+
+// redo replays the latest move taken back. Returns false if none or if the game changed.
+func (d *Driver) redo(ctx context.Context) bool {
+	n := len(d.undone)
+	if n == 0 {
+		return false
+	}
+	u := d.undone[n-1]
+	if u.hash != d.e.Board().Hash() || d.move(ctx, u.move) != nil {
+		d.undone = nil // game changed since the undo
+		return false
+	}
+	d.undone = d.undone[:n-1]
+	return true
+}
+
+// seek moves back or forward in the game history to the given ply, where 0 is the start
+// position: goto <ply>. Forward moves are limited to the moves taken back.
+func (d *Driver) seek(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: goto <ply>")
+	}
+	ply, err := strconv.Atoi(args[0])
+	if err != nil || ply < 0 {
+		return fmt.Errorf("invalid ply: '%v'", args[0])
+	}
+
+	current := plies(d.e.Board())
+	for ; current > ply && d.undo(ctx); current-- {
+	}
+	for ; current < ply && d.redo(ctx); current++ {
+	}
+	if current != ply {
+		return fmt.Errorf("ply %v not available: at ply %v", ply, current)
+	}
+	return nil
+}
+
+// plies returns the number of moves played from the start position.
+func plies(b *board.Board) int {
+	n := 0
+	for {
+		if _, ok := b.PopMove(); !ok {
+			return n
+		}
+		n++
+	}
+}
@@ -0,0 +1,63 @@
+package console_test
+
+import (
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	_, in, out := newDriver(t)
+
+	// sync returns the current position once all prior commands are processed. The invalid
+	// goto marks the end of the output.
+	sync := func() string {
+		in <- "print"
+		in <- "goto -"
+
+		var ret string
+		for {
+			line := expect(t, out, "")
+			if strings.HasPrefix(line, "fen:") {
+				ret = line
+			}
+			if strings.HasPrefix(line, "goto failed: invalid ply") {
+				return ret
+			}
+		}
+	}
+
+	in <- "e4"
+	in <- "e5"
+	in <- "Nf3"
+	last := sync()
+
+	in <- "undo"
+	in <- "undo"
+	in <- "redo"
+	assert.Contains(t, sync(), "rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/RNBQKBNR w")
+
+	in <- "first"
+	assert.Contains(t, sync(), fen.Initial)
+
+	in <- "goto 1"
+	assert.Contains(t, sync(), "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b")
+
+	in <- "last"
+	assert.Equal(t, last, sync())
+
+	in <- "goto 4"
+	expect(t, out, "goto failed: ply 4 not available: at ply 3")
+	sync()
+
+	// A new move invalidates redo.
+
+	in <- "undo"
+	in <- "d4"
+	in <- "undo"
+	in <- "redo"
+	assert.Contains(t, sync(), "PPP2PPP")
+	in <- "redo"
+	expect(t, out, "no move to redo")
+}
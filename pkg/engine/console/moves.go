@@ -0,0 +1,63 @@
+package console
+
+import (
+	"context"
+	"fmt"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/san"
+	"strings"
+)
+
+// moves lists the legal moves in SAN: moves [scores]. With scores, each move is searched to
+// one ply and listed with its score for the side to move, best first.
+func (d *Driver) moves(ctx context.Context, args []string) error {
+	b := d.e.Board()
+	pos, turn := b.Position(), b.Turn()
+
+	if len(args) == 0 {
+		moves := pos.LegalMoves(turn)
+		var list []string
+		for _, m := range moves {
+			list = append(list, san.Print(pos, turn, m))
+		}
+		d.out <- fmt.Sprintf("moves (%v): %v", len(moves), strings.Join(list, " "))
+		return nil
+	}
+	if args[0] != "scores" {
+		return fmt.Errorf("usage: moves [scores]")
+	}
+
+	list, err := d.e.Explain(ctx, 1)
+	if err != nil {
+		return err
+	}
+	for _, r := range list {
+		d.out <- fmt.Sprintf(" %-8v %v", san.Print(pos, turn, r.Move), r.Score)
+	}
+	d.out <- fmt.Sprintf("moves (%v)", len(list))
+	return nil
+}
+
+// threats lists the captures and checks available to the opponent, if it were to move.
+func (d *Driver) threats() {
+	b := d.e.Board()
+	pos, opp := b.Position(), b.Turn().Opponent()
+
+	var captures, checks []string
+	for _, m := range pos.LegalMoves(opp) {
+		if m.Type == board.EnPassant || m.Capture == board.King {
+			continue // en passant is only available to the side to move
+		}
+		if m.IsCapture() {
+			captures = append(captures, san.Print(pos, opp, m))
+		} else if pos.GivesCheck(m) {
+			checks = append(checks, san.Print(pos, opp, m))
+		}
+	}
+
+	if pos.IsChecked(b.Turn()) {
+		d.out <- "threats: in check"
+	}
+	d.out <- fmt.Sprintf("captures (%v): %v", len(captures), strings.Join(captures, " "))
+	d.out <- fmt.Sprintf("checks (%v): %v", len(checks), strings.Join(checks, " "))
+}
@@ -0,0 +1,21 @@
+package console_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMovesThreats(t *testing.T) {
+	_, in, out := newDriver(t)
+
+	in <- "moves"
+	assert.Contains(t, expect(t, out, "moves"), "moves (20): Nh3 Nf3")
+
+	in <- "reset r1bqkbnr/pppp1ppp/2n5/4p3/2B1P3/5Q2/PPPP1PPP/RNB1K1NR b KQkq - 3 3"
+	in <- "moves scores"
+	assert.Equal(t, "moves (31)", expect(t, out, "moves"))
+
+	in <- "threats"
+	assert.Equal(t, "captures (2): Qxf7# Bxf7+", expect(t, out, "captures"))
+	assert.Equal(t, "checks (0): ", expect(t, out, "checks"))
+}
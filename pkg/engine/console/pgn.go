@@ -0,0 +1,154 @@
+package console
+
+import (
+	"context"
+	"fmt"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/board/pgn"
+	"github.com/herohde/morlock/pkg/search"
+	"os"
+	"strconv"
+	"time"
+)
+
+// annotation is a PGN comment for a move in a position, such as the engine evaluation.
+type annotation struct {
+	move    board.Move
+	comment string
+}
+
+// annotate records the engine evaluation of the search as the comment of its best move in
+// the current position, such as "{0.35/12 1.2s}". The score is for the side to move.
+func (d *Driver) annotate(pv search.PV) {
+	if len(pv.Moves) == 0 {
+		return
+	}
+	comment := fmt.Sprintf("{%v/%v %.1fs}", pv.Score, pv.Depth, pv.Time.Seconds())
+	d.comments[d.e.Board().Hash()] = annotation{move: pv.Moves[0], comment: comment}
+}
+
+// save writes the current game to the given file in PGN with the engine evaluations of its
+// moves, if any, as comments: save <file>.
+func (d *Driver) save(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: save <file>")
+	}
+
+	white, black := "?", "?"
+	if d.game != nil {
+		white, black = "User", "User"
+		if d.game.side == board.White {
+			white = d.e.Name()
+		} else {
+			black = d.e.Name()
+		}
+	}
+	result := d.e.Result()
+
+	g := pgn.Game{
+		Tags: []pgn.Tag{
+			{Name: "Event", Value: "Console game"},
+			{Name: "Site", Value: "?"},
+			{Name: "Date", Value: time.Now().Format("2006.01.02")},
+			{Name: "Round", Value: "-"},
+			{Name: "White", Value: white},
+			{Name: "Black", Value: black},
+		},
+		Comments: map[int]string{},
+		Result:   "*",
+	}
+	if result.IsTerminal() {
+		g.Result = result.Outcome.String()
+	}
+	g.Tags = append(g.Tags, pgn.Tag{Name: "Result", Value: g.Result})
+
+	// Unwind the game to the start position to recover the moves and their comments.
+
+	b := d.e.Board()
+	var moves []board.Move
+	var comments []string
+	for {
+		m, ok := b.PopMove()
+		if !ok {
+			break
+		}
+		moves = append([]board.Move{m}, moves...)
+		comment := ""
+		if a, ok := d.comments[b.Hash()]; ok && a.move.Equals(m) {
+			comment = a.comment
+		}
+		comments = append([]string{comment}, comments...)
+	}
+	g.Moves = moves
+	for i, c := range comments {
+		if c != "" {
+			g.Comments[i] = c
+		}
+	}
+
+	if start := fen.Encode(b.Position(), b.Turn(), b.NoProgress(), b.FullMoves()); start != fen.Initial {
+		g.Tags = append(g.Tags, pgn.Tag{Name: "SetUp", Value: "1"}, pgn.Tag{Name: "FEN", Value: start})
+	}
+
+	str, err := pgn.Encode(g)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(args[0], []byte(str), 0644); err != nil {
+		return err
+	}
+	d.out <- fmt.Sprintf("game saved to %v", args[0])
+	return nil
+}
+
+// load restores a game from the given PGN file, by default the first: load <file> [<n>].
+// Comments are kept as annotations, so that a saved game round-trips.
+func (d *Driver) load(ctx context.Context, args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return fmt.Errorf("usage: load <file> [<n>]")
+	}
+
+	n := 1
+	if len(args) > 1 {
+		i, err := strconv.Atoi(args[1])
+		if err != nil || i < 1 {
+			return fmt.Errorf("invalid game number: '%v'", args[1])
+		}
+		n = i
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	games, err := pgn.Read(f)
+	if err != nil {
+		return err
+	}
+	if n > len(games) {
+		return fmt.Errorf("game %v not found: %v has %v games", n, args[0], len(games))
+	}
+	g := games[n-1]
+
+	b, err := g.Board()
+	if err != nil {
+		return err
+	}
+	comments := map[board.ZobristHash]annotation{}
+	for i, m := range g.Moves {
+		if c, ok := g.Comments[i]; ok {
+			comments[b.Hash()] = annotation{move: m, comment: c}
+		}
+		b.PushMove(m)
+	}
+
+	if err := d.e.SetGame(ctx, g.Start(), g.Moves); err != nil {
+		return err
+	}
+	d.comments = comments
+	d.out <- fmt.Sprintf("game loaded: %v", g)
+	return nil
+}
@@ -0,0 +1,54 @@
+package console_test
+
+import (
+	"github.com/herohde/morlock/pkg/engine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestSaveLoad(t *testing.T) {
+	e, in, out := newDriver(t, engine.WithOptions(engine.Options{Depth: 1}))
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "game.pgn")
+
+	// (1) Moves by the engine are saved with the search score and depth as comments.
+
+	in <- "e4"
+	in <- "e7e5"
+	in <- "play black"
+	expect(t, out, "play black")
+	in <- "Nf3"
+	expect(t, out, "bestmove")
+	in <- "save " + filename
+	expect(t, out, "game saved")
+
+	data, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	movetext := regexp.MustCompile(`1\. e4 e5 2\. Nf3 \S+ \{\S+/1 \d+\.\ds\} \*`)
+	saved := movetext.FindString(string(data))
+	require.NotEmpty(t, saved, string(data))
+
+	// (2) Loading restores the game and its comments, so that the moves round-trip. The tags
+	// of the players are not kept outside play mode.
+
+	position := e.Position()
+	in <- "reset"
+	in <- "load " + filename
+	expect(t, out, "game loaded")
+	assert.Equal(t, position, e.Position())
+
+	in <- "save " + filepath.Join(dir, "copy.pgn")
+	expect(t, out, "game saved")
+
+	copied, err := os.ReadFile(filepath.Join(dir, "copy.pgn"))
+	require.NoError(t, err)
+	assert.Equal(t, saved, movetext.FindString(string(copied)))
+
+	in <- "load " + filename + " 2"
+	expect(t, out, "load failed")
+}
@@ -0,0 +1,365 @@
+package console
+
+import (
+	"context"
+	"fmt"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/herohde/morlock/pkg/search/pvfmt"
+	"github.com/herohde/morlock/pkg/search/searchctl"
+	"github.com/seekerror/logw"
+	"github.com/seekerror/stdlib/pkg/lang"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultPlayTime is the default clock time per side in play mode.
+const DefaultPlayTime = 5 * time.Minute
+
+// game holds the state of play mode, where the engine plays one side against the user with
+// a clock. The engine ponders on the user's time on the expected reply, if known and
+// supported. Only accessed by the driver process.
+type game struct {
+	side  board.Color // engine side
+	clock clock
+
+	id      search.ID  // active search, if thinking or pondering
+	ponder  board.Move // expected user move, if pondering
+	expects bool       // true iff pondering on an expected user move
+
+	flag *time.Timer // fires when the user runs out of time, if the user is to move
+}
+
+// level is the time control for play mode, such as 5 minutes with a 3 second increment.
+type level struct {
+	limit, increment time.Duration
+}
+
+func (l level) String() string {
+	return fmt.Sprintf("%v+%v", l.limit.Minutes(), l.increment.Seconds())
+}
+
+// parseLevel parses a time control in minutes and increment seconds, such as "5+3" or "5 3".
+func parseLevel(args []string) (level, error) {
+	if len(args) == 1 {
+		args = strings.SplitN(args[0], "+", 2)
+	}
+	if len(args) < 1 || len(args) > 2 {
+		return level{}, fmt.Errorf("usage: level <minutes>[+<increment seconds>]")
+	}
+
+	minutes, err := strconv.ParseFloat(args[0], 64)
+	if err != nil || minutes <= 0 {
+		return level{}, fmt.Errorf("invalid minutes: '%v'", args[0])
+	}
+	ret := level{limit: time.Duration(minutes * float64(time.Minute))}
+	if len(args) > 1 {
+		seconds, err := strconv.ParseFloat(args[1], 64)
+		if err != nil || seconds < 0 {
+			return level{}, fmt.Errorf("invalid increment: '%v'", args[1])
+		}
+		ret.increment = time.Duration(seconds * float64(time.Second))
+	}
+	return ret, nil
+}
+
+// clock is a chess clock with an optional increment per move.
+type clock struct {
+	remaining [board.NumColors]time.Duration
+	increment time.Duration
+
+	turn  board.Color
+	start time.Time
+}
+
+func newClock(limit, increment time.Duration, turn board.Color) clock {
+	return clock{
+		remaining: [board.NumColors]time.Duration{limit, limit},
+		increment: increment,
+		turn:      turn,
+		start:     time.Now(),
+	}
+}
+
+// Remaining returns the remaining time of the side to move.
+func (c *clock) Remaining() time.Duration {
+	return c.remaining[c.turn] - time.Since(c.start)
+}
+
+// Press stops the clock of the side to move, adds any increment and starts the clock of the
+// opponent. Returns false if the side ran out of time.
+func (c *clock) Press() bool {
+	c.remaining[c.turn] -= time.Since(c.start)
+	ok := c.remaining[c.turn] > 0
+	c.remaining[c.turn] += c.increment
+
+	c.turn = c.turn.Opponent()
+	c.start = time.Now()
+	return ok
+}
+
+// TimeControl returns the remaining time as a time control for search.
+func (c *clock) TimeControl() searchctl.TimeControl {
+	remaining := c.remaining
+	remaining[c.turn] -= time.Since(c.start)
+	return searchctl.TimeControl{
+		White:    remaining[board.White],
+		Black:    remaining[board.Black],
+		WhiteInc: c.increment,
+		BlackInc: c.increment,
+	}
+}
+
+func (c *clock) String() string {
+	tc := c.TimeControl()
+	return fmt.Sprintf("clock white %v black %v", tc.White.Round(100*time.Millisecond), tc.Black.Round(100*time.Millisecond))
+}
+
+// play starts play mode: play [white|black [<minutes> [<increment seconds>]]]. The engine plays
+// the side to move by default. The time control is the level by default.
+func (d *Driver) play(ctx context.Context, args []string) error {
+	side := d.e.Board().Turn()
+	limit, increment := d.level.limit, d.level.increment
+
+	if len(args) > 0 {
+		switch strings.ToLower(args[0]) {
+		case "white", "w":
+			side = board.White
+		case "black", "b":
+			side = board.Black
+		default:
+			return fmt.Errorf("usage: play [white|black [<minutes> [<increment seconds>]]]")
+		}
+	}
+	if len(args) > 1 {
+		minutes, err := strconv.ParseFloat(args[1], 64)
+		if err != nil || minutes <= 0 {
+			return fmt.Errorf("invalid minutes: '%v'", args[1])
+		}
+		limit = time.Duration(minutes * float64(time.Minute))
+	}
+	if len(args) > 2 {
+		seconds, err := strconv.ParseFloat(args[2], 64)
+		if err != nil || seconds < 0 {
+			return fmt.Errorf("invalid increment: '%v'", args[2])
+		}
+		increment = time.Duration(seconds * float64(time.Second))
+	}
+
+	b := d.e.Board()
+	d.game = &game{side: side, clock: newClock(limit, increment, b.Turn())}
+	d.out <- fmt.Sprintf("play %v", printColor(side))
+
+	if d.gameOver(ctx) {
+		return nil
+	}
+	if b.Turn() == side {
+		d.think(ctx)
+	} else {
+		d.startFlag()
+	}
+	return nil
+}
+
+// startFlag starts the flag timer for the user to move.
+func (d *Driver) startFlag() {
+	d.stopFlag()
+	d.game.flag = time.NewTimer(d.game.clock.Remaining())
+}
+
+// stopFlag stops the flag timer, if running.
+func (d *Driver) stopFlag() {
+	if d.game != nil && d.game.flag != nil {
+		d.game.flag.Stop()
+		d.game.flag = nil
+	}
+}
+
+// flagged returns a channel that fires when the user runs out of time in play mode. Nil if
+// not active.
+func (d *Driver) flagged() <-chan time.Time {
+	if d.game == nil || d.game.flag == nil {
+		return nil
+	}
+	return d.game.flag.C
+}
+
+// lostOnTime ends play mode, because the user ran out of time while to move.
+func (d *Driver) lostOnTime(ctx context.Context) {
+	g := d.game
+	g.flag = nil
+	if g.expects {
+		_, _ = d.e.Halt(ctx)
+		_ = d.e.TakeBack(ctx)
+	}
+	d.out <- fmt.Sprintf("%v lost on time", printColor(g.side.Opponent()))
+	d.game = nil
+}
+
+// playMove makes a user move in play mode. If the engine pondered on the move, the search
+// resumes with a warm transposition table and, if reuse is enabled, at the pondered depth.
+func (d *Driver) playMove(ctx context.Context, move string) {
+	g := d.game
+	if d.e.Board().Turn() == g.side && !g.expects {
+		d.out <- "engine to move"
+		return
+	}
+
+	m, err := d.parseMove(move)
+	if err != nil {
+		d.out <- err.Error()
+		return
+	}
+
+	if g.expects {
+		pv, _ := d.e.Halt(ctx)
+		g.id, g.expects = 0, false
+
+		if g.ponder.Equals(m) {
+			d.out <- fmt.Sprintf("ponderhit %v, depth=%v", g.ponder, pv.Depth)
+			d.pressClock(ctx)
+			return
+		}
+		d.out <- fmt.Sprintf("pondermiss %v, depth=%v", g.ponder, pv.Depth)
+		_ = d.e.TakeBack(ctx)
+	}
+
+	if err := d.move(ctx, m); err != nil {
+		d.out <- fmt.Sprintf("invalid move: '%v'", move)
+		return
+	}
+	d.pressClock(ctx)
+}
+
+// pressClock ends the turn of the user after a move and lets the engine think, unless the
+// game is over.
+func (d *Driver) pressClock(ctx context.Context) {
+	d.stopFlag()
+	if !d.game.clock.Press() {
+		d.out <- fmt.Sprintf("%v lost on time", printColor(d.game.side.Opponent()))
+		d.game = nil
+		return
+	}
+	d.printBoard(ctx)
+	if !d.gameOver(ctx) {
+		d.think(ctx)
+	}
+}
+
+// think starts the engine search for its move under the clock.
+func (d *Driver) think(ctx context.Context) {
+	opt := searchctl.Options{ID: search.NewID(), TimeControl: lang.Some(d.game.clock.TimeControl())}
+
+	out, err := d.e.Analyze(ctx, opt)
+	if err != nil {
+		logw.Errorf(ctx, "Play search failed: %v", err)
+		d.game = nil
+		return
+	}
+	d.game.id = opt.ID
+
+	go func() {
+		var last search.PV
+		for pv := range out {
+			last = pv
+		}
+		last.ID = opt.ID
+		d.played <- last
+	}()
+}
+
+// searched makes the engine move in play mode once the search has completed and starts
+// pondering on the expected reply, if any.
+func (d *Driver) searched(ctx context.Context, pv search.PV) {
+	g := d.game
+	if g == nil || g.expects || g.id != pv.ID {
+		return // stale search
+	}
+	g.id = 0
+
+	if len(pv.Moves) == 0 {
+		d.out <- "no move"
+		d.game = nil
+		return
+	}
+
+	d.out <- pvfmt.Summary(pv)
+	d.out <- fmt.Sprintf("bestmove %v", pv.Moves[0])
+	d.annotate(pv)
+	if err := d.move(ctx, pv.Moves[0]); err != nil {
+		logw.Errorf(ctx, "Play move %v failed: %v", pv.Moves[0], err)
+		d.game = nil
+		return
+	}
+	if !g.clock.Press() {
+		d.out <- fmt.Sprintf("%v lost on time", printColor(g.side))
+		d.game = nil
+		return
+	}
+	d.printBoard(ctx)
+	if d.gameOver(ctx) {
+		return
+	}
+
+	d.startFlag()
+	if len(pv.Moves) > 1 && !d.e.Capabilities().NoPonder {
+		d.ponder(ctx, pv.Moves[1])
+	}
+}
+
+// ponder searches the position after the expected user move on the user's time.
+func (d *Driver) ponder(ctx context.Context, m board.Move) {
+	if err := d.move(ctx, m); err != nil {
+		return // not legal: do not ponder
+	}
+
+	opt := searchctl.Options{ID: search.NewID()}
+	out, err := d.e.Analyze(ctx, opt)
+	if err != nil {
+		_ = d.e.TakeBack(ctx)
+		return
+	}
+	d.game.id, d.game.ponder, d.game.expects = opt.ID, m, true
+	d.out <- fmt.Sprintf("ponder %v", m)
+
+	go func() {
+		for range out {
+			// ignore: wait for the user move
+		}
+	}()
+}
+
+// gameOver returns true and ends play mode if the game is over.
+func (d *Driver) gameOver(ctx context.Context) bool {
+	result := d.e.Result()
+	if !result.IsTerminal() {
+		d.out <- d.game.clock.String()
+		return false
+	}
+
+	d.out <- fmt.Sprintf("game over: %v", result)
+	d.game = nil
+	return true
+}
+
+// stopPlay ends play mode, if active. The engine then no longer moves on its own.
+func (d *Driver) stopPlay(ctx context.Context) {
+	if d.game == nil {
+		return
+	}
+	if d.game.expects {
+		_, _ = d.e.Halt(ctx)
+		_ = d.e.TakeBack(ctx)
+	}
+	d.stopFlag()
+	d.game = nil
+	d.out <- "play stopped"
+}
+
+func printColor(c board.Color) string {
+	if c == board.White {
+		return "white"
+	}
+	return "black"
+}
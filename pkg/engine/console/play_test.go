@@ -0,0 +1,104 @@
+package console_test
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/engine"
+	"github.com/herohde/morlock/pkg/engine/console"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPlay(t *testing.T) {
+	_, in, out := newDriver(t, engine.WithOptions(engine.Options{Depth: 2}))
+
+	in <- "play black 1"
+	expect(t, out, "play black")
+
+	// (1) Engine replies and ponders on the expected user move.
+
+	in <- "e2e4"
+	expect(t, out, "bestmove")
+	ponder := strings.TrimPrefix(expect(t, out, "ponder "), "ponder ")
+
+	// (2) User plays the expected move: ponderhit.
+
+	m := regexp.MustCompile(`([a-h][1-8])[-*]([a-h][1-8])`).FindStringSubmatch(ponder)
+	require.Len(t, m, 3, ponder)
+	in <- m[1] + m[2]
+	expect(t, out, "ponderhit")
+	expect(t, out, "bestmove")
+	expect(t, out, "ponder ")
+
+	// (3) User plays another move: pondermiss.
+
+	in <- "a2a3"
+	line := expect(t, out, "ponder")
+	if !strings.HasPrefix(line, "pondermiss") {
+		assert.True(t, strings.HasPrefix(line, "ponderhit"), line)
+	}
+	expect(t, out, "bestmove")
+
+	// (4) Any other command ends play mode.
+
+	in <- "reset"
+	expect(t, out, "play stopped")
+}
+
+// newDriver returns a console driver for a material alpha-beta engine with the given options
+// and its input and output channels. The driver is closed when the test completes.
+func newDriver(t *testing.T, opts ...engine.Option) (*engine.Engine, chan<- string, <-chan string) {
+	ctx := context.Background()
+
+	root := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+	e := engine.New(ctx, "test", "test", root, opts...)
+
+	in := make(chan string, 20)
+	d, out := console.NewDriver(ctx, e, in)
+	t.Cleanup(d.Close)
+
+	return e, in, out
+}
+
+// expect returns the next line with the given prefix.
+func expect(t *testing.T, out <-chan string, prefix string) string {
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case line, ok := <-out:
+			require.True(t, ok, "closed waiting for '%v'", prefix)
+			if strings.HasPrefix(line, prefix) {
+				return line
+			}
+		case <-timeout:
+			require.Fail(t, "timeout", "waiting for '%v'", prefix)
+		}
+	}
+}
+
+func TestPlayLevel(t *testing.T) {
+	_, in, out := newDriver(t, engine.WithOptions(engine.Options{Depth: 2}))
+
+	in <- "level 5+x"
+	expect(t, out, "invalid increment")
+	in <- "clock"
+	expect(t, out, "no game")
+
+	// User to move with a short time control and flags without moving.
+
+	in <- "level 0.005+1"
+	assert.Equal(t, "level 0.005+1", expect(t, out, "level"))
+	in <- "play black"
+	expect(t, out, "play black")
+	in <- "clock"
+	expect(t, out, "clock white")
+	expect(t, out, "white lost on time")
+
+	in <- "clock"
+	expect(t, out, "no game")
+}
@@ -0,0 +1,121 @@
+package console
+
+import (
+	"github.com/herohde/morlock/pkg/board"
+	"strings"
+)
+
+// view holds the board rendering options. Only accessed by the driver process.
+type view struct {
+	unicode  bool // chess symbols instead of letters for pieces
+	flip     bool // view from Black
+	nocoords bool // hide the rank and file coordinates
+	color    bool // ANSI colors for squares and pieces instead of a grid
+}
+
+const (
+	horizontal = "  ---------------------------------"
+	vertical   = " | "
+
+	ansiLight = "\x1b[48;5;180m"
+	ansiDark  = "\x1b[48;5;137m"
+	ansiWhite = "\x1b[1;97m"
+	ansiBlack = "\x1b[1;30m"
+	ansiReset = "\x1b[0m"
+)
+
+var symbols = [board.NumColors][board.NumPieces]string{
+	board.White: {board.Pawn: "♙", board.Knight: "♘", board.Bishop: "♗", board.Rook: "♖", board.Queen: "♕", board.King: "♔"},
+	board.Black: {board.Pawn: "♟", board.Knight: "♞", board.Bishop: "♝", board.Rook: "♜", board.Queen: "♛", board.King: "♚"},
+}
+
+// render returns the pieces of the position as a diagram, line by line.
+func (v view) render(p *board.Position) []string {
+	ranks := []board.Rank{board.Rank8, board.Rank7, board.Rank6, board.Rank5, board.Rank4, board.Rank3, board.Rank2, board.Rank1}
+	files := []board.File{board.FileA, board.FileB, board.FileC, board.FileD, board.FileE, board.FileF, board.FileG, board.FileH}
+	if v.flip {
+		for i, j := 0, len(ranks)-1; i < j; i, j = i+1, j-1 {
+			ranks[i], ranks[j] = ranks[j], ranks[i]
+			files[i], files[j] = files[j], files[i]
+		}
+	}
+
+	var ret []string
+	if !v.color {
+		ret = append(ret, v.files(files, "    ", "   "), horizontal)
+	} else {
+		ret = append(ret, v.files(files, "   ", "  "))
+	}
+
+	for _, r := range ranks {
+		var sb strings.Builder
+		if v.nocoords {
+			sb.WriteString(" ")
+		} else {
+			sb.WriteString(r.String())
+		}
+		if !v.color {
+			sb.WriteString(vertical)
+		} else {
+			sb.WriteString(" ")
+		}
+
+		for _, f := range files {
+			sq := board.NewSquare(f, r)
+			piece := " "
+			c, pc, ok := p.Square(sq)
+			if ok {
+				piece = v.piece(c, pc)
+			}
+
+			if !v.color {
+				sb.WriteString(piece)
+				sb.WriteString(vertical)
+				continue
+			}
+
+			bg := ansiDark
+			if (f.V()+r.V())%2 == 0 {
+				bg = ansiLight
+			}
+			fg := ansiBlack
+			if ok && c == board.White {
+				fg = ansiWhite
+			}
+			sb.WriteString(bg + fg + " " + piece + " " + ansiReset)
+		}
+		ret = append(ret, sb.String())
+		if !v.color {
+			ret = append(ret, horizontal)
+		}
+	}
+
+	if !v.color {
+		ret = append(ret, v.files(files, "    ", "   "))
+	} else {
+		ret = append(ret, v.files(files, "   ", "  "))
+	}
+	return ret
+}
+
+// files returns the file coordinates, if enabled.
+func (v view) files(files []board.File, prefix, sep string) string {
+	if v.nocoords {
+		return ""
+	}
+	var list []string
+	for _, f := range files {
+		list = append(list, f.String())
+	}
+	return prefix + strings.Join(list, sep)
+}
+
+func (v view) piece(c board.Color, p board.Piece) string {
+	if v.unicode {
+		if v.color {
+			return symbols[board.Black][p] // filled symbols, colored by side
+		}
+		return symbols[c][p]
+	}
+	return printPiece(c, p)
+}
@@ -0,0 +1,32 @@
+package console_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	_, in, out := newDriver(t)
+
+	in <- "reset 4k3/8/8/8/8/8/8/R3K3 w Q - 0 1"
+	expect(t, out, "fen:")
+	assert.Equal(t, "8 |   |   |   |   | k |   |   |   | ", expect(t, out, "8 |"))
+	assert.Equal(t, "1 | R |   |   |   | K |   |   |   | ", expect(t, out, "1 |"))
+
+	in <- "flip"
+	assert.Equal(t, "    h   g   f   e   d   c   b   a", expect(t, out, "    h"))
+	assert.Equal(t, "1 |   |   |   | K |   |   |   | R | ", expect(t, out, "1 |"))
+
+	in <- "unicode"
+	assert.Equal(t, "1 |   |   |   | ♔ |   |   |   | ♖ | ", expect(t, out, "1 |"))
+
+	in <- "nocoords"
+	assert.Equal(t, "  |   |   |   | ♔ |   |   |   | ♖ | ", expect(t, out, "  |"))
+
+	in <- "coords"
+	in <- "flip"
+	in <- "nounicode"
+	in <- "color"
+	assert.Equal(t, "   a  b  c  d  e  f  g  h", expect(t, out, "   a  b"))
+	assert.Contains(t, expect(t, out, "1 \x1b"), "1 \x1b[48;5;137m\x1b[1;97m R \x1b[0m\x1b[48;5;180m")
+}
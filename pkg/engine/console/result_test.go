@@ -0,0 +1,24 @@
+package console_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGameOver(t *testing.T) {
+	_, in, out := newDriver(t)
+
+	for _, m := range []string{"f3", "e5", "g4", "Qh4#"} {
+		in <- m
+	}
+	assert.Equal(t, "game over: 0-1 { Checkmate }", expect(t, out, "game over"))
+
+	in <- "a3"
+	assert.Equal(t, "game over: 0-1 { Checkmate } (reset, undo or load to continue)", expect(t, out, "game over"))
+
+	in <- "reset"
+	for _, m := range []string{"Nf3", "Nf6", "Ng1", "Ng8", "Nf3", "Nf6", "Ng1", "Ng8"} {
+		in <- m
+	}
+	assert.Contains(t, expect(t, out, "game over"), "Repetition")
+}
@@ -0,0 +1,104 @@
+package console
+
+import (
+	"context"
+	"fmt"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/san"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/herohde/morlock/pkg/search/searchctl"
+	"github.com/seekerror/stdlib/pkg/lang"
+	"strconv"
+)
+
+const (
+	// DefaultSelfPlayMoves is the default number of moves per side in self-play.
+	DefaultSelfPlayMoves = 100
+	// DefaultSelfPlayDepth is the self-play search depth, if the engine has no default depth.
+	DefaultSelfPlayDepth = 4
+)
+
+// selfplay lets the engine play both sides from the current position to the given search
+// depth until the game is over or each side has made n moves: selfplay [n] [depth] [<file>].
+// The moves are annotated with the engine evaluations and, if a file is given, the game is
+// saved as PGN. Blocks until done.
+func (d *Driver) selfplay(ctx context.Context, args []string) error {
+	n, depth := DefaultSelfPlayMoves, d.e.Options().Depth
+	if depth == 0 {
+		depth = DefaultSelfPlayDepth
+	}
+
+	if len(args) > 0 {
+		v, err := strconv.Atoi(args[0])
+		if err != nil || v < 1 {
+			return fmt.Errorf("invalid moves: '%v'", args[0])
+		}
+		n = v
+	}
+	if len(args) > 1 {
+		v, err := strconv.Atoi(args[1])
+		if err != nil || v < 1 {
+			return fmt.Errorf("invalid depth: '%v'", args[1])
+		}
+		depth = uint(v)
+	}
+	if len(args) > 3 {
+		return fmt.Errorf("usage: selfplay [n] [depth] [<file>]")
+	}
+
+	for i := 0; i < 2*n && !d.e.Result().IsTerminal(); i++ {
+		b := d.e.Board()
+
+		pv, err := d.bestLine(ctx, depth)
+		if err != nil {
+			return err
+		}
+		if len(pv.Moves) == 0 {
+			break
+		}
+		m := pv.Moves[0]
+
+		d.out <- fmt.Sprintf("%v %v {%v/%v}", printMoveNumber(b), san.Print(b.Position(), b.Turn(), m), pv.Score, pv.Depth)
+		d.annotate(pv)
+		if err := d.move(ctx, m); err != nil {
+			return err
+		}
+	}
+
+	d.printBoard(ctx)
+	if result := d.e.Result(); result.IsTerminal() {
+		d.out <- fmt.Sprintf("selfplay over: %v", result)
+	} else {
+		d.out <- fmt.Sprintf("selfplay stopped after %v moves", n)
+	}
+
+	if len(args) > 2 {
+		return d.save(args[2:])
+	}
+	return nil
+}
+
+// bestLine searches the current position to the given depth and returns the principal
+// variation with the move to play, which may be a blunder if enabled.
+func (d *Driver) bestLine(ctx context.Context, depth uint) (search.PV, error) {
+	out, err := d.e.Analyze(ctx, searchctl.Options{DepthLimit: lang.Some(depth)})
+	if err != nil {
+		return search.PV{}, err
+	}
+	var last search.PV
+	for pv := range out {
+		last = pv
+	}
+	if pv, err := d.e.Halt(ctx); err == nil && len(pv.Moves) > 0 {
+		last.Moves, last.Score = pv.Moves, pv.Score
+	}
+	return last, nil
+}
+
+// printMoveNumber returns the move number of the side to move, such as "12." or "12...".
+func printMoveNumber(b *board.Board) string {
+	if b.Turn() == board.White {
+		return fmt.Sprintf("%v.", b.FullMoves())
+	}
+	return fmt.Sprintf("%v...", b.FullMoves())
+}
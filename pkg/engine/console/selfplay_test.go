@@ -0,0 +1,23 @@
+package console_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSelfPlay(t *testing.T) {
+	e, in, out := newDriver(t)
+
+	in <- "selfplay 2 1"
+	expect(t, out, "1.")
+	expect(t, out, "1...")
+	expect(t, out, "2.")
+	expect(t, out, "2...")
+	expect(t, out, "selfplay stopped after 2 moves")
+	assert.Equal(t, 5, e.Board().Ply())
+
+	in <- "reset 7k/5Q2/6K1/8/8/8/8/8 w - - 0 1"
+	in <- "selfplay 10 2"
+	assert.Equal(t, "1. Qe8# {M1/2}", expect(t, out, "1."))
+	assert.Equal(t, "selfplay over: 1-0 { Checkmate }", expect(t, out, "selfplay"))
+}
@@ -1,7 +1,9 @@
+// Package engine contains the engine, which manages the game state and searches, and its options.
 package engine
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/herohde/morlock/pkg/board"
 	"github.com/herohde/morlock/pkg/board/fen"
@@ -11,47 +13,138 @@ import (
 	"github.com/seekerror/build"
 	"github.com/seekerror/logw"
 	"github.com/seekerror/stdlib/pkg/lang"
+	"github.com/seekerror/stdlib/pkg/util/mathx"
+	"math"
+	"math/rand"
 	"sync"
+	"time"
 )
 
 var version = build.NewVersion(0, 91, 1)
 
+var (
+	// ErrSearchActive is an error indicating that a search is already active.
+	ErrSearchActive = errors.New("search already active")
+	// ErrNoActiveSearch is an error indicating that no search is active.
+	ErrNoActiveSearch = errors.New("no active search")
+	// ErrNoMove is an error indicating that there is no move to take back.
+	ErrNoMove = errors.New("no move to take back")
+	// ErrNoEvaluator is an error indicating that the engine has no static evaluator.
+	ErrNoEvaluator = errors.New("no evaluator")
+	// ErrNoDepthLimit is an error indicating that a search has no depth limit, where it would
+	// not terminate.
+	ErrNoDepthLimit = errors.New("no depth limit")
+	// ErrNotTunable is an error indicating that the engine has no tunable parameters.
+	ErrNotTunable = errors.New("no tunable parameters")
+)
+
 // Options are search creation options.
 type Options struct {
-	// Depth is the search depth limit. If zero, there is no limit. Overridden by search
-	// options if provided.
+	// Depth is the search depth limit. If zero, there is no limit other than the internal
+	// maximum search depth. Overridden by search options if provided.
 	Depth uint
 	// Hash is the transposition table size in MB. If zero, the engine will not use
 	// a transposition table.
 	Hash uint
+	// Memory is the total memory budget in MB for the transposition table and auxiliary
+	// tables. If zero, there is no budget. Otherwise, all tables are scaled down
+	// proportionally to fit within the budget.
+	Memory uint
 	// Noise adds some millipawn randomness to the leaf evaluations.
 	Noise uint
+	// NoiseDistribution is the distribution of the noise. Default is uniform.
+	NoiseDistribution eval.Distribution
+	// NoisePerGame, if set, seeds the noise per game instead of per move: the noise of a
+	// position is then the same in all searches of the game. Otherwise, each search draws
+	// fresh noise.
+	NoisePerGame bool
+	// MultiPV is the number of best lines to search for analysis. If zero or one, only
+	// the best line is searched. Overridden by search options if provided.
+	MultiPV uint
+	// Seed is the random seed for noise, book and blunder choices. If zero, a new seed is
+	// chosen for each game. The seed in use is logged on reset. The seed alone replays a game
+	// exactly: choices are drawn in game order from a single stream seeded per game, and the
+	// noise of each search is derived from the seed and the position rather than from a
+	// shared stream, so it does not depend on prior searches. Replays must use the same
+	// options and depth-limited searches, because time-limited searches depend on the machine.
+	Seed int64
+	// Reuse, if set, starts the next search at a deeper initial depth if the game followed
+	// the principal variation of the last search. The transposition table is retained
+	// between moves regardless, so the shallow iterations are then mostly cutoffs.
+	Reuse bool
+	// Brain, if set, thinks on the opponent's time after the engine commits a move, i.e., a
+	// move that follows the principal variation of the last search. If the opponent plays the
+	// expected reply, the next search continues from the background search.
+	Brain bool
+	// Blunder is the probability in percent of playing the 2nd or 3rd best root move instead
+	// of the best move, if within the blunder margin. The alternatives are found by searching
+	// multiple lines. Separate from noise, it makes fixed-depth play less perfect, such as for
+	// training games. If zero, the best move is always played.
+	Blunder uint
+	// BlunderMargin is the maximum score loss in millipawns of a blunder.
+	BlunderMargin uint
+	// Contempt is the millipawn penalty of a draw for the engine, such as by repetition or
+	// stalemate. Positive contempt avoids draws and negative contempt seeks them. If zero,
+	// draws are scored as even.
+	Contempt int
 }
 
 func (o Options) String() string {
-	return fmt.Sprintf("{depth=%v, hash=%v, noise=%v}", o.Depth, o.Hash, o.Noise)
+	return fmt.Sprintf("{depth=%v, hash=%v, memory=%v, noise=%v/%v/%v, multipv=%v, seed=%v, reuse=%v, brain=%v, blunder=%v%%/%v, contempt=%v}", o.Depth, o.Hash, o.Memory, o.Noise, o.NoiseDistribution, o.NoiseSeeding(), o.MultiPV, o.Seed, o.Reuse, o.Brain, o.Blunder, o.BlunderMargin, o.Contempt)
+}
+
+// NoiseSeeding returns how the noise is seeded: "game" if per game and "move" otherwise.
+func (o Options) NoiseSeeding() string {
+	if o.NoisePerGame {
+		return "game"
+	}
+	return "move"
 }
 
 // Engine encapsulates game-playing logic, search and evaluation.
 type Engine struct {
 	name, author string
 
-	launcher searchctl.Launcher
-	factory  search.TranspositionTableFactory
-	zt       *board.ZobristTable
-	seed     int64
-	opts     Options
+	root        search.Search
+	launcher    searchctl.Launcher
+	tb          search.Tablebase
+	aspiration  eval.Pawns
+	watchdog    searchctl.Watchdog
+	factory     search.TranspositionTableFactory
+	aux         []auxTable
+	attribution Attribution
+	zt          *board.ZobristTable
+	zseed       int64
+	source      RandSource
+	keys        board.KeyOptions
+	caps        search.Capabilities
+	opts        Options
+	opponent    lang.Optional[Opponent]
+	tunable     eval.Tunable
+	evaluator   eval.Evaluator
 
 	b      *board.Board
 	tt     search.TranspositionTable
-	noise  eval.Random
+	seed   int64      // random seed of current game
+	choice *rand.Rand // random stream for choices, such as book moves
 	active searchctl.Handle
+	lines  uint // requested number of lines of the active search
+	game   bool // true iff the active search selects a move to play, i.e., not analysis
+	brain  searchctl.Brain
+	line   []board.Move      // remaining principal variation of the last search, if followed
+	depth  int               // remaining depth of line
+	fresh  bool              // true iff line starts at the root of the last search
+	order  []search.RootMove // root move order of a background search of the position, if any
 	mu     sync.Mutex
 }
 
 // Option is an engine creation option.
 type Option func(*Engine)
 
+// RandSource returns a random source seeded by the given seed. It allows the random streams
+// of the engine to be replaced, such as to record or script choices in tests.
+type RandSource func(seed int64) rand.Source
+
 // WithTable configures the engine to use the given transposition table factory.
 func WithTable(factory search.TranspositionTableFactory) Option {
 	return func(e *Engine) {
@@ -59,6 +152,29 @@ func WithTable(factory search.TranspositionTableFactory) Option {
 	}
 }
 
+// WithTablebase configures the engine to limit root moves to the moves that preserve the best
+// outcome of the given tablebase.
+func WithTablebase(tb search.Tablebase) Option {
+	return func(e *Engine) {
+		e.tb = tb
+	}
+}
+
+// WithAspiration configures the engine to search each iteration in an aspiration window of
+// the given half-width in pawns around the previous score, instead of the full window.
+func WithAspiration(window eval.Pawns) Option {
+	return func(e *Engine) {
+		e.aspiration = window
+	}
+}
+
+// WithWatchdog configures the engine to log searches that make no node progress.
+func WithWatchdog(w searchctl.Watchdog) Option {
+	return func(e *Engine) {
+		e.watchdog = w
+	}
+}
+
 // WithOptions sets default runtime options.
 func WithOptions(opts Options) Option {
 	return func(e *Engine) {
@@ -70,21 +186,45 @@ func WithOptions(opts Options) Option {
 // default seed of zero.
 func WithZobrist(seed int64) Option {
 	return func(e *Engine) {
-		e.seed = seed
+		e.zseed = seed
+	}
+}
+
+// WithRandSource configures the engine to use the given random source for book and blunder
+// choices and per-move noise instead of the default source. Noise seeded per game is derived
+// from the seed and position alone and does not use a source.
+func WithRandSource(source RandSource) Option {
+	return func(e *Engine) {
+		e.source = source
+	}
+}
+
+// WithKeyOptions configures the engine to include extra history-dependent components in
+// the search key, so that transposition table caching is sound for evaluations that
+// depend on such history. The history declared by the search capabilities is included
+// regardless.
+func WithKeyOptions(opts board.KeyOptions) Option {
+	return func(e *Engine) {
+		e.keys = opts
 	}
 }
 
 func New(ctx context.Context, name, author string, root search.Search, opts ...Option) *Engine {
 	e := &Engine{
-		name:     name,
-		author:   author,
-		launcher: &searchctl.Iterative{Root: root},
-		factory:  search.NewTranspositionTable,
+		name:    name,
+		author:  author,
+		root:    root,
+		factory: search.NewTranspositionTable,
+		source:  rand.NewSource,
 	}
 	for _, fn := range opts {
 		fn(e)
 	}
-	e.zt = board.NewZobristTable(e.seed)
+	e.caps = search.CapabilitiesOf(root)
+	e.keys = search.Capabilities{History: e.keys}.Merge(e.caps).History
+	e.launcher = &searchctl.Iterative{Root: root, TB: e.tb, Aspiration: e.aspiration, Watchdog: e.watchdog}
+	e.brain = searchctl.Brain{Launcher: e.launcher}
+	e.zt = board.NewZobristTable(e.zseed)
 
 	_ = e.Reset(ctx, fen.Initial)
 
@@ -102,6 +242,17 @@ func (e *Engine) Author() string {
 	return e.author
 }
 
+// Capabilities returns the capabilities of the search, which drivers consult to only offer
+// supported features.
+func (e *Engine) Capabilities() search.Capabilities {
+	return e.caps
+}
+
+// Root returns the root search, such as to compose it with other searches.
+func (e *Engine) Root() search.Search {
+	return e.root
+}
+
 func (e *Engine) Options() Options {
 	e.mu.Lock()
 	defer e.mu.Unlock()
@@ -130,6 +281,111 @@ func (e *Engine) SetNoise(millipawns uint) {
 	e.opts.Noise = millipawns
 }
 
+// SetNoiseDistribution sets the distribution of the evaluation noise.
+func (e *Engine) SetNoiseDistribution(dist eval.Distribution) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.opts.NoiseDistribution = dist
+}
+
+// SetNoisePerGame sets whether the evaluation noise is seeded per game instead of per move.
+func (e *Engine) SetNoisePerGame(perGame bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.opts.NoisePerGame = perGame
+}
+
+// SetMultiPV sets the number of best lines to search for analysis.
+func (e *Engine) SetMultiPV(lines uint) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.opts.MultiPV = lines
+}
+
+// SetReuse sets whether to start searches at a deeper initial depth if the game followed
+// the principal variation of the last search.
+func (e *Engine) SetReuse(reuse bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.opts.Reuse = reuse
+}
+
+// SetBrain sets whether to think on the opponent's time after the engine commits a move.
+func (e *Engine) SetBrain(brain bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.opts.Brain = brain
+	if !brain {
+		e.brain.Stop()
+	}
+}
+
+// SetBlunder sets the probability in percent of playing a weaker root move within the given
+// margin in millipawns instead of the best move.
+func (e *Engine) SetBlunder(percent, margin uint) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.opts.Blunder = mathx.Min(percent, 100)
+	e.opts.BlunderMargin = margin
+}
+
+// SetContempt sets the millipawn penalty of a draw for the engine.
+func (e *Engine) SetContempt(millipawns int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.opts.Contempt = millipawns
+}
+
+// SetSeed sets the random seed, effective from the next game. If zero, a new seed is
+// chosen for each game.
+func (e *Engine) SetSeed(seed int64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.opts.Seed = seed
+}
+
+// Seed returns the random seed of the current game.
+func (e *Engine) Seed() int64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.seed
+}
+
+// Choose returns a random choice in [0;n) from the current game random stream. Used for
+// book moves and similar choices outside search.
+func (e *Engine) Choose(n int) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.choice.Intn(n)
+}
+
+// Opponent returns the opponent, if known.
+func (e *Engine) Opponent() lang.Optional[Opponent] {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.opponent
+}
+
+// SetOpponent sets the opponent. The opponent is retained across games.
+func (e *Engine) SetOpponent(ctx context.Context, opp Opponent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	logw.Infof(ctx, "Opponent: %v", opp)
+	e.opponent = lang.Some(opp)
+}
+
 // Board returns a forked board.
 func (e *Engine) Board() *board.Board {
 	e.mu.Lock()
@@ -146,34 +402,110 @@ func (e *Engine) Position() string {
 	return fen.Encode(e.b.Position(), e.b.Turn(), e.b.NoProgress(), e.b.FullMoves())
 }
 
-// Reset resets the engine to a new starting position in FEN format.
-func (e *Engine) Reset(ctx context.Context, position string) error {
+// LegalMoves returns the legal moves in the current position.
+func (e *Engine) LegalMoves() []board.Move {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	logw.Infof(ctx, "Reset %v, depth=%v, TT=%vMB, noise=%vcp", position, e.opts.Depth, e.opts.Hash, e.opts.Noise/10)
+	return e.b.Position().LegalMoves(e.b.Turn())
+}
 
-	_, _ = e.haltSearchIfActive(ctx)
+// Result returns the result of the game in the current position. If the side to move has no
+// legal moves, the result is checkmate or stalemate.
+func (e *Engine) Result() board.Result {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	result := e.b.Result()
+	if !result.IsTerminal() && len(e.b.Position().LegalMoves(e.b.Turn())) == 0 {
+		result = e.b.Fork().AdjudicateNoLegalMoves()
+	}
+	return result
+}
 
+// InCheck returns true iff the side to move is in check.
+func (e *Engine) InCheck() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.b.Position().IsChecked(e.b.Turn())
+}
+
+// Reset resets the engine to a new starting position in FEN format.
+func (e *Engine) Reset(ctx context.Context, position string) error {
+	return e.SetGame(ctx, position, nil)
+}
+
+// SetGame resets the engine to a new starting position in FEN format and applies the given
+// moves, such as when a GUI replays a full game. The moves are validated and applied in one
+// operation without intermediate searches or logging. If the position or any move is not
+// valid, the engine is unchanged.
+func (e *Engine) SetGame(ctx context.Context, position string, moves []board.Move) error {
 	pos, turn, noprogress, fullmoves, err := fen.Decode(position)
 	if err != nil {
 		return err
 	}
-	e.b = board.NewBoard(e.zt, pos, turn, noprogress, fullmoves)
 
-	e.tt = search.NoTranspositionTable{}
-	if e.opts.Hash > 0 {
-		e.tt = e.factory(ctx, uint64(e.opts.Hash)<<20)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	b := board.NewBoard(e.zt, pos, turn, noprogress, fullmoves)
+	b.SetKeyOptions(e.keys)
+	for i, candidate := range moves {
+		m, ok := findMove(b.Position(), b.Turn(), candidate)
+		if !ok || !b.PushMove(m) {
+			return fmt.Errorf("%w: %v at ply %v", board.ErrIllegalMove, candidate, i+1)
+		}
 	}
-	e.noise = eval.Random{}
-	if e.opts.Noise > 0 {
-		e.noise = eval.NewRandom(int(e.opts.Noise), e.seed)
+
+	e.seed = e.opts.Seed
+	if e.seed == 0 {
+		e.seed = time.Now().UnixNano()%math.MaxInt32 + 1
 	}
+	e.choice = rand.New(e.source(e.seed))
 
+	logw.Infof(ctx, "Reset %v, moves=%v, depth=%v, TT=%vMB, noise=%vcp, seed=%v", position, len(moves), e.opts.Depth, e.opts.Hash, e.opts.Noise/10, e.seed)
+
+	_, _ = e.haltSearchIfActive(ctx)
+	e.brain.Stop()
+	e.resetLine()
+	e.b = b
+
+	e.resizeTables(ctx)
 	logw.Infof(ctx, "New board: %v", e.b)
 	return nil
 }
 
+// NewGame signals that the next search is from a different game, such as on "ucinewgame".
+// Any active search is halted and all tables are cleared, so that no entries carry over from
+// the previous game. The tables are reallocated with the current options.
+func (e *Engine) NewGame(ctx context.Context) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.clearTables(ctx)
+	logw.Infof(ctx, "New game, TT=%vMB", e.opts.Hash)
+}
+
+// ClearHash clears the transposition table and all auxiliary tables, such as on the UCI
+// "Clear Hash" button. Any active search is halted.
+func (e *Engine) ClearHash(ctx context.Context) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.clearTables(ctx)
+	logw.Infof(ctx, "Cleared hash, TT=%vMB", e.opts.Hash)
+}
+
+// clearTables halts any active search and reallocates all tables. Must be called with the
+// lock held.
+func (e *Engine) clearTables(ctx context.Context) {
+	_, _ = e.haltSearchIfActive(ctx)
+	e.brain.Stop()
+	e.resetLine()
+	e.resizeTables(ctx)
+}
+
 // Move selects the given move, usually an opponent move.
 func (e *Engine) Move(ctx context.Context, move string) error {
 	e.mu.Lock()
@@ -188,22 +520,42 @@ func (e *Engine) Move(ctx context.Context, move string) error {
 
 	_, _ = e.haltSearchIfActive(ctx)
 
-	moves := e.b.Position().PseudoLegalMoves(e.b.Turn())
-	for _, m := range moves {
-		if !candidate.Equals(m) {
-			continue
-		}
+	m, ok := findMove(e.b.Position(), e.b.Turn(), candidate)
+	if !ok {
+		return fmt.Errorf("%w: %v", board.ErrIllegalMove, candidate)
+	}
 
-		// Candidate is at least pseudo-legal.
+	// Candidate is at least pseudo-legal.
 
-		if !e.b.PushMove(m) {
-			return fmt.Errorf("illegal move: %v", m)
+	if !e.b.PushMove(m) {
+		return fmt.Errorf("%w: %v", board.ErrIllegalMove, m)
+	}
+
+	if e.brain.IsThinking() {
+		e.resetLine()
+		if pv, ok := e.brain.Resolve(ctx, m); ok {
+			e.line, e.depth, e.order = pv.Moves, pv.Depth, pv.Root
+		}
+	} else {
+		committed := e.fresh && len(e.line) > 0 && e.line[0].Equals(m)
+		e.followLine(m)
+		if committed && e.opts.Brain {
+			e.think(ctx)
 		}
+	}
 
-		logw.Infof(ctx, "Move %v: %v", m, e.b)
-		return nil
+	logw.Infof(ctx, "Move %v: %v", m, e.b)
+	return nil
+}
+
+// findMove returns the pseudo-legal move denoted by the candidate, if any.
+func findMove(pos *board.Position, turn board.Color, candidate board.Move) (board.Move, bool) {
+	for _, m := range pos.PseudoLegalMoves(turn) {
+		if isMove(pos, candidate, m) {
+			return m, true
+		}
 	}
-	return fmt.Errorf("invalid move: %v", candidate)
+	return board.Move{}, false
 }
 
 // TakeBack undoes the latest move.
@@ -212,11 +564,13 @@ func (e *Engine) TakeBack(ctx context.Context) error {
 	defer e.mu.Unlock()
 
 	_, _ = e.haltSearchIfActive(ctx)
+	e.brain.Stop()
 
 	m, ok := e.b.PopMove()
 	if !ok {
-		return fmt.Errorf("no move to take back")
+		return ErrNoMove
 	}
+	e.resetLine()
 
 	logw.Infof(ctx, "Takeback %v", m)
 	return nil
@@ -230,18 +584,135 @@ func (e *Engine) Analyze(ctx context.Context, opt searchctl.Options) (<-chan sea
 	if _, ok := opt.DepthLimit.V(); !ok {
 		opt.DepthLimit = lang.Some(e.opts.Depth)
 	}
+	if opt.MultiPV == 0 {
+		opt.MultiPV = e.opts.MultiPV
+	}
+	lines := opt.MultiPV
+	if !opt.Analysis {
+		opt.MultiPV = e.multiPV(lines)
+	}
+	if opt.Contempt == 0 {
+		opt.Contempt = e.contempt()
+	}
+	if len(opt.SearchMoves) > 0 {
+		opt.SearchMoves = e.legalSearchMoves(ctx, opt.SearchMoves)
+	}
+	if opt.ID == 0 {
+		opt.ID = search.NewID()
+	}
+	if e.opts.Reuse && opt.StartDepth == 0 && e.depth > 1 {
+		opt.StartDepth = uint(e.depth)
+	}
+	if len(opt.Order) == 0 {
+		opt.Order = e.order
+	}
+	if tc, ok := opt.TimeControl.V(); ok && e.caps.OwnTime {
+		opt.TimeControl = lang.Some(tc.Remaining(e.b.Turn()))
+	}
 
 	logw.Infof(ctx, "Analyze %v, opt=%v", e.b, opt)
 
 	if e.active != nil {
-		return nil, fmt.Errorf("search already active")
+		return nil, ErrSearchActive
 	}
+	e.brain.Stop()
+	e.tt.Age() // entries of previous searches lose replacement priority
 
-	handle, out := e.launcher.Launch(ctx, e.b.Fork(), e.tt, e.noise, opt)
-	e.active = handle
+	handle, out := e.launcher.Launch(ctx, e.b.Fork(), e.tt, e.noise(e.b), opt)
+	e.active, e.lines, e.game = handle, lines, !opt.Analysis
+	if opt.MultiPV > lines {
+		out = trimLines(out, lines) // report only the requested lines
+	}
 	return out, nil
 }
 
+// trimLines forwards the search results with only the given number of lines.
+func trimLines(in <-chan search.PV, lines uint) <-chan search.PV {
+	out := make(chan search.PV, 1)
+	go func() {
+		defer close(out)
+		for pv := range in {
+			pv.Alt = trimAlt(pv.Alt, lines)
+
+			select {
+			case <-out:
+			default:
+			}
+			out <- pv
+		}
+	}()
+	return out
+}
+
+// trimAlt returns the alternative lines of a result with the given number of lines.
+func trimAlt(alt []search.PV, lines uint) []search.PV {
+	if lines <= 1 {
+		return nil
+	}
+	return alt[:mathx.Min(len(alt), int(lines)-1)]
+}
+
+// noise returns the evaluation noise for a search of the given position. Noise is seeded by
+// the game seed and position, so that each search is reproducible regardless of any prior
+// searches. If seeded per game, the noise of each position is derived from the game seed
+// alone. Must be called with the lock held.
+func (e *Engine) noise(b *board.Board) eval.Random {
+	if e.opts.Noise > 0 {
+		if e.opts.NoisePerGame {
+			return eval.NewKeyedRandom(int(e.opts.Noise), e.opts.NoiseDistribution, e.seed)
+		}
+		return eval.NewRandomSource(int(e.opts.Noise), e.opts.NoiseDistribution, e.source(e.seed^int64(b.Hash())))
+	}
+	return eval.Random{}
+}
+
+// think starts a background search of the position after the expected reply on the
+// opponent's time, if known and legal. Must be called with the lock held.
+func (e *Engine) think(ctx context.Context) {
+	if len(e.line) == 0 {
+		return // no expected reply
+	}
+
+	b := e.b.Fork()
+	if !b.PushMove(e.line[0]) {
+		return // not legal
+	}
+	if len(b.Position().LegalMoves(b.Turn())) == 0 {
+		return // game over
+	}
+
+	opt := searchctl.Options{ID: search.NewID(), DepthLimit: lang.Some(e.opts.Depth), MultiPV: e.multiPV(e.opts.MultiPV), Contempt: e.contempt()}
+	e.brain.Think(ctx, b, e.line[0], e.tt, e.noise(b), opt)
+}
+
+// legalSearchMoves returns the legal moves among the given root moves. If none are legal,
+// the restriction is dropped and all moves are searched.
+func (e *Engine) legalSearchMoves(ctx context.Context, moves []board.Move) []board.Move {
+	var ret []board.Move
+	for _, m := range e.b.Position().LegalMoves(e.b.Turn()) {
+		for _, candidate := range moves {
+			if isMove(e.b.Position(), candidate, m) {
+				ret = append(ret, m)
+				break
+			}
+		}
+	}
+	if len(ret) < len(moves) {
+		logw.Warningf(ctx, "Ignoring illegal search moves: %v, legal: %v", board.PrintMoves(moves), board.PrintMoves(ret))
+	}
+	return ret
+}
+
+// isMove returns true iff the candidate denotes the given move. A castling move may also be
+// given as the King capturing its own Rook, as used for Chess960.
+func isMove(pos *board.Position, candidate, m board.Move) bool {
+	if candidate.Equals(m) {
+		return true
+	}
+	rook, _, ok := pos.CastlingRookMove(m)
+	return ok && candidate.From == m.From && candidate.To == rook && candidate.Promotion == board.NoPiece
+}
+
 // Halt halts the active search and returns the principal variation, if any.
 func (e *Engine) Halt(ctx context.Context) (search.PV, error) {
 	e.mu.Lock()
@@ -251,18 +722,89 @@ func (e *Engine) Halt(ctx context.Context) (search.PV, error) {
 
 	pv, ok := e.haltSearchIfActive(ctx)
 	if !ok {
-		return search.PV{}, fmt.Errorf("no active search")
+		return search.PV{}, ErrNoActiveSearch
+	}
+	if e.game {
+		if alt, ok := e.blunder(pv); ok {
+			logw.Infof(ctx, "Blunder on %v: %v", e.b, alt)
+			pv = alt
+			e.line, e.depth = pv.Moves, pv.Depth
+		}
 	}
+	pv.Alt = trimAlt(pv.Alt, e.lines)
 	return pv, nil
 }
 
 func (e *Engine) haltSearchIfActive(ctx context.Context) (search.PV, bool) {
 	if e.active != nil {
 		pv := e.active.Halt()
-		logw.Infof(ctx, "Search %v halted: %v", e.b, pv)
+		logw.Infof(ctx, "Search %v halted on %v: %v", pv.ID, e.b, pv)
 
 		e.active = nil
+		e.line, e.depth, e.fresh, e.order = pv.Moves, pv.Depth, true, nil
 		return pv, true
 	}
 	return search.PV{}, false
 }
+
+// contempt returns the contempt in pawns. Must be called with the lock held.
+func (e *Engine) contempt() eval.Pawns {
+	return eval.Pawns(e.opts.Contempt) / 1000
+}
+
+// blunderLines is the number of best root moves considered for a blunder, including the best.
+const blunderLines = 3
+
+// multiPV returns the number of lines to search for a move to play, given the requested number
+// of lines. If blunders are enabled, enough lines are searched to find the alternatives. Must
+// be called with the lock held.
+func (e *Engine) multiPV(lines uint) uint {
+	if e.opts.Blunder > 0 {
+		return mathx.Max(lines, blunderLines)
+	}
+	return lines
+}
+
+// blunder returns a weaker line of the search result to play instead of the best line, if
+// chosen with the blunder probability. Only lines with heuristic scores within the blunder
+// margin of the best line qualify. Must be called with the lock held.
+func (e *Engine) blunder(pv search.PV) (search.PV, bool) {
+	if e.opts.Blunder == 0 || !pv.Score.IsHeuristic() || len(pv.Moves) == 0 {
+		return pv, false
+	}
+
+	var candidates []search.PV
+	for i := 0; i < len(pv.Alt) && i < blunderLines-1; i++ {
+		alt := pv.Alt[i]
+		if len(alt.Moves) == 0 || !alt.Score.IsHeuristic() {
+			continue
+		}
+		if 1000*(pv.Score.Pawns-alt.Score.Pawns) <= eval.Pawns(e.opts.BlunderMargin) {
+			candidates = append(candidates, alt)
+		}
+	}
+	if len(candidates) == 0 || e.choice.Intn(100) >= int(e.opts.Blunder) {
+		return pv, false
+	}
+
+	alt := candidates[e.choice.Intn(len(candidates))]
+	ret := pv
+	ret.Moves, ret.Score, ret.Depth = alt.Moves, alt.Score, alt.Depth
+	return ret, true
+}
+
+// followLine updates the expected line after the given move. If the move deviates from the
+// line, the last search is not reused. Must be called with the lock held.
+func (e *Engine) followLine(m board.Move) {
+	if len(e.line) == 0 || !e.line[0].Equals(m) {
+		e.resetLine()
+		return
+	}
+	e.line, e.depth, e.fresh, e.order = e.line[1:], e.depth-1, false, nil
+}
+
+// resetLine clears the expected line and any search state for the position. Must be called
+// with the lock held.
+func (e *Engine) resetLine() {
+	e.line, e.depth, e.fresh, e.order = nil, 0, false, nil
+}
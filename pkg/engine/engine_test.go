@@ -0,0 +1,471 @@
+package engine_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/engine"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/herohde/morlock/pkg/search/searchctl"
+	"github.com/seekerror/stdlib/pkg/lang"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestSeed(t *testing.T) {
+	ctx := context.Background()
+
+	root := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+	opts := engine.Options{Depth: 2, Noise: 1000, Seed: 42}
+
+	analyze := func(e *engine.Engine) search.PV {
+		out, err := e.Analyze(ctx, searchctl.Options{DepthLimit: lang.Some(uint(2))})
+		require.NoError(t, err)
+
+		var last search.PV
+		for pv := range out {
+			last = pv
+		}
+		_, _ = e.Halt(ctx)
+		return last
+	}
+
+	// (1) Same seed: identical choices and noise, even if the searches differ.
+
+	a := engine.New(ctx, "test", "test", root, engine.WithOptions(opts))
+	b := engine.New(ctx, "test", "test", root, engine.WithOptions(opts))
+	assert.Equal(t, int64(42), a.Seed())
+	assert.Equal(t, a.Choose(1000), b.Choose(1000))
+
+	_ = analyze(b) // extra search does not perturb later searches
+	require.NoError(t, a.Move(ctx, "e2e4"))
+	require.NoError(t, b.Move(ctx, "e2e4"))
+
+	pa, pb := analyze(a), analyze(b)
+	assert.Equal(t, pa.Score, pb.Score)
+	assert.Equal(t, pa.Moves, pb.Moves)
+
+	// (2) No seed: new seed per game.
+
+	c := engine.New(ctx, "test", "test", root, engine.WithOptions(engine.Options{Depth: 2}))
+	assert.NotZero(t, c.Seed())
+
+	c.SetSeed(7)
+	require.NoError(t, c.Reset(ctx, fen.Initial))
+	assert.Equal(t, int64(7), c.Seed())
+
+	// (3) Injected source: used for the game stream and per-move noise.
+
+	var seeds []int64
+	source := func(seed int64) rand.Source {
+		seeds = append(seeds, seed)
+		return rand.NewSource(seed)
+	}
+	d := engine.New(ctx, "test", "test", root, engine.WithOptions(opts), engine.WithRandSource(source))
+	assert.Equal(t, []int64{42}, seeds)
+
+	require.NoError(t, d.Move(ctx, "e2e4"))
+	pd := analyze(d)
+	assert.Len(t, seeds, 2)
+	assert.Equal(t, pa.Score, pd.Score) // same stream as the default source
+}
+
+func TestSearchMoves(t *testing.T) {
+	ctx := context.Background()
+
+	root := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+	e := engine.New(ctx, "test", "test", root, engine.WithOptions(engine.Options{Depth: 2}))
+	require.NoError(t, e.Reset(ctx, "k7/7R/6R1/8/8/8/8/7K w - - 0 1"))
+
+	analyze := func(moves ...string) search.PV {
+		var opt searchctl.Options
+		for _, str := range moves {
+			m, err := board.ParseMove(str)
+			require.NoError(t, err)
+			opt.SearchMoves = append(opt.SearchMoves, m)
+		}
+
+		out, err := e.Analyze(ctx, opt)
+		require.NoError(t, err)
+
+		var last search.PV
+		for pv := range out {
+			last = pv
+		}
+		_, _ = e.Halt(ctx)
+		return last
+	}
+
+	assert.Equal(t, "Rg6-g8", analyze().Moves[0].String())
+	assert.Equal(t, "Rh7-h8", analyze("h7h8", "a1a2").Moves[0].String())
+	assert.Equal(t, "Rg6-g8", analyze("a1a2").Moves[0].String()) // illegal: no restriction
+}
+
+func TestBlunder(t *testing.T) {
+	ctx := context.Background()
+
+	root := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+	e := engine.New(ctx, "test", "test", root, engine.WithOptions(engine.Options{Depth: 2, Seed: 42, Blunder: 100}))
+
+	analyze := func(e *engine.Engine, opt searchctl.Options) (search.PV, search.PV) {
+		out, err := e.Analyze(ctx, opt)
+		require.NoError(t, err)
+
+		var last search.PV
+		for pv := range out {
+			last = pv
+		}
+		played, err := e.Halt(ctx)
+		require.NoError(t, err)
+		return last, played
+	}
+
+	// (1) Equal alternatives: play the 2nd or 3rd best move. Only the requested line is
+	// reported.
+
+	last, played := analyze(e, searchctl.Options{})
+	assert.Empty(t, last.Alt)
+	assert.Empty(t, played.Alt)
+	assert.NotEqual(t, last.Moves[0], played.Moves[0])
+
+	// (2) Analysis: play the best move.
+
+	last, played = analyze(e, searchctl.Options{Analysis: true})
+	assert.Empty(t, last.Alt)
+	assert.Equal(t, last.Moves[0], played.Moves[0])
+
+	// (3) Internal halts do not draw blunders: the seed replays the game.
+
+	other := engine.New(ctx, "test", "test", root, engine.WithOptions(engine.Options{Depth: 2, Seed: 42, Blunder: 100}))
+	_, expected := analyze(other, searchctl.Options{})
+
+	replay := engine.New(ctx, "test", "test", root, engine.WithOptions(engine.Options{Depth: 2, Seed: 42, Blunder: 100}))
+	_, err := replay.Analyze(ctx, searchctl.Options{})
+	require.NoError(t, err)
+	require.NoError(t, replay.Move(ctx, "e2e4"))
+	require.NoError(t, replay.TakeBack(ctx))
+	_, actual := analyze(replay, searchctl.Options{})
+	assert.Equal(t, expected.Moves[0], actual.Moves[0])
+
+	// (4) Forced mate or alternatives outside the margin: play the best move.
+
+	require.NoError(t, e.Reset(ctx, "k7/7R/6R1/8/8/8/8/7K w - - 0 1"))
+	last, played = analyze(e, searchctl.Options{})
+	assert.Equal(t, last.Moves[0], played.Moves[0])
+
+	require.NoError(t, e.Reset(ctx, "7k/8/8/8/8/8/8/Rq5K w - - 0 1"))
+	last, played = analyze(e, searchctl.Options{})
+	assert.Equal(t, "Ra1*b1", last.Moves[0].String())
+	assert.Equal(t, last.Moves[0], played.Moves[0])
+}
+
+func TestChess960Move(t *testing.T) {
+	ctx := context.Background()
+
+	root := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+	e := engine.New(ctx, "test", "test", root)
+
+	require.NoError(t, e.Reset(ctx, "5k1r/8/8/8/8/8/8/5K1R w Hh - 0 1"))
+	require.NoError(t, e.Move(ctx, "f1h1")) // King captures own Rook: castling
+	assert.Equal(t, "5k1r/8/8/8/8/8/8/5RK1 b k - 0 1", e.Position())
+
+	require.NoError(t, e.Reset(ctx, "5k1r/8/8/8/8/8/8/5K1R w Hh - 0 1"))
+	require.NoError(t, e.Move(ctx, "f1g1")) // normal King move
+	assert.Equal(t, "5k1r/8/8/8/8/8/8/6KR b k - 1 1", e.Position())
+}
+
+func TestReuse(t *testing.T) {
+	ctx := context.Background()
+
+	root := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+	e := engine.New(ctx, "test", "test", root, engine.WithOptions(engine.Options{Depth: 4, Hash: 1, Reuse: true}))
+
+	analyze := func() (int, search.PV) {
+		out, err := e.Analyze(ctx, searchctl.Options{})
+		require.NoError(t, err)
+
+		first, last := 0, search.PV{}
+		for pv := range out {
+			if first == 0 {
+				first = pv.Depth
+			}
+			last = pv
+		}
+		_, _ = e.Halt(ctx)
+		return first, last
+	}
+	move := func(m board.Move) string {
+		return m.From.String() + m.To.String()
+	}
+
+	// (1) Expected line: start at the remaining depth.
+
+	first, pv := analyze()
+	assert.Equal(t, 1, first)
+	require.Len(t, pv.Moves, 4)
+
+	require.NoError(t, e.Move(ctx, move(pv.Moves[0])))
+	require.NoError(t, e.Move(ctx, move(pv.Moves[1])))
+	first, _ = analyze()
+	assert.Equal(t, 2, first)
+
+	// (2) Deviation: start from scratch.
+
+	require.NoError(t, e.Reset(ctx, fen.Initial))
+	_, pv = analyze()
+	reply := "a7a6"
+	if move(pv.Moves[1]) == reply {
+		reply = "h7h6"
+	}
+	require.NoError(t, e.Move(ctx, move(pv.Moves[0])))
+	require.NoError(t, e.Move(ctx, reply))
+	first, _ = analyze()
+	assert.Equal(t, 1, first)
+}
+
+// BenchmarkReuse compares the nodes searched in self-play with and without reuse of the last
+// principal variation. The game follows the line of each search, so reuse applies to every
+// search after the first.
+func BenchmarkReuse(b *testing.B) {
+	ctx := context.Background()
+	root := search.AlphaBeta{Eval: search.Quiescence{Explore: search.CaptureExploration, Eval: search.Leaf{Eval: eval.PST{}}}}
+
+	for _, reuse := range []bool{false, true} {
+		b.Run(fmt.Sprintf("reuse=%v", reuse), func(b *testing.B) {
+			var nodes, iterations uint64
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				e := engine.New(ctx, "test", "test", root, engine.WithOptions(engine.Options{Depth: 4, Hash: 16, Reuse: reuse}))
+				b.StartTimer()
+
+				for ply := 0; ply < 10; ply++ {
+					out, err := e.Analyze(ctx, searchctl.Options{})
+					require.NoError(b, err)
+					for pv := range out {
+						nodes += pv.Nodes
+						iterations++
+					}
+					pv, err := e.Halt(ctx)
+					require.NoError(b, err)
+					require.NoError(b, e.Move(ctx, board.PrintUCIMove(pv.Moves[0], board.StandardCastlingLayout, false)))
+				}
+			}
+			b.ReportMetric(float64(nodes)/float64(b.N), "nodes/op")
+			b.ReportMetric(float64(iterations)/float64(b.N), "iterations/op")
+		})
+	}
+}
+
+func TestBrain(t *testing.T) {
+	ctx := context.Background()
+
+	root := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+	e := engine.New(ctx, "test", "test", root, engine.WithOptions(engine.Options{Depth: 4, Hash: 1, Reuse: true, Brain: true}))
+
+	analyze := func() (int, search.PV) {
+		out, err := e.Analyze(ctx, searchctl.Options{})
+		require.NoError(t, err)
+
+		first, last := 0, search.PV{}
+		for pv := range out {
+			if first == 0 {
+				first = pv.Depth
+			}
+			last = pv
+		}
+		_, _ = e.Halt(ctx)
+		return first, last
+	}
+	move := func(m board.Move) string {
+		return m.From.String() + m.To.String()
+	}
+
+	// (1) Expected reply: continue from the background search at full depth.
+
+	_, pv := analyze()
+	require.Len(t, pv.Moves, 4)
+
+	require.NoError(t, e.Move(ctx, move(pv.Moves[0])))
+	time.Sleep(200 * time.Millisecond) // let background search complete
+	require.NoError(t, e.Move(ctx, move(pv.Moves[1])))
+	first, _ := analyze()
+	assert.Equal(t, 4, first)
+
+	// (2) Unexpected reply: start from scratch.
+
+	require.NoError(t, e.Reset(ctx, fen.Initial))
+	_, pv = analyze()
+	reply := "a7a6"
+	if move(pv.Moves[1]) == reply {
+		reply = "h7h6"
+	}
+	require.NoError(t, e.Move(ctx, move(pv.Moves[0])))
+	require.NoError(t, e.Move(ctx, reply))
+	first, _ = analyze()
+	assert.Equal(t, 1, first)
+
+	// (3) Reset and take back discard the background search.
+
+	require.NoError(t, e.Reset(ctx, fen.Initial))
+	_, pv = analyze()
+	require.NoError(t, e.Move(ctx, move(pv.Moves[0])))
+	require.NoError(t, e.TakeBack(ctx))
+	require.NoError(t, e.Move(ctx, move(pv.Moves[0])))
+	require.NoError(t, e.Reset(ctx, fen.Initial))
+	first, _ = analyze()
+	assert.Equal(t, 1, first)
+}
+
+func TestEvaluate(t *testing.T) {
+	ctx := context.Background()
+
+	root := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+
+	e := engine.New(ctx, "test", "test", root)
+	_, err := e.Evaluate(ctx)
+	assert.Error(t, err)
+
+	e = engine.New(ctx, "test", "test", root, engine.WithEvaluator(eval.Material{}))
+	require.NoError(t, e.Reset(ctx, "4k3/8/8/8/8/8/8/R3K3 b - - 0 1"))
+	score, err := e.Evaluate(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, eval.Pawns(-5), score)
+
+	explanation, err := e.ExplainEvaluation(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, eval.Explanation{Score: -5, Terms: []eval.Term{{Name: "material", Score: -5}}}, explanation)
+}
+
+func TestSetGame(t *testing.T) {
+	ctx := context.Background()
+
+	root := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+	e := engine.New(ctx, "test", "test", root)
+
+	var moves []board.Move
+	for _, str := range []string{"e2e4", "e7e5", "g1f3", "b8c6", "f1b5", "a7a6", "b1c3", "g8f6"} {
+		m, err := board.ParseMove(str)
+		require.NoError(t, err)
+		moves = append(moves, m)
+	}
+
+	// (1) Same result as applying the moves one-by-one.
+
+	require.NoError(t, e.SetGame(ctx, fen.Initial, moves))
+	assert.Equal(t, "r1bqkb1r/1ppp1ppp/p1n2n2/1B2p3/4P3/2N2N2/PPPP1PPP/R1BQK2R w KQkq - 2 5", e.Position())
+	assert.Equal(t, 9, e.Board().Ply())
+
+	// (2) Invalid move: unchanged.
+
+	err := e.SetGame(ctx, fen.Initial, append(moves[:2:2], moves[3]))
+	assert.True(t, errors.Is(err, board.ErrIllegalMove))
+	assert.Equal(t, "r1bqkb1r/1ppp1ppp/p1n2n2/1B2p3/4P3/2N2N2/PPPP1PPP/R1BQK2R w KQkq - 2 5", e.Position())
+}
+
+func TestGameStatus(t *testing.T) {
+	ctx := context.Background()
+
+	root := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+	e := engine.New(ctx, "test", "test", root)
+
+	assert.Len(t, e.LegalMoves(), 20)
+	assert.False(t, e.InCheck())
+	assert.False(t, e.Result().IsTerminal())
+
+	// Fool's mate.
+
+	for _, m := range []string{"f2f3", "e7e5", "g2g4", "d8h4"} {
+		require.NoError(t, e.Move(ctx, m))
+	}
+	assert.Empty(t, e.LegalMoves())
+	assert.True(t, e.InCheck())
+	assert.Equal(t, board.Result{Outcome: board.Loss(board.White), Reason: board.Checkmate}, e.Result())
+
+	// Stalemate.
+
+	require.NoError(t, e.Reset(ctx, "7k/5Q2/6K1/8/8/8/8/8 b - - 0 1"))
+	assert.Empty(t, e.LegalMoves())
+	assert.False(t, e.InCheck())
+	assert.Equal(t, board.Result{Outcome: board.Draw, Reason: board.Stalemate}, e.Result())
+}
+
+func TestErrors(t *testing.T) {
+	ctx := context.Background()
+
+	root := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+	e := engine.New(ctx, "test", "test", root)
+
+	var invalid fen.ErrInvalidFEN
+	assert.True(t, errors.As(e.Reset(ctx, "8/8/8 w - - 0 1"), &invalid))
+	assert.Equal(t, "number of squares", invalid.Reason)
+
+	assert.True(t, errors.Is(e.Move(ctx, "e2e5"), board.ErrIllegalMove))
+
+	_, err := e.Halt(ctx)
+	assert.True(t, errors.Is(err, engine.ErrNoActiveSearch))
+
+	_, err = e.Analyze(ctx, searchctl.Options{})
+	require.NoError(t, err)
+	_, err = e.Analyze(ctx, searchctl.Options{})
+	assert.True(t, errors.Is(err, engine.ErrSearchActive))
+	_, _ = e.Halt(ctx)
+}
+
+// castledEval is a fake evaluator that depends on castling history.
+type castledEval struct {
+	eval.Material
+}
+
+func (castledEval) Capabilities() search.Capabilities {
+	return search.Capabilities{History: board.KeyOptions{Castled: true}, NoTT: true}
+}
+
+func TestCapabilities(t *testing.T) {
+	ctx := context.Background()
+
+	root := search.Stalemate{Eval: search.AlphaBeta{Eval: search.Leaf{Eval: castledEval{}}}}
+	e := engine.New(ctx, "test", "test", root, engine.WithOptions(engine.Options{Hash: 1}))
+
+	assert.True(t, e.Capabilities().NoTT)
+	assert.Equal(t, board.KeyOptions{Castled: true}, e.Board().KeyOptions())
+}
+
+// waiting is a fake search that waits for its move until the deadline, such as a human player
+// that runs out of time.
+type waiting struct{}
+
+func (waiting) Capabilities() search.Capabilities {
+	return search.Capabilities{NoTT: true, NoPonder: true, OwnTime: true}
+}
+
+func (waiting) Search(ctx context.Context, sctx *search.Context, b *board.Board, depth int) (uint64, eval.Score, []board.Move, error) {
+	if sctx.Deadline.IsZero() {
+		return 0, eval.InvalidScore, nil, fmt.Errorf("no deadline")
+	}
+	time.Sleep(time.Until(sctx.Deadline))
+	return 0, eval.InvalidScore, nil, search.ErrHalted
+}
+
+func TestOwnTime(t *testing.T) {
+	ctx := context.Background()
+
+	e := engine.New(ctx, "test", "test", waiting{}, engine.WithOptions(engine.Options{Depth: 1}))
+
+	// A budget would be a few milliseconds. The search waits for the remaining time instead.
+
+	tc := searchctl.TimeControl{White: 300 * time.Millisecond, Black: time.Minute}
+	start := time.Now()
+	out, err := e.Analyze(ctx, searchctl.Options{TimeControl: lang.Some(tc)})
+	require.NoError(t, err)
+	for range out {
+	}
+	_, _ = e.Halt(ctx)
+
+	assert.GreaterOrEqual(t, time.Since(start), 300*time.Millisecond-searchctl.MoveOverhead)
+}
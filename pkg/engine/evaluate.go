@@ -0,0 +1,38 @@
+package engine
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/eval"
+)
+
+// WithEvaluator registers the static evaluator of the engine, so that positions can be
+// evaluated without a search.
+func WithEvaluator(ev eval.Evaluator) Option {
+	return func(e *Engine) {
+		e.evaluator = ev
+	}
+}
+
+// Evaluate returns the static evaluation of the current position for the side to move. Noise
+// is not used.
+func (e *Engine) Evaluate(ctx context.Context) (eval.Pawns, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.evaluator == nil {
+		return 0, ErrNoEvaluator
+	}
+	return e.evaluator.Evaluate(ctx, e.b.Fork()), nil
+}
+
+// ExplainEvaluation returns the static evaluation of the current position for the side to move with a
+// per-term breakdown, if the evaluator is an Explainer. Noise is not used.
+func (e *Engine) ExplainEvaluation(ctx context.Context) (eval.Explanation, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.evaluator == nil {
+		return eval.Explanation{}, ErrNoEvaluator
+	}
+	return eval.Explain(ctx, e.evaluator, e.b.Fork()), nil
+}
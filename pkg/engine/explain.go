@@ -0,0 +1,78 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/search"
+	"sort"
+)
+
+// RootMoveReport holds the search result of a single legal move in the current position.
+type RootMoveReport struct {
+	Move  board.Move
+	Score eval.Score
+	PV    []board.Move // continuation after the move
+	Nodes uint64
+	// Rule is the rule or term attribution of the move, if supported by the engine.
+	Rule string
+}
+
+func (r RootMoveReport) String() string {
+	rule := ""
+	if r.Rule != "" {
+		rule = fmt.Sprintf("\t[%v]", r.Rule)
+	}
+	return fmt.Sprintf("%v\t%v\t\t(%v nodes\tpv %v)%v", r.Move, r.Score, r.Nodes, board.PrintMoves(r.PV), rule)
+}
+
+// Attribution returns a rule or term attribution for a legal move in the given position,
+// such as which plausible move rule selected it. Returns the empty string if none.
+type Attribution func(ctx context.Context, b *board.Board, move board.Move) string
+
+// WithAttribution configures the engine to attribute root moves in Explain.
+func WithAttribution(fn Attribution) Option {
+	return func(e *Engine) {
+		e.attribution = fn
+	}
+}
+
+// Explain searches each legal move in the current position to the given depth, or the
+// default depth if zero, and returns the results ordered by score, best first. The breakdown
+// uses no transposition table and no noise. It does not interfere with an active search.
+func (e *Engine) Explain(ctx context.Context, depth uint) ([]RootMoveReport, error) {
+	e.mu.Lock()
+	b := e.b.Fork()
+	if depth == 0 {
+		depth = e.opts.Depth
+	}
+	e.mu.Unlock()
+
+	if depth == 0 {
+		return nil, ErrNoDepthLimit
+	}
+
+	var ret []RootMoveReport
+	for _, move := range b.Position().LegalMoves(b.Turn()) {
+		sctx := &search.Context{TT: search.NoTranspositionTable{}, Ponder: []board.Move{move}}
+		nodes, score, moves, err := e.root.Search(ctx, sctx, b, int(depth))
+		if err != nil {
+			return nil, err
+		}
+		if len(moves) > 0 {
+			moves = moves[1:] // skip ponder move in pv breakdown
+		}
+
+		r := RootMoveReport{Move: move, Score: score, PV: moves, Nodes: nodes}
+		if e.attribution != nil {
+			r.Rule = e.attribution(ctx, b, move)
+		}
+		ret = append(ret, r)
+	}
+
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[j].Score.Less(ret[i].Score)
+	})
+	return ret, nil
+}
@@ -0,0 +1,42 @@
+package engine_test
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/engine"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	ctx := context.Background()
+
+	root := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+	attr := func(ctx context.Context, b *board.Board, move board.Move) string {
+		if move.IsCapture() {
+			return "capture"
+		}
+		return ""
+	}
+
+	e := engine.New(ctx, "test", "test", root, engine.WithAttribution(attr))
+	require.NoError(t, e.Reset(ctx, "4k3/8/8/8/3q4/8/8/3QK3 w - - 0 1"))
+
+	_, err := e.Explain(ctx, 0)
+	assert.ErrorIs(t, err, engine.ErrNoDepthLimit)
+
+	list, err := e.Explain(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, list, len(e.Board().Position().LegalMoves(board.White)))
+
+	assert.Equal(t, "Qd1*d4", list[0].Move.String())
+	assert.Equal(t, eval.HeuristicScore(9), list[0].Score)
+	assert.Equal(t, "capture", list[0].Rule)
+	assert.Len(t, list[0].PV, 1)
+	for i := 1; i < len(list); i++ {
+		assert.False(t, list[i-1].Score.Less(list[i].Score))
+	}
+}
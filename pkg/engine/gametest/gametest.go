@@ -0,0 +1,141 @@
+// Package gametest contains utilities for replaying historical games against an engine
+// configuration to find where a re-implementation diverges from the recorded moves.
+package gametest
+
+import (
+	"context"
+	"fmt"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/pgn"
+	"github.com/herohde/morlock/pkg/engine"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/herohde/morlock/pkg/search/searchctl"
+	"github.com/seekerror/stdlib/pkg/lang"
+	"strings"
+)
+
+// Config is an engine configuration for game replay.
+type Config struct {
+	// Name is the engine name.
+	Name string
+	// Root is the root search.
+	Root search.Search
+	// Depth is the search depth limit. Must be positive.
+	Depth uint
+	// Options are optional engine options.
+	Options []engine.Option
+}
+
+// New returns a new engine with the configuration.
+func (c Config) New(ctx context.Context) *engine.Engine {
+	opts := append([]engine.Option{engine.WithOptions(engine.Options{Depth: c.Depth})}, c.Options...)
+	return engine.New(ctx, c.Name, "", c.Root, opts...)
+}
+
+// Divergence is a position in the game where the engine selects a different move than
+// the recorded move.
+type Divergence struct {
+	// Ply is the zero-based move index in the game.
+	Ply int
+	// Position is the position before the move in FEN.
+	Position string
+	// Expected is the recorded move in the game.
+	Expected board.Move
+	// Actual is the engine move.
+	Actual search.PV
+	// Candidates are all legal moves with score, ordered best first.
+	Candidates []engine.RootMoveReport
+}
+
+func (d Divergence) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("move %v (%v): expected %v, actual %v\n", d.Ply/2+1, d.Position, d.Expected, d.Actual))
+	for i, c := range d.Candidates {
+		marker := " "
+		if c.Move.Equals(d.Expected) {
+			marker = "*"
+		}
+		sb.WriteString(fmt.Sprintf(" %v%2d. %v\n", marker, i+1, c))
+	}
+	return sb.String()
+}
+
+// Report holds the result of a game replay.
+type Report struct {
+	Game pgn.Game
+	Side board.Color
+	// Moves is the number of engine-side moves replayed.
+	Moves       int
+	Divergences []Divergence
+}
+
+// Matches returns the number of engine-side moves that matched the recorded game.
+func (r Report) Matches() int {
+	return r.Moves - len(r.Divergences)
+}
+
+func (r Report) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%v as %v: %v/%v moves match\n", r.Game, r.Side, r.Matches(), r.Moves))
+	for _, d := range r.Divergences {
+		sb.WriteString(d.String())
+	}
+	return sb.String()
+}
+
+// Replay steps through the game and searches each position where the given side is to move.
+// It reports where the selected move differs from the recorded one, along with candidate
+// lists and scores. Recorded moves are always played, regardless of divergence.
+func Replay(ctx context.Context, game pgn.Game, side board.Color, cfg Config) (Report, error) {
+	ret := Report{Game: game, Side: side}
+
+	e := cfg.New(ctx)
+	if err := e.Reset(ctx, game.Start()); err != nil {
+		return ret, err
+	}
+	layout := e.Board().Position().CastlingLayout()
+
+	for i, m := range game.Moves {
+		if e.Board().Turn() == side {
+			pv, err := analyze(ctx, e, cfg.Depth)
+			if err != nil {
+				return ret, fmt.Errorf("move %v: %w", i+1, err)
+			}
+
+			ret.Moves++
+			if len(pv.Moves) == 0 || !pv.Moves[0].Equals(m) {
+				list, err := e.Explain(ctx, cfg.Depth)
+				if err != nil {
+					return ret, fmt.Errorf("move %v: %w", i+1, err)
+				}
+
+				ret.Divergences = append(ret.Divergences, Divergence{
+					Ply:        i,
+					Position:   e.Position(),
+					Expected:   m,
+					Actual:     pv,
+					Candidates: list,
+				})
+			}
+		}
+
+		if err := e.Move(ctx, board.PrintUCIMove(m, layout, false)); err != nil {
+			return ret, fmt.Errorf("move %v: %w", i+1, err)
+		}
+	}
+	return ret, nil
+}
+
+func analyze(ctx context.Context, e *engine.Engine, depth uint) (search.PV, error) {
+	out, err := e.Analyze(ctx, searchctl.Options{DepthLimit: lang.Some(depth), Analysis: true})
+	if err != nil {
+		return search.PV{}, err
+	}
+
+	var last search.PV
+	for pv := range out {
+		last = pv
+	}
+	_, _ = e.Halt(ctx)
+	return last, nil
+}
@@ -0,0 +1,69 @@
+package gametest_test
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/pgn"
+	"github.com/herohde/morlock/pkg/engine/gametest"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestReplay(t *testing.T) {
+	ctx := context.Background()
+
+	games, err := pgn.Parse(`[FEN "4k3/8/8/8/3q4/8/8/3QK3 w - - 0 1"]
+
+1. Qd2 Qxd2+ 2. Kxd2 *`)
+	require.NoError(t, err)
+
+	cfg := gametest.Config{
+		Name:  "material",
+		Root:  search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}},
+		Depth: 2,
+	}
+
+	r, err := gametest.Replay(ctx, games[0], board.White, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 2, r.Moves)
+	assert.Equal(t, 1, r.Matches())
+	require.Len(t, r.Divergences, 1)
+
+	d := r.Divergences[0]
+	assert.Equal(t, 0, d.Ply)
+	assert.Equal(t, "Qd1-d2", d.Expected.String())
+	assert.Equal(t, "Qd1*d4", d.Actual.Moves[0].String())
+	assert.Equal(t, "Qd1*d4", d.Candidates[0].Move.String())
+	assert.Equal(t, eval.HeuristicScore(-9), d.Candidates[len(d.Candidates)-1].Score)
+
+	r, err = gametest.Replay(ctx, games[0], board.Black, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 1, r.Moves)
+	assert.Empty(t, r.Divergences)
+}
+
+func TestReferences(t *testing.T) {
+	ctx := context.Background()
+
+	games, err := pgn.Parse(`[FEN "4k3/8/8/8/3q4/8/8/3QK3 w - - 0 1"]
+
+1. Qd2 Qxd2+ 2. Kxd2 *`)
+	require.NoError(t, err)
+
+	refs := gametest.References(1, 2)
+	require.Len(t, refs, 2)
+	assert.Equal(t, "random", refs[0].Name)
+	assert.Equal(t, "material-2ply", refs[1].Name)
+
+	static := gametest.Static("material", eval.Material{})
+	assert.Equal(t, "material-static", static.Name)
+
+	for _, cfg := range append(refs, static) {
+		r, err := gametest.Replay(ctx, games[0], board.White, cfg)
+		require.NoError(t, err)
+		assert.Equal(t, 2, r.Moves)
+	}
+}
@@ -0,0 +1,46 @@
+package gametest
+
+import (
+	"fmt"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/search"
+)
+
+// Random returns the random mover reference configuration, which plays a uniformly random
+// legal move. It is the weakest possible anchor for strength measurements. The seed makes
+// the moves reproducible.
+func Random(seed int64) Config {
+	return Config{
+		Name:  "random",
+		Root:  search.NewRandom(seed),
+		Depth: 1,
+	}
+}
+
+// Material returns the material-only reference configuration at the given depth, which is
+// morlock with a full-width search and material evaluation only.
+func Material(depth uint) Config {
+	return Config{
+		Name:  fmt.Sprintf("material-%vply", depth),
+		Root:  search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}},
+		Depth: depth,
+	}
+}
+
+// Static returns the static reference configuration for the given evaluator, which plays the
+// move with the best static evaluation without search. It isolates the behavior of the
+// evaluation from the behavior of the search.
+func Static(name string, ev eval.Evaluator) Config {
+	return Config{
+		Name:  fmt.Sprintf("%v-static", name),
+		Root:  search.Static{Eval: search.Leaf{Eval: ev}},
+		Depth: 1,
+	}
+}
+
+// References returns the reference configurations used as calibrated anchors when measuring
+// the strength of the historical engines: the random mover and material-only morlock at
+// the given depth.
+func References(seed int64, depth uint) []Config {
+	return []Config{Random(seed), Material(depth)}
+}
@@ -0,0 +1,120 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/seekerror/logw"
+	"sort"
+	"strings"
+)
+
+// AuxTable is an auxiliary table, such as a pawn hash or evaluation cache, whose memory is
+// accounted against the engine memory budget. Must be thread-safe.
+type AuxTable interface {
+	// Resize clears and resizes the table to at most the given size in bytes.
+	Resize(ctx context.Context, size uint64)
+	// Size returns the size of the table in bytes.
+	Size() uint64
+}
+
+// WithAuxTable registers an auxiliary table under the given name with a requested size in MB.
+// The table is resized on reset, subject to the memory budget.
+func WithAuxTable(name string, table AuxTable, mb uint) Option {
+	return func(e *Engine) {
+		e.aux = append(e.aux, auxTable{name: name, table: table, mb: mb})
+	}
+}
+
+type auxTable struct {
+	name  string
+	table AuxTable
+	mb    uint
+}
+
+// Memory holds the allocated table sizes in bytes by name. The transposition table is
+// named "hash".
+type Memory map[string]uint64
+
+// Total returns the total allocated size in bytes.
+func (m Memory) Total() uint64 {
+	var ret uint64
+	for _, v := range m {
+		ret += v
+	}
+	return ret
+}
+
+func (m Memory) String() string {
+	var names []string
+	for k := range m {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var parts []string
+	for _, k := range names {
+		parts = append(parts, fmt.Sprintf("%v=%vKB", k, m[k]>>10))
+	}
+	return fmt.Sprintf("{%v, total=%vKB}", strings.Join(parts, ", "), m.Total()>>10)
+}
+
+// Memory returns the current memory allocation of the transposition table and all
+// auxiliary tables.
+func (e *Engine) Memory() Memory {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ret := Memory{"hash": e.tt.Size()}
+	for _, aux := range e.aux {
+		ret[aux.name] = aux.table.Size()
+	}
+	return ret
+}
+
+// SetMemory sets the total memory budget in MB, effective from the next game.
+func (e *Engine) SetMemory(mb uint) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.opts.Memory = mb
+}
+
+// allocate returns the requested sizes in bytes of the transposition table and auxiliary
+// tables, scaled down proportionally if over the memory budget.
+func allocate(budget, hash uint, aux []auxTable) (uint64, []uint64) {
+	sizes := []uint64{uint64(hash) << 20}
+	total := sizes[0]
+	for _, a := range aux {
+		sizes = append(sizes, uint64(a.mb)<<20)
+		total += uint64(a.mb) << 20
+	}
+
+	if limit := uint64(budget) << 20; budget > 0 && total > limit {
+		for i := range sizes {
+			sizes[i] = sizes[i] * limit / total
+		}
+	}
+	return sizes[0], sizes[1:]
+}
+
+// resizeTables (re)allocates all tables. Must be called with the lock held.
+func (e *Engine) resizeTables(ctx context.Context) {
+	size := e.opts.Hash
+	if e.caps.NoTT {
+		size = 0 // not sound
+	}
+	hash, aux := allocate(e.opts.Memory, size, e.aux)
+
+	e.tt = search.NoTranspositionTable{}
+	if hash > 0 {
+		e.tt = e.factory(ctx, hash)
+	}
+	for i, a := range e.aux {
+		a.table.Resize(ctx, aux[i])
+	}
+
+	if e.opts.Memory > 0 {
+		logw.Infof(ctx, "Memory budget %vMB: hash=%vKB, aux=%v", e.opts.Memory, hash>>10, aux)
+	}
+}
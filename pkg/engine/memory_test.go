@@ -0,0 +1,85 @@
+package engine_test
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/engine"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/herohde/morlock/pkg/search/searchctl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+
+	root := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+	cache := eval.NewCache(eval.Material{})
+
+	// (1) No budget: requested sizes.
+
+	e := engine.New(ctx, "test", "test", root,
+		engine.WithOptions(engine.Options{Hash: 4}),
+		engine.WithAuxTable("evalcache", cache, 4),
+	)
+	assert.Equal(t, engine.Memory{"hash": 4 << 20, "evalcache": 4 << 20}, e.Memory())
+
+	// (2) Budget: scaled down proportionally.
+
+	e.SetMemory(4)
+	require.NoError(t, e.Reset(ctx, fen.Initial))
+	assert.Equal(t, engine.Memory{"hash": 2 << 20, "evalcache": 2 << 20}, e.Memory())
+	assert.LessOrEqual(t, e.Memory().Total(), uint64(4<<20))
+
+	// (3) Budget larger than requested: unchanged.
+
+	e.SetMemory(64)
+	require.NoError(t, e.Reset(ctx, fen.Initial))
+	assert.Equal(t, uint64(8<<20), e.Memory().Total())
+}
+
+func TestClearHash(t *testing.T) {
+	ctx := context.Background()
+
+	var tables []search.TranspositionTable
+	factory := func(ctx context.Context, size uint64) search.TranspositionTable {
+		tt := search.NewTranspositionTable(ctx, size)
+		tables = append(tables, tt)
+		return tt
+	}
+
+	root := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+	e := engine.New(ctx, "test", "test", root,
+		engine.WithOptions(engine.Options{Depth: 3, Hash: 1}),
+		engine.WithTable(factory),
+	)
+	require.Len(t, tables, 1)
+
+	analyze := func() {
+		out, err := e.Analyze(ctx, searchctl.Options{})
+		require.NoError(t, err)
+		for range out {
+		}
+		_, _ = e.Halt(ctx)
+	}
+
+	// (1) Clear Hash: new empty table.
+
+	analyze()
+	assert.Greater(t, tables[0].Used(), 0.0)
+
+	e.ClearHash(ctx)
+	require.Len(t, tables, 2)
+	assert.Equal(t, 0.0, tables[1].Used())
+
+	// (2) New game: new empty table with the current size.
+
+	analyze()
+	e.SetHash(2)
+	e.NewGame(ctx)
+	require.Len(t, tables, 3)
+	assert.Equal(t, 0.0, tables[2].Used())
+	assert.Equal(t, uint64(2<<20), e.Memory()["hash"])
+}
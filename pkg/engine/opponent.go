@@ -0,0 +1,142 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/seekerror/stdlib/pkg/lang"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Opponent holds information about the opponent, such as provided by the UCI_Opponent option.
+type Opponent struct {
+	// Title is the title, such as "GM" or "IM". Empty if none.
+	Title string
+	// Elo is the rating, if known.
+	Elo lang.Optional[int]
+	// Computer is true iff the opponent is a computer.
+	Computer bool
+	// Name is the name, such as "Gary Kasparov". May contain spaces.
+	Name string
+}
+
+// ParseOpponent parses an opponent in the UCI_Opponent format:
+//
+//	[GM|IM|FM|WGM|WIM|none] [<elo>|none] [computer|human] <name>
+//
+// For example, "GM 2800 human Gary Kasparov" or "none none computer Shredder".
+func ParseOpponent(str string) (Opponent, error) {
+	parts := strings.Fields(str)
+	if len(parts) < 4 {
+		return Opponent{}, fmt.Errorf("invalid opponent: '%v'", str)
+	}
+
+	var ret Opponent
+	if !strings.EqualFold(parts[0], "none") {
+		ret.Title = strings.ToUpper(parts[0])
+	}
+	if !strings.EqualFold(parts[1], "none") {
+		elo, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return Opponent{}, fmt.Errorf("invalid opponent elo: '%v'", str)
+		}
+		ret.Elo = lang.Some(elo)
+	}
+	switch strings.ToLower(parts[2]) {
+	case "computer":
+		ret.Computer = true
+	case "human":
+		ret.Computer = false
+	default:
+		return Opponent{}, fmt.Errorf("invalid opponent type: '%v'", str)
+	}
+	ret.Name = strings.Join(parts[3:], " ")
+	return ret, nil
+}
+
+func (o Opponent) String() string {
+	title := "none"
+	if o.Title != "" {
+		title = o.Title
+	}
+	elo := "none"
+	if v, ok := o.Elo.V(); ok {
+		elo = strconv.Itoa(v)
+	}
+	kind := "human"
+	if o.Computer {
+		kind = "computer"
+	}
+	return fmt.Sprintf("%v %v %v %v", title, elo, kind, o.Name)
+}
+
+// OpponentAwareBook is an opening book that can select lines based on the opponent.
+type OpponentAwareBook interface {
+	Book
+	// FindForOpponent returns a list -- potentially empty -- of moves given a position
+	// and the opponent.
+	FindForOpponent(ctx context.Context, opp Opponent, fen string) ([]board.Move, error)
+}
+
+// FindBookMoves returns the book moves for the given position. If the opponent is known
+// and the book is opponent-aware, the opponent is used to select lines.
+func FindBookMoves(ctx context.Context, book Book, opp lang.Optional[Opponent], fen string) ([]board.Move, error) {
+	if aware, ok := book.(OpponentAwareBook); ok {
+		if o, ok := opp.V(); ok {
+			return aware.FindForOpponent(ctx, o, fen)
+		}
+	}
+	return book.Find(ctx, fen)
+}
+
+// RatedBook is an opening book for opponents rated below a given Elo.
+type RatedBook struct {
+	Below int
+	Book  Book
+}
+
+// OpponentBook is an opponent-aware book that keys books by opponent name or rating, such
+// as playing sharper lines against weaker opponents. Names take precedence over ratings,
+// and the lowest matching rating takes precedence over higher ones.
+type OpponentBook struct {
+	// Default is the book used if no other book applies. Required.
+	Default Book
+	// Names holds books for specific opponents by case-insensitive name.
+	Names map[string]Book
+	// Ratings holds books for rated opponents.
+	Ratings []RatedBook
+}
+
+func (b OpponentBook) Find(ctx context.Context, fen string) ([]board.Move, error) {
+	return b.Default.Find(ctx, fen)
+}
+
+func (b OpponentBook) FindForOpponent(ctx context.Context, opp Opponent, fen string) ([]board.Move, error) {
+	return b.Select(opp).Find(ctx, fen)
+}
+
+// Select returns the book for the given opponent.
+func (b OpponentBook) Select(opp Opponent) Book {
+	for name, book := range b.Names {
+		if strings.EqualFold(name, opp.Name) {
+			return book
+		}
+	}
+
+	if elo, ok := opp.Elo.V(); ok {
+		rated := make([]RatedBook, len(b.Ratings))
+		copy(rated, b.Ratings)
+		sort.SliceStable(rated, func(i, j int) bool {
+			return rated[i].Below < rated[j].Below
+		})
+
+		for _, r := range rated {
+			if elo < r.Below {
+				return r.Book
+			}
+		}
+	}
+	return b.Default
+}
@@ -0,0 +1,68 @@
+package engine_test
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/engine"
+	"github.com/seekerror/stdlib/pkg/lang"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseOpponent(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected engine.Opponent
+	}{
+		{"GM 2800 human Gary Kasparov", engine.Opponent{Title: "GM", Elo: lang.Some(2800), Name: "Gary Kasparov"}},
+		{"none none computer Shredder", engine.Opponent{Computer: true, Name: "Shredder"}},
+		{"wim 2100 human Judit", engine.Opponent{Title: "WIM", Elo: lang.Some(2100), Name: "Judit"}},
+	}
+
+	for _, tt := range tests {
+		actual, err := engine.ParseOpponent(tt.in)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, actual)
+	}
+
+	for _, in := range []string{"", "GM 2800 human", "GM strong human Gary", "GM 2800 alien Gary"} {
+		_, err := engine.ParseOpponent(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestOpponentBook(t *testing.T) {
+	ctx := context.Background()
+
+	solid, err := engine.NewBook([]engine.Line{{"d2d4"}})
+	require.NoError(t, err)
+	sharp, err := engine.NewBook([]engine.Line{{"e2e4"}})
+	require.NoError(t, err)
+	gambit, err := engine.NewBook([]engine.Line{{"f2f4"}})
+	require.NoError(t, err)
+
+	book := engine.OpponentBook{
+		Default: solid,
+		Names:   map[string]engine.Book{"Shredder": gambit},
+		Ratings: []engine.RatedBook{{Below: 1800, Book: sharp}},
+	}
+
+	tests := []struct {
+		opp      lang.Optional[engine.Opponent]
+		expected string
+	}{
+		{lang.Optional[engine.Opponent]{}, "d2-d4"},
+		{lang.Some(engine.Opponent{Name: "Unknown"}), "d2-d4"},
+		{lang.Some(engine.Opponent{Elo: lang.Some(2400), Name: "Strong"}), "d2-d4"},
+		{lang.Some(engine.Opponent{Elo: lang.Some(1200), Name: "Weak"}), "e2-e4"},
+		{lang.Some(engine.Opponent{Elo: lang.Some(3000), Computer: true, Name: "shredder"}), "f2-f4"},
+	}
+
+	for _, tt := range tests {
+		moves, err := engine.FindBookMoves(ctx, book, tt.opp, fen.Initial)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, board.PrintMoves(moves))
+	}
+}
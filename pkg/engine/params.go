@@ -0,0 +1,47 @@
+package engine
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/seekerror/logw"
+	"io"
+)
+
+// WithTunable registers the tunable evaluation parameters of the engine, so that they can be
+// exported and imported as JSON.
+func WithTunable(t eval.Tunable) Option {
+	return func(e *Engine) {
+		e.tunable = t
+	}
+}
+
+// ExportParameters writes the tunable evaluation parameters as JSON.
+func (e *Engine) ExportParameters(w io.Writer) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.tunable == nil {
+		return ErrNotTunable
+	}
+	return eval.ExportParameters(w, e.tunable)
+}
+
+// ImportParameters reads tunable evaluation parameters as JSON. Any active search is halted
+// and all tables are cleared, because cached scores are then stale.
+func (e *Engine) ImportParameters(ctx context.Context, r io.Reader) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.tunable == nil {
+		return ErrNotTunable
+	}
+
+	_, _ = e.haltSearchIfActive(ctx)
+	if err := eval.ImportParameters(r, e.tunable); err != nil {
+		return err
+	}
+	e.resizeTables(ctx)
+
+	logw.Infof(ctx, "Imported parameters: %v", e.tunable.Parameters())
+	return nil
+}
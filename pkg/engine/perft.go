@@ -0,0 +1,54 @@
+package engine
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board"
+)
+
+// Divide is the perft count of the subtree after a move.
+type Divide struct {
+	Move  board.Move
+	Nodes uint64
+}
+
+// Perft counts the leaf nodes of the legal move tree of the current position to the given
+// depth, divided by initial move. Convenient for checking move generation from an edited
+// position. It does not affect the current game.
+func (e *Engine) Perft(ctx context.Context, depth int) []Divide {
+	e.mu.Lock()
+	pos, turn := *e.b.Position(), e.b.Turn()
+	e.mu.Unlock()
+
+	if depth < 1 {
+		return nil
+	}
+
+	var ret []Divide
+	for _, m := range pos.LegalMoves(turn) {
+		undo, _ := pos.MakeMove(m)
+		ret = append(ret, Divide{Move: m, Nodes: perft(&pos, turn.Opponent(), depth-1)})
+		pos.UnmakeMove(m, undo)
+	}
+	return ret
+}
+
+// perft counts the leaf nodes of the legal move tree. The legal moves are counted directly
+// at depth 1.
+func perft(pos *board.Position, turn board.Color, depth int) uint64 {
+	if depth == 0 {
+		return 1
+	}
+
+	moves := pos.LegalMoves(turn)
+	if depth == 1 {
+		return uint64(len(moves))
+	}
+
+	var ret uint64
+	for _, m := range moves {
+		undo, _ := pos.MakeMove(m)
+		ret += perft(pos, turn.Opponent(), depth-1)
+		pos.UnmakeMove(m, undo)
+	}
+	return ret
+}
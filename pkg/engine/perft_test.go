@@ -0,0 +1,34 @@
+package engine_test
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/engine"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestPerft(t *testing.T) {
+	ctx := context.Background()
+
+	root := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+	e := engine.New(ctx, "test", "test", root)
+
+	sum := func(list []engine.Divide) uint64 {
+		var ret uint64
+		for _, d := range list {
+			ret += d.Nodes
+		}
+		return ret
+	}
+
+	assert.Len(t, e.Perft(ctx, 1), 20)
+	assert.Equal(t, uint64(8902), sum(e.Perft(ctx, 3)))
+
+	require.NoError(t, e.Reset(ctx, "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1"))
+	before := e.Position()
+	assert.Equal(t, uint64(97862), sum(e.Perft(ctx, 3)))
+	assert.Equal(t, before, e.Position()) // game unaffected
+}
@@ -0,0 +1,269 @@
+package engine
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NumPolyglotKeys is the number of random numbers that define Polyglot position keys: 768 for
+// pieces on squares, 4 for castling rights, 8 for en passant files and 1 for the turn.
+const NumPolyglotKeys = 781
+
+// PolyglotKeys is the table of random numbers that defines Polyglot position keys. Books are
+// only compatible with other programs if the standard table, StandardPolyglotKeys, is used.
+type PolyglotKeys [NumPolyglotKeys]uint64
+
+// ReadPolyglotKeys reads the table of random numbers as hexadecimal values separated by
+// whitespace or commas. C-style "0x" prefixes and "ULL" suffixes are allowed, so that the
+// table can be copied verbatim from the specification.
+func ReadPolyglotKeys(r io.Reader) (*PolyglotKeys, error) {
+	var ret PolyglotKeys
+
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanWords)
+
+	n := 0
+	for scanner.Scan() {
+		for _, str := range strings.Split(scanner.Text(), ",") {
+			str = strings.TrimRight(strings.TrimPrefix(strings.ToLower(str), "0x"), "ul")
+			if str == "" {
+				continue
+			}
+			if n == NumPolyglotKeys {
+				return nil, fmt.Errorf("too many keys: expected %v", NumPolyglotKeys)
+			}
+			v, err := strconv.ParseUint(str, 16, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid key %v: %v", n, err)
+			}
+			ret[n] = v
+			n++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if n != NumPolyglotKeys {
+		return nil, fmt.Errorf("too few keys: %v, expected %v", n, NumPolyglotKeys)
+	}
+	return &ret, nil
+}
+
+// Key returns the Polyglot key of the position. The en passant file is only included if a
+// pawn of the side to move can capture en passant.
+func (k *PolyglotKeys) Key(pos *board.Position, turn board.Color) uint64 {
+	var ret uint64
+	for sq := board.ZeroSquare; sq < board.NumSquares; sq++ {
+		if c, p, ok := pos.Square(sq); ok {
+			ret ^= k[64*polyglotPiece(c, p)+8*sq.Rank().V()+polyglotFile(sq.File())]
+		}
+	}
+
+	for i, right := range []board.Castling{board.WhiteKingSideCastle, board.WhiteQueenSideCastle, board.BlackKingSideCastle, board.BlackQueenSideCastle} {
+		if pos.Castling().IsAllowed(right) {
+			ret ^= k[768+i]
+		}
+	}
+
+	if ep, ok := pos.EnPassant(); ok && canCaptureEnPassant(pos, turn, ep) {
+		ret ^= k[772+polyglotFile(ep.File())]
+	}
+
+	if turn == board.White {
+		ret ^= k[780]
+	}
+	return ret
+}
+
+// PolyglotMove returns the Polyglot encoding of the move. Castling is encoded as the King
+// capturing its own Rook.
+func PolyglotMove(pos *board.Position, m board.Move) uint16 {
+	to := m.To
+	if rook, _, ok := pos.CastlingRookMove(m); ok {
+		to = rook
+	}
+
+	var promo uint16
+	switch m.Promotion {
+	case board.Knight:
+		promo = 1
+	case board.Bishop:
+		promo = 2
+	case board.Rook:
+		promo = 3
+	case board.Queen:
+		promo = 4
+	}
+
+	return uint16(polyglotFile(to.File())) | uint16(to.Rank().V())<<3 |
+		uint16(polyglotFile(m.From.File()))<<6 | uint16(m.From.Rank().V())<<9 | promo<<12
+}
+
+// PolyglotEntry is a Polyglot book entry.
+type PolyglotEntry struct {
+	Key    uint64
+	Move   uint16
+	Weight uint16
+	Learn  uint32
+}
+
+// PolyglotEntries returns the Polyglot book entries of the tree, sorted by key and weight.
+// The weight of a move is its total score in half-points, so that lost moves are never
+// chosen.
+func (t *BookTree) PolyglotEntries(keys *PolyglotKeys) []PolyglotEntry {
+	var ret []PolyglotEntry
+	for _, node := range t.nodes {
+		key := keys.Key(node.pos, node.turn)
+		for _, e := range t.entries(node) {
+			weight := uint16(math.Min(math.Round(2*e.Points), math.MaxUint16))
+			if weight == 0 {
+				continue
+			}
+			ret = append(ret, PolyglotEntry{Key: key, Move: PolyglotMove(node.pos, e.Move), Weight: weight})
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Key != ret[j].Key {
+			return ret[i].Key < ret[j].Key
+		}
+		if ret[i].Weight != ret[j].Weight {
+			return ret[i].Weight > ret[j].Weight
+		}
+		return ret[i].Move < ret[j].Move
+	})
+	return ret
+}
+
+// WritePolyglot writes the entries in the binary Polyglot book format: 16 bytes per entry
+// in big-endian order.
+func WritePolyglot(w io.Writer, entries []PolyglotEntry) error {
+	buf := make([]byte, 16)
+	for _, e := range entries {
+		binary.BigEndian.PutUint64(buf[0:], e.Key)
+		binary.BigEndian.PutUint16(buf[8:], e.Move)
+		binary.BigEndian.PutUint16(buf[10:], e.Weight)
+		binary.BigEndian.PutUint32(buf[12:], e.Learn)
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadPolyglot reads entries in the binary Polyglot book format. The entries are returned
+// in file order, which is sorted by key for valid books.
+func ReadPolyglot(r io.Reader) ([]PolyglotEntry, error) {
+	var ret []PolyglotEntry
+
+	br := bufio.NewReader(r)
+	buf := make([]byte, 16)
+	for {
+		if _, err := io.ReadFull(br, buf); err != nil {
+			if err == io.EOF {
+				return ret, nil
+			}
+			return nil, fmt.Errorf("entry %v: %v", len(ret), err)
+		}
+		ret = append(ret, PolyglotEntry{
+			Key:    binary.BigEndian.Uint64(buf[0:]),
+			Move:   binary.BigEndian.Uint16(buf[8:]),
+			Weight: binary.BigEndian.Uint16(buf[10:]),
+			Learn:  binary.BigEndian.Uint32(buf[12:]),
+		})
+	}
+}
+
+// NewPolyglotBook returns an opening book of the given Polyglot entries. Moves with zero
+// weight are never chosen.
+func NewPolyglotBook(keys *PolyglotKeys, entries []PolyglotEntry) Book {
+	sorted := make([]PolyglotEntry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Key < sorted[j].Key
+	})
+	return &polyglotBook{keys: keys, entries: sorted}
+}
+
+type polyglotBook struct {
+	keys    *PolyglotKeys
+	entries []PolyglotEntry // sorted by key
+}
+
+func (b *polyglotBook) Find(ctx context.Context, position string) ([]board.Move, error) {
+	pos, turn, _, _, err := fen.Decode(position)
+	if err != nil {
+		return nil, err
+	}
+
+	key := b.keys.Key(pos, turn)
+	legal := pos.LegalMoves(turn)
+
+	var ret []board.Move
+	for i := sort.Search(len(b.entries), func(i int) bool { return b.entries[i].Key >= key }); i < len(b.entries) && b.entries[i].Key == key; i++ {
+		if b.entries[i].Weight == 0 {
+			continue
+		}
+		for _, m := range legal {
+			if PolyglotMove(pos, m) == b.entries[i].Move {
+				ret = append(ret, m)
+				break
+			}
+		}
+	}
+	return ret, nil
+}
+
+// polyglotPiece returns the Polyglot piece kind: black pawn=0, white pawn=1, .., white king=11.
+func polyglotPiece(c board.Color, p board.Piece) int {
+	var kind int
+	switch p {
+	case board.Pawn:
+		kind = 0
+	case board.Knight:
+		kind = 1
+	case board.Bishop:
+		kind = 2
+	case board.Rook:
+		kind = 3
+	case board.Queen:
+		kind = 4
+	case board.King:
+		kind = 5
+	}
+	if c == board.White {
+		return 2*kind + 1
+	}
+	return 2 * kind
+}
+
+// polyglotFile returns the Polyglot file: a=0, .., h=7.
+func polyglotFile(f board.File) int {
+	return int(board.FileA - f)
+}
+
+// canCaptureEnPassant returns true iff a pawn of the given color is next to the pawn that
+// can be captured en passant on the target square.
+func canCaptureEnPassant(pos *board.Position, turn board.Color, target board.Square) bool {
+	rank := board.Rank5
+	if turn == board.Black {
+		rank = board.Rank4
+	}
+	for _, f := range []int{target.File().V() - 1, target.File().V() + 1} {
+		if f < 0 || f >= int(board.NumFiles) {
+			continue
+		}
+		if c, p, ok := pos.Square(board.NewSquare(board.File(f), rank)); ok && c == turn && p == board.Pawn {
+			return true
+		}
+	}
+	return false
+}
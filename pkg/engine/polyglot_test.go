@@ -0,0 +1,157 @@
+package engine_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/board/pgn"
+	"github.com/herohde/morlock/pkg/engine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestPolyglot(t *testing.T) {
+	// Use a custom table, as read from a keys file.
+
+	r := rand.New(rand.NewSource(1))
+	var sb strings.Builder
+	for i := 0; i < engine.NumPolyglotKeys; i++ {
+		_, _ = fmt.Fprintf(&sb, "0x%016XULL,\n", r.Uint64())
+	}
+	keys, err := engine.ReadPolyglotKeys(strings.NewReader(sb.String()))
+	require.NoError(t, err)
+
+	_, err = engine.ReadPolyglotKeys(strings.NewReader("0x1 0x2"))
+	assert.Error(t, err)
+
+	key := func(pos string) uint64 {
+		p, turn, _, _, err := fen.Decode(pos)
+		require.NoError(t, err)
+		return keys.Key(p, turn)
+	}
+
+	// (1) Keys: en passant only if capture is possible.
+
+	assert.NotEqual(t, key(fen.Initial), key("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR b KQkq - 0 1"))
+	assert.NotEqual(t, key(fen.Initial), key("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w Kkq - 0 1"))
+	assert.Equal(t, key("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1"), key("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1"))
+	assert.NotEqual(t, key("rnbqkbnr/ppp1pppp/8/8/3pP3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1"), key("rnbqkbnr/ppp1pppp/8/8/3pP3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1"))
+
+	// (2) Moves: castling is King captures Rook.
+
+	tests := []struct {
+		pos      string
+		move     string
+		expected uint16
+	}{
+		{fen.Initial, "e2e4", 4<<6 | 1<<9 | 4 | 3<<3},
+		{"4k3/8/8/8/8/8/8/4K2R w K - 0 1", "e1g1", 4<<6 | 7},
+		{"4k3/8/8/8/8/8/8/R3K3 w Q - 0 1", "e1c1", 4<<6 | 0},
+		{"4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b7b8n", 1<<6 | 6<<9 | 1 | 7<<3 | 1<<12},
+	}
+
+	for _, tt := range tests {
+		p, turn, _, _, err := fen.Decode(tt.pos)
+		require.NoError(t, err)
+		candidate, err := board.ParseMove(tt.move)
+		require.NoError(t, err)
+
+		var found bool
+		for _, m := range p.PseudoLegalMoves(turn) {
+			if m.Equals(candidate) {
+				assert.Equal(t, tt.expected, engine.PolyglotMove(p, m), "move: %v", tt.move)
+				found = true
+			}
+		}
+		assert.True(t, found, "move: %v", tt.move)
+	}
+
+	// (3) Entries: sorted by key and written in big-endian order.
+
+	entries := []engine.PolyglotEntry{{Key: 1, Move: 2, Weight: 3, Learn: 4}, {Key: 5}}
+	var buf bytes.Buffer
+	require.NoError(t, engine.WritePolyglot(&buf, entries))
+	require.Equal(t, 32, buf.Len())
+	assert.Equal(t, uint64(1), binary.BigEndian.Uint64(buf.Bytes()[0:]))
+	assert.Equal(t, uint16(2), binary.BigEndian.Uint16(buf.Bytes()[8:]))
+	assert.Equal(t, uint16(3), binary.BigEndian.Uint16(buf.Bytes()[10:]))
+	assert.Equal(t, uint32(4), binary.BigEndian.Uint32(buf.Bytes()[12:]))
+
+	// (4) Tree: weights are half-points. Lost moves are omitted.
+
+	games, err := pgn.Parse("1. e4 e5 1-0\n\n1. e4 c5 1-0\n")
+	require.NoError(t, err)
+
+	tree := engine.NewBookTree(engine.BookOptions{})
+	for _, g := range games {
+		_, err := tree.Add(g)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []engine.PolyglotEntry{{Key: key(fen.Initial), Move: 4<<6 | 1<<9 | 4 | 3<<3, Weight: 4}}, tree.PolyglotEntries(keys))
+
+	// (5) Book: read back entries. Zero-weight moves are not chosen.
+
+	games, err = pgn.Parse("1. e4 e5 2. Nf3 1-0\n\n1. e4 c5 0-1\n\n1. d4 d5 0-1\n")
+	require.NoError(t, err)
+
+	tree = engine.NewBookTree(engine.BookOptions{})
+	for _, g := range games {
+		_, err := tree.Add(g)
+		require.NoError(t, err)
+	}
+	buf.Reset()
+	require.NoError(t, engine.WritePolyglot(&buf, tree.PolyglotEntries(keys)))
+
+	read, err := engine.ReadPolyglot(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, tree.PolyglotEntries(keys), read)
+
+	_, err = engine.ReadPolyglot(bytes.NewReader(make([]byte, 10)))
+	assert.Error(t, err)
+
+	ctx := context.Background()
+	book := engine.NewPolyglotBook(keys, append(read, engine.PolyglotEntry{Key: key(fen.Initial), Move: 3 | 3<<3 | 3<<6 | 1<<9}))
+
+	list, err := book.Find(ctx, fen.Initial)
+	require.NoError(t, err)
+	assert.Equal(t, "e2-e4", board.PrintMoves(list))
+
+	list, err = book.Find(ctx, "rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/RNBQKBNR w KQkq e6 0 2")
+	require.NoError(t, err)
+	assert.Equal(t, "Ng1-f3", board.PrintMoves(list))
+
+	list, err = book.Find(ctx, "4k3/8/8/8/8/8/8/4K3 w - - 0 1")
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestStandardPolyglotKeys(t *testing.T) {
+	// Test keys from the Polyglot book format specification.
+
+	tests := []struct {
+		moves    []string
+		expected uint64
+	}{
+		{nil, 0x463b96181691fc9c},
+		{[]string{"e2e4"}, 0x823c9b50fd114196},
+		{[]string{"e2e4", "d7d5"}, 0x0756b94461c50fb0},
+		{[]string{"e2e4", "d7d5", "e4e5"}, 0x662fafb965db29d4},
+		{[]string{"e2e4", "d7d5", "e4e5", "f7f5"}, 0x22a48b5a8e47ff78},
+		{[]string{"e2e4", "d7d5", "e4e5", "f7f5", "e1e2"}, 0x652a607ca3f242c1},
+		{[]string{"e2e4", "d7d5", "e4e5", "f7f5", "e1e2", "e8f7"}, 0x00fdd303c946bdd9},
+		{[]string{"a2a4", "b7b5", "h2h4", "b5b4", "c2c4"}, 0x3c8123ea7b067637},
+		{[]string{"a2a4", "b7b5", "h2h4", "b5b4", "c2c4", "b4c3", "a1a3"}, 0x5c3f9b829b279560},
+	}
+
+	for _, tt := range tests {
+		b, err := fen.NewBoard(fen.Initial, tt.moves...)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, engine.StandardPolyglotKeys.Key(b.Position(), b.Turn()), "moves: %v", tt.moves)
+	}
+}
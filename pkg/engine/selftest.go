@@ -0,0 +1,168 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/search"
+	"time"
+)
+
+// Check is the result of a self-test check.
+type Check struct {
+	Name     string
+	Err      error // nil if passed
+	Duration time.Duration
+}
+
+// Passed returns true iff the check passed.
+func (c Check) Passed() bool {
+	return c.Err == nil
+}
+
+func (c Check) String() string {
+	if c.Err != nil {
+		return fmt.Sprintf("FAIL %v: %v", c.Name, c.Err)
+	}
+	return fmt.Sprintf("PASS %v (%v)", c.Name, c.Duration.Round(time.Millisecond))
+}
+
+// SelfTest runs a fast health check of move generation, FEN, the transposition table and
+// search, such as before a tournament. It does not affect the current game or tables. The
+// mate search uses a plain alpha-beta search, so that the check is independent of the engine
+// heuristics. The engine search is only checked to return a legal move.
+func (e *Engine) SelfTest(ctx context.Context) []Check {
+	e.mu.Lock()
+	root, zt := e.root, e.zt
+	e.mu.Unlock()
+
+	checks := []struct {
+		name string
+		fn   func(ctx context.Context) error
+	}{
+		{"perft", checkPerft},
+		{"fen", checkFEN},
+		{"tt", checkTranspositionTable},
+		{"mate", checkMate},
+		{"search", func(ctx context.Context) error {
+			return checkSearch(ctx, root, zt)
+		}},
+	}
+
+	var ret []Check
+	for _, c := range checks {
+		start := time.Now()
+		err := c.fn(ctx)
+		ret = append(ret, Check{Name: c.name, Err: err, Duration: time.Since(start)})
+	}
+	return ret
+}
+
+func checkPerft(ctx context.Context) error {
+	tests := []struct {
+		fen      string
+		depth    int
+		expected uint64
+	}{
+		{fen.Initial, 3, 8902},
+		{"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", 2, 2039},
+		{"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", 3, 2812},
+	}
+
+	for _, tt := range tests {
+		pos, turn, _, _, err := fen.Decode(tt.fen)
+		if err != nil {
+			return err
+		}
+		if actual := perft(pos, turn, tt.depth); actual != tt.expected {
+			return fmt.Errorf("perft(%v) of %v = %v, want %v", tt.depth, tt.fen, actual, tt.expected)
+		}
+	}
+	return nil
+}
+
+func checkFEN(ctx context.Context) error {
+	tests := []string{
+		fen.Initial,
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"rnbqkbnr/pp1ppppp/8/2p5/4P3/8/PPPP1PPP/RNBQKBNR w KQkq c6 0 2",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 b - - 12 40",
+	}
+
+	for _, tt := range tests {
+		pos, turn, np, fm, err := fen.Decode(tt)
+		if err != nil {
+			return err
+		}
+		if actual := fen.Encode(pos, turn, np, fm); actual != tt {
+			return fmt.Errorf("round trip of %v = %v", tt, actual)
+		}
+	}
+	return nil
+}
+
+func checkTranspositionTable(ctx context.Context) error {
+	tt := search.NewTranspositionTable(ctx, 1<<20)
+
+	hash := board.ZobristHash(0x123456789abcdef)
+	move := board.Move{From: board.E2, To: board.E4}
+	score := eval.HeuristicScore(1.5)
+
+	if _, _, _, _, ok := tt.Read(hash); ok {
+		return fmt.Errorf("unexpected entry in empty table")
+	}
+	if !tt.Write(hash, search.LowerBound, 3, 4, score, move) {
+		return fmt.Errorf("write failed")
+	}
+	bound, depth, s, m, ok := tt.Read(hash)
+	if !ok || bound != search.LowerBound || depth != 4 || s != score || !m.Equals(move) {
+		return fmt.Errorf("read mismatch: %v, %v, %v, %v, %v", ok, bound, depth, s, m)
+	}
+	if _, _, _, _, ok := tt.Read(hash ^ 1); ok {
+		return fmt.Errorf("unexpected entry for different hash")
+	}
+	return nil
+}
+
+func checkMate(ctx context.Context) error {
+	// Mate-in-2: 1. Nf6+ gxf6 2. Bxf7#.
+
+	b, err := fen.NewBoard("r2qkb1r/pp2nppp/3p4/2pNN1B1/2BnP3/3P4/PPP2PPP/R2bK2R w KQkq - 1 1")
+	if err != nil {
+		return err
+	}
+
+	ab := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+	_, score, pv, err := ab.Search(ctx, search.EmptyContext, b, 4)
+	if err != nil {
+		return err
+	}
+	if score != eval.MateInXScore(3) || len(pv) == 0 || pv[0].String() != "Nd5-f6" {
+		return fmt.Errorf("mate-in-2 not found: score=%v, pv=%v", score, board.PrintMoves(pv))
+	}
+	return nil
+}
+
+func checkSearch(ctx context.Context, root search.Search, zt *board.ZobristTable) error {
+	pos, turn, np, fm, err := fen.Decode(fen.Initial)
+	if err != nil {
+		return err
+	}
+	b := board.NewBoard(zt, pos, turn, np, fm)
+
+	_, _, pv, err := root.Search(ctx, search.EmptyContext, b, 1)
+	if err != nil {
+		return err
+	}
+	if len(pv) == 0 {
+		return fmt.Errorf("no move found")
+	}
+	for _, m := range pos.LegalMoves(turn) {
+		if m.Equals(pv[0]) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %v", board.ErrIllegalMove, pv[0])
+}
@@ -0,0 +1,23 @@
+package engine_test
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/engine"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSelfTest(t *testing.T) {
+	ctx := context.Background()
+
+	root := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+	e := engine.New(ctx, "test", "test", root)
+
+	checks := e.SelfTest(ctx)
+	assert.Len(t, checks, 5)
+	for _, c := range checks {
+		assert.True(t, c.Passed(), "check: %v", c)
+	}
+}
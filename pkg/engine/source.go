@@ -0,0 +1,57 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// PositionSource is an external source of positions, such as a board-recognition tool
+// for diagrams or an electronic board. It allows "set up this position" workflows to
+// share a single code path regardless of where the position comes from.
+type PositionSource interface {
+	// Position returns the current position in FEN format. The FEN may be partial and
+	// contain only the piece placement. Arguments are source-specific, such as an image file.
+	Position(ctx context.Context, args ...string) (string, error)
+}
+
+// CommandSource is a position source that runs an external command, such as a board
+// recognition tool, with the given arguments followed by any source arguments. The command
+// is expected to print the position in FEN format on stdout.
+type CommandSource struct {
+	Name string
+	Args []string
+}
+
+func (c CommandSource) Position(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, c.Name, append(append([]string{}, c.Args...), args...)...)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("command %v failed: %w", c.Name, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Setup resets the engine to the position of the given source. A partial FEN is completed
+// with default values.
+func Setup(ctx context.Context, e *Engine, src PositionSource, args ...string) error {
+	pos, err := src.Position(ctx, args...)
+	if err != nil {
+		return err
+	}
+	return e.Reset(ctx, CompleteFEN(pos))
+}
+
+// CompleteFEN completes a partial FEN, such as only the piece placement, with default
+// values for any missing fields: white to move, no castling rights, no en passant square
+// and no progress.
+func CompleteFEN(pos string) string {
+	defaults := []string{"8/8/8/8/8/8/8/8", "w", "-", "-", "0", "1"}
+
+	parts := strings.Fields(pos)
+	if len(parts) < len(defaults) {
+		parts = append(parts, defaults[len(parts):]...)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,43 @@
+package engine_test
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/engine"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type staticSource string
+
+func (s staticSource) Position(ctx context.Context, args ...string) (string, error) {
+	return string(s), nil
+}
+
+func TestCompleteFEN(t *testing.T) {
+	tests := []struct {
+		in, expected string
+	}{
+		{"4k3/8/8/8/8/8/8/4K2R", "4k3/8/8/8/8/8/8/4K2R w - - 0 1"},
+		{"4k3/8/8/8/8/8/8/4K2R b", "4k3/8/8/8/8/8/8/4K2R b - - 0 1"},
+		{"4k3/8/8/8/8/8/8/4K2R w K - 3 40", "4k3/8/8/8/8/8/8/4K2R w K - 3 40"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, engine.CompleteFEN(tt.in))
+	}
+}
+
+func TestSetup(t *testing.T) {
+	ctx := context.Background()
+
+	root := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+	e := engine.New(ctx, "test", "test", root)
+
+	require.NoError(t, engine.Setup(ctx, e, staticSource("4k3/8/8/8/8/8/8/4K2R b")))
+	assert.Equal(t, "4k3/8/8/8/8/8/8/4K2R b - - 0 1", e.Position())
+
+	assert.Error(t, engine.Setup(ctx, e, staticSource("not a position")))
+}
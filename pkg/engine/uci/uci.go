@@ -12,11 +12,13 @@ import (
 	"github.com/herohde/morlock/pkg/engine"
 	"github.com/herohde/morlock/pkg/eval"
 	"github.com/herohde/morlock/pkg/search"
+	"github.com/herohde/morlock/pkg/search/pvfmt"
 	"github.com/herohde/morlock/pkg/search/searchctl"
 	"github.com/seekerror/logw"
 	"github.com/seekerror/stdlib/pkg/lang"
 	"github.com/seekerror/stdlib/pkg/util/iox"
-	"math/rand"
+	"github.com/seekerror/stdlib/pkg/util/mathx"
+	"math"
 	"strconv"
 	"strings"
 	"sync/atomic"
@@ -25,21 +27,50 @@ import (
 
 const ProtocolName = "uci"
 
+// maxMultiPV is the maximum number of lines in MultiPV mode.
+const maxMultiPV = 64
+
+// maxNoise is the maximum evaluation noise and blunder margin in millipawns.
+const maxNoise = 10_000
+
+// maxContempt is the maximum absolute draw contempt in millipawns.
+const maxContempt = 5_000
+
 // Option is an UCI driver option.
 type Option func(*options)
 
 type options struct {
-	useBook bool
-	book    engine.Book
-	rand    *rand.Rand
+	useBook  bool
+	book     engine.Book
+	bookFile string
+	fileBook engine.Book // book loaded from bookFile, if any. Takes precedence.
+	keys     *engine.PolyglotKeys
 }
 
-// UseBook instructs the driver to use the given opening book.
-func UseBook(book engine.Book, seed int64) Option {
+// UseBook instructs the driver to use the given opening book. Book moves are chosen using
+// the engine random seed.
+func UseBook(book engine.Book) Option {
 	return func(opt *options) {
 		opt.useBook = true
 		opt.book = book
-		opt.rand = rand.New(rand.NewSource(seed))
+	}
+}
+
+// UseBookFile instructs the driver to use the opening book loaded from the given file instead
+// of any book given by UseBook. It is the default value of the BookFile option.
+func UseBookFile(filename string, book engine.Book) Option {
+	return func(opt *options) {
+		opt.useBook = true
+		opt.bookFile = filename
+		opt.fileBook = book
+	}
+}
+
+// UsePolyglotKeys sets the Polyglot keys for loading Polyglot books with the BookFile option,
+// instead of the standard keys.
+func UsePolyglotKeys(keys *engine.PolyglotKeys) Option {
+	return func(opt *options) {
+		opt.keys = keys
 	}
 }
 
@@ -47,14 +78,20 @@ func UseBook(book engine.Book, seed int64) Option {
 type Driver struct {
 	iox.AsyncCloser
 
-	e   *engine.Engine
-	opt options
+	e           *engine.Engine
+	opt         options
+	defaultBook engine.Book // book if BookFile is empty, if any
 
 	out chan<- string
 
-	active       atomic.Bool    // user is waiting for engine to move
-	ponder       chan search.PV // chan for intermediate search information
-	lastPosition string         // last position line (empty if no last position)
+	active       atomic.Bool                          // user is waiting for engine to move
+	debug        atomic.Bool                          // send additional info strings
+	chess960     atomic.Bool                          // castling moves are written as King captures own Rook
+	layout       atomic.Pointer[board.CastlingLayout] // castling layout of current game
+	id           atomic.Uint64                        // search ID of latest search
+	ponder       chan search.PV                       // chan for intermediate search information
+	messages     chan string                          // chan for search messages to send as info strings
+	lastPosition string                               // last position line (empty if no last position)
 }
 
 func NewDriver(ctx context.Context, e *engine.Engine, in <-chan string, opts ...Option) (*Driver, <-chan string) {
@@ -63,13 +100,20 @@ func NewDriver(ctx context.Context, e *engine.Engine, in <-chan string, opts ...
 		fn(&opt)
 	}
 
+	defaultBook := opt.book
+	if opt.fileBook != nil {
+		opt.book = opt.fileBook
+	}
+
 	out := make(chan string, 100)
 	d := &Driver{
 		AsyncCloser: iox.NewAsyncCloser(),
 		e:           e,
 		opt:         opt,
+		defaultBook: defaultBook,
 		out:         out,
 		ponder:      make(chan search.PV, 400),
+		messages:    make(chan string, 100),
 	}
 	go d.process(ctx, in)
 
@@ -217,12 +261,28 @@ func (d *Driver) process(ctx context.Context, in <-chan string) {
 	//	   "option name NalimovPath type string default c:\\n"
 	//	   "option name Clear Hash type button\n"
 
-	d.out <- fmt.Sprintf("option name Depth type spin default %v min 0 max %v", d.e.Options().Depth, 100)
-	d.out <- fmt.Sprintf("option name Hash type spin default %v min 0 max %v", d.e.Options().Hash, 16<<10)
-	d.out <- fmt.Sprintf("option name Noise type spin default %v min 0 max %v", d.e.Options().Noise, 10_000)
-
-	if d.opt.book != nil {
-		d.out <- fmt.Sprintf("option name OwnBook type check default %v", d.opt.useBook)
+	d.out <- fmt.Sprintf("option name Depth type spin default %v min 0 max %v", d.e.Options().Depth, searchctl.MaxDepth)
+	caps := d.e.Capabilities()
+	if !caps.NoTT {
+		d.out <- fmt.Sprintf("option name Hash type spin default %v min 0 max %v", d.e.Options().Hash, 16<<10)
+		d.out <- "option name Clear Hash type button"
+	}
+	d.out <- fmt.Sprintf("option name Memory type spin default %v min 0 max %v", d.e.Options().Memory, 64<<10)
+	d.out <- fmt.Sprintf("option name Noise type spin default %v min 0 max %v", d.e.Options().Noise, maxNoise)
+	d.out <- fmt.Sprintf("option name NoiseDistribution type combo default %v var %v var %v", d.e.Options().NoiseDistribution, eval.Uniform, eval.Gaussian)
+	d.out <- fmt.Sprintf("option name NoiseSeeding type combo default %v var move var game", d.e.Options().NoiseSeeding())
+	d.out <- fmt.Sprintf("option name MultiPV type spin default %v min 1 max %v", mathx.Max(d.e.Options().MultiPV, 1), maxMultiPV)
+	d.out <- fmt.Sprintf("option name Seed type spin default %v min 0 max %v", d.e.Options().Seed, int64(math.MaxInt64))
+	d.out <- fmt.Sprintf("option name Reuse type check default %v", d.e.Options().Reuse)
+	d.out <- fmt.Sprintf("option name Contempt type spin default %v min %v max %v", d.e.Options().Contempt, -maxContempt, maxContempt)
+	d.out <- fmt.Sprintf("option name Blunder type spin default %v min 0 max 100", d.e.Options().Blunder)
+	d.out <- fmt.Sprintf("option name BlunderMargin type spin default %v min 0 max %v", d.e.Options().BlunderMargin, maxNoise)
+
+	d.out <- fmt.Sprintf("option name OwnBook type check default %v", d.opt.useBook)
+	d.out <- fmt.Sprintf("option name BookFile type string default %v", printOptionString(d.opt.bookFile))
+	d.out <- "option name UCI_Opponent type string default <empty>"
+	if !caps.NoChess960 {
+		d.out <- "option name UCI_Chess960 type check default false"
 	}
 
 	// * uciok
@@ -248,6 +308,8 @@ func (d *Driver) process(ctx context.Context, in <-chan string) {
 			cmd := parts[0]
 			args := parts[1:]
 
+			d.report(ctx, debugLevel, "received %v", strings.TrimSpace(line))
+
 			switch strings.ToLower(cmd) {
 			case "isready":
 				// * isready
@@ -281,6 +343,10 @@ func (d *Driver) process(ctx context.Context, in <-chan string) {
 				//	This mode should be switched off by default and this command can be sent
 				//	any time, also when the engine is thinking.
 
+				if len(args) > 0 {
+					d.debug.Store(args[0] == "on")
+				}
+
 			case "setoption":
 				// * setoption name <id> [value <x>]
 				//
@@ -297,26 +363,85 @@ func (d *Driver) process(ctx context.Context, in <-chan string) {
 				//	   "setoption name Clear Hash\n"
 				//	   "setoption name NalimovPath value c:\chess\tb\4;c:\chess\tb\5\n"
 
-				var name, value string
-				if len(args) > 1 {
-					name = args[1]
-				}
-				if len(args) > 3 {
-					value = args[3]
-				}
+				name, value := parseSetOption(args)
 
 				switch name {
 				case "OwnBook":
 					d.opt.useBook, _ = strconv.ParseBool(value)
+				case "BookFile":
+					if value == "" || value == "<empty>" {
+						d.opt.book, d.opt.bookFile = d.defaultBook, ""
+						break
+					}
+					book, err := engine.LoadBook(value, d.opt.keys)
+					if err != nil {
+						d.report(ctx, errorLevel, "invalid book file %v: %v", value, err)
+						break
+					}
+					d.opt.useBook, d.opt.book, d.opt.bookFile = true, book, value
+					d.report(ctx, infoLevel, "book file %v", value)
 				case "Hash":
 					hash, _ := strconv.Atoi(value)
 					d.e.SetHash(uint(hash))
+				case "Clear Hash":
+					d.e.ClearHash(ctx)
+				case "Memory":
+					memory, _ := strconv.Atoi(value)
+					d.e.SetMemory(uint(mathx.Max(memory, 0)))
 				case "Depth":
 					depth, _ := strconv.Atoi(value)
-					d.e.SetDepth(uint(depth))
+					d.e.SetDepth(uint(mathx.Min(mathx.Max(depth, 0), searchctl.MaxDepth)))
 				case "Noise":
 					noise, _ := strconv.Atoi(value)
-					d.e.SetNoise(uint(noise))
+					d.e.SetNoise(uint(mathx.Min(mathx.Max(noise, 0), maxNoise)))
+				case "NoiseDistribution":
+					dist, ok := eval.ParseDistribution(strings.ToLower(value))
+					if !ok {
+						logw.Warningf(ctx, "Ignoring invalid noise distribution: %v", value)
+						break
+					}
+					d.e.SetNoiseDistribution(dist)
+				case "NoiseSeeding":
+					switch strings.ToLower(value) {
+					case "move":
+						d.e.SetNoisePerGame(false)
+					case "game":
+						d.e.SetNoisePerGame(true)
+					default:
+						logw.Warningf(ctx, "Ignoring invalid noise seeding: %v", value)
+					}
+				case "MultiPV":
+					lines, _ := strconv.Atoi(value)
+					d.e.SetMultiPV(uint(mathx.Min(mathx.Max(lines, 1), maxMultiPV)))
+				case "Seed":
+					seed, _ := strconv.ParseInt(value, 10, 64)
+					d.e.SetSeed(mathx.Max(seed, 0))
+				case "Reuse":
+					reuse, _ := strconv.ParseBool(value)
+					d.e.SetReuse(reuse)
+				case "Contempt":
+					contempt, _ := strconv.Atoi(value)
+					d.e.SetContempt(mathx.Min(mathx.Max(contempt, -maxContempt), maxContempt))
+				case "Blunder":
+					percent, _ := strconv.Atoi(value)
+					d.e.SetBlunder(uint(mathx.Min(mathx.Max(percent, 0), 100)), d.e.Options().BlunderMargin)
+				case "BlunderMargin":
+					margin, _ := strconv.Atoi(value)
+					d.e.SetBlunder(d.e.Options().Blunder, uint(mathx.Min(mathx.Max(margin, 0), maxNoise)))
+				case "UCI_Chess960":
+					chess960, _ := strconv.ParseBool(value)
+					if chess960 && d.e.Capabilities().NoChess960 {
+						logw.Warningf(ctx, "Ignoring Chess960: not supported")
+						break
+					}
+					d.chess960.Store(chess960)
+				case "UCI_Opponent":
+					opp, err := engine.ParseOpponent(value)
+					if err != nil {
+						logw.Warningf(ctx, "Ignoring invalid opponent: %v", err)
+						break
+					}
+					d.e.SetOpponent(ctx, opp)
 				}
 
 			case "register":
@@ -349,6 +474,7 @@ func (d *Driver) process(ctx context.Context, in <-chan string) {
 				//   after "ucinewgame" to wait for the engine to finish its operation.
 
 				d.ensureInactive(ctx)
+				d.e.NewGame(ctx)
 				d.lastPosition = ""
 
 			case "position":
@@ -366,47 +492,47 @@ func (d *Driver) process(ctx context.Context, in <-chan string) {
 					// Continuation of game.
 
 					moves := strings.TrimSpace(strings.TrimPrefix(line, d.lastPosition))
-					for _, arg := range strings.Split(moves, " ") {
+
+					var applied int
+					var err error
+					for _, arg := range strings.Fields(moves) {
 						if arg == "moves" {
 							continue
 						}
+						if err = d.e.Move(ctx, arg); err != nil {
+							err = fmt.Errorf("invalid move '%v': %w", arg, err)
+							break
+						}
+						applied++
+					}
+					if err != nil {
+						// Keep the previous position.
 
-						if err := d.e.Move(ctx, arg); err != nil {
-							logw.Errorf(ctx, "Invalid position move '%v': %v: %v", arg, line, err)
-							return
+						for ; applied > 0; applied-- {
+							_ = d.e.TakeBack(ctx)
 						}
+						d.report(ctx, errorLevel, "invalid position: %v: %v", line, err)
+						break
 					}
 
 					d.lastPosition = line
 					break
 				}
 
-				// New position.
+				// New position. Apply all moves at once, which is faster for long games. The
+				// engine is unchanged if the position or any move is invalid.
 
-				position := fen.Initial
-				if len(args) >= 7 && args[0] == "fen" {
-					position = strings.Join(args[1:7], " ")
+				position, moves, err := parsePosition(args)
+				if err == nil {
+					err = d.e.SetGame(ctx, position, moves)
 				}
-				if err := d.e.Reset(ctx, position); err != nil {
-					logw.Errorf(ctx, "Invalid position: %v", line)
-					return
+				if err != nil {
+					d.report(ctx, errorLevel, "invalid position: %v: %v", line, err)
+					break
 				}
+				layout := d.e.Board().Position().CastlingLayout()
+				d.layout.Store(&layout)
 
-				move := false
-				for _, arg := range args {
-					if arg == "moves" {
-						move = true
-						continue
-					}
-					if !move {
-						continue
-					}
-
-					if err := d.e.Move(ctx, arg); err != nil {
-						logw.Errorf(ctx, "Invalid position move '%v': %v: %v", arg, line, err)
-						return
-					}
-				}
 				d.lastPosition = line
 
 			case "go":
@@ -454,9 +580,11 @@ func (d *Driver) process(ctx context.Context, in <-chan string) {
 
 				d.ensureInactive(ctx)
 
-				var opt searchctl.Options
+				opt := searchctl.Options{ID: search.NewID(), Progress: d.progress, Info: d.message}
+				d.id.Store(uint64(opt.ID))
+				d.report(ctx, infoLevel, "search %v: %v", opt.ID, line)
+
 				infinite := false
-				timeout := time.Duration(0)
 
 				useTimeControl := false
 				var timeControl searchctl.TimeControl
@@ -464,18 +592,18 @@ func (d *Driver) process(ctx context.Context, in <-chan string) {
 				for i := 0; i < len(args); i++ {
 					cmd := args[i]
 					switch cmd {
-					case "wtime", "btime", "movestogo", "depth", "movetime":
+					case "wtime", "btime", "winc", "binc", "movestogo", "depth", "movetime":
 						// Next argument is an int.
 
 						i++
 						if i == len(args) {
-							logw.Errorf(ctx, "No argument for %v: %v", cmd, line)
-							return
+							d.report(ctx, errorLevel, "no argument for %v: %v", cmd, line)
+							continue
 						}
 						n, err := strconv.Atoi(args[i])
-						if err != nil {
-							logw.Errorf(ctx, "Invalid argument for %v: %v", line, err)
-							return
+						if err != nil || n < 0 {
+							d.report(ctx, errorLevel, "invalid argument for %v: '%v': ignored", cmd, args[i])
+							continue
 						}
 
 						switch cmd {
@@ -487,48 +615,78 @@ func (d *Driver) process(ctx context.Context, in <-chan string) {
 						case "btime":
 							useTimeControl = true
 							timeControl.Black = time.Millisecond * time.Duration(n)
+						case "winc":
+							timeControl.WhiteInc = time.Millisecond * time.Duration(n)
+						case "binc":
+							timeControl.BlackInc = time.Millisecond * time.Duration(n)
 						case "movestogo":
 							useTimeControl = true
 							timeControl.Moves = n
 						case "movetime":
-							timeout = time.Millisecond * time.Duration(n)
+							useTimeControl = true
+							timeControl.MoveTime = time.Millisecond * time.Duration(n)
 						}
 
 					case "infinite":
 						infinite = true
 
+					case "searchmoves":
+						// All following arguments that are moves.
+
+						for i+1 < len(args) {
+							m, err := board.ParseMove(args[i+1])
+							if err != nil {
+								break
+							}
+							opt.SearchMoves = append(opt.SearchMoves, m)
+							i++
+						}
+
 					default:
 						// silently ignore anything not handled.
 					}
 				}
 
-				if useTimeControl {
+				opt.Analysis = infinite
+				if useTimeControl && !infinite {
 					opt.TimeControl = lang.Some(timeControl)
+					budget := timeControl
+					if d.e.Capabilities().OwnTime {
+						budget = timeControl.Remaining(d.e.Board().Turn())
+					}
+					d.report(ctx, debugLevel, "search %v: time %v, budget %v", opt.ID, timeControl, budget.Budget(d.e.Board().Turn()))
 				}
 
-				if d.opt.useBook && d.opt.book != nil {
+				if d.opt.useBook && d.opt.book != nil && len(opt.SearchMoves) == 0 {
 					// Use opening book if possible.
 
-					moves, err := d.opt.book.Find(ctx, d.e.Position())
+					moves, err := engine.FindBookMoves(ctx, d.opt.book, d.e.Opponent(), d.e.Position())
 					if err != nil {
-						logw.Errorf(ctx, "Failed to find book move for %v: %v", d.e.Position(), err)
-						return
+						d.report(ctx, errorLevel, "failed to find book move for %v: %v", d.e.Position(), err)
 					}
 
 					if len(moves) > 0 {
-						winner := moves[d.opt.rand.Intn(len(moves))]
-						pv := search.PV{Moves: []board.Move{winner}}
+						winner := moves[d.e.Choose(len(moves))]
+						pv := search.PV{ID: opt.ID, Moves: []board.Move{winner}}
+						d.report(ctx, infoLevel, "search %v: book move %v of %v", opt.ID, winner, moves)
 
 						d.active.Store(true)
 						d.searchCompleted(ctx, pv)
 						break
 					} // else: no book move
+					d.report(ctx, debugLevel, "search %v: no book move", opt.ID)
+				} else if d.opt.book != nil {
+					d.report(ctx, debugLevel, "search %v: book skipped (OwnBook %v, searchmoves %v)", opt.ID, d.opt.useBook, len(opt.SearchMoves))
 				}
 
 				out, err := d.e.Analyze(ctx, opt)
 				if err != nil {
-					logw.Errorf(ctx, "Analyze failed: %v", err)
-					return
+					// Every go must be answered with a bestmove.
+
+					d.report(ctx, errorLevel, "search %v failed: %v", opt.ID, err)
+					d.active.Store(true)
+					d.searchCompleted(ctx, search.PV{ID: opt.ID})
+					break
 				}
 				d.active.Store(true)
 
@@ -545,14 +703,6 @@ func (d *Driver) process(ctx context.Context, in <-chan string) {
 					}
 				}()
 
-				// Enforce move time limit, if set.
-
-				if timeout > 0 {
-					time.AfterFunc(timeout, func() {
-						_, _ = d.e.Halt(ctx)
-					})
-				}
-
 			case "stop":
 				// * stop
 				//
@@ -561,8 +711,11 @@ func (d *Driver) process(ctx context.Context, in <-chan string) {
 
 				pv, err := d.e.Halt(ctx)
 				if err != nil {
-					d.searchCompleted(ctx, pv)
+					d.report(ctx, debugLevel, "stop: %v", err)
+					break
 				}
+				d.report(ctx, infoLevel, "search %v: stopped", pv.ID)
+				d.searchCompleted(ctx, pv)
 
 			case "ponderhit":
 				// * ponderhit
@@ -646,8 +799,15 @@ func (d *Driver) process(ctx context.Context, in <-chan string) {
 			//	   If  is greater than 1, always send all k lines in k strings together.
 			//		The engine should only send this if the option "UCI_ShowCurrLine" is set to true.
 
+			if d.active.Load() && uint64(pv.ID) == d.id.Load() {
+				for _, line := range d.printPV(pv) {
+					d.out <- line
+				}
+			} // else: stale search
+
+		case msg := <-d.messages:
 			if d.active.Load() {
-				d.out <- printPV(pv)
+				d.out <- fmt.Sprintf("info string %v", msg)
 			}
 
 		case <-d.Closed():
@@ -666,6 +826,14 @@ func (d *Driver) ensureInactive(ctx context.Context) {
 
 func (d *Driver) searchCompleted(ctx context.Context, pv search.PV) {
 	if d.active.CompareAndSwap(true, false) {
+		d.report(ctx, infoLevel, "search %v: completed", pv.ID)
+		if pv.Stats.Nodes > 0 {
+			d.report(ctx, infoLevel, "search %v: stats %v", pv.ID, pvfmt.Stats(pv.Stats))
+		}
+		if d.debug.Load() && !d.e.Capabilities().NoTT {
+			d.report(ctx, debugLevel, "search %v: hashfull %v, memory %v", pv.ID, pvfmt.Hashfull(pv.Hash), d.e.Memory())
+		}
+
 		if len(pv.Moves) > 0 {
 			// * bestmove <move1> [ ponder <move2> ]
 			//
@@ -676,8 +844,10 @@ func (d *Driver) searchCompleted(ctx context.Context, pv search.PV) {
 			//	Directly before that the engine should send a final "info" command with the final search information,
 			//	the GUI has the complete statistics about the last search.
 
-			d.out <- printPV(pv)
-			d.out <- fmt.Sprintf("bestmove %v", printMove(pv.Moves[0]))
+			for _, line := range d.printPV(pv) {
+				d.out <- line
+			}
+			d.out <- fmt.Sprintf("bestmove %v", d.printMove(pv.Moves[0]))
 		} else {
 			// No PV. Position is checkmate or stalemate. Send NullMove.
 
@@ -686,52 +856,177 @@ func (d *Driver) searchCompleted(ctx context.Context, pv search.PV) {
 	} // else: stale or duplicate result
 }
 
-func printPV(pv search.PV) string {
+// message forwards a message of the active search, if there is room. Messages are sent to
+// the GUI as info strings.
+func (d *Driver) message(msg string) {
+	select {
+	case d.messages <- msg:
+	default:
+	}
+}
+
+// progress forwards an intermediate progress update of the active search, if there is room.
+// Updates are periodic, so dropping one is harmless.
+func (d *Driver) progress(pv search.PV) {
+	select {
+	case d.ponder <- pv:
+	default:
+	}
+}
+
+// level is the severity of a driver message. It determines whether the message is logged
+// and whether it is sent to the GUI as an info string.
+type level int
+
+const (
+	// debugLevel messages, such as received commands or time allocations, are sent in debug
+	// mode only. They are not logged.
+	debugLevel level = iota
+	// infoLevel messages, such as the search ID of driver decisions, are logged and sent in
+	// debug mode.
+	infoLevel
+	// errorLevel messages are logged and always sent.
+	errorLevel
+)
+
+// report logs the message and sends it to the GUI as an info string, depending on its level.
+func (d *Driver) report(ctx context.Context, lvl level, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+
+	switch lvl {
+	case errorLevel:
+		logw.Errorf(ctx, "UCI %v", msg)
+		d.out <- fmt.Sprintf("info string error: %v", msg)
+	case infoLevel:
+		logw.Infof(ctx, "UCI %v", msg)
+		fallthrough
+	default:
+		if d.debug.Load() {
+			d.out <- fmt.Sprintf("info string %v", msg)
+		}
+	}
+}
+
+// printOptionString returns the value of a string option, using "<empty>" for no value.
+func printOptionString(value string) string {
+	if value == "" {
+		return "<empty>"
+	}
+	return value
+}
+
+// parsePosition returns the position in FEN format and the moves of "position [fen <fenstring>
+// | startpos] moves <move1> .... <movei>". The position is not validated.
+func parsePosition(args []string) (string, []board.Move, error) {
+	n := len(args)
+	for i, arg := range args {
+		if arg == "moves" {
+			n = i
+			break
+		}
+	}
+
+	var position string
+	switch {
+	case n == 1 && args[0] == "startpos":
+		position = fen.Initial
+	case n > 1 && args[0] == "fen":
+		position = strings.Join(args[1:n], " ")
+	default:
+		return "", nil, fmt.Errorf("expected 'startpos' or 'fen <fenstring>'")
+	}
+
+	var moves []board.Move
+	for i := n + 1; i < len(args); i++ {
+		m, err := board.ParseMove(args[i])
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid move '%v': %w", args[i], err)
+		}
+		moves = append(moves, m)
+	}
+	return position, moves, nil
+}
+
+// parseSetOption returns the name and value of "setoption name <id> [value <x>]". Both the
+// name and value may contain spaces.
+func parseSetOption(args []string) (string, string) {
+	var name, value []string
+
+	var cur *[]string
+	for _, arg := range args {
+		switch {
+		case arg == "name" && cur == nil:
+			cur = &name
+		case arg == "value" && cur == &name:
+			cur = &value
+		case cur != nil:
+			*cur = append(*cur, arg)
+		}
+	}
+	return strings.Join(name, " "), strings.Join(value, " ")
+}
+
+// printPV returns the info lines for the principal variation. In MultiPV mode, all lines
+// are returned together with their "multipv" rank.
+func (d *Driver) printPV(pv search.PV) []string {
+	if len(pv.Alt) == 0 {
+		return []string{d.printInfo(pv, 0)}
+	}
+
+	ret := []string{d.printInfo(pv, 1)}
+	for i, alt := range pv.Alt {
+		alt.Nodes, alt.Time, alt.Hash, alt.TB = pv.Nodes, pv.Time, pv.Hash, pv.TB
+		ret = append(ret, d.printInfo(alt, i+2))
+	}
+	return ret
+}
+
+func (d *Driver) printInfo(pv search.PV, multipv int) string {
 	// "info depth 2 score cp 214 time 1242 nodes 2124 nps 34928 pv e2e4 e7e5 g1f3"
 
 	parts := []string{"info"}
 	parts = append(parts, fmt.Sprintf("depth %v", pv.Depth))
-	if !pv.Score.IsHeuristic() {
+	if multipv > 0 {
+		parts = append(parts, fmt.Sprintf("multipv %v", multipv))
+	}
+	switch {
+	case pv.Score.IsInvalid():
+		// Intermediate progress update. No score.
+	case !pv.Score.IsHeuristic():
 		moves := eval.IncrementMateDistance(pv.Score).Mate / 2
 		parts = append(parts, fmt.Sprintf("score mate %v", moves))
-	} else {
+	default:
 		parts = append(parts, fmt.Sprintf("score cp %v", int(pv.Score.Pawns*100)))
 	}
 	if pv.Nodes > 0 {
-		parts = append(parts, fmt.Sprintf("nodes %v", pv.Nodes))
+		parts = append(parts, fmt.Sprintf("nodes %v", pvfmt.Nodes(pv.Nodes)))
 	}
 	if pv.Time > 0 {
-		parts = append(parts, fmt.Sprintf("time %v", pv.Time.Milliseconds()))
+		parts = append(parts, fmt.Sprintf("time %v", pvfmt.Millis(pv.Time)))
 	}
-	if pv.Nodes > 0 && pv.Time > 0 {
-		parts = append(parts, fmt.Sprintf("nps %v", uint64(time.Second)*pv.Nodes/uint64(pv.Time)))
+	if pv.Nodes > 0 {
+		parts = append(parts, fmt.Sprintf("nps %v", pvfmt.NPS(pv.Nodes, pv.Time)))
 	}
 	if pv.Hash > 0 {
-		parts = append(parts, fmt.Sprintf("hashfull %v", int(1000*pv.Hash)))
+		parts = append(parts, fmt.Sprintf("hashfull %v", pvfmt.Hashfull(pv.Hash)))
+	}
+	if pv.TB > 0 {
+		parts = append(parts, fmt.Sprintf("tbhits %v", pv.TB))
 	}
 	if len(pv.Moves) > 0 {
 		parts = append(parts, "pv")
-		parts = append(parts, board.FormatMoves(pv.Moves, printMove))
+		parts = append(parts, board.FormatMoves(pv.Moves, d.printMove))
 	}
 
 	return strings.Join(parts, " ")
 }
 
-func printMove(m board.Move) string {
-	return fmt.Sprintf("%v%v%v", m.From, m.To, printPromoPiece(m.Promotion))
-}
-
-func printPromoPiece(p board.Piece) string {
-	switch p {
-	case board.Queen:
-		return "q"
-	case board.Rook:
-		return "r"
-	case board.Knight:
-		return "n"
-	case board.Bishop:
-		return "b"
-	default:
-		return ""
+// printMove returns the move in UCI notation. In Chess960 mode, castling moves are written as
+// the King capturing its own Rook, such as "e1h1". The Rook squares do not change during a game.
+func (d *Driver) printMove(m board.Move) string {
+	layout := board.StandardCastlingLayout
+	if l := d.layout.Load(); l != nil {
+		layout = *l
 	}
+	return board.PrintUCIMove(m, layout, d.chess960.Load())
 }
@@ -0,0 +1,150 @@
+package uci_test
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/engine"
+	"github.com/herohde/morlock/pkg/engine/uci"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMalformedInput(t *testing.T) {
+	ctx := context.Background()
+
+	root := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+	e := engine.New(ctx, "test", "test", root, engine.WithOptions(engine.Options{Depth: 2}))
+
+	in := make(chan string, 10)
+	d, out := uci.NewDriver(ctx, e, in)
+	defer d.Close()
+
+	expect(t, out, "uciok")
+
+	in <- "position startpos moves e2e4"
+	in <- "isready"
+	expect(t, out, "readyok")
+	e4 := e.Position()
+
+	// (1) Invalid positions are reported and the previous position is kept.
+
+	tests := []string{
+		"position startpos moves e2e4 e7e5 e1e3", // illegal continuation
+		"position startpos moves e2e4 e7e5 xyz",  // malformed continuation
+		"position startpos moves d2d5",           // illegal move
+		"position startpos moves d2",             // malformed move
+		"position fen 8/8/8 w - - 0 1",           // invalid fen
+		"position fen",                           // missing fen
+		"position",                               // missing position
+		"position e2e4",                          // unknown position
+	}
+	for _, tt := range tests {
+		in <- tt
+		expect(t, out, "info string error: invalid position")
+		in <- "isready"
+		expect(t, out, "readyok")
+		assert.Equal(t, e4, e.Position(), "failed: %v", tt)
+	}
+
+	// (2) Invalid search arguments are ignored, but the search still completes.
+
+	for _, tt := range []string{"go depth x", "go depth -1", "go depth 2 movetime"} {
+		in <- tt
+		expect(t, out, "info string error: ")
+		expect(t, out, "bestmove")
+	}
+
+	// (3) The driver is still processing commands.
+
+	in <- "position startpos moves e2e4 e7e5"
+	in <- "go depth 1"
+	expect(t, out, "bestmove")
+	assert.NotEqual(t, e4, e.Position())
+}
+
+// expect returns the next line with the given prefix.
+func expect(t *testing.T, out <-chan string, prefix string) string {
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case line, ok := <-out:
+			require.True(t, ok, "closed waiting for '%v'", prefix)
+			if strings.HasPrefix(line, prefix) {
+				return line
+			}
+		case <-timeout:
+			require.Fail(t, "timeout", "waiting for '%v'", prefix)
+		}
+	}
+}
+
+func TestBookFile(t *testing.T) {
+	ctx := context.Background()
+
+	filename := filepath.Join(t.TempDir(), "book.txt")
+	require.NoError(t, os.WriteFile(filename, []byte("e2e4 d7d5 d1h5\n"), 0644))
+
+	root := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+	e := engine.New(ctx, "test", "test", root, engine.WithOptions(engine.Options{Depth: 1}))
+
+	in := make(chan string, 10)
+	d, out := uci.NewDriver(ctx, e, in)
+	defer d.Close()
+
+	assert.Equal(t, "option name OwnBook type check default false", expect(t, out, "option name OwnBook"))
+	assert.Equal(t, "option name BookFile type string default <empty>", expect(t, out, "option name BookFile"))
+	expect(t, out, "uciok")
+
+	in <- "setoption name BookFile value " + filepath.Join(t.TempDir(), "missing.txt")
+	expect(t, out, "info string error: invalid book file")
+
+	in <- "setoption name BookFile value " + filename
+	in <- "position startpos moves e2e4 d7d5"
+	in <- "go depth 1"
+	assert.Equal(t, "bestmove d1h5", expect(t, out, "bestmove"))
+
+	in <- "setoption name OwnBook value false"
+	in <- "go depth 1"
+	assert.Equal(t, "bestmove e4d5", expect(t, out, "bestmove"))
+}
+
+func TestSearchInfo(t *testing.T) {
+	ctx := context.Background()
+
+	root := waiting{release: make(chan struct{})}
+	e := engine.New(ctx, "test", "test", root, engine.WithOptions(engine.Options{Depth: 1}))
+
+	in := make(chan string, 10)
+	d, out := uci.NewDriver(ctx, e, in)
+	defer d.Close()
+
+	expect(t, out, "uciok")
+
+	in <- "position startpos"
+	in <- "go depth 1"
+	assert.Equal(t, "info string waiting for move", expect(t, out, "info string"))
+
+	close(root.release)
+	assert.Equal(t, "bestmove e2e4", expect(t, out, "bestmove"))
+}
+
+// waiting is a search that reports a message and waits until released, such as for a move
+// on an external board. It then plays e2e4.
+type waiting struct {
+	release chan struct{}
+}
+
+func (w waiting) Search(ctx context.Context, sctx *search.Context, b *board.Board, depth int) (uint64, eval.Score, []board.Move, error) {
+	if sctx.Info != nil {
+		sctx.Info("waiting for move")
+	}
+	<-w.release
+	return 1, eval.ZeroScore, []board.Move{{Type: board.Jump, Piece: board.Pawn, From: board.E2, To: board.E4}}, nil
+}
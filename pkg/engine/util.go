@@ -5,16 +5,22 @@ import (
 	"context"
 	"fmt"
 	"github.com/seekerror/logw"
+	"io"
 	"os"
 )
 
 // ReadStdinLines reads stdin lines into a chan. Async.
 func ReadStdinLines(ctx context.Context) <-chan string {
+	return ReadLines(ctx, os.Stdin)
+}
+
+// ReadLines reads lines from the given reader into a chan, which is closed at EOF. Async.
+func ReadLines(ctx context.Context, r io.Reader) <-chan string {
 	ret := make(chan string, 1)
 	go func() {
 		defer close(ret)
 
-		scanner := bufio.NewScanner(os.Stdin)
+		scanner := bufio.NewScanner(r)
 		for scanner.Scan() {
 			logw.Debugf(ctx, "<< %v", scanner.Text())
 			ret <- scanner.Text()
@@ -25,8 +31,13 @@ func ReadStdinLines(ctx context.Context) <-chan string {
 
 // WriteStdoutLines writes lines from the given chan to stdout.
 func WriteStdoutLines(ctx context.Context, out <-chan string) {
+	WriteLines(ctx, os.Stdout, out)
+}
+
+// WriteLines writes lines from the given chan to the given writer.
+func WriteLines(ctx context.Context, w io.Writer, out <-chan string) {
 	for line := range out {
 		logw.Debugf(ctx, ">> %v", line)
-		_, _ = fmt.Fprintln(os.Stdout, line)
+		_, _ = fmt.Fprintln(w, line)
 	}
 }
@@ -0,0 +1,64 @@
+package eval
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/seekerror/logw"
+	"math"
+	"math/bits"
+	"sync/atomic"
+)
+
+// Cache is an evaluation cache keyed by the board search key. It is only suitable for evaluators
+// that depend on the position and any history captured by the key options. Each entry packs the upper
+// hash bits and the score into 8 bytes. Thread-safe. Empty until resized.
+type Cache struct {
+	eval  Evaluator
+	table atomic.Pointer[[]atomic.Uint64]
+}
+
+// NewCache returns a new, empty evaluation cache for the given evaluator.
+func NewCache(eval Evaluator) *Cache {
+	return &Cache{eval: eval}
+}
+
+func (c *Cache) Evaluate(ctx context.Context, b *board.Board) Pawns {
+	t := c.table.Load()
+	if t == nil || len(*t) == 0 {
+		return c.eval.Evaluate(ctx, b)
+	}
+
+	hash := uint64(b.Key())
+	entry := &(*t)[hash&uint64(len(*t)-1)]
+
+	cacheProbes.Add(1)
+	if v := entry.Load(); v != 0 && uint32(v>>32) == uint32(hash>>32) {
+		cacheHits.Add(1)
+		return Pawns(math.Float32frombits(uint32(v)))
+	}
+
+	ret := c.eval.Evaluate(ctx, b)
+	entry.Store((hash>>32)<<32 | uint64(math.Float32bits(float32(ret))))
+	return ret
+}
+
+// Resize clears and resizes the cache to at most the given size in bytes.
+func (c *Cache) Resize(ctx context.Context, size uint64) {
+	var n uint64
+	if size >= 8 {
+		n = uint64(1) << (63 - bits.LeadingZeros64(size>>3))
+	}
+
+	logw.Infof(ctx, "Allocating %vKB evaluation cache with %v entries", (n<<3)>>10, n)
+
+	t := make([]atomic.Uint64, n)
+	c.table.Store(&t)
+}
+
+// Size returns the size of the cache in bytes.
+func (c *Cache) Size() uint64 {
+	if t := c.table.Load(); t != nil {
+		return uint64(len(*t)) << 3
+	}
+	return 0
+}
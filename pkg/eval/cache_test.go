@@ -0,0 +1,58 @@
+package eval_test
+
+import (
+	"context"
+	"expvar"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+
+	counter := &counting{eval: eval.Material{}}
+	cache := eval.NewCache(counter)
+	assert.Equal(t, uint64(0), cache.Size())
+
+	b, err := fen.NewBoard("4k3/8/8/8/8/8/8/2Q1K3 w - - 0 1", "e1e2")
+	require.NoError(t, err)
+
+	// (1) Empty: no caching.
+
+	assert.Equal(t, eval.Pawns(-9), cache.Evaluate(ctx, b))
+	assert.Equal(t, eval.Pawns(-9), cache.Evaluate(ctx, b))
+	assert.Equal(t, 2, counter.n)
+
+	// (2) Resized: cached.
+
+	cache.Resize(ctx, 1<<10)
+	assert.Equal(t, uint64(1<<10), cache.Size())
+
+	probes := eval.Metrics.Get("cache_probes").(*expvar.Int).Value()
+	hits := eval.Metrics.Get("cache_hits").(*expvar.Int).Value()
+
+	assert.Equal(t, eval.Pawns(-9), cache.Evaluate(ctx, b))
+	assert.Equal(t, eval.Pawns(-9), cache.Evaluate(ctx, b))
+	assert.Equal(t, 3, counter.n)
+
+	b.PopMove()
+	assert.Equal(t, eval.Pawns(9), cache.Evaluate(ctx, b))
+	assert.Equal(t, 4, counter.n)
+
+	assert.Equal(t, probes+3, eval.Metrics.Get("cache_probes").(*expvar.Int).Value())
+	assert.Equal(t, hits+1, eval.Metrics.Get("cache_hits").(*expvar.Int).Value())
+}
+
+type counting struct {
+	eval eval.Evaluator
+	n    int
+}
+
+func (c *counting) Evaluate(ctx context.Context, b *board.Board) eval.Pawns {
+	c.n++
+	return c.eval.Evaluate(ctx, b)
+}
@@ -10,7 +10,7 @@ func FindCapture(pos *board.Position, side board.Color, sq board.Square) []board
 	var ret []board.Placement
 
 	for _, piece := range board.KingQueenRookKnightBishop {
-		bb := board.Attackboard(pos.Rotated(), sq, piece) & pos.Piece(side, piece)
+		bb := board.Attackboard(pos.All(), sq, piece) & pos.Piece(side, piece)
 		for _, from := range bb.ToSquares() {
 			ret = append(ret, board.Placement{Piece: piece, Color: side, Square: from})
 		}
@@ -12,6 +12,17 @@ type Evaluator interface {
 	Evaluate(ctx context.Context, b *board.Board) Pawns
 }
 
+// Sum is the sum of the evaluators, such as a base evaluation with additional terms.
+type Sum []Evaluator
+
+func (s Sum) Evaluate(ctx context.Context, b *board.Board) Pawns {
+	var pawns Pawns
+	for _, ev := range s {
+		pawns += ev.Evaluate(ctx, b)
+	}
+	return pawns
+}
+
 // Material returns the nominal material advantage balance for the side to move.
 type Material struct{}
 
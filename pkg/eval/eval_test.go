@@ -0,0 +1,22 @@
+package eval_test
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/eval/evaltest"
+	"testing"
+)
+
+// TestGolden checks the evaluation terms against the golden corpus in testdata. Run with
+// -update to regenerate it.
+func TestGolden(t *testing.T) {
+	evaltest.Golden{
+		File: "testdata/material.json",
+		Terms: map[string]evaltest.Term{
+			"material": func(ctx context.Context, b *board.Board) float64 {
+				return float64(eval.Material{}.Evaluate(ctx, b))
+			},
+		},
+	}.Run(t)
+}
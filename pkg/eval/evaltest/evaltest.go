@@ -0,0 +1,169 @@
+// Package evaltest contains a golden-file harness for evaluation tests. A golden file holds a
+// corpus of positions with expected values for a set of named evaluation terms. Values are
+// compared with a numeric tolerance and can be regenerated with the -update flag:
+//
+//	go test ./cmd/sargon/sargon -run TestGolden -update
+//
+// Regeneration keeps positions and notes, recomputes all values and adds any missing
+// standard positions, so that evaluation internals can be refactored without hand-editing
+// expectations. The diff of the golden file then shows exactly what changed.
+package evaltest
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"math"
+	"os"
+	"sort"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "Regenerate golden evaluation files")
+
+// DefaultTolerance is the default numerical tolerance for golden values.
+const DefaultTolerance = 0.001
+
+// Positions is the standard corpus of positions included in all golden files.
+var Positions = []string{
+	fen.Initial,
+	"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+	"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+	"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+	"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+	"r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10",
+	"k7/8/8/8/8/8/8/7K w - - 0 1",
+	"k7/8/8/8/8/8/8/7K b - - 0 1",
+}
+
+// Term is a named evaluation term of a position.
+type Term func(ctx context.Context, b *board.Board) float64
+
+// Case is a golden test case: a position with optional moves and the expected term values.
+type Case struct {
+	FEN    string             `json:"fen"`
+	Moves  []string           `json:"moves,omitempty"`
+	Note   string             `json:"note,omitempty"`
+	Values map[string]float64 `json:"values"`
+}
+
+// Golden is a golden file of cases for a set of evaluation terms.
+type Golden struct {
+	// File is the golden file, usually in testdata.
+	File string
+	// Terms are the named evaluation terms.
+	Terms map[string]Term
+	// Tolerance is the numerical tolerance. If zero, DefaultTolerance is used.
+	Tolerance float64
+}
+
+// Run evaluates all cases in the golden file and compares the values within tolerance. If
+// the -update flag is set, it instead regenerates the file.
+func (g Golden) Run(t *testing.T) {
+	t.Helper()
+	ctx := context.Background()
+
+	cases, err := Read(g.File)
+	if err != nil && !(*update && os.IsNotExist(err)) {
+		t.Fatalf("failed to read golden file %v: %v", g.File, err)
+	}
+
+	if *update {
+		cases = addPositions(cases, Positions)
+		for i, c := range cases {
+			b := g.board(t, c)
+
+			c.Values = map[string]float64{}
+			for name, fn := range g.Terms {
+				c.Values[name] = round(fn(ctx, b))
+			}
+			cases[i] = c
+		}
+		if err := Write(g.File, cases); err != nil {
+			t.Fatalf("failed to write golden file %v: %v", g.File, err)
+		}
+		t.Logf("updated golden file %v: %v cases", g.File, len(cases))
+		return
+	}
+
+	tolerance := g.Tolerance
+	if tolerance == 0 {
+		tolerance = DefaultTolerance
+	}
+
+	for _, c := range cases {
+		b := g.board(t, c)
+
+		for _, name := range names(g.Terms) {
+			expected, ok := c.Values[name]
+			if !ok {
+				t.Errorf("missing golden value for %v in %v %v (run with -update)", name, c.FEN, c.Moves)
+				continue
+			}
+			if actual := g.Terms[name](ctx, b); math.Abs(actual-expected) > tolerance {
+				t.Errorf("%v mismatch for %v %v: expected %v, actual %v (%v)", name, c.FEN, c.Moves, expected, actual, c.Note)
+			}
+		}
+	}
+}
+
+func (g Golden) board(t *testing.T, c Case) *board.Board {
+	b, err := fen.NewBoard(c.FEN, c.Moves...)
+	if err != nil {
+		t.Fatalf("invalid golden position %v %v: %v", c.FEN, c.Moves, err)
+	}
+	return b
+}
+
+// Read reads a golden file.
+func Read(file string) ([]Case, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var ret []Case
+	if err := json.Unmarshal(data, &ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Write writes a golden file.
+func Write(file string, cases []Case) error {
+	data, err := json.MarshalIndent(cases, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(data, '\n'), 0644)
+}
+
+func addPositions(cases []Case, positions []string) []Case {
+	seen := map[string]bool{}
+	for _, c := range cases {
+		if len(c.Moves) == 0 {
+			seen[c.FEN] = true
+		}
+	}
+	for _, pos := range positions {
+		if !seen[pos] {
+			cases = append(cases, Case{FEN: pos})
+		}
+	}
+	return cases
+}
+
+func names(terms map[string]Term) []string {
+	var ret []string
+	for name := range terms {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// round rounds to 4 decimals to keep golden files readable.
+func round(v float64) float64 {
+	return math.Round(v*1e4) / 1e4
+}
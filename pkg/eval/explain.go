@@ -0,0 +1,86 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"github.com/herohde/morlock/pkg/board"
+	"strings"
+)
+
+// Term is a named evaluation term and its contribution to the score.
+type Term struct {
+	Name  string
+	Score Pawns
+}
+
+func (t Term) String() string {
+	return fmt.Sprintf("%v=%v", t.Name, t.Score)
+}
+
+// Explanation is a per-term breakdown of the evaluation of a position for the side to move.
+type Explanation struct {
+	Score Pawns
+	Terms []Term
+}
+
+func (e Explanation) String() string {
+	var parts []string
+	for _, t := range e.Terms {
+		parts = append(parts, t.String())
+	}
+	return fmt.Sprintf("%v (%v)", e.Score, strings.Join(parts, " "))
+}
+
+// Explainer is an evaluator that can explain its evaluation as a per-term breakdown, such as
+// to study which rule of a historical evaluation contributed what. The term contributions sum
+// to the score, up to any rounding by the evaluator.
+type Explainer interface {
+	Evaluator
+	// Explain returns the evaluation of the position with a per-term breakdown.
+	Explain(ctx context.Context, b *board.Board) Explanation
+}
+
+// Explain explains the evaluation of the position, if an Explainer. Otherwise, the score is
+// a single "eval" term.
+func Explain(ctx context.Context, ev Evaluator, b *board.Board) Explanation {
+	if e, ok := ev.(Explainer); ok {
+		return e.Explain(ctx, b)
+	}
+	score := ev.Evaluate(ctx, b)
+	return Explanation{Score: score, Terms: []Term{{Name: "eval", Score: score}}}
+}
+
+// single returns an explanation with a single term.
+func single(name string, score Pawns) Explanation {
+	return Explanation{Score: score, Terms: []Term{{Name: name, Score: score}}}
+}
+
+func (s Sum) Explain(ctx context.Context, b *board.Board) Explanation {
+	var ret Explanation
+	for _, ev := range s {
+		e := Explain(ctx, ev, b)
+		ret.Score += e.Score
+		ret.Terms = append(ret.Terms, e.Terms...)
+	}
+	return ret
+}
+
+func (c *Cache) Explain(ctx context.Context, b *board.Board) Explanation {
+	return Explain(ctx, c.eval, b)
+}
+
+func (m Material) Explain(ctx context.Context, b *board.Board) Explanation {
+	return single("material", m.Evaluate(ctx, b))
+}
+
+func (p PST) Explain(ctx context.Context, b *board.Board) Explanation {
+	return single("pst", p.Evaluate(ctx, b))
+}
+
+func (p PawnStructure) Explain(ctx context.Context, b *board.Board) Explanation {
+	return single("pawn-structure", p.Evaluate(ctx, b))
+}
+
+func (k KingSafety) Explain(ctx context.Context, b *board.Board) Explanation {
+	return single("king-safety", k.Evaluate(ctx, b))
+}
@@ -0,0 +1,39 @@
+package eval_test
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// constant is an evaluator that cannot explain itself.
+type constant eval.Pawns
+
+func (c constant) Evaluate(ctx context.Context, b *board.Board) eval.Pawns {
+	return eval.Pawns(c)
+}
+
+func TestExplain(t *testing.T) {
+	ctx := context.Background()
+
+	b, err := fen.NewBoard("4k3/pp6/8/8/8/8/P7/R3K3 b - - 0 1")
+	require.NoError(t, err)
+
+	e := eval.Explain(ctx, constant(2), b)
+	assert.Equal(t, eval.Explanation{Score: 2, Terms: []eval.Term{{Name: "eval", Score: 2}}}, e)
+
+	ev := eval.Sum{eval.Material{}, eval.NewPawnStructure(nil), constant(2)}
+	e = eval.Explain(ctx, eval.NewCache(ev), b)
+	assert.Equal(t, ev.Evaluate(ctx, b), e.Score)
+
+	require.Len(t, e.Terms, 3)
+	assert.Equal(t, eval.Term{Name: "material", Score: -4}, e.Terms[0])
+	assert.Equal(t, "pawn-structure", e.Terms[1].Name)
+	assert.Equal(t, eval.Term{Name: "eval", Score: 2}, e.Terms[2])
+
+	assert.Equal(t, "2.00 (eval=2.00)", eval.Explain(ctx, constant(2), b).String())
+}
@@ -0,0 +1,107 @@
+package eval
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board"
+)
+
+// KingSafety evaluates the safety of both kings by pawn shield, open files near the king and
+// the weight of enemy pieces attacking the king zone, i.e., the king square and its neighbors.
+// The score is scaled by game phase, because king safety matters little in the endgame. It
+// returns the balance for the side to move and can be added to any evaluator.
+type KingSafety struct {
+	// Shield is the bonus per own pawn in front of the king on the king file or an adjacent
+	// file, one and two ranks ahead.
+	Shield [2]Pawns
+	// SemiOpen is the bonus per file at or next to the king without own pawns. Usually negative.
+	SemiOpen Pawns
+	// Open is the bonus per file at or next to the king without any pawns, instead of SemiOpen.
+	// Usually negative.
+	Open Pawns
+	// Attack is the attack weight per enemy piece attacking the king zone by piece.
+	Attack [board.NumPieces]Pawns
+	// AttackScale is the fraction of the total attack weight used by number of attackers. The
+	// last value is used for more attackers. A single attacker is rarely dangerous.
+	AttackScale []Pawns
+}
+
+// NewKingSafety returns a king safety evaluator with default weights.
+func NewKingSafety() KingSafety {
+	return KingSafety{
+		Shield:      [2]Pawns{0.1, 0.05},
+		SemiOpen:    -0.15,
+		Open:        -0.25,
+		Attack:      [board.NumPieces]Pawns{board.Knight: 0.2, board.Bishop: 0.2, board.Rook: 0.3, board.Queen: 0.5},
+		AttackScale: []Pawns{0, 0, 0.5, 0.75, 0.9, 1},
+	}
+}
+
+func (k KingSafety) Evaluate(ctx context.Context, b *board.Board) Pawns {
+	pos := b.Position()
+	turn := b.Turn()
+
+	score := k.Score(pos, turn) - k.Score(pos, turn.Opponent())
+	return Taper(score, 0, Phase(pos))
+}
+
+// Score returns the untapered king safety score of the color.
+func (k KingSafety) Score(pos *board.Position, c board.Color) Pawns {
+	if pos.Piece(c, board.King) == 0 {
+		return 0
+	}
+	king := pos.KingSquare(c)
+	own, opp := pos.Piece(c, board.Pawn), pos.Piece(c.Opponent(), board.Pawn)
+
+	var ret Pawns
+
+	// (1) Pawn shield and open files.
+
+	files := board.BitFile(king.File()) | adjacentFiles(king.File())
+	for i, bonus := range k.Shield {
+		rank := king.Rank() + board.Rank(i+1)
+		if c == board.Black {
+			rank = king.Rank() - board.Rank(i+1)
+		}
+		if rank < board.NumRanks { // unsigned: also guards underflow for Black
+			ret += bonus * Pawns((own & files & board.BitRank(rank)).PopCount())
+		}
+	}
+
+	for f := board.ZeroFile; f < board.NumFiles; f++ {
+		file := board.BitFile(f)
+		if files&file == 0 || own&file != 0 {
+			continue
+		}
+		if opp&file == 0 {
+			ret += k.Open
+		} else {
+			ret += k.SemiOpen
+		}
+	}
+
+	// (2) Attacks on the king zone.
+
+	if len(k.AttackScale) > 0 {
+		zone := board.KingAttackboard(king) | board.BitMask(king)
+		all := pos.All()
+
+		attackers := 0
+		var weight Pawns
+		for _, p := range []board.Piece{board.Knight, board.Bishop, board.Rook, board.Queen} {
+			for bb := pos.Piece(c.Opponent(), p); bb != 0; {
+				sq := bb.LastPopSquare()
+				bb ^= board.BitMask(sq)
+
+				if board.Attackboard(all, sq, p)&zone != 0 {
+					attackers++
+					weight += k.Attack[p]
+				}
+			}
+		}
+		if attackers >= len(k.AttackScale) {
+			attackers = len(k.AttackScale) - 1
+		}
+		ret -= weight * k.AttackScale[attackers]
+	}
+	return ret
+}
@@ -0,0 +1,56 @@
+package eval_test
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestKingSafety(t *testing.T) {
+	ks := eval.NewKingSafety()
+
+	tests := []struct {
+		fen      string
+		expected eval.Pawns // White score
+	}{
+		{fen.Initial, 0.3},
+		{"6k1/5ppp/8/8/8/8/5PPP/6K1 w - - 0 1", 0.3},
+		{"6k1/5ppp/8/8/8/6P1/5P1P/6K1 w - - 0 1", 0.25},         // fianchetto
+		{"6k1/5ppp/8/8/8/8/5P1P/6K1 w - - 0 1", 0.2 - 0.15},     // semi-open file
+		{"6k1/5p1p/8/8/8/8/5P1P/6K1 w - - 0 1", 0.2 - 0.25},     // open file
+		{"6k1/5ppp/8/8/6q1/8/5PPP/6K1 w - - 0 1", 0.3},          // single attacker
+		{"6k1/5ppp/8/8/6q1/4n3/5PPP/6K1 w - - 0 1", 0.3 - 0.35}, // queen and knight
+		{"6k1/5ppp/8/8/8/8/8/6K1 w - - 0 1", -0.15 * 3},
+	}
+
+	for _, tt := range tests {
+		pos, _, _, _, err := fen.Decode(tt.fen)
+		require.NoError(t, err)
+
+		assert.InDelta(t, float64(tt.expected), float64(ks.Score(pos, board.White)), 0.0001, "failed: %v", tt.fen)
+	}
+
+	t.Run("evaluate", func(t *testing.T) {
+		ctx := context.Background()
+
+		score := func(position string) eval.Pawns {
+			b, err := fen.NewBoard(position)
+			require.NoError(t, err)
+			return ks.Evaluate(ctx, b)
+		}
+
+		assert.InDelta(t, 0, float64(score(fen.Initial)), 0.0001)
+		assert.InDelta(t, 0, float64(score("6k1/5ppp/8/8/8/8/5P1P/6K1 w - - 0 1")), 0.0001, "endgame")
+
+		// Tapered by game phase: 2 rooks and a queen = 8 of 24.
+
+		w := score("r5k1/5ppp/8/8/8/6q1/5P1P/R5K1 w - - 0 1")
+		b := score("r5k1/5ppp/8/8/8/6q1/5P1P/R5K1 b - - 0 1")
+		assert.InDelta(t, float64(w), float64(-b), 0.0001)
+		assert.InDelta(t, (0.2-0.15-0.3)/3, float64(w), 0.0001)
+	})
+}
@@ -0,0 +1,21 @@
+package eval
+
+import "expvar"
+
+// Metrics holds process-wide evaluation table metrics, exported via expvar as "eval".
+// Counters aggregate over all tables in the process.
+var Metrics = expvar.NewMap("eval")
+
+var (
+	cacheProbes = new(expvar.Int) // evaluation cache reads
+	cacheHits   = new(expvar.Int) // evaluation cache reads that found the position
+	pawnProbes  = new(expvar.Int) // pawn table reads
+	pawnHits    = new(expvar.Int) // pawn table reads that found the pawn structure
+)
+
+func init() {
+	Metrics.Set("cache_probes", cacheProbes)
+	Metrics.Set("cache_hits", cacheHits)
+	Metrics.Set("pawn_probes", pawnProbes)
+	Metrics.Set("pawn_hits", pawnHits)
+}
@@ -0,0 +1,67 @@
+package eval
+
+import (
+	"github.com/herohde/morlock/pkg/board"
+)
+
+// Attacks returns the squares attacked by the color, including by pawns and the king. Does
+// not include en passant.
+func Attacks(pos *board.Position, c board.Color) board.Bitboard {
+	return pos.AttackedSquares(c.Opponent())
+}
+
+// Control returns the number of squares attacked by the color, but not by the opponent.
+// Populated squares are included.
+func Control(pos *board.Position, c board.Color) int {
+	return (Attacks(pos, c) &^ Attacks(pos, c.Opponent())).PopCount()
+}
+
+// AttackCounts returns the number of squares attacked by each piece of the color, summed by
+// piece type. Squares attacked by multiple pieces are counted for each.
+func AttackCounts(pos *board.Position, c board.Color) [board.NumPieces]int {
+	return counts(pos, c, false, board.EmptyBitboard)
+}
+
+// Mobility returns the number of pseudo-legal moves of the pieces of the color by piece type,
+// ignoring pins, checks, castling, en passant and promotions. Pawns count single pushes and
+// captures.
+func Mobility(pos *board.Position, c board.Color) [board.NumPieces]int {
+	return counts(pos, c, true, pos.Color(c))
+}
+
+// SafeMobility returns the mobility of the pieces of the color by piece type, excluding moves
+// to squares attacked by opponent pawns. Pawns count single pushes and captures.
+func SafeMobility(pos *board.Position, c board.Color) [board.NumPieces]int {
+	unsafe := board.PawnCaptureboard(c.Opponent(), pos.Piece(c.Opponent(), board.Pawn))
+	return counts(pos, c, true, pos.Color(c)|unsafe)
+}
+
+// counts returns the number of attacked squares, or pseudo-legal target squares if moves, of
+// each piece of the color by piece type, excluding the given squares.
+func counts(pos *board.Position, c board.Color, moves bool, exclude board.Bitboard) [board.NumPieces]int {
+	var ret [board.NumPieces]int
+
+	all := pos.All()
+	for _, p := range board.KingQueenRookKnightBishop {
+		for bb := pos.Piece(c, p); bb != 0; {
+			sq := bb.LastPopSquare()
+			bb ^= board.BitMask(sq)
+
+			ret[p] += (board.Attackboard(all, sq, p) &^ exclude).PopCount()
+		}
+	}
+
+	var pushes board.Bitboard
+	targets := ^board.EmptyBitboard
+	if moves {
+		pushes, targets = board.PawnMoveboard(all, c, pos.Piece(c, board.Pawn)), pos.Color(c.Opponent())
+	}
+	for bb := pos.Piece(c, board.Pawn); bb != 0; {
+		sq := bb.LastPopSquare()
+		bb ^= board.BitMask(sq)
+
+		ret[board.Pawn] += (board.PawnCaptureboard(c, board.BitMask(sq)) & targets &^ exclude).PopCount()
+	}
+	ret[board.Pawn] += (pushes &^ exclude).PopCount()
+	return ret
+}
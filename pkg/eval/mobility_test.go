@@ -0,0 +1,44 @@
+package eval_test
+
+import (
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMobility(t *testing.T) {
+	pos, _, _, _, err := fen.Decode(fen.Initial)
+	require.NoError(t, err)
+
+	expected := [board.NumPieces]int{board.Pawn: 8, board.Knight: 4} // single pushes only
+	assert.Equal(t, expected, eval.Mobility(pos, board.White))
+	assert.Equal(t, expected, eval.SafeMobility(pos, board.White))
+	assert.Equal(t, expected, eval.Mobility(pos, board.Black))
+
+	attacks := [board.NumPieces]int{board.Pawn: 14, board.Knight: 6, board.Bishop: 4, board.Rook: 4, board.Queen: 5, board.King: 5}
+	assert.Equal(t, attacks, eval.AttackCounts(pos, board.White))
+	assert.Equal(t, 22, eval.Control(pos, board.White)) // 3rd rank and own pieces, except a1 and h1
+	assert.Equal(t, 22, eval.Control(pos, board.Black))
+
+	// Knight on d4 with 8 moves, 1 of which is to a square attacked by the e6 pawn. Rook on
+	// a1 is blocked by the king on e1 and the a2 pawn. The pawn on a2 can push, but not capture.
+
+	pos, _, _, _, err = fen.Decode("4k3/8/4p3/8/3N4/8/P7/R3K3 w - - 0 1")
+	require.NoError(t, err)
+
+	mobility := eval.Mobility(pos, board.White)
+	assert.Equal(t, 8, mobility[board.Knight])
+	assert.Equal(t, 3, mobility[board.Rook])
+	assert.Equal(t, 1, mobility[board.Pawn])
+	assert.Equal(t, 5, mobility[board.King])
+
+	safe := eval.SafeMobility(pos, board.White)
+	assert.Equal(t, 7, safe[board.Knight])
+
+	assert.Equal(t, 1, eval.AttackCounts(pos, board.White)[board.Pawn])
+	assert.Equal(t, 5, eval.AttackCounts(pos, board.White)[board.Rook]) // includes own pieces
+	assert.Equal(t, (eval.Attacks(pos, board.White) &^ eval.Attacks(pos, board.Black)).PopCount(), eval.Control(pos, board.White))
+}
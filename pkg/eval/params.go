@@ -0,0 +1,71 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Tunable is an evaluator with named numeric parameters, such as weights or limits, that
+// can be exported and imported. Booleans are represented as 0 or 1.
+type Tunable interface {
+	// Parameters returns the current parameter values by name.
+	Parameters() map[string]float64
+	// SetParameters updates the given parameters. Parameters not present are unchanged.
+	// Returns an error if a name is unknown or a value is not valid.
+	SetParameters(params map[string]float64) error
+}
+
+// ExportParameters writes the parameters of the evaluator as an indented JSON object with
+// sorted names.
+func ExportParameters(w io.Writer, t Tunable) error {
+	data, err := json.MarshalIndent(t.Parameters(), "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// ImportParameters reads a JSON object of parameters and updates the evaluator. Names
+// not present are unchanged. Nothing is changed if any name is unknown.
+func ImportParameters(r io.Reader, t Tunable) error {
+	var params map[string]float64
+	if err := json.NewDecoder(r).Decode(&params); err != nil {
+		return fmt.Errorf("invalid parameters: %v", err)
+	}
+
+	current := t.Parameters()
+	var unknown []string
+	for name := range params {
+		if _, ok := current[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown parameters: %v", unknown)
+	}
+	return t.SetParameters(params)
+}
+
+// ParameterBool returns the parameter representation of a boolean.
+func ParameterBool(v bool) float64 {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+// ParseParameterBool returns the boolean value of a parameter, which must be 0 or 1.
+func ParseParameterBool(name string, v float64) (bool, error) {
+	switch v {
+	case 0:
+		return false, nil
+	case 1:
+		return true, nil
+	default:
+		return false, fmt.Errorf("invalid parameter %v: %v is not 0 or 1", name, v)
+	}
+}
@@ -0,0 +1,64 @@
+package eval_test
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+type weights struct {
+	pawn  float64
+	check bool
+}
+
+func (w *weights) Parameters() map[string]float64 {
+	return map[string]float64{"pawn": w.pawn, "check": eval.ParameterBool(w.check)}
+}
+
+func (w *weights) SetParameters(params map[string]float64) error {
+	for name, v := range params {
+		switch name {
+		case "pawn":
+			w.pawn = v
+		case "check":
+			b, err := eval.ParseParameterBool(name, v)
+			if err != nil {
+				return err
+			}
+			w.check = b
+		default:
+			return fmt.Errorf("unknown parameter: %v", name)
+		}
+	}
+	return nil
+}
+
+func TestParameters(t *testing.T) {
+	w := &weights{pawn: 1.5, check: true}
+
+	var buf bytes.Buffer
+	require.NoError(t, eval.ExportParameters(&buf, w))
+	assert.Equal(t, "{\n  \"check\": 1,\n  \"pawn\": 1.5\n}\n", buf.String())
+
+	// Round trip.
+
+	other := &weights{}
+	require.NoError(t, eval.ImportParameters(&buf, other))
+	assert.Equal(t, w, other)
+
+	// Partial update.
+
+	require.NoError(t, eval.ImportParameters(strings.NewReader(`{"pawn": 2}`), other))
+	assert.Equal(t, &weights{pawn: 2, check: true}, other)
+
+	// Invalid input leaves the parameters unchanged.
+
+	for _, str := range []string{`{"pawn": 3, "knight": 3}`, `{"pawn": "3"}`, `[1]`, `{"check": 2}`} {
+		assert.Error(t, eval.ImportParameters(strings.NewReader(str), other), str)
+	}
+	assert.Equal(t, 2.0, other.pawn)
+}
@@ -0,0 +1,202 @@
+package eval
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/seekerror/logw"
+	"math"
+	"math/bits"
+	"sync/atomic"
+)
+
+// PawnStructure evaluates doubled, isolated, backward and passed pawns. Terms are tapered by
+// game phase. The untapered scores depend on the pawns only and are cached in the pawn table,
+// if present. Returns the balance for the side to move.
+type PawnStructure struct {
+	Doubled  Weight // per extra pawn on a file
+	Isolated Weight // per pawn without friendly pawns on adjacent files
+	Backward Weight // per pawn behind its neighbors, whose stop square is attacked by a pawn
+	// Passed is the bonus per passed pawn by relative rank.
+	Passed [board.NumRanks]Weight
+
+	Table *PawnTable
+}
+
+// NewPawnStructure returns a pawn structure evaluator with default weights and an optional
+// pawn table.
+func NewPawnStructure(table *PawnTable) PawnStructure {
+	return PawnStructure{
+		Doubled:  Weight{MG: -0.1, EG: -0.25},
+		Isolated: Weight{MG: -0.1, EG: -0.15},
+		Backward: Weight{MG: -0.08, EG: -0.12},
+		Passed: [board.NumRanks]Weight{
+			board.Rank2: {MG: 0.05, EG: 0.1},
+			board.Rank3: {MG: 0.1, EG: 0.15},
+			board.Rank4: {MG: 0.15, EG: 0.25},
+			board.Rank5: {MG: 0.25, EG: 0.45},
+			board.Rank6: {MG: 0.45, EG: 0.8},
+			board.Rank7: {MG: 0.7, EG: 1.2},
+		},
+		Table: table,
+	}
+}
+
+func (p PawnStructure) Evaluate(ctx context.Context, b *board.Board) Pawns {
+	pos := b.Position()
+
+	var mg, eg Pawns
+	if p.Table != nil {
+		hash := b.PawnHash()
+		if m, e, ok := p.Table.Read(hash); ok {
+			mg, eg = m, e
+		} else {
+			mg, eg = p.Score(pos)
+			p.Table.Write(hash, mg, eg)
+		}
+	} else {
+		mg, eg = p.Score(pos)
+	}
+
+	ret := Taper(mg, eg, Phase(pos))
+	if b.Turn() == board.Black {
+		return -ret
+	}
+	return ret
+}
+
+// Score returns the untapered middlegame and endgame pawn structure balance for White.
+func (p PawnStructure) Score(pos *board.Position) (Pawns, Pawns) {
+	wmg, weg := p.score(pos, board.White)
+	bmg, beg := p.score(pos, board.Black)
+	return wmg - bmg, weg - beg
+}
+
+func (p PawnStructure) score(pos *board.Position, c board.Color) (Pawns, Pawns) {
+	own, opp := pos.Piece(c, board.Pawn), pos.Piece(c.Opponent(), board.Pawn)
+	attacked := board.PawnCaptureboard(c.Opponent(), opp)
+
+	var w Weight
+	add := func(weight Weight, n int) {
+		w.MG += weight.MG * Pawns(n)
+		w.EG += weight.EG * Pawns(n)
+	}
+
+	for f := board.ZeroFile; f < board.NumFiles; f++ {
+		if n := (own & board.BitFile(f)).PopCount(); n > 1 {
+			add(p.Doubled, n-1)
+		}
+	}
+
+	for bb := own; bb != 0; {
+		sq := bb.LastPopSquare()
+		bb ^= board.BitMask(sq)
+
+		f, r := sq.File(), sq.Rank()
+		adjacent := adjacentFiles(f)
+
+		if opp&(board.BitFile(f)|adjacent)&forwardRanks(c, r) == 0 {
+			add(p.Passed[board.RelativeRank(c, r)], 1)
+		}
+		if own&adjacent == 0 {
+			add(p.Isolated, 1)
+			continue
+		}
+		if own&adjacent&^forwardRanks(c, r) == 0 {
+			if stop, ok := board.PawnPushDirection(c).Step(sq); ok && attacked.IsSet(stop) {
+				add(p.Backward, 1)
+			}
+		}
+	}
+	return w.MG, w.EG
+}
+
+// adjacentFiles returns the mask of the files next to the given file.
+func adjacentFiles(f board.File) board.Bitboard {
+	var ret board.Bitboard
+	if f > board.ZeroFile {
+		ret |= board.BitFile(f - 1)
+	}
+	if f < board.NumFiles-1 {
+		ret |= board.BitFile(f + 1)
+	}
+	return ret
+}
+
+// forwardRanks returns the mask of the ranks strictly ahead of the given rank for the color.
+func forwardRanks(c board.Color, r board.Rank) board.Bitboard {
+	var ret board.Bitboard
+	for i := board.ZeroRank; i < board.NumRanks; i++ {
+		if (c == board.White && i > r) || (c == board.Black && i < r) {
+			ret |= board.BitRank(i)
+		}
+	}
+	return ret
+}
+
+// PawnTable is a pawn structure hash table keyed by the pawn hash. Each entry holds the
+// untapered middlegame and endgame scores as float32s in one word and the hash xor'ed with
+// that word in another, so that torn entries are not matched. Thread-safe. Empty until resized.
+type PawnTable struct {
+	table atomic.Pointer[[]pawnEntry]
+}
+
+type pawnEntry struct {
+	key, data atomic.Uint64
+}
+
+// NewPawnTable returns a new, empty pawn table.
+func NewPawnTable() *PawnTable {
+	return &PawnTable{}
+}
+
+// Read returns the middlegame and endgame scores of the pawn hash, if present.
+func (t *PawnTable) Read(hash board.ZobristHash) (Pawns, Pawns, bool) {
+	tab := t.table.Load()
+	if tab == nil || len(*tab) == 0 {
+		return 0, 0, false
+	}
+
+	e := &(*tab)[uint64(hash)&uint64(len(*tab)-1)]
+	data := e.data.Load()
+
+	pawnProbes.Add(1)
+	if e.key.Load()^data != uint64(hash) {
+		return 0, 0, false
+	}
+	pawnHits.Add(1)
+	return Pawns(math.Float32frombits(uint32(data >> 32))), Pawns(math.Float32frombits(uint32(data))), true
+}
+
+// Write stores the middlegame and endgame scores of the pawn hash, replacing any entry.
+func (t *PawnTable) Write(hash board.ZobristHash, mg, eg Pawns) {
+	tab := t.table.Load()
+	if tab == nil || len(*tab) == 0 {
+		return
+	}
+
+	e := &(*tab)[uint64(hash)&uint64(len(*tab)-1)]
+	data := uint64(math.Float32bits(float32(mg)))<<32 | uint64(math.Float32bits(float32(eg)))
+	e.key.Store(uint64(hash) ^ data)
+	e.data.Store(data)
+}
+
+// Resize clears and resizes the table to at most the given size in bytes.
+func (t *PawnTable) Resize(ctx context.Context, size uint64) {
+	var n uint64
+	if size >= 16 {
+		n = uint64(1) << (63 - bits.LeadingZeros64(size>>4))
+	}
+
+	logw.Infof(ctx, "Allocating %vKB pawn table with %v entries", (n<<4)>>10, n)
+
+	tab := make([]pawnEntry, n)
+	t.table.Store(&tab)
+}
+
+// Size returns the size of the table in bytes.
+func (t *PawnTable) Size() uint64 {
+	if tab := t.table.Load(); tab != nil {
+		return uint64(len(*tab)) << 4
+	}
+	return 0
+}
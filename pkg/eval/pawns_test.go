@@ -0,0 +1,75 @@
+package eval_test
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestPawnStructure(t *testing.T) {
+	ps := eval.PawnStructure{
+		Doubled:  eval.Weight{MG: -1, EG: -1},
+		Isolated: eval.Weight{MG: -10, EG: -10},
+		Backward: eval.Weight{MG: -100, EG: -100},
+		Passed:   [board.NumRanks]eval.Weight{board.Rank2: {MG: 1000}, board.Rank5: {MG: 10000}},
+	}
+
+	tests := []struct {
+		fen      string
+		expected eval.Pawns // middlegame score for White
+	}{
+		{fen.Initial, 0},
+		{"4k3/pppppppp/8/8/8/8/PPPPPPPP/4K3 w - - 0 1", 0},
+		{"4k3/8/8/8/8/8/PPPPPPPP/4K3 w - - 0 1", 8000},        // all passed
+		{"4k3/pp6/8/8/8/8/P1P5/4K3 w - - 0 1", -20},           // isolated vs connected
+		{"4k3/p7/8/8/8/P7/P7/4K3 w - - 0 1", -1 - 20 + 10},    // doubled and isolated
+		{"4k3/8/8/8/1P1p4/8/2P5/4K3 w - - 0 1", -100 + 10},    // c2 backward, d4 isolated
+		{"4k3/8/8/1P6/8/8/8/4K3 b - - 0 1", 10000 - 10},       // passed and isolated
+		{"4k3/8/8/1Pp5/8/8/8/4K3 w - - 0 1", 10000 - 10 + 10}, // both passed and isolated
+		{"4k3/p7/8/1P6/8/8/8/4K3 w - - 0 1", -10 + 10},        // not passed
+		{"4k3/8/8/1P6/8/8/2P5/4K3 w - - 0 1", 10000 + 1000},   // connected, not backward
+	}
+
+	for _, tt := range tests {
+		pos, _, _, _, err := fen.Decode(tt.fen)
+		require.NoError(t, err)
+
+		mg, _ := ps.Score(pos)
+		assert.Equal(t, tt.expected, mg, "failed: %v", tt.fen)
+	}
+}
+
+func TestPawnTable(t *testing.T) {
+	ctx := context.Background()
+
+	table := eval.NewPawnTable()
+	ps := eval.NewPawnStructure(table)
+
+	b, err := fen.NewBoard("4k3/p7/8/1P6/4p3/8/2P5/4K3 b - - 0 1")
+	require.NoError(t, err)
+
+	_, _, ok := table.Read(b.PawnHash())
+	assert.False(t, ok, "empty until resized")
+
+	expected := eval.NewPawnStructure(nil).Evaluate(ctx, b)
+	assert.Equal(t, expected, ps.Evaluate(ctx, b))
+
+	table.Resize(ctx, 1<<10)
+	assert.Equal(t, uint64(1<<10), table.Size())
+
+	assert.Equal(t, expected, ps.Evaluate(ctx, b))
+	mg, eg, ok := table.Read(b.PawnHash())
+	require.True(t, ok)
+	assert.Equal(t, expected, -eval.Taper(mg, eg, eval.Phase(b.Position())))
+	assert.Equal(t, expected, ps.Evaluate(ctx, b))
+
+	table.Write(b.PawnHash(), 1, 2)
+	mg, eg, ok = table.Read(b.PawnHash())
+	require.True(t, ok)
+	assert.Equal(t, eval.Pawns(1), mg)
+	assert.Equal(t, eval.Pawns(2), eg)
+}
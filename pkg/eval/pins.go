@@ -19,7 +19,7 @@ func FindPins(pos *board.Position, side board.Color, piece board.Piece) []Pin {
 
 		// (1) Rook/Queen pins
 
-		rooks := board.RookAttackboard(pos.Rotated(), target)
+		rooks := board.RookAttackboard(pos.All(), target)
 		pins := rooks & pos.Color(side)
 		for pins != 0 {
 			pinned := pins.LastPopSquare()
@@ -27,7 +27,7 @@ func FindPins(pos *board.Position, side board.Color, piece board.Piece) []Pin {
 
 			attackers := pos.Piece(side.Opponent(), board.Queen) | pos.Piece(side.Opponent(), board.Rook)
 
-			candidate := (board.RookAttackboard(pos.Rotated().Xor(pinned), target) &^ rooks) & attackers
+			candidate := (board.RookAttackboard(pos.All()^board.BitMask(pinned), target) &^ rooks) & attackers
 			if candidate != 0 {
 				attacker := candidate.LastPopSquare()
 
@@ -38,7 +38,7 @@ func FindPins(pos *board.Position, side board.Color, piece board.Piece) []Pin {
 
 		// (1) Bishop/Queen pins
 
-		bishops := board.BishopAttackboard(pos.Rotated(), target)
+		bishops := board.BishopAttackboard(pos.All(), target)
 		pins = bishops & pos.Color(side)
 		for pins != 0 {
 			pinned := pins.LastPopSquare()
@@ -46,7 +46,7 @@ func FindPins(pos *board.Position, side board.Color, piece board.Piece) []Pin {
 
 			attackers := pos.Piece(side.Opponent(), board.Queen) | pos.Piece(side.Opponent(), board.Bishop)
 
-			candidate := (board.BishopAttackboard(pos.Rotated().Xor(pinned), target) &^ bishops) & attackers
+			candidate := (board.BishopAttackboard(pos.All()^board.BitMask(pinned), target) &^ bishops) & attackers
 			if candidate != 0 {
 				attacker := candidate.LastPopSquare()
 
@@ -0,0 +1,72 @@
+package eval_test
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestPhase(t *testing.T) {
+	tests := []struct {
+		fen      string
+		expected int
+	}{
+		{fen.Initial, eval.MaxPhase},
+		{"4k3/pppppppp/8/8/8/8/PPPPPPPP/4K3 w - - 0 1", 0},
+		{"3qk3/8/8/8/8/8/8/R3K3 w - - 0 1", 6},
+		{"QQQQk3/8/8/8/8/8/8/QQQQK3 w - - 0 1", eval.MaxPhase},
+	}
+
+	for _, tt := range tests {
+		pos, _, _, _, err := fen.Decode(tt.fen)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, eval.Phase(pos), "failed: %v", tt.fen)
+	}
+}
+
+func TestPST(t *testing.T) {
+	ctx := context.Background()
+
+	score := func(position string) eval.Pawns {
+		b, err := fen.NewBoard(position)
+		require.NoError(t, err)
+		return eval.PST{}.Evaluate(ctx, b)
+	}
+
+	t.Run("symmetric", func(t *testing.T) {
+		assert.InDelta(t, 0, float64(score(fen.Initial)), 0.0001)
+
+		// Mirrored positions with the other side to move have the same score.
+
+		tests := [][2]string{
+			{"r1bqkbnr/pppp1ppp/2n5/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R w KQkq - 2 3", "rnbqkb1r/pppp1ppp/5n2/4p3/4P3/2N5/PPPP1PPP/R1BQKBNR b KQkq - 2 3"},
+			{"8/5k2/8/3p4/8/2N5/5K2/8 w - - 0 1", "8/5k2/2n5/8/3P4/8/5K2/8 b - - 0 1"},
+		}
+		for _, tt := range tests {
+			assert.InDelta(t, float64(score(tt[0])), float64(score(tt[1])), 0.0001, "failed: %v", tt)
+		}
+	})
+
+	t.Run("squares", func(t *testing.T) {
+		assert.True(t, score("4k3/8/8/8/4N3/8/8/4K3 w - - 0 1") > score("4k3/8/8/8/8/8/8/N3K3 w - - 0 1"), "knight prefers center")
+		assert.True(t, score("4k3/8/8/8/8/8/8/4K3 b - - 0 1") == 0, "symmetric kings")
+		assert.True(t, score("4k3/P7/8/8/8/8/8/4K3 w - - 0 1") > score("4k3/8/8/8/8/8/P7/4K3 w - - 0 1"), "passed pawn advances")
+	})
+
+	t.Run("tapered", func(t *testing.T) {
+		// The king prefers shelter in the middlegame, but the center in the endgame.
+
+		mg, eg := eval.PieceSquareValue(board.White, board.King, board.G1)
+		cmg, ceg := eval.PieceSquareValue(board.White, board.King, board.E4)
+		assert.True(t, mg > cmg)
+		assert.True(t, eg < ceg)
+
+		assert.Equal(t, mg, eval.Taper(mg, eg, eval.MaxPhase))
+		assert.Equal(t, eg, eval.Taper(mg, eg, 0))
+		assert.Equal(t, (mg+eg)/2, eval.Taper(mg, eg, eval.MaxPhase/2))
+	})
+}
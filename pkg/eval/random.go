@@ -3,21 +3,85 @@ package eval
 import (
 	"context"
 	"github.com/herohde/morlock/pkg/board"
+	"math"
 	"math/rand"
 )
 
+// Distribution is a noise distribution.
+type Distribution uint8
+
+const (
+	// Uniform noise is uniformly distributed in the range [-limit/2; limit/2].
+	Uniform Distribution = iota
+	// Gaussian noise is normally distributed with a standard deviation of limit/4, truncated
+	// to the range [-limit/2; limit/2]. Small perturbations are thus more likely than large ones.
+	Gaussian
+)
+
+// ParseDistribution parses a noise distribution by name, such as "gaussian".
+func ParseDistribution(str string) (Distribution, bool) {
+	switch str {
+	case "uniform":
+		return Uniform, true
+	case "gaussian":
+		return Gaussian, true
+	default:
+		return 0, false
+	}
+}
+
+func (d Distribution) String() string {
+	switch d {
+	case Uniform:
+		return "uniform"
+	case Gaussian:
+		return "gaussian"
+	default:
+		return "?"
+	}
+}
+
 // Random is a randomized noise generator. It is used to a small amount of randomness to evaluations. The
 // limit specifies how many millipawns to add/remove in the range [-limit/2; limit/2]. The default value
 // always returns zero.
 type Random struct {
 	rand  *rand.Rand
 	limit int
+	dist  Distribution
+	key   uint64
+	keyed bool
 }
 
+// NewRandom returns a uniform noise generator with a random stream seeded by the given seed.
 func NewRandom(limit int, seed int64) Random {
+	return NewRandomDistribution(limit, Uniform, seed)
+}
+
+// NewRandomDistribution returns a noise generator of the given distribution with a random stream
+// seeded by the given seed. The noise of a position thus depends on the evaluation order.
+func NewRandomDistribution(limit int, dist Distribution, seed int64) Random {
+	return NewRandomSource(limit, dist, rand.NewSource(seed))
+}
+
+// NewRandomSource returns a noise generator of the given distribution with a random stream
+// from the given source.
+func NewRandomSource(limit int, dist Distribution, src rand.Source) Random {
 	return Random{
 		limit: limit,
-		rand:  rand.New(rand.NewSource(seed)),
+		dist:  dist,
+		rand:  rand.New(src),
+	}
+}
+
+// NewKeyedRandom returns a noise generator of the given distribution, where the noise is derived
+// from the seed and position hash alone. The noise of a position is thus the same across searches,
+// such as for all moves of a game with a fixed seed.
+func NewKeyedRandom(limit int, dist Distribution, seed int64) Random {
+	return Random{
+		limit: limit,
+		dist:  dist,
+		key:   uint64(seed),
+		keyed: true,
 	}
 }
 
@@ -25,5 +89,49 @@ func (n Random) Evaluate(ctx context.Context, b *board.Board) Pawns {
 	if n.limit <= 0 {
 		return 0
 	}
-	return Pawns(n.rand.Intn(n.limit)-n.limit/2) / 1000
+	if n.keyed {
+		src := splitmix(n.key ^ uint64(b.Hash()))
+		return n.sample(&src)
+	}
+	return n.sample(n.rand)
+}
+
+// sampler is a random stream for sampling noise, such as *rand.Rand.
+type sampler interface {
+	Intn(n int) int
+	NormFloat64() float64
+}
+
+func (n Random) sample(r sampler) Pawns {
+	switch n.dist {
+	case Gaussian:
+		half := float64(n.limit / 2)
+		v := math.Round(r.NormFloat64() * float64(n.limit) / 4)
+		return Pawns(math.Max(-half, math.Min(v, half))) / 1000
+	default:
+		return Pawns(r.Intn(n.limit)-n.limit/2) / 1000
+	}
+}
+
+// splitmix is a small, fast random stream with a 64-bit state. Used for keyed noise, where
+// a new stream is needed for each evaluation, so it is sampled directly.
+type splitmix uint64
+
+func (s *splitmix) Uint64() uint64 {
+	*s += 0x9e3779b97f4a7c15
+	z := uint64(*s)
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+func (s *splitmix) Intn(n int) int {
+	return int(s.Uint64() % uint64(n))
+}
+
+// NormFloat64 returns a standard normally distributed value by the Box-Muller transform.
+func (s *splitmix) NormFloat64() float64 {
+	u1 := (float64(s.Uint64()>>11) + 0.5) / (1 << 53) // (0;1), so the logarithm is finite
+	u2 := float64(s.Uint64()>>11) / (1 << 53)
+	return math.Sqrt(-2*math.Log(u1)) * math.Cos(2*math.Pi*u2)
 }
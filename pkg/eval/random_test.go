@@ -0,0 +1,52 @@
+package eval_test
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRandom(t *testing.T) {
+	ctx := context.Background()
+
+	b, err := fen.NewBoard(fen.Initial)
+	require.NoError(t, err)
+
+	d, ok := eval.ParseDistribution("gaussian")
+	assert.True(t, ok)
+	assert.Equal(t, eval.Gaussian, d)
+	_, ok = eval.ParseDistribution("cauchy")
+	assert.False(t, ok)
+
+	assert.Equal(t, eval.Pawns(0), eval.Random{}.Evaluate(ctx, b))
+
+	for _, dist := range []eval.Distribution{eval.Uniform, eval.Gaussian} {
+		// (1) Stream: noise is within limits and varies per evaluation.
+
+		r := eval.NewRandomDistribution(100, dist, 42)
+		seen := map[eval.Pawns]bool{}
+		for i := 0; i < 100; i++ {
+			n := r.Evaluate(ctx, b)
+			assert.LessOrEqual(t, float64(n), 0.0501, dist)
+			assert.GreaterOrEqual(t, float64(n), -0.0501, dist)
+			seen[n] = true
+		}
+		assert.Greater(t, len(seen), 1, dist)
+
+		// (2) Keyed: noise is the same for a position, but varies by position and seed.
+
+		k := eval.NewKeyedRandom(1000, dist, 42)
+		n := k.Evaluate(ctx, b)
+		assert.Equal(t, n, k.Evaluate(ctx, b))
+		assert.Equal(t, n, eval.NewKeyedRandom(1000, dist, 42).Evaluate(ctx, b))
+
+		varies := false
+		for seed := int64(1); seed < 10; seed++ {
+			varies = varies || eval.NewKeyedRandom(1000, dist, seed).Evaluate(ctx, b) != n
+		}
+		assert.True(t, varies, dist)
+	}
+}
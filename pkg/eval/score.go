@@ -138,10 +138,10 @@ func (s Score) Less(o Score) bool {
 		case Heuristic:
 			return s.Mate < 0
 		case MateInX:
-			if s.Mate < 0 || o.Mate < 0 {
+			if (s.Mate < 0) != (o.Mate < 0) {
 				return s.Mate < o.Mate
 			}
-			return s.Mate > o.Mate
+			return s.Mate > o.Mate // closer is better if winning and worse if losing
 		}
 	}
 
@@ -180,6 +180,24 @@ func IncrementMateDistance(s Score) Score {
 	}
 }
 
+// DecrementMateDistance removes 1 ply from a MateInX score and is the inverse of
+// IncrementMateDistance. It converts a search window bound to the frame of a child node.
+// Heuristic and Inf/NegInf scores are unchanged.
+func DecrementMateDistance(s Score) Score {
+	switch {
+	case s.Type != MateInX:
+		return s
+	case s.Mate == 1:
+		return InfScore
+	case s.Mate == -1:
+		return NegInfScore
+	case s.Mate < 0:
+		return MateInXScore(s.Mate + 1)
+	default:
+		return MateInXScore(s.Mate - 1)
+	}
+}
+
 // Max returns the largest of the given scores.
 func Max(a, b Score) Score {
 	if a.Less(b) {
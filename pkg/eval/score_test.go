@@ -0,0 +1,45 @@
+package eval_test
+
+import (
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestScoreLess(t *testing.T) {
+	ordered := []eval.Score{
+		eval.NegInfScore,
+		eval.MateInXScore(-1),
+		eval.MateInXScore(-2),
+		eval.MateInXScore(-9),
+		eval.HeuristicScore(-3),
+		eval.ZeroScore,
+		eval.HeuristicScore(3),
+		eval.MateInXScore(9),
+		eval.MateInXScore(2),
+		eval.MateInXScore(1),
+		eval.InfScore,
+	}
+
+	for i, a := range ordered {
+		for j, b := range ordered {
+			assert.Equalf(t, i < j, a.Less(b), "%v < %v", a, b)
+		}
+	}
+}
+
+func TestMateDistance(t *testing.T) {
+	tests := []eval.Score{
+		eval.NegInfScore,
+		eval.MateInXScore(-1),
+		eval.MateInXScore(-4),
+		eval.ZeroScore,
+		eval.MateInXScore(3),
+		eval.MateInXScore(1),
+		eval.InfScore,
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt, eval.DecrementMateDistance(eval.IncrementMateDistance(tt)))
+	}
+}
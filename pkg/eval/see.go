@@ -0,0 +1,81 @@
+package eval
+
+import (
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/seekerror/stdlib/pkg/util/mathx"
+)
+
+// seePieces lists the pieces in order of increasing nominal value for static exchange.
+var seePieces = []board.Piece{board.Pawn, board.Knight, board.Bishop, board.Rook, board.Queen, board.King}
+
+// SEE returns the static exchange evaluation of a move in nominal pawns, i.e., the net material
+// gain for the side moving assuming both sides alternately recapture on the destination square
+// with the least valuable piece as long as it is favorable. Sliding pieces behind other attackers
+// (x-rays) join the exchange as the squares are vacated. Pins are ignored, but the King only
+// recaptures if the square is no longer defended. Pawns that recapture on the last rank
+// promote to Queen. Quiet moves evaluate whether the piece can be won at the destination.
+func SEE(pos *board.Position, m board.Move) Pawns {
+	side, _, ok := pos.Square(m.From)
+	if !ok {
+		return 0
+	}
+
+	occ := pos.All() &^ board.BitMask(m.From)
+	if m.Type == board.EnPassant {
+		occ &^= board.BitMask(board.NewSquare(m.To.File(), m.From.Rank()))
+	}
+
+	onSquare := NominalValue(m.Piece)
+	if m.IsPromotion() {
+		onSquare = NominalValue(m.Promotion)
+	}
+
+	var gain [32]Pawns
+	gain[0] = NominalValueGain(m)
+
+	d, turn := 0, side.Opponent()
+	for d+1 < len(gain) {
+		from, attacker, ok := leastValuableAttacker(pos, occ, m.To, turn)
+		if !ok {
+			break
+		}
+		if attacker == board.King {
+			if _, _, defended := leastValuableAttacker(pos, occ&^board.BitMask(from), m.To, turn.Opponent()); defended {
+				break // illegal: King cannot capture a defended piece
+			}
+		}
+
+		d++
+		gain[d] = onSquare - gain[d-1]
+		onSquare = NominalValue(attacker)
+		if attacker == board.Pawn && m.To.Rank() == board.PromotionRank(turn) {
+			gain[d] += NominalValue(board.Queen) - NominalValue(board.Pawn)
+			onSquare = NominalValue(board.Queen)
+		}
+
+		occ &^= board.BitMask(from)
+		turn = turn.Opponent()
+	}
+
+	for ; d > 0; d-- {
+		gain[d-1] = -mathx.Max(-gain[d-1], gain[d])
+	}
+	return gain[0]
+}
+
+// leastValuableAttacker returns the least valuable piece of the given color that attacks the
+// square with the given occupancy, if any. Only pieces in the occupancy are considered.
+func leastValuableAttacker(pos *board.Position, occ board.Bitboard, sq board.Square, side board.Color) (board.Square, board.Piece, bool) {
+	for _, piece := range seePieces {
+		var bb board.Bitboard
+		if piece == board.Pawn {
+			bb = board.PawnCaptureboard(side.Opponent() /* reverse direction */, board.BitMask(sq))
+		} else {
+			bb = board.Attackboard(occ, sq, piece)
+		}
+		if bb &= pos.Piece(side, piece) & occ; bb != board.EmptyBitboard {
+			return bb.LastPopSquare(), piece, true
+		}
+	}
+	return 0, board.NoPiece, false
+}
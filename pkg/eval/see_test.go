@@ -0,0 +1,53 @@
+package eval_test
+
+import (
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSEE(t *testing.T) {
+	tests := []struct {
+		fen      string
+		move     string
+		expected eval.Pawns
+	}{
+		{"4k3/8/8/4p3/8/8/8/4QK2 w - - 0 1", "e1e5", 1},     // undefended
+		{"4k3/8/3p4/4p3/8/8/8/4QK2 w - - 0 1", "e1e5", -8},  // defended by pawn
+		{"4r1k1/8/8/4p3/8/8/4R3/4RK2 w - - 0 1", "e2e5", 1}, // x-ray rook recaptures
+		{"4r1k1/8/8/4p3/8/8/4R3/5K2 w - - 0 1", "e2e5", -4}, // .. but not alone
+		{"4k3/4p3/8/8/8/8/8/4QK2 w - - 0 1", "e1e7", -8},    // defended by king
+		{"4k3/4p3/8/8/8/8/4Q3/4RK2 w - - 0 1", "e2e7", 1},   // .. which cannot recapture
+		{"4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", "e5d6", 1},    // en passant
+		{"r3k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b7a8q", 13},   // capture promotion
+		{"1r2k3/P7/8/8/8/8/8/4K3 w - - 0 1", "a7a8q", -1},   // promotion lost to rook
+		{"4k3/8/8/8/8/2n5/3P4/3RK3 b - - 0 1", "c3d1", 2},   // king recaptures
+		{"4k3/8/8/3r4/8/2N5/8/4K3 b - - 0 1", "d5d3", 0},    // quiet move to safe square
+		{"4k3/8/8/3r4/8/2N5/8/4K3 b - - 0 1", "d5b5", -5},   // quiet move to attacked square
+	}
+
+	for _, tt := range tests {
+		pos, turn, _, _, err := fen.Decode(tt.fen)
+		require.NoError(t, err)
+
+		m := findMove(t, pos, turn, tt.move)
+		assert.Equal(t, tt.expected, eval.SEE(pos, m), "failed: %v %v", tt.fen, tt.move)
+	}
+}
+
+func findMove(t *testing.T, pos *board.Position, turn board.Color, str string) board.Move {
+	t.Helper()
+
+	m, err := board.ParseMove(str)
+	require.NoError(t, err)
+	for _, c := range pos.PseudoLegalMoves(turn) {
+		if c.Equals(m) {
+			return c
+		}
+	}
+	require.FailNow(t, "move not found", str)
+	return board.Move{}
+}
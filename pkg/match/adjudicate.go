@@ -0,0 +1,127 @@
+// Package match contains utilities for automated games between engines, such as self-play.
+package match
+
+import (
+	"context"
+	"fmt"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/search"
+)
+
+const (
+	// ScoreAdjudication is the reason for a result adjudicated by engine scores.
+	ScoreAdjudication board.Reason = "Score adjudication"
+	// TablebaseAdjudication is the reason for a result adjudicated by a tablebase.
+	TablebaseAdjudication board.Reason = "Tablebase adjudication"
+	// MaxLengthAdjudication is the reason for a draw adjudicated by game length.
+	MaxLengthAdjudication board.Reason = "Maximum game length"
+)
+
+// Rules are game adjudication rules, so that automated games terminate sensibly. The zero
+// value never adjudicates.
+type Rules struct {
+	// WinScore, if positive, adjudicates a win if the scores of both sides agree that one side
+	// is ahead by at least the given pawns for WinMoves consecutive moves each.
+	WinScore eval.Pawns
+	// WinMoves is the number of consecutive moves for win adjudication. Must be positive.
+	WinMoves int
+	// DrawScore, if positive, adjudicates a draw if the scores of both sides are within the
+	// given pawns of zero for DrawMoves consecutive moves each, after DrawMinMoves moves.
+	DrawScore eval.Pawns
+	// DrawMoves is the number of consecutive moves for draw adjudication. Must be positive.
+	DrawMoves int
+	// DrawMinMoves is the number of moves before draw adjudication, if any.
+	DrawMinMoves int
+	// Tablebase, if set, adjudicates positions in the tablebase by the tablebase outcome.
+	Tablebase search.Tablebase
+	// MaxMoves, if positive, adjudicates a draw after the given number of moves.
+	MaxMoves int
+}
+
+func (r Rules) String() string {
+	return fmt.Sprintf("{win=%v/%v, draw=%v/%v/%v, tb=%v, max=%v}", r.WinScore, r.WinMoves, r.DrawScore, r.DrawMoves, r.DrawMinMoves, r.Tablebase != nil, r.MaxMoves)
+}
+
+// Adjudicator adjudicates a game by the given rules. It tracks the scores reported by the
+// engines for each move of the game. Not thread-safe.
+type Adjudicator struct {
+	rules Rules
+
+	plies     int // moves by either side
+	win, loss int // consecutive plies with White ahead or behind by the win score
+	draw      int // consecutive plies within the draw score
+}
+
+// NewAdjudicator returns an adjudicator for a new game.
+func NewAdjudicator(rules Rules) *Adjudicator {
+	return &Adjudicator{rules: rules}
+}
+
+// Adjudicate records a move, given the board after the move and the score reported by the
+// engine that made it from its point of view, and returns the adjudicated result, if any. An
+// invalid score, such as for a book move, interrupts consecutive scores. Terminal positions
+// are not adjudicated.
+func (a *Adjudicator) Adjudicate(ctx context.Context, b *board.Board, score eval.Score) (board.Result, bool) {
+	a.plies++
+
+	if b.Result().IsTerminal() {
+		return board.Result{}, false
+	}
+
+	if a.rules.Tablebase != nil {
+		if wdl, _, ok := a.rules.Tablebase.Probe(ctx, b); ok {
+			switch wdl {
+			case search.TBWin:
+				return board.Result{Outcome: board.Win(b.Turn()), Reason: TablebaseAdjudication}, true
+			case search.TBLoss:
+				return board.Result{Outcome: board.Loss(b.Turn()), Reason: TablebaseAdjudication}, true
+			default:
+				return board.Result{Outcome: board.Draw, Reason: TablebaseAdjudication}, true
+			}
+		}
+	}
+
+	if b.Turn() == board.White {
+		score = score.Negate() // move by Black
+	}
+	a.update(score)
+
+	if n := 2 * a.rules.WinMoves; a.rules.WinScore > 0 && n > 0 {
+		switch {
+		case a.win >= n:
+			return board.Result{Outcome: board.WhiteWins, Reason: ScoreAdjudication}, true
+		case a.loss >= n:
+			return board.Result{Outcome: board.BlackWins, Reason: ScoreAdjudication}, true
+		}
+	}
+	if n := 2 * a.rules.DrawMoves; a.rules.DrawScore > 0 && n > 0 && a.draw >= n && a.plies >= 2*a.rules.DrawMinMoves {
+		return board.Result{Outcome: board.Draw, Reason: ScoreAdjudication}, true
+	}
+	if a.rules.MaxMoves > 0 && a.plies >= 2*a.rules.MaxMoves {
+		return board.Result{Outcome: board.Draw, Reason: MaxLengthAdjudication}, true
+	}
+	return board.Result{}, false
+}
+
+// update updates the consecutive score counts with the given score for White.
+func (a *Adjudicator) update(score eval.Score) {
+	if score.IsInvalid() {
+		a.win, a.loss, a.draw = 0, 0, 0
+		return
+	}
+
+	margin := eval.HeuristicScore(a.rules.WinScore)
+	a.win = next(a.win, !score.Less(margin))
+	a.loss = next(a.loss, !margin.Negate().Less(score))
+
+	limit := eval.HeuristicScore(a.rules.DrawScore)
+	a.draw = next(a.draw, score.IsHeuristic() && !limit.Less(score) && !score.Less(limit.Negate()))
+}
+
+func next(count int, ok bool) int {
+	if ok {
+		return count + 1
+	}
+	return 0
+}
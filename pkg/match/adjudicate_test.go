@@ -0,0 +1,113 @@
+package match_test
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/match"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// fewPieces is a tablebase of positions with at most 3 pieces, where the side to move wins
+// if it has more pieces.
+type fewPieces struct{}
+
+func (fewPieces) Probe(ctx context.Context, b *board.Board) (search.WDL, int, bool) {
+	pos := b.Position()
+	if pos.All().PopCount() > 3 {
+		return search.TBDraw, 0, false
+	}
+	own, opp := pos.Color(b.Turn()).PopCount(), pos.Color(b.Turn().Opponent()).PopCount()
+	switch {
+	case own > opp:
+		return search.TBWin, 1, true
+	case own < opp:
+		return search.TBLoss, 1, true
+	default:
+		return search.TBDraw, 1, true
+	}
+}
+
+func TestAdjudicator(t *testing.T) {
+	ctx := context.Background()
+
+	white, err := fen.NewBoard(fen.Initial, "e2e4") // Black to move
+	require.NoError(t, err)
+	black, err := fen.NewBoard(fen.Initial, "e2e4", "e7e5") // White to move
+	require.NoError(t, err)
+
+	// play records the given scores for alternating moves by White and Black.
+	play := func(a *match.Adjudicator, scores ...eval.Score) (board.Result, bool) {
+		for i, s := range scores {
+			b := white
+			if i%2 == 1 {
+				b = black
+			}
+			if r, ok := a.Adjudicate(ctx, b, s); ok {
+				return r, i == len(scores)-1
+			}
+		}
+		return board.Result{}, false
+	}
+
+	h := eval.HeuristicScore
+
+	t.Run("none", func(t *testing.T) {
+		a := match.NewAdjudicator(match.Rules{})
+		_, ok := play(a, h(10), h(-10), h(10), h(-10), h(0), h(0))
+		assert.False(t, ok)
+	})
+
+	t.Run("win", func(t *testing.T) {
+		rules := match.Rules{WinScore: 5, WinMoves: 2}
+
+		r, ok := play(match.NewAdjudicator(rules), h(6), h(-7), h(8), eval.MateInXScore(-3))
+		assert.True(t, ok)
+		assert.Equal(t, board.Result{Outcome: board.WhiteWins, Reason: match.ScoreAdjudication}, r)
+
+		r, ok = play(match.NewAdjudicator(rules), h(-6), h(7), h(-8), h(9))
+		assert.True(t, ok)
+		assert.Equal(t, board.Result{Outcome: board.BlackWins, Reason: match.ScoreAdjudication}, r)
+
+		_, ok = play(match.NewAdjudicator(rules), h(6), h(-4), h(8), h(-7), h(6))
+		assert.False(t, ok, "sides disagree")
+
+		_, ok = play(match.NewAdjudicator(rules), h(6), h(-7), eval.InvalidScore, h(-7))
+		assert.False(t, ok, "interrupted")
+	})
+
+	t.Run("draw", func(t *testing.T) {
+		rules := match.Rules{DrawScore: 0.1, DrawMoves: 2, DrawMinMoves: 3}
+
+		_, ok := play(match.NewAdjudicator(rules), h(0), h(0), h(0), h(0.2), h(0), h(0), h(0))
+		assert.False(t, ok)
+
+		r, ok := play(match.NewAdjudicator(rules), h(0), h(0), h(0), h(0.2), h(0), h(0), h(0), h(-0.1))
+		assert.True(t, ok)
+		assert.Equal(t, board.Result{Outcome: board.Draw, Reason: match.ScoreAdjudication}, r)
+	})
+
+	t.Run("maxlength", func(t *testing.T) {
+		a := match.NewAdjudicator(match.Rules{MaxMoves: 2})
+		r, ok := play(a, h(0), h(0), h(0), h(0))
+		assert.True(t, ok)
+		assert.Equal(t, board.Result{Outcome: board.Draw, Reason: match.MaxLengthAdjudication}, r)
+	})
+
+	t.Run("tablebase", func(t *testing.T) {
+		a := match.NewAdjudicator(match.Rules{Tablebase: fewPieces{}})
+
+		_, ok := a.Adjudicate(ctx, white, h(0))
+		assert.False(t, ok)
+
+		b, err := fen.NewBoard("4k3/8/8/8/8/8/8/R3K3 b - - 0 1")
+		require.NoError(t, err)
+		r, ok := a.Adjudicate(ctx, b, h(0))
+		assert.True(t, ok)
+		assert.Equal(t, board.Result{Outcome: board.WhiteWins, Reason: match.TablebaseAdjudication}, r)
+	})
+}
@@ -0,0 +1,181 @@
+package match
+
+import (
+	"context"
+	"fmt"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/pgn"
+	"github.com/herohde/morlock/pkg/engine"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/herohde/morlock/pkg/search/searchctl"
+	"github.com/seekerror/stdlib/pkg/lang"
+	"time"
+)
+
+// Limits are the search limits of a game. If there is no clock, each engine searches to the
+// depth limit, or its default depth if zero.
+type Limits struct {
+	// Time, if positive, is the clock time per side. A side loses if it runs out of time.
+	Time time.Duration
+	// Increment is the clock increment per move.
+	Increment time.Duration
+	// Depth, if positive, limits the search depth of each move.
+	Depth uint
+}
+
+func (l Limits) String() string {
+	if l.Time > 0 {
+		return fmt.Sprintf("%v+%v depth=%v", l.Time, l.Increment, l.Depth)
+	}
+	return fmt.Sprintf("depth=%v", l.Depth)
+}
+
+// Game is a game played between two engines.
+type Game struct {
+	// White and Black are the engine names.
+	White, Black string
+	// Opening is the opening of the game. The opening moves are included in Moves.
+	Opening Opening
+	// Moves are the moves played from the starting position.
+	Moves []board.Move
+	// Result is the result of the game. It is undecided if the game was stopped early.
+	Result board.Result
+}
+
+func (g Game) String() string {
+	return fmt.Sprintf("%v - %v: %v (%v moves)", g.White, g.Black, g.Result, (len(g.Moves)+1)/2)
+}
+
+// PGN returns the game as a PGN game with the given event and round tags.
+func (g Game) PGN(event string, round int) pgn.Game {
+	ret := pgn.Game{
+		Tags: []pgn.Tag{
+			{Name: "Event", Value: event},
+			{Name: "Site", Value: "?"},
+			{Name: "Date", Value: "????.??.??"},
+			{Name: "Round", Value: fmt.Sprint(round)},
+			{Name: "White", Value: g.White},
+			{Name: "Black", Value: g.Black},
+			{Name: "Result", Value: resultTag(g.Result)},
+		},
+		Moves:  g.Moves,
+		Result: resultTag(g.Result),
+	}
+	if g.Opening.Start != InitialOpening.Start {
+		ret.Tags = append(ret.Tags, pgn.Tag{Name: "SetUp", Value: "1"}, pgn.Tag{Name: "FEN", Value: g.Opening.Start})
+	}
+	if g.Result.Reason != "" {
+		ret.Tags = append(ret.Tags, pgn.Tag{Name: "Termination", Value: string(g.Result.Reason)})
+	}
+	return ret
+}
+
+// Play plays a game between the given engines from the opening under the given limits and
+// adjudication rules. The engines are reset to the starting position and must be distinct.
+func Play(ctx context.Context, white, black *engine.Engine, opening Opening, limits Limits, rules Rules) (Game, error) {
+	ret := Game{White: white.Name(), Black: black.Name(), Opening: opening}
+
+	for _, e := range []*engine.Engine{white, black} {
+		if err := e.Reset(ctx, opening.Start); err != nil {
+			return ret, err
+		}
+	}
+	layout := white.Board().Position().CastlingLayout()
+	move := func(m board.Move) error {
+		for _, e := range []*engine.Engine{white, black} {
+			if err := e.Move(ctx, board.PrintUCIMove(m, layout, false)); err != nil {
+				return fmt.Errorf("move %v: %w", m, err)
+			}
+		}
+		ret.Moves = append(ret.Moves, m)
+		return nil
+	}
+
+	for _, m := range opening.Moves {
+		if err := move(m); err != nil {
+			return ret, fmt.Errorf("invalid opening %v: %w", opening, err)
+		}
+	}
+
+	turn := white.Board().Turn()
+	clock := [board.NumColors]time.Duration{limits.Time, limits.Time}
+	adjudicator := NewAdjudicator(rules)
+
+	for {
+		if ret.Result = white.Result(); ret.Result.IsTerminal() {
+			return ret, nil
+		}
+
+		e := white
+		if turn == board.Black {
+			e = black
+		}
+
+		opt := searchctl.Options{}
+		if limits.Depth > 0 {
+			opt.DepthLimit = lang.Some(limits.Depth)
+		}
+		if limits.Time > 0 {
+			if limits.Depth == 0 {
+				opt.DepthLimit = lang.Some(uint(0)) // no limit other than the clock
+			}
+			opt.TimeControl = lang.Some(searchctl.TimeControl{
+				White:    clock[board.White],
+				Black:    clock[board.Black],
+				WhiteInc: limits.Increment,
+				BlackInc: limits.Increment,
+			})
+		}
+
+		start := time.Now()
+		pv, err := bestLine(ctx, e, opt)
+		if err != nil {
+			return ret, err
+		}
+		if limits.Time > 0 {
+			if clock[turn] -= time.Since(start); clock[turn] <= 0 {
+				ret.Result = board.Result{Outcome: board.Loss(turn), Reason: board.TimedOut}
+				return ret, nil
+			}
+			clock[turn] += limits.Increment
+		}
+		if len(pv.Moves) == 0 {
+			return ret, fmt.Errorf("no move by %v in %v", e.Name(), e.Position())
+		}
+
+		if err := move(pv.Moves[0]); err != nil {
+			return ret, err
+		}
+		turn = turn.Opponent()
+
+		if result, ok := adjudicator.Adjudicate(ctx, white.Board(), pv.Score); ok {
+			ret.Result = result
+			return ret, nil
+		}
+	}
+}
+
+// bestLine searches the current position with the given options and returns the principal
+// variation of the last completed iteration.
+func bestLine(ctx context.Context, e *engine.Engine, opt searchctl.Options) (search.PV, error) {
+	out, err := e.Analyze(ctx, opt)
+	if err != nil {
+		return search.PV{}, err
+	}
+	var last search.PV
+	for pv := range out {
+		last = pv
+	}
+	if pv, err := e.Halt(ctx); err == nil && len(pv.Moves) > 0 {
+		last.Moves, last.Score = pv.Moves, pv.Score // move to play, such as a blunder
+	}
+	return last, nil
+}
+
+// resultTag returns the PGN result of the game, such as "1-0" or "*" if undecided.
+func resultTag(r board.Result) string {
+	if r.IsTerminal() {
+		return r.Outcome.String()
+	}
+	return "*"
+}
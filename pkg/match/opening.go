@@ -0,0 +1,50 @@
+package match
+
+import (
+	"fmt"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/epd"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/board/pgn"
+)
+
+// Opening is a starting position and any forced opening moves of a game.
+type Opening struct {
+	// Start is the starting position in FEN format.
+	Start string
+	// Moves are the opening moves played from the starting position, if any.
+	Moves []board.Move
+}
+
+func (o Opening) String() string {
+	if len(o.Moves) == 0 {
+		return o.Start
+	}
+	return fmt.Sprintf("%v %v", o.Start, board.PrintMoves(o.Moves))
+}
+
+// InitialOpening is the initial position with no opening moves.
+var InitialOpening = Opening{Start: fen.Initial}
+
+// OpeningsFromEPD returns the positions of the given EPD records as openings.
+func OpeningsFromEPD(records []epd.Record) []Opening {
+	var ret []Opening
+	for _, r := range records {
+		ret = append(ret, Opening{Start: r.FEN()})
+	}
+	return ret
+}
+
+// OpeningsFromPGN returns the first moves of the given games as openings, limited to the given
+// number of plies, if positive.
+func OpeningsFromPGN(games []pgn.Game, plies int) []Opening {
+	var ret []Opening
+	for _, g := range games {
+		moves := g.Moves
+		if plies > 0 && len(moves) > plies {
+			moves = moves[:plies]
+		}
+		ret = append(ret, Opening{Start: g.Start(), Moves: moves})
+	}
+	return ret
+}
@@ -0,0 +1,146 @@
+package match
+
+import (
+	"fmt"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/pgn"
+	"math"
+	"sort"
+	"strings"
+)
+
+// MaxElo is the cap of the absolute Elo difference, which is infinite for a perfect score.
+const MaxElo = 1000
+
+// Stats are the match statistics of a player against an opponent or the field.
+type Stats struct {
+	Player string `json:"player"`
+	// Opponent is the opponent, if head-to-head. Empty if against all opponents.
+	Opponent string `json:"opponent,omitempty"`
+	Wins     int    `json:"wins"`
+	Draws    int    `json:"draws"`
+	Losses   int    `json:"losses"`
+	// Score is the fraction of points scored in [0;1].
+	Score float64 `json:"score"`
+	// Elo is the estimated Elo difference to the opponent.
+	Elo float64 `json:"elo"`
+	// Error is the 95% confidence error margin of the Elo difference.
+	Error float64 `json:"error"`
+	// LOS is the likelihood of superiority in [0;1], i.e., the probability that the player
+	// is stronger than the opponent. Draws carry no information.
+	LOS float64 `json:"los"`
+}
+
+// NewStats returns the statistics of the given record.
+func NewStats(player, opponent string, r Record) Stats {
+	ret := Stats{Player: player, Opponent: opponent, Wins: r.Wins, Draws: r.Draws, Losses: r.Losses, LOS: 0.5}
+
+	n := float64(r.Games())
+	if n == 0 {
+		return ret
+	}
+
+	ret.Score = r.Points() / n
+	ret.Elo = eloDiff(ret.Score)
+
+	// Per-game variance of the score. The error margin is the half-width of the 95% confidence
+	// interval of the score, converted to Elo.
+
+	w, d, l := float64(r.Wins)/n, float64(r.Draws)/n, float64(r.Losses)/n
+	variance := w*math.Pow(1-ret.Score, 2) + d*math.Pow(0.5-ret.Score, 2) + l*math.Pow(ret.Score, 2)
+	margin := 1.959964 * math.Sqrt(variance/n)
+	ret.Error = (eloDiff(math.Min(ret.Score+margin, 1)) - eloDiff(math.Max(ret.Score-margin, 0))) / 2
+
+	if decisive := float64(r.Wins + r.Losses); decisive > 0 {
+		ret.LOS = 0.5 * (1 + math.Erf(float64(r.Wins-r.Losses)/math.Sqrt(2*decisive)))
+	}
+	return ret
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("%v: %v/%v, elo=%+.1f ±%.1f, los=%.1f%%", s.Player, s.Score, s.Wins+s.Draws+s.Losses, s.Elo, s.Error, 100*s.LOS)
+}
+
+// eloDiff returns the Elo difference for the given expected score, capped by MaxElo.
+func eloDiff(score float64) float64 {
+	if score <= 0 || score >= 1 {
+		return math.Copysign(MaxElo, score-0.5)
+	}
+	return math.Max(-MaxElo, math.Min(-400*math.Log10(1/score-1), MaxElo))
+}
+
+// Report holds the statistics of each player against the field, ordered by score, and of
+// each pair of players head-to-head.
+type Report struct {
+	Players []Stats `json:"players"`
+	Pairs   []Stats `json:"pairs"`
+}
+
+// NewReport returns the statistics of the given games. Undecided games are ignored.
+func NewReport(games []Game) Report {
+	type pair struct{ a, b string }
+	pairs := map[pair]Record{}
+	for _, g := range games {
+		a, b := pair{g.White, g.Black}, pair{g.Black, g.White}
+		ra, rb := pairs[a], pairs[b]
+		ra.Add(g.Result, board.White)
+		rb.Add(g.Result, board.Black)
+		pairs[a], pairs[b] = ra, rb
+	}
+
+	var ret Report
+	for name, r := range Standings(games) {
+		ret.Players = append(ret.Players, NewStats(name, "", r))
+	}
+	sort.Slice(ret.Players, func(i, j int) bool {
+		if ret.Players[i].Score != ret.Players[j].Score {
+			return ret.Players[i].Score > ret.Players[j].Score
+		}
+		return ret.Players[i].Player < ret.Players[j].Player
+	})
+	for p, r := range pairs {
+		if p.a < p.b && r.Games() > 0 {
+			ret.Pairs = append(ret.Pairs, NewStats(p.a, p.b, r))
+		}
+	}
+	sort.Slice(ret.Pairs, func(i, j int) bool {
+		if ret.Pairs[i].Player != ret.Pairs[j].Player {
+			return ret.Pairs[i].Player < ret.Pairs[j].Player
+		}
+		return ret.Pairs[i].Opponent < ret.Pairs[j].Opponent
+	})
+	return ret
+}
+
+func (r Report) String() string {
+	var sb strings.Builder
+	for i, s := range r.Players {
+		sb.WriteString(fmt.Sprintf("%2d. %-24v %3d  %+7.1f ±%5.1f  los=%5.1f%%  %v\n", i+1, s.Player, s.Wins+s.Draws+s.Losses, s.Elo, s.Error, 100*s.LOS, Record{Wins: s.Wins, Draws: s.Draws, Losses: s.Losses}))
+	}
+	for _, s := range r.Pairs {
+		sb.WriteString(fmt.Sprintf("    %-48v %3d  %+7.1f ±%5.1f  los=%5.1f%%  %v\n", s.Player+" - "+s.Opponent, s.Wins+s.Draws+s.Losses, s.Elo, s.Error, 100*s.LOS, Record{Wins: s.Wins, Draws: s.Draws, Losses: s.Losses}))
+	}
+	return sb.String()
+}
+
+// GamesFromPGN returns the players and results of the given PGN games, such as from a
+// previous match, for statistics. Games without a result are undecided.
+func GamesFromPGN(games []pgn.Game) []Game {
+	var ret []Game
+	for _, g := range games {
+		white, _ := g.Tag("White")
+		black, _ := g.Tag("Black")
+
+		result := board.Result{Outcome: board.Undecided}
+		switch g.Result {
+		case "1-0":
+			result.Outcome = board.WhiteWins
+		case "0-1":
+			result.Outcome = board.BlackWins
+		case "1/2-1/2":
+			result.Outcome = board.Draw
+		}
+		ret = append(ret, Game{White: white, Black: black, Opening: Opening{Start: g.Start()}, Moves: g.Moves, Result: result})
+	}
+	return ret
+}
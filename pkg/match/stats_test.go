@@ -0,0 +1,79 @@
+package match_test
+
+import (
+	"encoding/json"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/pgn"
+	"github.com/herohde/morlock/pkg/match"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	s := match.NewStats("a", "", match.Record{Wins: 6, Draws: 2, Losses: 2})
+	assert.InDelta(t, 0.7, s.Score, 0.0001)
+	assert.InDelta(t, 147.2, s.Elo, 0.1)
+	assert.InDelta(t, 0.921, s.LOS, 0.001)
+	assert.Greater(t, s.Error, 100.0) // few games
+
+	s = match.NewStats("a", "", match.Record{Draws: 10})
+	assert.Equal(t, 0.0, s.Elo)
+	assert.Equal(t, 0.0, s.Error)
+	assert.Equal(t, 0.5, s.LOS)
+
+	s = match.NewStats("a", "", match.Record{Losses: 3})
+	assert.Equal(t, -float64(match.MaxElo), s.Elo)
+	assert.Less(t, s.LOS, 0.05)
+
+	s = match.NewStats("a", "", match.Record{})
+	assert.Equal(t, match.Stats{Player: "a", LOS: 0.5}, s)
+}
+
+func TestReport(t *testing.T) {
+	games, err := pgn.Parse(`[White "a"]
+[Black "b"]
+[Result "1-0"]
+
+1-0
+
+[White "b"]
+[Black "a"]
+[Result "1/2-1/2"]
+
+1/2-1/2
+
+[White "c"]
+[Black "a"]
+[Result "0-1"]
+
+0-1
+
+[White "c"]
+[Black "b"]
+
+*
+`)
+	require.NoError(t, err)
+
+	list := match.GamesFromPGN(games)
+	require.Len(t, list, 4)
+	assert.Equal(t, board.Undecided, list[3].Result.Outcome)
+
+	r := match.NewReport(list)
+	require.Len(t, r.Players, 3)
+	assert.Equal(t, "a", r.Players[0].Player)
+	assert.Equal(t, 2, r.Players[0].Wins)
+	assert.Equal(t, 1, r.Players[0].Draws)
+	assert.Equal(t, "c", r.Players[2].Player)
+
+	require.Len(t, r.Pairs, 2)
+	assert.Equal(t, "a", r.Pairs[0].Player)
+	assert.Equal(t, "b", r.Pairs[0].Opponent)
+	assert.InDelta(t, 0.75, r.Pairs[0].Score, 0.0001)
+	assert.Equal(t, "c", r.Pairs[1].Opponent)
+
+	data, err := json.Marshal(r)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"player":"a","opponent":"b"`)
+}
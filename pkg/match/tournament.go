@@ -0,0 +1,221 @@
+package match
+
+import (
+	"context"
+	"fmt"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/engine"
+	"github.com/herohde/morlock/pkg/search"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Player is a named engine factory. A new engine is created for each game, so that games
+// can be played concurrently.
+type Player struct {
+	Name string
+	New  func(ctx context.Context) *engine.Engine
+}
+
+// ByColor returns a player that plays White with the search of the white player and Black
+// with the search of the black player, such as to compare rule interpretations within one
+// player. Each color uses its own transposition table of the hash size of its player, so
+// that no entries are shared. The remaining options, such as the depth, are those of the
+// white player.
+func ByColor(white, black Player) Player {
+	name := fmt.Sprintf("%v/%v", white.Name, black.Name)
+	return Player{
+		Name: name,
+		New: func(ctx context.Context) *engine.Engine {
+			w, b := white.New(ctx), black.New(ctx)
+
+			root := search.ByColor{
+				White:   w.Root(),
+				Black:   b.Root(),
+				WhiteTT: newTable(ctx, w),
+				BlackTT: newTable(ctx, b),
+			}
+			opts := w.Options()
+			opts.Hash = 0 // per color
+			return engine.New(ctx, name, "", root, engine.WithOptions(opts))
+		},
+	}
+}
+
+// newTable returns a transposition table as configured for the engine.
+func newTable(ctx context.Context, e *engine.Engine) search.TranspositionTable {
+	if hash := e.Options().Hash; hash > 0 && !e.Capabilities().NoTT {
+		return search.NewTranspositionTable(ctx, uint64(hash)<<20)
+	}
+	return search.NoTranspositionTable{}
+}
+
+// Pairing is a scheduled game between two players, given by index.
+type Pairing struct {
+	Round        int
+	White, Black int
+	Opening      Opening
+}
+
+// RoundRobin returns the pairings of a round robin tournament between the given number of
+// players. In each round, every pair of players plays a game with each color from the same
+// opening. The openings are used in order and repeated as needed.
+func RoundRobin(players, rounds int, openings []Opening) []Pairing {
+	var ret []Pairing
+	for r := 0; r < rounds; r++ {
+		for i := 0; i < players; i++ {
+			for j := i + 1; j < players; j++ {
+				ret = appendGamePair(ret, r+1, i, j, openings)
+			}
+		}
+	}
+	return ret
+}
+
+// Gauntlet returns the pairings of a gauntlet tournament, where the first player plays every
+// other player. In each round, every pair of players plays a game with each color from the
+// same opening. The openings are used in order and repeated as needed.
+func Gauntlet(players, rounds int, openings []Opening) []Pairing {
+	var ret []Pairing
+	for r := 0; r < rounds; r++ {
+		for j := 1; j < players; j++ {
+			ret = appendGamePair(ret, r+1, 0, j, openings)
+		}
+	}
+	return ret
+}
+
+func appendGamePair(list []Pairing, round, a, b int, openings []Opening) []Pairing {
+	opening := InitialOpening
+	if len(openings) > 0 {
+		opening = openings[(len(list)/2)%len(openings)]
+	}
+	return append(list, Pairing{Round: round, White: a, Black: b, Opening: opening}, Pairing{Round: round, White: b, Black: a, Opening: opening})
+}
+
+// Tournament is a set of games between players under the same limits and adjudication rules.
+type Tournament struct {
+	Players  []Player
+	Pairings []Pairing
+	Limits   Limits
+	Rules    Rules
+	// Concurrency is the number of games played at the same time. Default is 1.
+	Concurrency int
+}
+
+// Run plays all games of the tournament and returns them in pairing order. The given function
+// is called for each completed game, in completion order, such as to write it out. Games that
+// fail, such as due to an engine error, are returned undecided.
+func (t Tournament) Run(ctx context.Context, fn func(Pairing, Game)) []Game {
+	ret := make([]Game, len(t.Pairings))
+
+	concurrency := t.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	next := make(chan int)
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				p := t.Pairings[i]
+				white, black := t.Players[p.White], t.Players[p.Black]
+
+				g, err := Play(ctx, white.New(ctx), black.New(ctx), p.Opening, t.Limits, t.Rules)
+				if err != nil {
+					g.Result = board.Result{Outcome: board.Undecided, Reason: board.Reason(err.Error())}
+				}
+				g.White, g.Black = white.Name, black.Name
+
+				mu.Lock()
+				ret[i] = g
+				if fn != nil {
+					fn(p, g)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for i := range t.Pairings {
+		if ctx.Err() != nil {
+			break
+		}
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	return ret
+}
+
+// Record is the number of wins, draws and losses of a player. Undecided games are not counted.
+type Record struct {
+	Wins, Draws, Losses int
+}
+
+// Games returns the number of decided games.
+func (r Record) Games() int {
+	return r.Wins + r.Draws + r.Losses
+}
+
+// Points returns the number of points, with 1 for a win and 1/2 for a draw.
+func (r Record) Points() float64 {
+	return float64(r.Wins) + float64(r.Draws)/2
+}
+
+func (r Record) String() string {
+	return fmt.Sprintf("+%v =%v -%v", r.Wins, r.Draws, r.Losses)
+}
+
+// Add adds the result of a game from the point of view of the given color.
+func (r *Record) Add(result board.Result, c board.Color) {
+	switch result.Outcome {
+	case board.Win(c):
+		r.Wins++
+	case board.Loss(c):
+		r.Losses++
+	case board.Draw:
+		r.Draws++
+	}
+}
+
+// Standings returns the record of each player by name from the given games.
+func Standings(games []Game) map[string]Record {
+	ret := map[string]Record{}
+	for _, g := range games {
+		w, b := ret[g.White], ret[g.Black]
+		w.Add(g.Result, board.White)
+		b.Add(g.Result, board.Black)
+		ret[g.White], ret[g.Black] = w, b
+	}
+	return ret
+}
+
+// PrintStandings returns the standings as a table ordered by points.
+func PrintStandings(standings map[string]Record) string {
+	var names []string
+	for name := range standings {
+		names = append(names, name)
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		a, b := standings[names[i]], standings[names[j]]
+		if a.Points() != b.Points() {
+			return a.Points() > b.Points()
+		}
+		return names[i] < names[j]
+	})
+
+	var sb strings.Builder
+	for i, name := range names {
+		r := standings[name]
+		sb.WriteString(fmt.Sprintf("%2d. %-24v %5.1f/%-3v %v\n", i+1, name, r.Points(), r.Games(), r))
+	}
+	return sb.String()
+}
@@ -0,0 +1,113 @@
+package match_test
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/pgn"
+	"github.com/herohde/morlock/pkg/engine"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/match"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func material(name string) match.Player {
+	return match.Player{
+		Name: name,
+		New: func(ctx context.Context) *engine.Engine {
+			root := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+			return engine.New(ctx, name, "test", root, engine.WithOptions(engine.Options{Depth: 2}))
+		},
+	}
+}
+
+func TestByColor(t *testing.T) {
+	ctx := context.Background()
+
+	p := match.ByColor(material("a"), material("b"))
+	assert.Equal(t, "a/b", p.Name)
+
+	e := p.New(ctx)
+	assert.Equal(t, uint(2), e.Options().Depth)
+	assert.Equal(t, uint(0), e.Options().Hash)
+
+	g, err := match.Play(ctx, e, material("c").New(ctx), match.Opening{Start: "7k/8/6K1/8/8/8/8/5Q2 w - - 0 1"}, match.Limits{}, match.Rules{})
+	require.NoError(t, err)
+	assert.Equal(t, board.WhiteWins, g.Result.Outcome)
+}
+
+func TestSchedule(t *testing.T) {
+	openings := []match.Opening{{Start: "a"}, {Start: "b"}}
+
+	rr := match.RoundRobin(3, 2, openings)
+	require.Len(t, rr, 12)
+	assert.Equal(t, match.Pairing{Round: 1, White: 0, Black: 1, Opening: openings[0]}, rr[0])
+	assert.Equal(t, match.Pairing{Round: 1, White: 1, Black: 0, Opening: openings[0]}, rr[1])
+	assert.Equal(t, match.Pairing{Round: 1, White: 0, Black: 2, Opening: openings[1]}, rr[2])
+	assert.Equal(t, 2, rr[11].Round)
+
+	g := match.Gauntlet(3, 1, nil)
+	require.Len(t, g, 4)
+	for _, p := range g {
+		assert.True(t, p.White == 0 || p.Black == 0)
+		assert.Equal(t, match.InitialOpening, p.Opening)
+	}
+}
+
+func TestPlay(t *testing.T) {
+	ctx := context.Background()
+
+	a, b := material("a"), material("b")
+
+	// (1) Checkmate in the opening position.
+
+	g, err := match.Play(ctx, a.New(ctx), b.New(ctx), match.Opening{Start: "7k/8/6K1/8/8/8/8/5Q2 w - - 0 1"}, match.Limits{}, match.Rules{})
+	require.NoError(t, err)
+	assert.Equal(t, board.WhiteWins, g.Result.Outcome)
+	assert.Equal(t, board.Checkmate, g.Result.Reason)
+	assert.Len(t, g.Moves, 1)
+
+	// (2) Adjudicated by length under a clock.
+
+	g, err = match.Play(ctx, a.New(ctx), b.New(ctx), match.InitialOpening, match.Limits{Time: time.Minute, Depth: 1}, match.Rules{MaxMoves: 3})
+	require.NoError(t, err)
+	assert.Equal(t, board.Result{Outcome: board.Draw, Reason: match.MaxLengthAdjudication}, g.Result)
+	assert.Len(t, g.Moves, 6)
+
+	str, err := pgn.Encode(g.PGN("test", 1))
+	require.NoError(t, err)
+	assert.Contains(t, str, `[White "a `)
+	assert.Contains(t, str, `[Termination "Maximum game length"]`)
+	assert.True(t, strings.HasSuffix(str, " 1/2-1/2\n"), str)
+}
+
+func TestTournament(t *testing.T) {
+	ctx := context.Background()
+
+	tour := match.Tournament{
+		Players:     []match.Player{material("a"), material("b"), material("c")},
+		Pairings:    match.RoundRobin(3, 1, []match.Opening{{Start: "4k3/8/8/8/8/8/8/R3K3 w - - 0 1"}}),
+		Limits:      match.Limits{Depth: 1},
+		Rules:       match.Rules{MaxMoves: 2},
+		Concurrency: 2,
+	}
+
+	count := 0
+	games := tour.Run(ctx, func(p match.Pairing, g match.Game) {
+		count++
+	})
+	require.Len(t, games, 6)
+	assert.Equal(t, 6, count)
+	assert.Equal(t, "a", games[0].White)
+	assert.Equal(t, "b", games[0].Black)
+
+	standings := match.Standings(games)
+	for _, name := range []string{"a", "b", "c"} {
+		assert.Equal(t, match.Record{Draws: 4}, standings[name])
+	}
+	assert.Contains(t, match.PrintStandings(standings), " 1. a")
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/herohde/morlock/pkg/board"
 	"github.com/herohde/morlock/pkg/eval"
 	"github.com/seekerror/stdlib/pkg/util/contextx"
+	"time"
 )
 
 // AlphaBeta implements alpha-beta pruning. Pseudo-code:
@@ -34,16 +35,38 @@ import (
 type AlphaBeta struct {
 	Explore Exploration
 	Eval    QuietSearch
+	// NullMove, if positive, enables null-move pruning with the given depth reduction. A
+	// null-move cutoff is verified by a reduced search of the position without null moves to
+	// guard against zugzwang. Disabled by default to preserve the historical engines. Ignored
+	// if the evaluation declares null moves unsound.
+	NullMove int
+}
+
+func (p AlphaBeta) Capabilities() Capabilities {
+	return CapabilitiesOf(p.Eval)
 }
 
 func (p AlphaBeta) Search(ctx context.Context, sctx *Context, b *board.Board, depth int) (uint64, eval.Score, []board.Move, error) {
 	run := &runAlphaBeta{
-		explore: fullIfNotSet(p.Explore),
-		eval:    p.Eval,
-		tt:      sctx.TT,
-		noise:   sctx.Noise,
-		ponder:  sctx.Ponder,
-		b:       b,
+		explore:  fullIfNotSet(p.Explore),
+		eval:     p.Eval,
+		id:       sctx.ID,
+		tt:       sctx.TT,
+		noise:    sctx.Noise,
+		contempt: sctx.Contempt,
+		ponder:   sctx.Ponder,
+		order:    sctx.Order,
+		progress: sctx.Progress,
+		trace:    sctx.Trace,
+		stats:    sctx.Stats,
+		null:     p.NullMove,
+		b:        b,
+	}
+	if sctx.IsRestricted() {
+		run.root = sctx
+	}
+	if p.NullMove > 0 && CapabilitiesOf(p.Eval).NoNullMove {
+		run.null = 0 // not sound
 	}
 	low, high := eval.NegInfScore, eval.InfScore
 	if !sctx.Alpha.IsInvalid() {
@@ -61,57 +84,113 @@ func (p AlphaBeta) Search(ctx context.Context, sctx *Context, b *board.Board, de
 }
 
 type runAlphaBeta struct {
-	explore Exploration
-	eval    QuietSearch
-	tt      TranspositionTable
-	noise   eval.Random
-	b       *board.Board
-	nodes   uint64
+	explore  Exploration
+	eval     QuietSearch
+	id       ID
+	tt       TranspositionTable
+	noise    eval.Random
+	contempt Contempt
+	b        *board.Board
+	nodes    uint64
+	buf      []board.Move // move generation buffer
+
+	progress *Progress
+	trace    *Trace
+	stats    *Stats
+
+	null   int  // null-move depth reduction, if positive
+	height int  // plies from the root
+	verify bool // true iff null moves are disabled, such as during verification
 
 	ponder []board.Move
+	root   *Context   // root move restrictions, if any
+	order  *RootOrder // root move order, if any
 }
 
 // search returns the positive score for the color.
 func (m *runAlphaBeta) search(ctx context.Context, depth int, alpha, beta eval.Score) (eval.Score, []board.Move) {
+	if m.trace == nil {
+		score, pv, _ := m.visit(ctx, depth, alpha, beta)
+		return score, pv
+	}
+
+	move := ""
+	if last, ok := m.b.LastMove(); ok {
+		move = last.String()
+	} else if m.height > 0 {
+		move = "null"
+	}
+	m.trace.Enter(m.b.Key(), move, depth, alpha, beta)
+	score, pv, cutoff := m.visit(ctx, depth, alpha, beta)
+	m.trace.Exit(score, cutoff)
+	return score, pv
+}
+
+// visit searches the current node and returns the positive score for the color and the
+// reason the node was not fully searched, if any.
+func (m *runAlphaBeta) visit(ctx context.Context, depth int, alpha, beta eval.Score) (eval.Score, []board.Move, Cutoff) {
 	if contextx.IsCancelled(ctx) {
-		return eval.InvalidScore, nil
+		return eval.InvalidScore, nil, HaltedCutoff
 	}
-	if m.b.Result().Outcome == board.Draw {
-		return eval.ZeroScore, nil
+	if isSearchDraw(m.b, m.height) {
+		return m.contempt.DrawScore(m.b.Turn()), nil, DrawCutoff
 	}
 
+	root, order := m.root, m.order
+	m.root, m.order = nil, nil
+
+	// Probe the table. An entry at least as deep either determines the result or narrows the
+	// window. The bound stored afterwards is relative to the original window.
+
+	origAlpha, origBeta := alpha, beta
+
 	var best board.Move
-	if bound, d, score, m, ok := m.tt.Read(m.b.Hash()); ok {
-		best = m
-		if depth == d && bound == ExactBound {
-			// logw.Debugf(ctx, "TT: %v@%v = %v, %v", bound, d, score, move)
-			return score, nil // cutoff
-		} // else: not deep enough or precise enough
+	if bound, d, score, move, ok := m.probe(); ok {
+		best = move
+		if d >= depth && root == nil && m.height > 0 {
+			if isCutoff(bound, score, alpha, beta) {
+				// logw.Debugf(ctx, "TT: %v@%v = %v, %v", bound, d, score, move)
+				return score, nil, TableCutoff
+			}
+			alpha, beta = narrowWindow(bound, score, alpha, beta)
+		} // else: not deep enough
 	}
 
 	if depth == 0 {
-		sctx := &Context{Alpha: alpha, Beta: beta, TT: m.tt, Noise: m.noise}
+		sctx := &Context{ID: m.id, Alpha: alpha, Beta: beta, TT: m.tt, Noise: m.noise, Contempt: m.contempt, Progress: m.progress, Stats: m.stats}
 		nodes, score := m.eval.QuietSearch(ctx, sctx, m.b)
 		m.nodes += nodes
+		m.stats.AddQuiet(nodes)
 
-		m.tt.Write(m.b.Hash(), ExactBound, m.b.Ply(), 0, score, board.Move{})
-		return score, nil
+		m.stats.AddStore(m.tt.Write(m.b.Key(), boundOf(score, origAlpha, origBeta), m.b.Ply(), 0, score, board.Move{}))
+		return score, nil, HorizonCutoff
 	}
 
 	m.nodes++
+	m.progress.Add(1)
+	m.stats.AddNode()
+
+	if score, ok := m.searchNullMove(ctx, depth, alpha, beta); ok {
+		return score, nil, NullMoveCutoff
+	}
 
 	hasLegalMove := false
-	bound := ExactBound
+	searched := 0 // number of searched moves
 	var pv []board.Move
 
-	priority, explore := m.explore(ctx, m.b)
+	priority, explore := m.explore(ctx, m.b, depth)
 
 	if len(m.ponder) > 0 {
 		explore = m.ponder[0].Equals // overwrite: use ponder move even if not intended to be explored
 		m.ponder = m.ponder[1:]
 	}
 
-	moves := board.NewMoveList(m.b.Position().PseudoLegalMoves(m.b.Turn()), board.First(best, priority))
+	priority = board.First(best, priority)
+	if order != nil && order.Len() > 0 {
+		priority = order.Priority(priority) // previous iteration takes precedence at the root
+	}
+
+	moves := board.NewMoveList(candidateMoves(m.b, &m.buf), priority) // copied: buffer can be reused
 	for {
 		move, ok := moves.Next()
 		if !ok {
@@ -121,40 +200,107 @@ func (m *runAlphaBeta) search(ctx context.Context, depth int, alpha, beta eval.S
 			continue // skip: not legal
 		}
 
-		if explore(move) {
-			score, rem := m.search(ctx, depth-1, beta.Negate(), alpha.Negate())
+		if explore(move) && (root == nil || root.IsRootMove(move)) {
+			nodes, start := m.nodes, time.Now()
+			low, high := childWindow(alpha, beta)
+			m.height++
+			score, rem := m.search(ctx, depth-1, low, high)
+			m.height--
 			score = eval.IncrementMateDistance(score).Negate()
-			if alpha.Less(score) {
+			raised := alpha.Less(score)
+			if raised {
 				alpha = score
 				pv = append([]board.Move{move}, rem...)
 			}
+			if order != nil && !contextx.IsCancelled(ctx) {
+				order.Update(move, score, raised, m.nodes-nodes, time.Since(start))
+			}
+			searched++
 		}
 
 		m.b.PopMove()
 		hasLegalMove = true
 
 		if alpha == beta || beta.Less(alpha) {
-			bound = LowerBound
+			m.stats.AddCutoff(searched - 1)
 			break // cutoff
 		}
 	}
 
 	if !hasLegalMove {
 		if result := m.b.AdjudicateNoLegalMoves(); result.Reason == board.Checkmate {
-			return eval.NegInfScore, nil
+			return eval.NegInfScore, nil, MateCutoff
 		}
-		return eval.ZeroScore, nil
+		return m.contempt.DrawScore(m.b.Turn()), nil, StalemateCutoff
+	}
+
+	if root == nil && !contextx.IsCancelled(ctx) {
+		m.stats.AddStore(m.tt.Write(m.b.Key(), boundOf(alpha, origAlpha, origBeta), m.b.Ply(), depth, alpha, firstOr(pv, best)))
 	}
+	if !alpha.Less(beta) {
+		return alpha, pv, BetaCutoff
+	}
+	return alpha, pv, NoCutoff
+}
 
-	if bound == ExactBound {
-		m.tt.Write(m.b.Hash(), bound, m.b.Ply(), depth, alpha, firstOrNone(pv))
+// probe reads the table entry of the current position, if any.
+func (m *runAlphaBeta) probe() (Bound, int, eval.Score, board.Move, bool) {
+	bound, depth, score, move, ok := m.tt.Read(m.b.Key())
+	m.stats.AddProbe(ok)
+	return bound, depth, score, move, ok
+}
+
+// searchNullMove tries a null-move cutoff: if passing the turn still fails high in a reduced
+// search, the position is assumed to fail high as well. The cutoff is then verified by a
+// reduced search without null moves. Null moves are not tried at the root, in check, when
+// pondering, for mate bounds or if the side to move has only pawns left, where zugzwang is
+// common. Returns the score and true iff cutoff.
+func (m *runAlphaBeta) searchNullMove(ctx context.Context, depth int, alpha, beta eval.Score) (eval.Score, bool) {
+	if m.null <= 0 || m.verify || m.height == 0 || depth <= m.null || len(m.ponder) > 0 || !beta.IsHeuristic() {
+		return eval.InvalidScore, false
+	}
+	pos, turn := m.b.Position(), m.b.Turn()
+	if pos.Piece(turn, board.Knight)|pos.Piece(turn, board.Bishop)|pos.Piece(turn, board.Rook)|pos.Piece(turn, board.Queen) == 0 {
+		return eval.InvalidScore, false
 	}
-	return alpha, pv
+	if !m.b.PushNullMove() {
+		return eval.InvalidScore, false // in check
+	}
+
+	low, high := childWindow(alpha, beta)
+	m.height++
+	score, _ := m.search(ctx, depth-1-m.null, low, high)
+	m.height--
+	m.b.PopNullMove()
+
+	if contextx.IsCancelled(ctx) {
+		return eval.InvalidScore, false
+	}
+	if score = eval.IncrementMateDistance(score).Negate(); score.Less(beta) {
+		return eval.InvalidScore, false
+	}
+
+	m.stats.AddResearch()
+	m.verify = true
+	score, _ = m.search(ctx, depth-m.null, alpha, beta)
+	m.verify = false
+
+	if contextx.IsCancelled(ctx) || score.Less(beta) {
+		return eval.InvalidScore, false
+	}
+	return score, true
+}
+
+// childWindow returns the [alpha;beta] window as viewed from a child node, where mates are
+// one ply closer.
+func childWindow(alpha, beta eval.Score) (eval.Score, eval.Score) {
+	return eval.DecrementMateDistance(beta).Negate(), eval.DecrementMateDistance(alpha).Negate()
 }
 
-func firstOrNone(pv []board.Move) board.Move {
+// firstOr returns the first move of the line, if any, or the given fallback move.
+func firstOr(pv []board.Move, fallback board.Move) board.Move {
 	if len(pv) == 0 {
-		return board.Move{}
+		return fallback
 	}
 	return pv[0]
 }
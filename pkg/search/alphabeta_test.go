@@ -2,6 +2,7 @@ package search_test
 
 import (
 	"context"
+	"github.com/herohde/morlock/pkg/board"
 	"github.com/herohde/morlock/pkg/board/fen"
 	"github.com/herohde/morlock/pkg/eval"
 	"github.com/herohde/morlock/pkg/search"
@@ -87,3 +88,258 @@ func BenchmarkAlphaBeta1_TT(b *testing.B) {
 		s.Search(ctx, &search.Context{TT: tt}, pos, 4)
 	}
 }
+
+func TestAlphaBetaRootMoves(t *testing.T) {
+	ctx := context.Background()
+
+	b, err := fen.NewBoard("k7/7R/6R1/8/8/8/8/7K w - - 0 1")
+	require.NoError(t, err)
+
+	pvs := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+
+	m, err := board.ParseMove("g6g8")
+	require.NoError(t, err)
+	alt, err := board.ParseMove("h7h8")
+	require.NoError(t, err)
+
+	_, score, moves, err := pvs.Search(ctx, &search.Context{TT: search.NoTranspositionTable{}, Moves: []board.Move{m, alt}}, b, 2)
+	require.NoError(t, err)
+	assert.Equal(t, eval.MateInXScore(1), score)
+	assert.True(t, m.Equals(moves[0]))
+
+	_, score, moves, err = pvs.Search(ctx, &search.Context{TT: search.NoTranspositionTable{}, Moves: []board.Move{alt}}, b, 2)
+	require.NoError(t, err)
+	assert.True(t, score.IsHeuristic())
+	assert.True(t, alt.Equals(moves[0]))
+
+	_, _, moves, err = pvs.Search(ctx, &search.Context{TT: search.NoTranspositionTable{}, Moves: []board.Move{m, alt}, Exclude: []board.Move{m}}, b, 2)
+	require.NoError(t, err)
+	assert.True(t, alt.Equals(moves[0]))
+}
+
+func TestAlphaBetaContempt(t *testing.T) {
+	ctx := context.Background()
+
+	// White can capture the knight into a draw by insufficient material or stay a knight down.
+
+	b, err := fen.NewBoard("8/8/8/4k3/8/8/3n4/4K3 w - - 0 1")
+	require.NoError(t, err)
+
+	ab := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+
+	tests := []struct {
+		contempt eval.Pawns
+		expected eval.Score
+		capture  bool
+	}{
+		{0, eval.ZeroScore, true},
+		{1, eval.HeuristicScore(-1), true},
+		{5, eval.HeuristicScore(-3), false},
+		{-1, eval.HeuristicScore(1), true},
+	}
+
+	for _, tt := range tests {
+		sctx := &search.Context{TT: search.NoTranspositionTable{}, Contempt: search.Contempt{Side: board.White, Pawns: tt.contempt}}
+		_, score, moves, err := ab.Search(ctx, sctx, b, 1)
+		require.NoError(t, err)
+		require.NotEmpty(t, moves)
+
+		assert.Equalf(t, tt.expected, score, "failed: %v", tt.contempt)
+		assert.Equalf(t, tt.capture, moves[0].String() == "Ke1*d2", "failed: %v: %v", tt.contempt, moves[0])
+	}
+
+	// The opponent values the draw the other way.
+
+	c := search.Contempt{Side: board.White, Pawns: 0.5}
+	assert.Equal(t, eval.HeuristicScore(-0.5), c.DrawScore(board.White))
+	assert.Equal(t, eval.HeuristicScore(0.5), c.DrawScore(board.Black))
+	assert.Equal(t, eval.ZeroScore, search.Contempt{}.DrawScore(board.Black))
+}
+
+func TestAlphaBetaRepetition(t *testing.T) {
+	ctx := context.Background()
+
+	// A repetition along the search path is a draw, but not a repetition of a position before
+	// the search root.
+
+	ab := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+	contempt := search.Contempt{Side: board.White, Pawns: 1}
+
+	var shuffle []board.Move
+	for _, str := range []string{"g1f3", "g8f6", "f3g1", "f6g8"} {
+		m, err := board.ParseMove(str)
+		require.NoError(t, err)
+		shuffle = append(shuffle, m)
+	}
+
+	b, err := fen.NewBoard(fen.Initial)
+	require.NoError(t, err)
+
+	_, score, _, err := ab.Search(ctx, &search.Context{TT: search.NoTranspositionTable{}, Ponder: shuffle, Contempt: contempt}, b, 5)
+	require.NoError(t, err)
+	assert.Equal(t, eval.HeuristicScore(-1), score)
+
+	b, err = fen.NewBoard(fen.Initial, "g1f3", "g8f6", "f3g1")
+	require.NoError(t, err)
+
+	_, score, _, err = ab.Search(ctx, &search.Context{TT: search.NoTranspositionTable{}, Ponder: shuffle[3:], Contempt: search.Contempt{Side: board.Black, Pawns: 1}}, b, 2)
+	require.NoError(t, err)
+	assert.Equal(t, eval.ZeroScore, score)
+}
+
+func TestAlphaBetaBounds(t *testing.T) {
+	ctx := context.Background()
+
+	qs := search.Quiescence{Explore: search.CaptureExploration, Eval: search.Leaf{Eval: eval.Material{}}}
+	ab := search.AlphaBeta{Eval: qs}
+
+	t.Run("root", func(t *testing.T) {
+		tests := []struct {
+			alpha, beta eval.Score
+			expected    search.Bound
+		}{
+			{eval.NegInfScore, eval.InfScore, search.ExactBound},
+			{eval.HeuristicScore(5), eval.HeuristicScore(6), search.UpperBound},   // fail-low
+			{eval.HeuristicScore(-6), eval.HeuristicScore(-5), search.LowerBound}, // fail-high
+		}
+
+		for _, tt := range tests {
+			b, err := fen.NewBoard(fen.Initial)
+			require.NoError(t, err)
+
+			table := search.NewTranspositionTable(ctx, 1<<20)
+			sctx := &search.Context{Alpha: tt.alpha, Beta: tt.beta, TT: table}
+			_, _, _, err = ab.Search(ctx, sctx, b, 2)
+			require.NoError(t, err)
+
+			bound, depth, _, _, ok := table.Read(b.Key())
+			require.True(t, ok)
+			assert.Equal(t, tt.expected, bound, "window: [%v;%v]", tt.alpha, tt.beta)
+			assert.Equal(t, 2, depth)
+		}
+	})
+
+	t.Run("window", func(t *testing.T) {
+		// Narrow window searches must not poison a later full-window search.
+
+		positions := []string{
+			"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+			"r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10",
+			"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+		}
+
+		for _, pos := range positions {
+			b, err := fen.NewBoard(pos)
+			require.NoError(t, err)
+
+			_, expected, _, err := ab.Search(ctx, search.EmptyContext, b, 3)
+			require.NoError(t, err)
+
+			table := search.NewTranspositionTable(ctx, 1<<20)
+			for _, w := range []eval.Pawns{-2, -1, 0, 1, 2} {
+				sctx := &search.Context{Alpha: eval.HeuristicScore(w), Beta: eval.HeuristicScore(w + 0.5), TT: table}
+				_, _, _, err := ab.Search(ctx, sctx, b, 3)
+				require.NoError(t, err)
+			}
+
+			_, actual, _, err := ab.Search(ctx, &search.Context{TT: table}, b, 3)
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual, "pos: %v", pos)
+		}
+	})
+
+	t.Run("deeper", func(t *testing.T) {
+		// Entries from a deeper search cut off a shallower search below the root.
+
+		b, err := fen.NewBoard("r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10")
+		require.NoError(t, err)
+
+		fresh, _, _, err := ab.Search(ctx, &search.Context{TT: search.NewTranspositionTable(ctx, 1<<20)}, b, 3)
+		require.NoError(t, err)
+
+		table := search.NewTranspositionTable(ctx, 1<<20)
+		_, _, _, err = ab.Search(ctx, &search.Context{TT: table}, b, 4)
+		require.NoError(t, err)
+
+		nodes, _, moves, err := ab.Search(ctx, &search.Context{TT: table}, b, 3)
+		require.NoError(t, err)
+		assert.NotEmpty(t, moves)
+		assert.Less(t, nodes, fresh/2, "no deeper cutoffs")
+	})
+}
+
+func TestAlphaBetaLongestResistance(t *testing.T) {
+	ctx := context.Background()
+
+	// Black is lost: Kg8 allows Rb8# (mate-in-1), while Ra8 only loses the Rook before
+	// Qxa8# (mate-in-2). The search must prefer the longest resistance.
+
+	b, err := fen.NewBoard("7k/8/6K1/8/8/8/r7/1R3Q2 b - - 0 1")
+	require.NoError(t, err)
+
+	minimax := search.Minimax{Eval: search.Leaf{Eval: eval.Material{}}}
+	ab := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+
+	_, expected, _, err := minimax.Search(ctx, search.EmptyContext, b, 5)
+	require.NoError(t, err)
+	assert.Equal(t, eval.MateInXScore(-4), expected)
+
+	for _, sctx := range []*search.Context{search.EmptyContext, {TT: search.NewTranspositionTable(ctx, 1<<20)}} {
+		_, score, moves, err := ab.Search(ctx, sctx, b, 5)
+		require.NoError(t, err)
+		assert.Equal(t, expected, score)
+		require.NotEmpty(t, moves)
+		assert.NotEqual(t, "Kh8-g8", moves[0].String())
+	}
+}
+
+func TestAlphaBetaNullMove(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		fen   string
+		depth int
+	}{
+		{fen.Initial, 4},
+		{"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", 4},
+		{"r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10", 4},
+		{"k7/7R/7R/8/8/8/8/7K w - - 0 1", 4},
+		{"8/8/8/8/8/2k5/p7/K7 b - - 0 1", 5}, // zugzwang: pawns only
+	}
+
+	plain := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+	null := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}, NullMove: 2}
+
+	for _, tt := range tests {
+		b, err := fen.NewBoard(tt.fen)
+		require.NoError(t, err)
+
+		n, expected, _, _ := plain.Search(ctx, search.EmptyContext, b, tt.depth)
+		n2, actual, _, _ := null.Search(ctx, search.EmptyContext, b, tt.depth)
+		t.Logf("POS: %v; NODES: %v (null move %v)", tt.fen, n, n2)
+
+		assert.Equalf(t, expected, actual, "failed: %v", tt.fen)
+		assert.Equalf(t, tt.fen, fen.Encode(b.Position(), b.Turn(), b.NoProgress(), b.FullMoves()), "board not restored: %v", tt.fen)
+	}
+}
+
+func TestAlphaBetaStats(t *testing.T) {
+	ctx := context.Background()
+
+	b, err := fen.NewBoard(fen.Initial)
+	require.NoError(t, err)
+
+	stats := &search.Stats{}
+	sctx := &search.Context{TT: search.NewTranspositionTable(ctx, 1<<16), Stats: stats}
+
+	ab := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+	nodes, _, _, err := ab.Search(ctx, sctx, b, 3)
+	require.NoError(t, err)
+
+	assert.Equal(t, nodes, stats.Nodes+stats.QuietNodes)
+	assert.Positive(t, stats.Cutoffs)
+	assert.Positive(t, stats.CutoffIndex[0])
+	assert.Positive(t, stats.Probes)
+	assert.Positive(t, stats.Stores)
+	assert.LessOrEqual(t, stats.Hits, stats.Probes)
+}
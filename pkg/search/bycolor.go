@@ -0,0 +1,38 @@
+package search
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/eval"
+)
+
+// ByColor is a root search that dispatches to a color-specific search based on the side to
+// move at the root. It allows asymmetric configurations within a single process, such as
+// different rule interpretations for White and Black, for controlled experiments. If given,
+// each color searches with its own transposition table instead of the table of the context,
+// so that entries of one configuration are never used by the other.
+type ByColor struct {
+	White, Black     Search
+	WhiteTT, BlackTT TranspositionTable
+}
+
+func (s ByColor) Capabilities() Capabilities {
+	return CapabilitiesOf(s.White).Merge(CapabilitiesOf(s.Black))
+}
+
+func (s ByColor) Search(ctx context.Context, sctx *Context, b *board.Board, depth int) (uint64, eval.Score, []board.Move, error) {
+	if b.Turn() == board.White {
+		return s.White.Search(ctx, withTable(sctx, s.WhiteTT), b, depth)
+	}
+	return s.Black.Search(ctx, withTable(sctx, s.BlackTT), b, depth)
+}
+
+// withTable returns a copy of the context with the given transposition table, if not nil.
+func withTable(sctx *Context, tt TranspositionTable) *Context {
+	if tt == nil {
+		return sctx
+	}
+	c := *sctx
+	c.TT = tt
+	return &c
+}
@@ -0,0 +1,73 @@
+package search_test
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestByColor(t *testing.T) {
+	ctx := context.Background()
+
+	// White only sees material. Black sees nothing and picks the first legal move.
+
+	s := search.ByColor{
+		White: search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}},
+		Black: search.AlphaBeta{Eval: search.Leaf{Eval: zero{}}},
+	}
+
+	b, err := fen.NewBoard("4k3/8/8/8/3q4/8/8/3QK3 w - - 0 1")
+	require.NoError(t, err)
+
+	_, score, moves, err := s.Search(ctx, search.EmptyContext, b, 1)
+	require.NoError(t, err)
+	assert.Equal(t, eval.HeuristicScore(9), score)
+	assert.Equal(t, "Qd1*d4", moves[0].String())
+
+	b, err = fen.NewBoard("4k3/8/8/8/3q4/8/8/3QK3 b - - 0 1")
+	require.NoError(t, err)
+
+	_, score, _, err = s.Search(ctx, search.EmptyContext, b, 1)
+	require.NoError(t, err)
+	assert.Equal(t, eval.ZeroScore, score)
+}
+
+func TestByColorTables(t *testing.T) {
+	ctx := context.Background()
+
+	s := search.ByColor{
+		White:   search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}},
+		Black:   search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}},
+		WhiteTT: search.NewTranspositionTable(ctx, 1<<20),
+		BlackTT: search.NewTranspositionTable(ctx, 1<<20),
+	}
+	shared := search.NewTranspositionTable(ctx, 1<<20)
+
+	b, err := fen.NewBoard("4k3/8/8/8/3q4/8/8/3QK3 w - - 0 1")
+	require.NoError(t, err)
+
+	_, _, _, err = s.Search(ctx, &search.Context{TT: shared}, b, 2)
+	require.NoError(t, err)
+	assert.Positive(t, s.WhiteTT.Used())
+	assert.Zero(t, s.BlackTT.Used())
+	assert.Zero(t, shared.Used())
+
+	b, err = fen.NewBoard("4k3/8/8/8/3q4/8/8/3QK3 b - - 0 1")
+	require.NoError(t, err)
+
+	_, _, _, err = s.Search(ctx, &search.Context{TT: shared}, b, 2)
+	require.NoError(t, err)
+	assert.Positive(t, s.BlackTT.Used())
+	assert.Zero(t, shared.Used())
+}
+
+type zero struct{}
+
+func (zero) Evaluate(ctx context.Context, b *board.Board) eval.Pawns {
+	return 0
+}
@@ -0,0 +1,72 @@
+package search
+
+import (
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/eval"
+)
+
+// Capabilities describe what a search composition requires and supports, so that the engine
+// and drivers can configure it correctly without knowledge of the particular engine. The zero
+// value is a search without special requirements that supports all features.
+type Capabilities struct {
+	// History is the history-dependent state the evaluation depends on, such as whether a
+	// side has castled. It must be part of the search key for caching to be sound.
+	History board.KeyOptions
+	// NoTT indicates that transposition tables are not sound, such as for an evaluation
+	// relative to the search root.
+	NoTT bool
+	// NoNullMove indicates that null-move pruning is not sound.
+	NoNullMove bool
+	// NoPonder indicates that the search cannot ponder on the opponent's time.
+	NoPonder bool
+	// NoChess960 indicates that the search does not support Chess960.
+	NoChess960 bool
+	// OwnTime indicates that the search takes as long as the move takes, such as waiting for
+	// a move on an external board. Time controls then only limit the search to the remaining
+	// time on the clock instead of a budget. The search must observe the context deadline.
+	OwnTime bool
+}
+
+// Capable is an optional interface for search components to declare their capabilities.
+// Components that wrap other components should merge their capabilities.
+type Capable interface {
+	Capabilities() Capabilities
+}
+
+// CapabilitiesOf returns the declared capabilities of the search component, such as a Search,
+// QuietSearch or Evaluator. The capabilities of a sum of evaluators are merged. Returns the
+// zero value if not declared.
+func CapabilitiesOf(v any) Capabilities {
+	switch c := v.(type) {
+	case Capable:
+		return c.Capabilities()
+	case eval.Sum:
+		var ret Capabilities
+		for _, ev := range c {
+			ret = ret.Merge(CapabilitiesOf(ev))
+		}
+		return ret
+	default:
+		return Capabilities{}
+	}
+}
+
+// Merge returns the capabilities of a composition of components, which has the requirements
+// of both and supports only what both support.
+func (c Capabilities) Merge(o Capabilities) Capabilities {
+	ret := Capabilities{
+		History: board.KeyOptions{
+			Castled:    c.History.Castled || o.History.Castled,
+			MoveBucket: c.History.MoveBucket,
+		},
+		NoTT:       c.NoTT || o.NoTT,
+		NoNullMove: c.NoNullMove || o.NoNullMove,
+		NoPonder:   c.NoPonder || o.NoPonder,
+		NoChess960: c.NoChess960 || o.NoChess960,
+		OwnTime:    c.OwnTime || o.OwnTime,
+	}
+	if o.History.MoveBucket > 0 && (ret.History.MoveBucket == 0 || o.History.MoveBucket < ret.History.MoveBucket) {
+		ret.History.MoveBucket = o.History.MoveBucket // finer bucket
+	}
+	return ret
+}
@@ -0,0 +1,40 @@
+package search_test
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// historical is a fake evaluator with declared capabilities.
+type historical struct {
+	caps search.Capabilities
+}
+
+func (h historical) Capabilities() search.Capabilities {
+	return h.caps
+}
+
+func (historical) Evaluate(ctx context.Context, b *board.Board) eval.Pawns {
+	return 0
+}
+
+func TestCapabilities(t *testing.T) {
+	castled := historical{caps: search.Capabilities{History: board.KeyOptions{Castled: true}, NoTT: true}}
+	bucket := historical{caps: search.Capabilities{History: board.KeyOptions{MoveBucket: 10}, NoPonder: true, OwnTime: true}}
+
+	assert.Equal(t, search.Capabilities{}, search.CapabilitiesOf(search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}))
+
+	s := search.AlphaBeta{Eval: search.Quiescence{Eval: search.Leaf{Eval: castled}}}
+	assert.Equal(t, castled.caps, search.CapabilitiesOf(s))
+
+	root := search.Stalemate{Eval: search.Swindle{Eval: s, Opponent: search.AlphaBeta{Eval: search.Leaf{Eval: bucket}}}}
+	expected := search.Capabilities{History: board.KeyOptions{Castled: true, MoveBucket: 10}, NoTT: true, NoPonder: true, OwnTime: true}
+	assert.Equal(t, expected, search.CapabilitiesOf(root))
+
+	sum := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Sum{castled, eval.NewKingSafety(), bucket}}}
+	assert.Equal(t, expected, search.CapabilitiesOf(sum))
+}
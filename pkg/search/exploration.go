@@ -6,15 +6,20 @@ import (
 	"github.com/herohde/morlock/pkg/eval"
 )
 
-// Exploration defines move selection and priority in a given position. Limited exploration is required
-// by quiescence search and can be used for forward pruning in full search. Default: explore all
-// moves in MVVLVA order.
-type Exploration func(ctx context.Context, b *board.Board) (board.MovePriorityFn, board.MovePredicateFn)
+// Exploration defines move selection and priority in a given position at the given remaining depth,
+// which is zero in quiescence search. Limited exploration is required by quiescence search and can be
+// used for forward pruning in full search. Default: explore all moves in MVVLVA order.
+type Exploration func(ctx context.Context, b *board.Board, depth int) (board.MovePriorityFn, board.MovePredicateFn)
 
-func FullExploration(ctx context.Context, b *board.Board) (board.MovePriorityFn, board.MovePredicateFn) {
+func FullExploration(ctx context.Context, b *board.Board, depth int) (board.MovePriorityFn, board.MovePredicateFn) {
 	return MVVLVA, IsAnyMove
 }
 
+// CaptureExploration explores captures and promotions in MVV-LVA order. Suitable for quiescence search.
+func CaptureExploration(ctx context.Context, b *board.Board, depth int) (board.MovePriorityFn, board.MovePredicateFn) {
+	return MVVLVA, IsCaptureOrPromotion
+}
+
 // Selection returns a move order and priority for exploring the given moves.
 func Selection(list []board.Move) (board.MovePriorityFn, board.MovePredicateFn) {
 	rank := map[board.Move]board.MovePriority{}
@@ -44,3 +49,8 @@ func MVVLVA(m board.Move) board.MovePriority {
 func IsAnyMove(m board.Move) bool {
 	return true
 }
+
+// IsCaptureOrPromotion selects captures, incl. en passant, and promotions.
+func IsCaptureOrPromotion(m board.Move) bool {
+	return m.IsCaptureOrEnPassant() || m.IsPromotion()
+}
@@ -0,0 +1,24 @@
+package search
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// ID is a process-unique search ID. It is included in log lines and info strings to
+// correlate interleaved output from pondering, analysis and match play. Zero if none.
+type ID uint64
+
+var lastID atomic.Uint64
+
+// NewID returns a new search ID.
+func NewID() ID {
+	return ID(lastID.Add(1))
+}
+
+func (id ID) String() string {
+	if id == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("#%d", uint64(id))
+}
@@ -30,6 +30,10 @@ type Minimax struct {
 	Eval Evaluator
 }
 
+func (m Minimax) Capabilities() Capabilities {
+	return CapabilitiesOf(m.Eval)
+}
+
 func (m Minimax) Search(ctx context.Context, sctx *Context, b *board.Board, depth int) (uint64, eval.Score, []board.Move, error) {
 	run := &runMinimax{eval: m.Eval, b: b}
 	score, moves := run.search(ctx, sctx, depth)
@@ -43,17 +47,19 @@ type runMinimax struct {
 	eval  Evaluator
 	b     *board.Board
 	nodes uint64
+	buf   [][]board.Move // move generation buffers by depth
 }
 
 // search returns the positive score for the color.
 func (m *runMinimax) search(ctx context.Context, sctx *Context, depth int) (eval.Score, []board.Move) {
 	m.nodes++
+	sctx.Progress.Add(1)
 
 	if contextx.IsCancelled(ctx) {
 		return eval.ZeroScore, nil
 	}
 	if m.b.Result().Outcome == board.Draw {
-		return eval.ZeroScore, nil
+		return sctx.Contempt.DrawScore(m.b.Turn()), nil
 	}
 	if depth == 0 {
 		return eval.HeuristicScore(m.eval.Evaluate(ctx, sctx, m.b)), nil
@@ -63,7 +69,10 @@ func (m *runMinimax) search(ctx context.Context, sctx *Context, depth int) (eval
 	score := eval.NegInfScore
 	var pv []board.Move
 
-	moves := m.b.Position().PseudoLegalMoves(m.b.Turn())
+	for len(m.buf) <= depth {
+		m.buf = append(m.buf, nil)
+	}
+	moves := candidateMoves(m.b, &m.buf[depth])
 	for _, move := range moves {
 		if m.b.PushMove(move) {
 			s, rem := m.search(ctx, sctx, depth-1)
@@ -82,7 +91,7 @@ func (m *runMinimax) search(ctx context.Context, sctx *Context, depth int) (eval
 		if result := m.b.AdjudicateNoLegalMoves(); result.Reason == board.Checkmate {
 			return eval.NegInfScore, nil
 		}
-		return eval.ZeroScore, nil
+		return sctx.Contempt.DrawScore(m.b.Turn()), nil
 	}
 
 	return score, pv
@@ -0,0 +1,125 @@
+package search
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/eval"
+)
+
+// MTDf implements the MTD(f) root driver, which converges on the score with a series of
+// minimal window searches around a guess. It relies on a transposition table to make the
+// repeated searches cheap and uses any root entry as the first guess. Pseudo-code:
+//
+// function mtdf(root, f, d) is
+//
+//	g := f
+//	upperBound := +∞
+//	lowerBound := −∞
+//	while lowerBound < upperBound do
+//	    β := max(g, lowerBound + 1)
+//	    g := alphabeta(root, β − 1, β, d)
+//	    if g < β then
+//	        upperBound := g
+//	    else
+//	        lowerBound := g
+//	return g
+//
+// Scores are fractional pawns, so the minimal window is Epsilon wide. The test value moves
+// by a step that doubles while the search keeps failing in the same direction, because a
+// fail-hard search only returns the window bound on fail low. Mate scores fall back to a full
+// window search, as are searches that do not converge.
+//
+// See: https://people.csail.mit.edu/plaat/mtdf.html.
+type MTDf struct {
+	// Eval is the underlying minimal window search, usually AlphaBeta.
+	Eval Search
+	// Epsilon is the minimal window width in pawns. If zero, DefaultMTDfEpsilon is used.
+	Epsilon eval.Pawns
+}
+
+const DefaultMTDfEpsilon eval.Pawns = 0.01
+
+// maxMTDfPasses is the maximum number of minimal window searches before falling back to a full
+// window search. A fail-hard search may never reveal a mate score in a minimal window, but
+// keep failing high (or low) with the bound.
+const maxMTDfPasses = 32
+
+func (s MTDf) Capabilities() Capabilities {
+	return CapabilitiesOf(s.Eval)
+}
+
+func (s MTDf) Search(ctx context.Context, sctx *Context, b *board.Board, depth int) (uint64, eval.Score, []board.Move, error) {
+	eps := s.epsilon()
+
+	guess := eval.ZeroScore
+	if sctx.TT != nil {
+		if _, _, score, _, ok := sctx.TT.Read(b.Key()); ok && score.IsHeuristic() {
+			guess = score
+		}
+	}
+
+	var nodes uint64
+	lower, upper := eval.NegInfScore, eval.InfScore
+	var pv []board.Move // line of the lower bound
+
+	test, step, high := guess.Pawns, eps, false
+	for i := 0; i < maxMTDfPasses; i++ {
+		window := *sctx
+		window.Alpha, window.Beta = eval.HeuristicScore(test-eps), eval.HeuristicScore(test)
+
+		n, score, moves, err := s.Eval.Search(ctx, &window, b, depth)
+		nodes += n
+		if err != nil {
+			return nodes, eval.InvalidScore, nil, err
+		}
+		if !score.IsHeuristic() {
+			break // mate: not fractional
+		}
+
+		// Update the bounds and move the test value in the same direction by a doubling step,
+		// if the search failed the same way again, while remaining within bounds.
+
+		fail := !score.Less(window.Beta)
+		if fail == high {
+			step *= 2
+		} else {
+			step = eps
+		}
+		high = fail
+
+		if high {
+			lower, pv = score, moves
+			test = score.Pawns + step
+			if !upper.IsInf() && upper.Pawns < test {
+				test = upper.Pawns
+			}
+		} else {
+			upper = score
+			test = score.Pawns - step + eps
+			if !lower.IsNegInf() && test < lower.Pawns+eps {
+				test = lower.Pawns + eps
+			}
+		}
+
+		if !lower.IsNegInf() && !upper.IsInf() && upper.Pawns-lower.Pawns < eps {
+			if len(pv) > 0 {
+				return nodes, lower, pv, nil
+			}
+			break // no line, such as if no legal moves
+		}
+	}
+
+	sctx.Stats.AddResearch()
+	full := *sctx
+	full.Alpha, full.Beta = eval.NegInfScore, eval.InfScore
+
+	n, score, moves, err := s.Eval.Search(ctx, &full, b, depth)
+	return nodes + n, score, moves, err
+}
+
+func (s MTDf) epsilon() eval.Pawns {
+	if s.Epsilon <= 0 {
+		return DefaultMTDfEpsilon
+	}
+	return s.Epsilon
+}
@@ -0,0 +1,48 @@
+package search_test
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMTDf(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		fen   string
+		depth int
+	}{
+		{fen.Initial, 4},
+		{"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", 4},
+		{"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1", 4},
+		{"r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10", 3},
+		{"k7/7R/7R/8/8/8/8/7K w - - 0 1", 4},  // mate
+		{"7k/5Q2/6K1/8/8/8/8/8 b - - 0 1", 2}, // stalemate
+	}
+
+	ab := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+	mtdf := search.MTDf{Eval: ab}
+
+	for _, tt := range tests {
+		b, err := fen.NewBoard(tt.fen)
+		require.NoError(t, err)
+
+		_, expected, _, err := ab.Search(ctx, search.EmptyContext, b, tt.depth)
+		require.NoError(t, err)
+
+		n, actual, pv, err := mtdf.Search(ctx, &search.Context{TT: search.NewTranspositionTable(ctx, 1<<20)}, b, tt.depth)
+		require.NoError(t, err)
+		t.Logf("POS: %v; NODES: %v, PV: %v", tt.fen, n, board.PrintMoves(pv))
+
+		assert.Equalf(t, expected, actual, "failed: %v", tt.fen)
+		if expected != eval.ZeroScore || len(b.Position().LegalMoves(b.Turn())) > 0 {
+			assert.NotEmptyf(t, pv, "no pv: %v", tt.fen)
+		}
+	}
+}
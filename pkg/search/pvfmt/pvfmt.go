@@ -0,0 +1,91 @@
+// Package pvfmt contains overflow-safe formatting of search statistics, such as nodes per
+// second, for protocol drivers and metrics. GUIs should never receive absurd values, even for
+// very fast book or mate returns where the measured time is zero or tiny.
+package pvfmt
+
+import (
+	"fmt"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/search"
+	"math"
+	"math/bits"
+	"time"
+)
+
+const (
+	// MaxNPS is the saturation limit for nodes per second.
+	MaxNPS uint64 = 1_000_000_000
+	// MaxNodes is the saturation limit for node counts. Some GUIs parse them as signed.
+	MaxNodes uint64 = math.MaxInt64
+	// MinTime is the smallest duration used for rates. Shorter durations are not meaningful.
+	MinTime = time.Millisecond
+)
+
+// Nodes returns the node count, saturated at MaxNodes.
+func Nodes(n uint64) uint64 {
+	if n > MaxNodes {
+		return MaxNodes
+	}
+	return n
+}
+
+// Millis returns the duration in milliseconds. Negative durations are zero.
+func Millis(d time.Duration) int64 {
+	if d < 0 {
+		return 0
+	}
+	return d.Milliseconds()
+}
+
+// NPS returns the nodes per second for the given duration, which is at least MinTime.
+// The result is saturated at MaxNPS and does not overflow.
+func NPS(nodes uint64, d time.Duration) uint64 {
+	if nodes == 0 {
+		return 0
+	}
+	if d < MinTime {
+		d = MinTime
+	}
+
+	hi, lo := bits.Mul64(nodes, uint64(time.Second))
+	if hi >= uint64(d) {
+		return MaxNPS // quotient would overflow
+	}
+	nps, _ := bits.Div64(hi, lo, uint64(d))
+	if nps > MaxNPS {
+		return MaxNPS
+	}
+	return nps
+}
+
+// Hashfull returns the hash table usage in permille, clamped to [0;1000].
+func Hashfull(used float64) int {
+	switch {
+	case math.IsNaN(used) || used <= 0:
+		return 0
+	case used >= 1:
+		return 1000
+	default:
+		return int(1000 * used)
+	}
+}
+
+// Stats returns a human-readable summary of the search statistics: beta cutoffs and the share
+// on the first move, table probes, hits and stores, the share of quiet search nodes and
+// re-searches.
+func Stats(s search.Stats) string {
+	return fmt.Sprintf("cutoffs=%v first=%v%% probes=%v hits=%v%% stores=%v quiet=%v%% researches=%v", s.Cutoffs, percent(s.CutoffIndex[0], s.Cutoffs), s.Probes, percent(s.Hits, s.Probes), s.Stores, percent(s.QuietNodes, s.Nodes+s.QuietNodes), s.Researches)
+}
+
+// percent returns n as an integer percentage of total, or zero if no total.
+func percent(n, total uint64) int {
+	if total == 0 {
+		return 0
+	}
+	return int(100 * float64(n) / float64(total))
+}
+
+// Summary returns a human-readable summary of the principal variation with safe statistics.
+func Summary(pv search.PV) string {
+	return fmt.Sprintf("id=%v depth=%v score=%v nodes=%v time=%v nps=%v hash=%v%% pv=%v", pv.ID, pv.Depth, pv.Score, Nodes(pv.Nodes), time.Duration(Millis(pv.Time))*time.Millisecond, NPS(pv.Nodes, pv.Time), Hashfull(pv.Hash)/10, board.PrintMoves(pv.Moves))
+}
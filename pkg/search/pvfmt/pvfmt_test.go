@@ -0,0 +1,59 @@
+package pvfmt_test
+
+import (
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/herohde/morlock/pkg/search/pvfmt"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestNPS(t *testing.T) {
+	tests := []struct {
+		nodes    uint64
+		d        time.Duration
+		expected uint64
+	}{
+		{0, 0, 0},
+		{0, time.Second, 0},
+		{1000, time.Second, 1000},
+		{1000, 0, 1_000_000},                      // zero duration: MinTime
+		{1000, -time.Second, 1_000_000},           // negative duration: MinTime
+		{5, time.Microsecond, 5000},               // tiny duration: MinTime
+		{1 << 40, time.Second, pvfmt.MaxNPS},      // saturated
+		{math.MaxUint64, time.Hour, pvfmt.MaxNPS}, // no overflow
+		{1 << 62, time.Duration(math.MaxInt64), 500_000_000},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, pvfmt.NPS(tt.nodes, tt.d), "nps(%v, %v)", tt.nodes, tt.d)
+	}
+}
+
+func TestNodes(t *testing.T) {
+	assert.Equal(t, uint64(42), pvfmt.Nodes(42))
+	assert.Equal(t, pvfmt.MaxNodes, pvfmt.Nodes(math.MaxUint64))
+}
+
+func TestMillis(t *testing.T) {
+	assert.Equal(t, int64(0), pvfmt.Millis(-time.Second))
+	assert.Equal(t, int64(0), pvfmt.Millis(time.Microsecond))
+	assert.Equal(t, int64(1500), pvfmt.Millis(1500*time.Millisecond))
+}
+
+func TestHashfull(t *testing.T) {
+	assert.Equal(t, 0, pvfmt.Hashfull(math.NaN()))
+	assert.Equal(t, 0, pvfmt.Hashfull(-1))
+	assert.Equal(t, 250, pvfmt.Hashfull(0.25))
+	assert.Equal(t, 1000, pvfmt.Hashfull(1.5))
+}
+
+func TestStats(t *testing.T) {
+	s := search.Stats{Nodes: 300, QuietNodes: 700, Cutoffs: 40, Probes: 500, Hits: 125, Stores: 90, Researches: 2}
+	s.CutoffIndex[0] = 30
+	s.CutoffIndex[3] = 10
+
+	assert.Equal(t, "cutoffs=40 first=75% probes=500 hits=25% stores=90 quiet=70% researches=2", pvfmt.Stats(s))
+	assert.Equal(t, "cutoffs=0 first=0% probes=0 hits=0% stores=0 quiet=0% researches=0", pvfmt.Stats(search.Stats{}))
+}
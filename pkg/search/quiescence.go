@@ -7,14 +7,48 @@ import (
 	"github.com/seekerror/stdlib/pkg/util/contextx"
 )
 
-// Quiescence implements a configurable alpha-beta QuietSearch.
+// Quiescence implements a configurable alpha-beta QuietSearch. By default, it explores the
+// moves selected by Explore with the static evaluation as a floor, but without stand-pat
+// cutoffs or pruning, so that the historical "considerable move" variants remain faithful.
+// The modern refinements are opt-in.
 type Quiescence struct {
 	Explore Exploration
 	Eval    Evaluator
+	// Checks is the number of plies, if any, where checking moves are explored in addition
+	// to the explored moves. Quiet checks improve tactical awareness at low depth.
+	Checks int
+	// Probe enables transposition table reads in the quiet search. Any entry is at least as
+	// deep as the quiet search, so exact entries and sufficient bounds cut off.
+	Probe bool
+	// Store enables transposition table writes of depth-0 entries with the score bound
+	// relative to the search window. Requires Probe to be useful.
+	Store bool
+	// StandPat enables stand-pat cutoffs: the static evaluation is a lower bound and fails high
+	// immediately, unless in check. Only captures and queen promotions are then generated,
+	// except for all evasions in check and all moves for quiet checks. Checkmate is only
+	// detected in check and stalemate is not detected.
+	StandPat bool
+	// SEE prunes captures that lose material by static exchange evaluation, unless in check.
+	SEE bool
+	// Delta, if positive, prunes captures and promotions that cannot raise alpha even if the
+	// material gain is increased by the given safety margin in pawns, unless in check.
+	Delta eval.Pawns
+}
+
+func (q Quiescence) Capabilities() Capabilities {
+	return CapabilitiesOf(q.Eval)
 }
 
 func (q Quiescence) QuietSearch(ctx context.Context, sctx *Context, b *board.Board) (uint64, eval.Score) {
-	run := &runQuiescence{explore: q.Explore, eval: q.Eval, b: b}
+	run := &runQuiescence{explore: fullIfNotSet(q.Explore), eval: q.Eval, checks: q.Checks, standPat: q.StandPat, see: q.SEE, delta: q.Delta, progress: sctx.Progress, b: b}
+	if sctx.TT != nil {
+		if q.Probe {
+			run.probe = sctx.TT
+		}
+		if q.Store {
+			run.store = sctx.TT
+		}
+	}
 
 	low, high := eval.NegInfScore, eval.InfScore
 	if !sctx.Alpha.IsInvalid() {
@@ -24,50 +58,96 @@ func (q Quiescence) QuietSearch(ctx context.Context, sctx *Context, b *board.Boa
 		high = sctx.Beta
 	}
 
-	score := run.search(ctx, sctx, low, high)
+	score := run.search(ctx, sctx, 0, low, high)
 	return run.nodes, score
 }
 
 type runQuiescence struct {
-	explore Exploration
-	eval    Evaluator
-	b       *board.Board
-	nodes   uint64
+	explore  Exploration
+	eval     Evaluator
+	checks   int
+	standPat bool
+	see      bool
+	delta    eval.Pawns
+	probe    TranspositionTable // nil if not probing
+	store    TranspositionTable // nil if not storing
+	progress *Progress
+	b        *board.Board
+	nodes    uint64
+	buf      []board.Move // move generation buffer
 }
 
 // search returns the positive score for the color.
-func (r *runQuiescence) search(ctx context.Context, sctx *Context, alpha, beta eval.Score) eval.Score {
+func (r *runQuiescence) search(ctx context.Context, sctx *Context, ply int, alpha, beta eval.Score) eval.Score {
 	if contextx.IsCancelled(ctx) {
 		return eval.ZeroScore
 	}
-	if r.b.Result().Outcome == board.Draw {
-		return eval.ZeroScore
+	if isSearchDraw(r.b, ply) {
+		return sctx.Contempt.DrawScore(r.b.Turn())
+	}
+
+	if r.probe != nil {
+		bound, _, score, _, ok := r.probe.Read(r.b.Key())
+		sctx.Stats.AddProbe(ok)
+		if ok {
+			if isCutoff(bound, score, alpha, beta) {
+				return score // cutoff
+			}
+		}
 	}
 
 	r.nodes++
+	r.progress.Add(1)
+	low := alpha
+
+	pos, turn := r.b.Position(), r.b.Turn()
+	inCheck := false
+	if r.standPat || r.see || r.delta > 0 {
+		inCheck = pos.IsChecked(turn)
+	}
 
 	hasLegalMoves := false
-	turn := r.b.Turn()
-	score := eval.HeuristicScore(r.eval.Evaluate(ctx, sctx, r.b))
-	alpha = eval.Max(alpha, score)
+	stand := r.eval.Evaluate(ctx, sctx, r.b)
+	score := eval.HeuristicScore(stand)
+	if r.standPat && !inCheck && !score.Less(beta) {
+		return score // stand-pat cutoff
+	}
+	if !r.standPat || !inCheck {
+		alpha = eval.Max(alpha, score)
+	}
 
-	// NOTE: Don't cutoff based on evaluation here. See if any legal moves first.
-	// Also do not report mate-in-X endings.
+	// NOTE: Don't cutoff based on evaluation here, unless standing pat. See if any legal moves
+	// first. Also do not report mate-in-X endings.
 
-	priority, explore := r.explore(ctx, r.b)
+	priority, explore := r.explore(ctx, r.b, 0)
+	checks := ply < r.checks
+	capturesOnly := r.standPat && !inCheck && !checks
 
-	moves := board.NewMoveList(r.b.Position().PseudoLegalMoves(turn), priority)
+	var candidates []board.Move
+	if capturesOnly {
+		candidates = pos.PseudoLegalCapturesInto(turn, r.buf)
+		r.buf = candidates
+	} else {
+		candidates = candidateMoves(r.b, &r.buf)
+	}
+
+	moves := board.NewMoveList(candidates, priority) // copied: buffer can be reused
 	for {
 		m, ok := moves.Next()
 		if !ok {
 			break
 		}
+		if capturesOnly && m.IsPromotion() && m.Promotion != board.Queen {
+			continue // skip: underpromotion
+		}
+		pruned := !inCheck && r.prune(pos, stand, alpha, m)
 		if !r.b.PushMove(m) {
 			continue // skip: not legal
 		}
 
-		if explore(m) {
-			score := r.search(ctx, sctx, beta.Negate(), alpha.Negate())
+		if !pruned && (explore(m) || (checks && r.b.Position().IsChecked(r.b.Turn()))) {
+			low, high := childWindow(alpha, beta)
+			score := r.search(ctx, sctx, ply+1, low, high)
 			score = eval.IncrementMateDistance(score).Negate()
 			alpha = eval.Max(alpha, score)
 		}
@@ -81,10 +161,32 @@ func (r *runQuiescence) search(ctx context.Context, sctx *Context, alpha, beta e
 	}
 
 	if !hasLegalMoves {
+		if capturesOnly {
+			return alpha // no captures: stand pat
+		}
 		if result := r.b.AdjudicateNoLegalMoves(); result.Reason == board.Checkmate {
 			return eval.NegInfScore
 		}
-		return eval.ZeroScore
+		return sctx.Contempt.DrawScore(r.b.Turn())
+	}
+
+	if r.store != nil && !contextx.IsCancelled(ctx) {
+		sctx.Stats.AddStore(r.store.Write(r.b.Key(), boundOf(alpha, low, beta), r.b.Ply(), 0, alpha, board.Move{}))
 	}
 	return alpha
 }
+
+// prune returns true iff the capture or promotion is pruned by delta pruning or static exchange
+// evaluation. Other moves are not pruned.
+func (r *runQuiescence) prune(pos *board.Position, stand eval.Pawns, alpha eval.Score, m board.Move) bool {
+	if !m.IsCaptureOrEnPassant() && !m.IsPromotion() {
+		return false
+	}
+	if r.delta > 0 && alpha.IsHeuristic() && stand+eval.NominalValueGain(m)+r.delta < alpha.Pawns {
+		return true // cannot raise alpha
+	}
+	if r.see && m.IsCaptureOrEnPassant() && eval.SEE(pos, m) < 0 {
+		return true // loses material
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package search_test
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestQuiescence(t *testing.T) {
+	tests := []struct {
+		fen      string
+		checks   int
+		expected eval.Score
+	}{
+		{fen.Initial, 0, eval.ZeroScore},
+		{fen.Initial, 1, eval.ZeroScore},
+		{"6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", 0, eval.HeuristicScore(2)},   // quiet back-rank mate not seen
+		{"6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", 1, eval.MateInXScore(1)},     // .. but seen with checks
+		{"6k1/5ppp/8/8/8/8/8/R5K1 b - - 0 1", 1, eval.HeuristicScore(-2)},  // no checks for black
+		{"6k1/5ppp/8/8/3b4/8/8/R6K w - - 0 1", 0, eval.HeuristicScore(-1)}, // captures only
+		{"6k1/5ppp/8/8/3b4/8/8/R6K w - - 0 1", 1, eval.MateInXScore(1)},    // ..
+		{"6k1/5ppp/8/8/3b4/8/8/R6K b - - 0 1", 1, eval.HeuristicScore(6)},  // wins the rook
+	}
+
+	for _, tt := range tests {
+		b, err := fen.NewBoard(tt.fen)
+		require.NoError(t, err)
+
+		qs := search.Quiescence{
+			Explore: search.CaptureExploration,
+			Eval:    search.Leaf{Eval: eval.Material{}},
+			Checks:  tt.checks,
+		}
+		_, actual := qs.QuietSearch(context.Background(), search.EmptyContext, b)
+		assert.Equal(t, tt.expected, actual, "failed: %v", tt.fen)
+	}
+}
+
+func TestQuiescenceTT(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []string{
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10",
+		"6k1/5ppp/8/8/3b4/8/8/R6K b - - 0 1",
+	}
+
+	for _, tt := range tests {
+		b, err := fen.NewBoard(tt)
+		require.NoError(t, err)
+
+		qs := search.Quiescence{Explore: search.CaptureExploration, Eval: search.Leaf{Eval: eval.Material{}}}
+		_, expected := qs.QuietSearch(ctx, search.EmptyContext, b)
+
+		qs.Probe, qs.Store = true, true
+		sctx := &search.Context{TT: search.NewTranspositionTable(ctx, 1<<20)}
+
+		first, actual := qs.QuietSearch(ctx, sctx, b)
+		assert.Equal(t, expected, actual, "failed: %v", tt)
+
+		second, actual := qs.QuietSearch(ctx, sctx, b)
+		assert.Equal(t, expected, actual, "failed: %v", tt)
+		assert.Less(t, second, first, "no TT cutoffs: %v", tt)
+	}
+}
+
+func TestQuiescenceModern(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		fen      string
+		expected eval.Score
+	}{
+		{fen.Initial, eval.ZeroScore},
+		{"6k1/5ppp/8/8/3b4/8/8/R6K b - - 0 1", eval.HeuristicScore(6)}, // wins the rook
+		{"7k/6Q1/6K1/8/8/8/8/8 b - - 0 1", eval.NegInfScore},           // checkmate in check
+		{"7k/5Q2/6K1/8/8/8/8/8 b - - 0 1", eval.HeuristicScore(-9)},    // stalemate not detected
+		{"4k3/8/3p4/4p3/8/8/8/4QK2 w - - 0 1", eval.HeuristicScore(7)}, // Qxe5 loses the queen
+	}
+
+	for _, tt := range tests {
+		b, err := fen.NewBoard(tt.fen)
+		require.NoError(t, err)
+
+		qs := search.Quiescence{
+			Eval:     search.Leaf{Eval: eval.Material{}},
+			StandPat: true,
+			SEE:      true,
+			Delta:    2,
+		}
+		_, actual := qs.QuietSearch(ctx, search.EmptyContext, b)
+		assert.Equal(t, tt.expected, actual, "failed: %v", tt.fen)
+	}
+
+	// Pruning reduces the nodes searched, but not the score.
+
+	b, err := fen.NewBoard("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	require.NoError(t, err)
+
+	qs := search.Quiescence{Explore: search.CaptureExploration, Eval: search.Leaf{Eval: eval.Material{}}, StandPat: true}
+	n1, s1 := qs.QuietSearch(ctx, search.EmptyContext, b)
+	qs.SEE = true
+	n2, s2 := qs.QuietSearch(ctx, search.EmptyContext, b)
+	qs.Delta = 1
+	n3, s3 := qs.QuietSearch(ctx, search.EmptyContext, b)
+
+	assert.Less(t, n2, n1)
+	assert.LessOrEqual(t, n3, n2)
+	assert.Equal(t, s1, s2)
+	assert.Equal(t, s2, s3)
+}
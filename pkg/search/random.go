@@ -0,0 +1,61 @@
+package search
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/eval"
+	"math/rand"
+	"sync"
+)
+
+// Random is a root search that plays a uniformly random legal move regardless of depth. It has
+// no chess knowledge and is a reference engine for calibrating strength measurements. The zero
+// value uses the global source. Thread-safe, because the engine may run concurrent searches,
+// such as a background search.
+type Random struct {
+	mu   *sync.Mutex
+	rand *rand.Rand
+}
+
+// NewRandom returns a random mover with a random stream seeded by the given seed.
+func NewRandom(seed int64) Random {
+	return Random{mu: &sync.Mutex{}, rand: rand.New(rand.NewSource(seed))}
+}
+
+func (s Random) Capabilities() Capabilities {
+	return Capabilities{NoPonder: true}
+}
+
+func (s Random) Search(ctx context.Context, sctx *Context, b *board.Board, depth int) (uint64, eval.Score, []board.Move, error) {
+	legal := b.Position().LegalMoves(b.Turn())
+	sctx.Progress.Add(1)
+
+	if len(legal) == 0 {
+		if result := b.AdjudicateNoLegalMoves(); result.Reason == board.Checkmate {
+			return 1, eval.NegInfScore, nil, nil
+		}
+		return 1, eval.ZeroScore, nil, nil
+	}
+
+	var moves []board.Move
+	for _, m := range legal {
+		if sctx.IsRootMove(m) {
+			moves = append(moves, m)
+		}
+	}
+	if len(moves) == 0 {
+		return 1, eval.NegInfScore, nil, nil // no root moves allowed
+	}
+	return 1, eval.ZeroScore, []board.Move{moves[s.intn(len(moves))]}, nil
+}
+
+func (s Random) intn(n int) int {
+	if s.rand == nil {
+		return rand.Intn(n)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.rand.Intn(n)
+}
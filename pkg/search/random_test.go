@@ -0,0 +1,67 @@
+package search_test
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRandom(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("legal", func(t *testing.T) {
+		b, err := fen.NewBoard(fen.Initial)
+		require.NoError(t, err)
+
+		s := search.NewRandom(1)
+		seen := map[board.Move]bool{}
+		for i := 0; i < 200; i++ {
+			_, score, pv, err := s.Search(ctx, search.EmptyContext, b, 3)
+			require.NoError(t, err)
+			require.Len(t, pv, 1)
+			assert.Equal(t, eval.ZeroScore, score)
+			assert.True(t, b.PushMove(pv[0]))
+			b.PopMove()
+			seen[pv[0]] = true
+		}
+		assert.Len(t, seen, 20)
+	})
+
+	t.Run("restricted", func(t *testing.T) {
+		b, err := fen.NewBoard(fen.Initial)
+		require.NoError(t, err)
+
+		m, err := board.ParseMove("e2e4")
+		require.NoError(t, err)
+
+		_, _, pv, err := search.Random{}.Search(ctx, &search.Context{Moves: []board.Move{m}}, b, 1)
+		require.NoError(t, err)
+		require.Len(t, pv, 1)
+		assert.True(t, m.Equals(pv[0]))
+	})
+
+	t.Run("terminal", func(t *testing.T) {
+		tests := []struct {
+			fen      string
+			expected eval.Score
+		}{
+			{"7k/6Q1/6K1/8/8/8/8/8 b - - 0 1", eval.NegInfScore}, // checkmate
+			{"7k/5Q2/6K1/8/8/8/8/8 b - - 0 1", eval.ZeroScore},   // stalemate
+		}
+
+		for _, tt := range tests {
+			b, err := fen.NewBoard(tt.fen)
+			require.NoError(t, err)
+
+			_, score, pv, err := search.Random{}.Search(ctx, search.EmptyContext, b, 1)
+			require.NoError(t, err)
+			assert.Empty(t, pv)
+			assert.Equal(t, tt.expected, score)
+		}
+	})
+}
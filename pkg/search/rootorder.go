@@ -0,0 +1,109 @@
+package search
+
+import (
+	"fmt"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/eval"
+	"math"
+	"sort"
+	"time"
+)
+
+// RootOrder holds the root move scores of previous iterations, so that the next iteration
+// searches the previous best move first, followed by the other moves that raised alpha and
+// then the remaining moves by score. It is updated by the root search. Not thread-safe: it
+// must be owned by a single iterative search.
+type RootOrder struct {
+	moves map[board.Move]rootMove
+	seq   int
+}
+
+// RootMove holds the score and search statistics of a root move from its latest search.
+type RootMove struct {
+	Move  board.Move
+	Score eval.Score
+	Nodes uint64
+	Time  time.Duration
+}
+
+func (r RootMove) String() string {
+	return fmt.Sprintf("%v %vms nodes=%v score=%v", r.Move, r.Time.Milliseconds(), r.Nodes, r.Score)
+}
+
+type rootMove struct {
+	RootMove
+	raised bool // move raised alpha, i.e., was best so far
+	seq    int
+}
+
+// NewRootOrder returns a new, empty root order.
+func NewRootOrder() *RootOrder {
+	return &RootOrder{moves: map[board.Move]rootMove{}}
+}
+
+// NewRootOrderFrom returns a new root order seeded with the given root moves in order, such
+// as from a previous search of the same position. The first move is considered the best move.
+func NewRootOrderFrom(moves []RootMove) *RootOrder {
+	ret := NewRootOrder()
+	for i, rm := range moves {
+		ret.Update(rm.Move, rm.Score, i == 0, rm.Nodes, rm.Time)
+	}
+	return ret
+}
+
+// Len returns the number of root moves with a score.
+func (o *RootOrder) Len() int {
+	return len(o.moves)
+}
+
+// Update records the score and search statistics of a root move and whether it raised alpha.
+func (o *RootOrder) Update(m board.Move, score eval.Score, raised bool, nodes uint64, d time.Duration) {
+	o.seq++
+	o.moves[m] = rootMove{RootMove: RootMove{Move: m, Score: score, Nodes: nodes, Time: d}, raised: raised, seq: o.seq}
+}
+
+// Moves returns the root moves with a score in order.
+func (o *RootOrder) Moves() []RootMove {
+	var ret []RootMove
+	for _, rm := range o.sorted() {
+		ret = append(ret, rm.RootMove)
+	}
+	return ret
+}
+
+// Priority returns a move priority that orders root moves with a score first. Other moves are
+// ordered by the given fallback priority.
+func (o *RootOrder) Priority(fallback board.MovePriorityFn) board.MovePriorityFn {
+	rank := map[board.Move]board.MovePriority{}
+	for i, rm := range o.sorted() {
+		rank[rm.Move] = board.MovePriority(math.MaxInt16 - i)
+	}
+
+	return func(m board.Move) board.MovePriority {
+		if p, ok := rank[m]; ok {
+			return p
+		}
+		return fallback(m)
+	}
+}
+
+func (o *RootOrder) sorted() []rootMove {
+	list := make([]rootMove, 0, len(o.moves))
+	for _, rm := range o.moves {
+		list = append(list, rm)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		switch {
+		case a.raised != b.raised:
+			return a.raised
+		case a.Score != b.Score:
+			return b.Score.Less(a.Score)
+		case a.raised:
+			return a.seq > b.seq // later raise is better
+		default:
+			return a.seq < b.seq
+		}
+	})
+	return list
+}
@@ -0,0 +1,62 @@
+package search_test
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestRootOrder(t *testing.T) {
+	moves := []board.Move{
+		{Type: board.Normal, From: board.E2, To: board.E4},
+		{Type: board.Normal, From: board.D2, To: board.D4},
+		{Type: board.Normal, From: board.C2, To: board.C4},
+		{Type: board.Normal, From: board.B2, To: board.B4},
+		{Type: board.Normal, From: board.A2, To: board.A4},
+	}
+
+	order := search.NewRootOrder()
+	order.Update(moves[0], eval.HeuristicScore(1), true, 10, time.Millisecond)
+	order.Update(moves[1], eval.HeuristicScore(1), false, 10, time.Millisecond)
+	order.Update(moves[2], eval.HeuristicScore(2), true, 10, time.Millisecond)
+	order.Update(moves[3], eval.HeuristicScore(-1), false, 10, time.Millisecond)
+	assert.Equal(t, 4, order.Len())
+	assert.Equal(t, "c2-c4 1ms nodes=10 score=2.00", order.Moves()[0].String())
+
+	list := append([]board.Move{}, moves...)
+	board.SortByPriority(list, order.Priority(func(m board.Move) board.MovePriority { return 0 }))
+	assert.Equal(t, []board.Move{moves[2], moves[0], moves[1], moves[3], moves[4]}, list)
+
+	seeded := search.NewRootOrderFrom(order.Moves())
+	assert.Equal(t, order.Moves(), seeded.Moves())
+}
+
+func TestAlphaBetaRootOrder(t *testing.T) {
+	ctx := context.Background()
+
+	b, err := fen.NewBoard("k7/7R/6R1/8/8/8/8/7K w - - 0 1")
+	require.NoError(t, err)
+
+	order := search.NewRootOrder()
+	sctx := &search.Context{TT: search.NoTranspositionTable{}, Order: order}
+	ab := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+
+	for depth := 1; depth <= 2; depth++ {
+		_, _, moves, err := ab.Search(ctx, sctx, b, depth)
+		require.NoError(t, err)
+		require.NotEmpty(t, moves)
+
+		assert.Equal(t, len(b.Position().LegalMoves(b.Turn())), order.Len())
+
+		legal := b.Position().LegalMoves(b.Turn())
+		board.SortByPriority(legal, order.Priority(search.MVVLVA))
+		assert.Equal(t, moves[0], legal[0])
+		assert.Equal(t, moves[0], order.Moves()[0].Move)
+	}
+}
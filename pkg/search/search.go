@@ -1,3 +1,4 @@
+// Package search contains game tree search algorithms and utilities.
 package search
 
 import (
@@ -5,6 +6,8 @@ import (
 	"errors"
 	"github.com/herohde/morlock/pkg/board"
 	"github.com/herohde/morlock/pkg/eval"
+	"sync/atomic"
+	"time"
 )
 
 // ErrHalted is an error indicating that the search was halted.
@@ -12,15 +15,115 @@ var ErrHalted = errors.New("search halted")
 
 // Context holds optional context for search implementations.
 type Context struct {
+	ID          ID           // Search ID for log correlation, if any
 	Alpha, Beta eval.Score   // Limit search to a [Alpha;Beta] Window
 	Ponder      []board.Move // Limit search to variation, if present.
+	Moves       []board.Move // Limit root search to the given moves, if present.
+	Exclude     []board.Move // Exclude root moves, if present. Used for MultiPV.
+	Order       *RootOrder   // Root move order from previous iterations, if any. Updated by search.
 
-	TT    TranspositionTable // HashTable (user configurable)
-	Noise eval.Random        // Evaluation noise (user configurable)
+	TT       TranspositionTable // HashTable (user configurable)
+	Noise    eval.Random        // Evaluation noise (user configurable)
+	Contempt Contempt           // Draw score (user configurable)
+	Progress *Progress          // Live node count, if monitored. Updated by search.
+	Trace    *Trace             // Visited nodes, if traced. Updated by search.
+	Stats    *Stats             // Search statistics, if collected. Updated by search.
+	Info     func(string)       // Messages for the user, if reported. For searches that wait for external input.
+
+	Deadline time.Time // Hard time limit, if any. For searches that do not halt, such as waiting for a move.
+}
+
+// Contempt is the value of avoiding a draw for the side to move at the root of the search. A
+// positive contempt scores draws below zero for that side and above zero for the opponent, so
+// that the side does not settle for a repetition or stalemate when slightly better. The zero
+// value scores draws as zero.
+type Contempt struct {
+	Side  board.Color
+	Pawns eval.Pawns
+}
+
+// DrawScore returns the score of a draw for the given side to move.
+func (c Contempt) DrawScore(turn board.Color) eval.Score {
+	if c.Pawns == 0 {
+		return eval.ZeroScore
+	}
+	if turn == c.Side {
+		return eval.HeuristicScore(-c.Pawns)
+	}
+	return eval.HeuristicScore(c.Pawns)
+}
+
+// Progress is a live node count of a search, for monitoring it from another goroutine, such
+// as a watchdog. A nil Progress ignores updates. Thread-safe.
+type Progress struct {
+	nodes atomic.Uint64
+}
+
+// Add adds searched nodes.
+func (p *Progress) Add(nodes uint64) {
+	if p != nil {
+		p.nodes.Add(nodes)
+	}
+}
+
+// Nodes returns the number of nodes searched so far.
+func (p *Progress) Nodes() uint64 {
+	if p == nil {
+		return 0
+	}
+	return p.nodes.Load()
 }
 
 var EmptyContext = &Context{TT: NoTranspositionTable{}}
 
+// IsRestricted returns true iff root moves are restricted by Moves or Exclude.
+func (c *Context) IsRestricted() bool {
+	return len(c.Moves) > 0 || len(c.Exclude) > 0
+}
+
+// IsRootMove returns true iff the given move may be searched at the root.
+func (c *Context) IsRootMove(m board.Move) bool {
+	if len(c.Moves) > 0 && !containsMove(c.Moves, m) {
+		return false
+	}
+	return !containsMove(c.Exclude, m)
+}
+
+func containsMove(list []board.Move, m board.Move) bool {
+	for _, e := range list {
+		if e.Equals(m) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSearchDraw returns true iff the position is a draw by the board or by a repetition within
+// the given number of plies, i.e., along the search path. A repetition within the search is a
+// draw, because either side can repeat it again. Checkmate takes precedence over the 50-move
+// rule.
+func isSearchDraw(b *board.Board, plies int) bool {
+	if result := b.Result(); result.Outcome == board.Draw {
+		if result.Reason == board.NoProgress && b.Position().IsChecked(b.Turn()) {
+			return len(b.Position().LegalMoves(b.Turn())) > 0
+		}
+		return true
+	}
+	return plies >= 4 && b.HasRepeated(plies)
+}
+
+// candidateMoves returns the moves to try in the current position: the legal evasions, if in
+// check, and otherwise all pseudo-legal moves. The moves are generated into the buffer, which
+// is updated for reuse once the moves are no longer needed.
+func candidateMoves(b *board.Board, buf *[]board.Move) []board.Move {
+	moves, ok := b.Position().EvasionMovesInto(b.Turn(), *buf)
+	if !ok {
+		moves = b.Position().PseudoLegalMovesInto(b.Turn(), *buf)
+	}
+	*buf = moves
+	return moves
+}
+
 // Search implements search of the game tree to a given depth. Context is cancelled if halted. Thread-safe.
 type Search interface {
 	Search(ctx context.Context, sctx *Context, b *board.Board, depth int) (uint64, eval.Score, []board.Move, error)
@@ -47,6 +150,10 @@ func (s Leaf) Evaluate(ctx context.Context, sctx *Context, b *board.Board) eval.
 	return s.Eval.Evaluate(ctx, b) + sctx.Noise.Evaluate(ctx, b)
 }
 
+func (s Leaf) Capabilities() Capabilities {
+	return CapabilitiesOf(s.Eval)
+}
+
 func (s Leaf) QuietSearch(ctx context.Context, sctx *Context, b *board.Board) (uint64, eval.Score) {
 	return 1, eval.HeuristicScore(s.Evaluate(ctx, sctx, b))
 }
@@ -0,0 +1,71 @@
+package searchctl
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/seekerror/logw"
+)
+
+// Brain thinks on the opponent's time, also known as "permanent brain", independently of any
+// pondering requested by a driver. After the engine commits a move, it searches the position
+// after the expected reply in the background with the shared transposition table. If the
+// opponent plays the expected reply, the background search seeds the next search. Otherwise,
+// it is discarded. Not thread-safe: it must be owned by the engine.
+type Brain struct {
+	// Launcher launches the background searches.
+	Launcher Launcher
+
+	active   Handle
+	expected board.Move
+}
+
+// IsThinking returns true iff a background search is active.
+func (br *Brain) IsThinking() bool {
+	return br.active != nil
+}
+
+// Think starts a background search of the given position, which is the position after the
+// expected reply. It expects an exclusive (forked) board. Any active background search is
+// discarded.
+func (br *Brain) Think(ctx context.Context, b *board.Board, reply board.Move, tt search.TranspositionTable, noise eval.Random, opt Options) {
+	br.Stop()
+
+	handle, out := br.Launcher.Launch(ctx, b, tt, noise, opt)
+	go func() {
+		for range out {
+			// ignore: only the final PV is used
+		}
+	}()
+	br.active, br.expected = handle, reply
+
+	logw.Infof(ctx, "Brain %v thinking on expected reply %v", opt.ID, reply)
+}
+
+// Resolve halts the background search, if any, given the actual reply. Returns the principal
+// variation of the background search if the reply was the expected reply.
+func (br *Brain) Resolve(ctx context.Context, reply board.Move) (search.PV, bool) {
+	if br.active == nil {
+		return search.PV{}, false
+	}
+
+	pv := br.active.Halt()
+	hit := br.expected.Equals(reply)
+	br.active, br.expected = nil, board.Move{}
+
+	if !hit {
+		logw.Infof(ctx, "Brain %v missed reply %v", pv.ID, reply)
+		return search.PV{}, false
+	}
+	logw.Infof(ctx, "Brain %v hit reply %v: %v", pv.ID, reply, pv)
+	return pv, true
+}
+
+// Stop halts and discards the background search, if any. Idempotent.
+func (br *Brain) Stop() {
+	if br.active != nil {
+		br.active.Halt()
+		br.active, br.expected = nil, board.Move{}
+	}
+}
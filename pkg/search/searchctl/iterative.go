@@ -2,28 +2,48 @@ package searchctl
 
 import (
 	"context"
+	"errors"
 	"github.com/herohde/morlock/pkg/board"
 	"github.com/herohde/morlock/pkg/eval"
 	"github.com/herohde/morlock/pkg/search"
 	"github.com/seekerror/logw"
 	"github.com/seekerror/stdlib/pkg/util/contextx"
 	"github.com/seekerror/stdlib/pkg/util/iox"
+	"github.com/seekerror/stdlib/pkg/util/mathx"
+	"runtime/pprof"
+	"strconv"
 	"sync"
 	"time"
 )
 
-// Iterative is a search harness for iterative deepening search.
+// MaxDepth is the maximum iterative search depth. It applies if no depth limit is given or
+// the given limit is larger, so that an unlimited search cannot run away.
+const MaxDepth = 64
+
+// Iterative is a search harness for iterative deepening search. If a tablebase is present,
+// the root moves are limited to the moves that preserve the best tablebase outcome.
 type Iterative struct {
 	Root search.Search
+	TB   search.Tablebase
+	// Aspiration, if positive, is the half-width in pawns of the initial search window around
+	// the score of the previous iteration. The window is widened on fail high or fail low
+	// until the score falls inside. If zero, each iteration searches the full window.
+	Aspiration eval.Pawns
+	// Watchdog, if enabled, logs searches that make no node progress.
+	Watchdog Watchdog
 }
 
 func (i *Iterative) Launch(ctx context.Context, b *board.Board, tt search.TranspositionTable, noise eval.Random, opt Options) (Handle, <-chan search.PV) {
+	if opt.ID == 0 {
+		opt.ID = search.NewID()
+	}
+
 	out := make(chan search.PV, 1)
 	h := &handle{
 		init: iox.NewAsyncCloser(),
 		quit: iox.NewAsyncCloser(),
 	}
-	go h.process(ctx, i.Root, b, tt, noise, opt, out)
+	go h.process(ctx, *i, b, tt, noise, opt, out)
 
 	return h, out
 }
@@ -35,41 +55,109 @@ type handle struct {
 	mu sync.Mutex
 }
 
-func (h *handle) process(ctx context.Context, root search.Search, b *board.Board, tt search.TranspositionTable, noise eval.Random, opt Options, out chan search.PV) {
+func (h *handle) process(ctx context.Context, i Iterative, b *board.Board, tt search.TranspositionTable, noise eval.Random, opt Options, out chan search.PV) {
 	defer h.init.Close()
 	defer close(out)
 
-	sctx := &search.Context{Alpha: eval.NegInfScore, Beta: eval.InfScore, TT: tt, Noise: noise}
-	soft, useSoft := EnforceTimeControl(ctx, h, opt.TimeControl, b.Turn())
+	sctx := &search.Context{ID: opt.ID, Moves: opt.SearchMoves, Alpha: eval.NegInfScore, Beta: eval.InfScore, TT: tt, Noise: noise, Contempt: search.Contempt{Side: b.Turn(), Pawns: opt.Contempt}, Order: search.NewRootOrderFrom(opt.Order), Progress: &search.Progress{}, Stats: &search.Stats{}, Info: opt.Info}
+	tbhits := filterTablebase(ctx, i.TB, b, sctx)
 
 	wctx, cancel := contextx.WithQuitCancel(ctx, h.quit.Closed())
 	defer cancel()
 
-	depth := 1
+	begin := time.Now()
+	budget, useBudget := EnforceTimeControl(wctx, h, opt.TimeControl, b.Turn())
+	if useBudget {
+		sctx.Deadline = begin.Add(budget.Hard)
+	}
+
+	go i.Watchdog.watch(wctx, opt.ID, b.Position().String(), sctx.Progress)
+
+	iter := &iteration{}
+	go reportProgress(wctx, opt, tt, sctx.Progress, iter)
+
+	recordSearch()
+
+	depth := startDepth(opt)
+	prev := eval.InvalidScore // score of the previous iteration, if any
+	var last search.PV        // line of the previous iteration, if any
 	for !h.quit.IsClosed() {
 		start := time.Now()
+		iter.begin(depth, sctx.Progress.Nodes())
+
+		// Search the best line and, if MultiPV, the next best lines by excluding the
+		// first move of the lines already found.
 
-		nodes, score, moves, err := root.Search(wctx, sctx, b, depth)
-		if err != nil {
-			if err == search.ErrHalted {
-				return // Halt was called.
+		var lines []search.PV
+		var nodes uint64
+		for len(lines) == 0 || uint(len(lines)) < opt.MultiPV {
+			sctx.Exclude = nil
+			for _, line := range lines {
+				sctx.Exclude = append(sctx.Exclude, line.Moves[0])
 			}
-			logw.Errorf(ctx, "Search failed on %v at depth=%v: %v", b, depth, err)
-			return
-		}
 
-		pv := search.PV{
-			Depth: depth,
-			Nodes: nodes,
-			Score: score,
-			Moves: moves,
-			Time:  time.Since(start),
+			// Search the best line in an aspiration window around the previous score, if
+			// enabled. The window is widened and the line searched again if it fails.
+
+			w := newWindow(prev, i.Aspiration)
+			if len(lines) > 0 {
+				w = newWindow(eval.InvalidScore, 0)
+			}
+
+			var n uint64
+			var score eval.Score
+			var moves []board.Move
+			var err error
+			for {
+				sctx.Alpha, sctx.Beta = w.alpha, w.beta
+
+				// Label the iteration for profiling, so that deep iterations can be isolated.
+
+				pprof.Do(wctx, pprof.Labels("search", "iterative", "id", opt.ID.String(), "depth", strconv.Itoa(depth)), func(ctx context.Context) {
+					n, score, moves, err = i.Root.Search(ctx, sctx, b, depth)
+				})
+				if err != nil {
+					if errors.Is(err, search.ErrHalted) {
+						return // Halt was called.
+					}
+					logw.Errorf(ctx, "Search %v failed on %v at depth=%v: %v", opt.ID, b, depth, err)
+					return
+				}
+				nodes += n
+
+				if !w.widen(score) {
+					break
+				}
+				sctx.Stats.AddResearch()
+				logw.Debugf(ctx, "Search %v failed aspiration window at depth=%v: %v", opt.ID, depth, score)
+			}
+			sctx.Alpha, sctx.Beta = eval.NegInfScore, eval.InfScore
+
+			if len(lines) > 0 && (len(moves) == 0 || !sctx.IsRootMove(moves[0])) {
+				break // no more lines, or the search does not honor Exclude
+			}
+			lines = append(lines, search.PV{ID: opt.ID, Depth: depth, Score: score, Moves: moves})
+			if len(moves) == 0 {
+				break // checkmate or stalemate
+			}
 		}
+
+		pv := lines[0]
+		pv.Nodes = nodes
+		pv.Time = time.Since(start)
+		pv.Alt = lines[1:]
+		pv.Root = sctx.Order.Moves()
+		pv.TB = tbhits
+		pv.Stats = *sctx.Stats
 		if tt != nil {
 			pv.Hash = tt.Used()
 		}
+		score, moves := pv.Score, pv.Moves
+		unstable := IsUnstable(last, pv)
 
-		logw.Debugf(ctx, "Searched %v: %v", b.Position(), pv)
+		logw.Debugf(ctx, "Search %v searched %v: %v", opt.ID, b.Position(), pv)
+		recordIteration(pv, last.Stats)
+		prev, last = score, pv
 
 		h.mu.Lock()
 		h.pv = pv
@@ -83,18 +171,121 @@ func (h *handle) process(ctx context.Context, root search.Search, b *board.Board
 
 		h.init.Close()
 		if limit, ok := opt.DepthLimit.V(); ok && uint(depth) == limit {
+			return // halt: reached depth limit
+		}
+		if depth >= MaxDepth {
 			return // halt: reached max depth
 		}
+		if len(moves) == 0 && len(b.Position().LegalMoves(b.Turn())) == 0 {
+			return // halt: checkmate or stalemate. Exact result.
+		}
 		if md, ok := score.MateDistance(); ok && int(md) <= depth {
 			return // halt: forced mate found within full width search. Exact result.
 		}
-		if useSoft && soft < time.Since(start) {
-			return // halt: exceeded soft time limit. Do not start new search.
+		if useBudget {
+			soft := budget.Soft
+			if unstable {
+				soft = budget.Extended()
+			}
+			if soft < time.Since(begin) {
+				return // halt: exceeded soft time limit. Do not start new search.
+			}
 		}
 		depth++
 	}
 }
 
+// window is an aspiration window around an expected score. A full window is [-inf;inf].
+type window struct {
+	expected    eval.Score
+	delta       eval.Pawns
+	alpha, beta eval.Score
+	lows, highs int // number of fail lows and fail highs
+}
+
+// maxWindowFails is the number of fails on one side of the window before it is fully opened.
+const maxWindowFails = 3
+
+func newWindow(expected eval.Score, delta eval.Pawns) *window {
+	w := &window{expected: expected, delta: delta, alpha: eval.NegInfScore, beta: eval.InfScore}
+	if delta > 0 && expected.IsHeuristic() {
+		w.alpha = eval.HeuristicScore(expected.Pawns - delta)
+		w.beta = eval.HeuristicScore(expected.Pawns + delta)
+	}
+	return w
+}
+
+// widen widens the window if the score falls outside, i.e., the search failed low or high.
+// The failing side is widened exponentially and eventually fully opened. Returns true iff
+// widened and the search must be repeated.
+func (w *window) widen(score eval.Score) bool {
+	switch {
+	case !w.alpha.IsNegInf() && !w.alpha.Less(score):
+		w.lows++
+		w.alpha = eval.NegInfScore
+		if w.lows < maxWindowFails {
+			w.alpha = eval.HeuristicScore(w.expected.Pawns - w.delta*eval.Pawns(int(1)<<(2*w.lows)))
+		}
+		return true
+	case !w.beta.IsInf() && !score.Less(w.beta):
+		w.highs++
+		w.beta = eval.InfScore
+		if w.highs < maxWindowFails {
+			w.beta = eval.HeuristicScore(w.expected.Pawns + w.delta*eval.Pawns(int(1)<<(2*w.highs)))
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// filterTablebase limits the root moves to the moves that preserve the best tablebase outcome,
+// if the position is in the tablebase. Any given root moves are respected. Returns tbhits.
+func filterTablebase(ctx context.Context, tb search.Tablebase, b *board.Board, sctx *search.Context) uint64 {
+	if tb == nil {
+		return 0
+	}
+	moves, wdl, hits, ok := search.TablebaseRootMoves(ctx, tb, b)
+	if !ok {
+		return hits
+	}
+
+	if len(sctx.Moves) > 0 {
+		var list []board.Move
+		for _, m := range sctx.Moves {
+			if sctx.IsRootMove(m) && containsMove(moves, m) {
+				list = append(list, m)
+			}
+		}
+		if len(list) == 0 {
+			return hits // keep the given root moves
+		}
+		moves = list
+	}
+
+	logw.Debugf(ctx, "Search %v tablebase %v: %v", sctx.ID, wdl, board.PrintMoves(moves))
+	sctx.Moves = moves
+	return hits
+}
+
+func containsMove(list []board.Move, m board.Move) bool {
+	for _, e := range list {
+		if e.Equals(m) {
+			return true
+		}
+	}
+	return false
+}
+
+// startDepth returns the depth of the first iteration, bounded by any depth limit.
+func startDepth(opt Options) int {
+	depth := mathx.Min(mathx.Max(int(opt.StartDepth), 1), MaxDepth)
+	if limit, ok := opt.DepthLimit.V(); ok && limit > 0 {
+		depth = mathx.Min(depth, int(limit))
+	}
+	return depth
+}
+
 func (h *handle) Halt() search.PV {
 	<-h.init.Closed()
 	h.quit.Close()
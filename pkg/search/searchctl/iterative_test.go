@@ -0,0 +1,283 @@
+package searchctl_test
+
+import (
+	"context"
+	"expvar"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/herohde/morlock/pkg/search/searchctl"
+	"github.com/seekerror/stdlib/pkg/lang"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestIterative(t *testing.T) {
+	ctx := context.Background()
+	root := &searchctl.Iterative{Root: search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}}
+
+	tests := []struct {
+		pos      string
+		moves    []string
+		expected int
+	}{
+		{"8/8/8/8/8/8/8/Kq5k w - - 0 1", []string{"a1b1"}, searchctl.MaxDepth}, // draw: no limit
+		{"7k/5Q2/6K1/8/8/8/8/8 b - - 0 1", nil, 1},                             // stalemate
+		{"7k/8/6K1/8/8/8/8/5Q2 w - - 0 1", nil, 2},                             // mate-in-1
+	}
+
+	for _, tt := range tests {
+		b, err := fen.NewBoard(tt.pos, tt.moves...)
+		require.NoError(t, err)
+
+		h, out := root.Launch(ctx, b, search.NoTranspositionTable{}, eval.Random{}, searchctl.Options{})
+
+		var last search.PV
+		for pv := range out {
+			last = pv
+		}
+		assert.Equal(t, tt.expected, last.Depth, "pos: %v", tt.pos)
+		assert.Equal(t, last, h.Halt())
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	ctx := context.Background()
+	root := &searchctl.Iterative{Root: search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}}
+
+	searches := searchctl.Metrics.Get("searches")
+	before := int64(0)
+	if searches != nil {
+		before = searches.(*expvar.Int).Value()
+	}
+
+	b, err := fen.NewBoard("7k/8/6K1/8/8/8/8/5Q2 w - - 0 1")
+	require.NoError(t, err)
+
+	_, out := root.Launch(ctx, b, search.NoTranspositionTable{}, eval.Random{}, searchctl.Options{})
+	for range out {
+	}
+
+	assert.Equal(t, before+1, searchctl.Metrics.Get("searches").(*expvar.Int).Value())
+	assert.Equal(t, int64(2), searchctl.Metrics.Get("depth").(*expvar.Int).Value())
+	assert.Greater(t, searchctl.Metrics.Get("nodes").(*expvar.Int).Value(), int64(0))
+	for _, name := range []string{"quiet_nodes", "cutoffs", "probes", "hits", "stores", "researches"} {
+		assert.NotNil(t, searchctl.Metrics.Get(name), name)
+	}
+}
+
+func TestIterativeID(t *testing.T) {
+	ctx := context.Background()
+	root := &searchctl.Iterative{Root: search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}}
+
+	b, err := fen.NewBoard("7k/8/6K1/8/8/8/8/5Q2 w - - 0 1")
+	require.NoError(t, err)
+
+	id := search.NewID()
+	_, out := root.Launch(ctx, b, search.NoTranspositionTable{}, eval.Random{}, searchctl.Options{ID: id})
+	for pv := range out {
+		assert.Equal(t, id, pv.ID)
+	}
+
+	_, out = root.Launch(ctx, b, search.NoTranspositionTable{}, eval.Random{}, searchctl.Options{})
+	for pv := range out {
+		assert.Greater(t, pv.ID, id)
+	}
+}
+
+func TestIterativeMultiPV(t *testing.T) {
+	ctx := context.Background()
+	root := &searchctl.Iterative{Root: search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}}
+
+	b, err := fen.NewBoard("k7/7R/6R1/8/8/8/8/7K w - - 0 1")
+	require.NoError(t, err)
+
+	opt := searchctl.Options{DepthLimit: lang.Some(uint(2)), MultiPV: 3}
+	_, out := root.Launch(ctx, b, search.NewTranspositionTable(ctx, 1<<20), eval.Random{}, opt)
+
+	var last search.PV
+	for pv := range out {
+		last = pv
+	}
+
+	require.Len(t, last.Alt, 2)
+	assert.Equal(t, eval.MateInXScore(1), last.Score)
+	assert.Equal(t, "Rg6-g8", last.Moves[0].String())
+
+	lines := append([]search.PV{last}, last.Alt...)
+	for i := 1; i < len(lines); i++ {
+		assert.False(t, lines[i-1].Score.Less(lines[i].Score), "lines out of order: %v", lines)
+		for j := 0; j < i; j++ {
+			assert.False(t, lines[i].Moves[0].Equals(lines[j].Moves[0]), "duplicate line: %v", lines)
+		}
+	}
+}
+
+func TestIterativeMultiPVNoExclude(t *testing.T) {
+	ctx := context.Background()
+	root := &searchctl.Iterative{Root: search.Minimax{Eval: search.Leaf{Eval: eval.Material{}}}}
+
+	b, err := fen.NewBoard("k7/7R/6R1/8/8/8/8/7K w - - 0 1")
+	require.NoError(t, err)
+
+	// Minimax ignores Exclude and would find the same line again: only the best line is kept.
+
+	opt := searchctl.Options{DepthLimit: lang.Some(uint(2)), MultiPV: 3}
+	_, out := root.Launch(ctx, b, search.NoTranspositionTable{}, eval.Random{}, opt)
+
+	var last search.PV
+	for pv := range out {
+		last = pv
+	}
+
+	assert.Empty(t, last.Alt)
+	assert.Equal(t, eval.MateInXScore(1), last.Score)
+}
+
+func TestIterativeAspiration(t *testing.T) {
+	ctx := context.Background()
+	s := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+
+	tests := []string{
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+		"k7/7R/7R/8/8/8/8/7K w - - 0 1", // mate-in-3
+	}
+
+	for _, tt := range tests {
+		b, err := fen.NewBoard(tt)
+		require.NoError(t, err)
+
+		var scores []eval.Score
+		for _, aspiration := range []eval.Pawns{0, 0.25} {
+			root := &searchctl.Iterative{Root: s, Aspiration: aspiration}
+
+			opt := searchctl.Options{DepthLimit: lang.Some(uint(4))}
+			_, out := root.Launch(ctx, b, search.NewTranspositionTable(ctx, 1<<20), eval.Random{}, opt)
+
+			var last search.PV
+			for pv := range out {
+				last = pv
+			}
+			require.NotEmpty(t, last.Moves, "pos: %v", tt)
+			scores = append(scores, last.Score)
+		}
+		assert.Equal(t, scores[0], scores[1], "pos: %v", tt)
+	}
+}
+
+// drawTablebase is a fake tablebase where every position is drawn.
+type drawTablebase struct{}
+
+func (drawTablebase) Probe(ctx context.Context, b *board.Board) (search.WDL, int, bool) {
+	return search.TBDraw, 0, true
+}
+
+func TestIterativeTablebase(t *testing.T) {
+	ctx := context.Background()
+	s := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+
+	b, err := fen.NewBoard("k7/7R/6R1/8/8/8/8/7K w - - 0 1")
+	require.NoError(t, err)
+
+	// The mate is the only winning move. Of the 30 legal moves, 2 end the game and are not
+	// probed. Otherwise, the given root moves are respected.
+
+	tests := []struct {
+		tb       search.Tablebase
+		moves    []string
+		expected string
+		hits     uint64
+	}{
+		{nil, []string{"h1g1"}, "Kh1-g1", 0},
+		{search.NoTablebase{}, []string{"h1g1"}, "Kh1-g1", 0},
+		{drawTablebase{}, nil, "Rg6-g8", 28},
+		{drawTablebase{}, []string{"h1g1"}, "Kh1-g1", 28},
+		{drawTablebase{}, []string{"h1g1", "g6g8"}, "Rg6-g8", 28},
+	}
+
+	for _, tt := range tests {
+		root := &searchctl.Iterative{Root: s, TB: tt.tb}
+
+		var moves []board.Move
+		for _, m := range tt.moves {
+			move, err := board.ParseMove(m)
+			require.NoError(t, err)
+			moves = append(moves, move)
+		}
+
+		opt := searchctl.Options{DepthLimit: lang.Some(uint(1)), SearchMoves: moves}
+		_, out := root.Launch(ctx, b, search.NoTranspositionTable{}, eval.Random{}, opt)
+
+		var last search.PV
+		for pv := range out {
+			last = pv
+		}
+		assert.Equal(t, tt.expected, last.Moves[0].String())
+		assert.Equal(t, tt.hits, last.TB)
+	}
+}
+
+// stuckSearch is a fake search that makes no progress until halted.
+type stuckSearch struct{}
+
+func (stuckSearch) Search(ctx context.Context, sctx *search.Context, b *board.Board, depth int) (uint64, eval.Score, []board.Move, error) {
+	<-ctx.Done()
+	return 0, eval.InvalidScore, nil, search.ErrHalted
+}
+
+func TestIterativeWatchdog(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	root := &searchctl.Iterative{Root: stuckSearch{}, Watchdog: searchctl.Watchdog{Timeout: 10 * time.Millisecond, Stacks: true}}
+
+	before := int64(0)
+	if stalls := searchctl.Metrics.Get("stalls"); stalls != nil {
+		before = stalls.(*expvar.Int).Value()
+	}
+
+	b, err := fen.NewBoard(fen.Initial)
+	require.NoError(t, err)
+
+	_, out := root.Launch(ctx, b, search.NoTranspositionTable{}, eval.Random{}, searchctl.Options{})
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	for range out {
+	}
+
+	assert.Equal(t, before+1, searchctl.Metrics.Get("stalls").(*expvar.Int).Value())
+}
+
+func TestIterativeProgress(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	root := &searchctl.Iterative{Root: stuckSearch{}}
+
+	b, err := fen.NewBoard(fen.Initial)
+	require.NoError(t, err)
+
+	updates := make(chan search.PV, 100)
+	opt := searchctl.Options{
+		ID:               search.NewID(),
+		Progress:         func(pv search.PV) { updates <- pv },
+		ProgressInterval: 10 * time.Millisecond,
+	}
+
+	_, out := root.Launch(ctx, b, search.NewTranspositionTable(ctx, 1<<10), eval.Random{}, opt)
+
+	pv := <-updates
+	assert.Equal(t, opt.ID, pv.ID)
+	assert.Equal(t, 1, pv.Depth)
+	assert.True(t, pv.Score.IsInvalid())
+	assert.Empty(t, pv.Moves)
+	assert.Positive(t, pv.Time)
+
+	cancel()
+	for range out {
+	}
+}
@@ -9,18 +9,48 @@ import (
 	"github.com/herohde/morlock/pkg/search"
 	"github.com/seekerror/stdlib/pkg/lang"
 	"strings"
+	"time"
 )
 
 // Options hold dynamic search options. The user may change these on a particular search.
 type Options struct {
+	// ID, if set, is the search ID used to correlate log lines. Zero means a new ID is assigned.
+	ID search.ID
 	// DepthLimit, if set, limits the search to the given ply depth. Zero means no limit.
 	DepthLimit lang.Optional[uint]
 	// TimeControl, if set, limits the search to the given time parameters.
 	TimeControl lang.Optional[TimeControl]
+	// SearchMoves, if set, limits the search to the given root moves.
+	SearchMoves []board.Move
+	// MultiPV, if greater than one, searches for the given number of best lines.
+	MultiPV uint
+	// StartDepth, if greater than one, is the depth of the first iteration. Used to reuse
+	// the transposition table of a previous search along the expected line.
+	StartDepth uint
+	// Order, if set, seeds the root move order, best first. Used to continue from a previous
+	// search of the same position, such as a background search on the opponent's time.
+	Order []search.RootMove
+	// Progress, if set, is called periodically during the search with the node count, time and
+	// hash utilization of the iteration in progress. The updates have no score or moves.
+	Progress func(search.PV)
+	// ProgressInterval is the interval of progress updates. Zero means the default interval.
+	ProgressInterval time.Duration
+	// Info, if set, is called with messages of the search for the user, such as the state of
+	// an external clock. It must not block.
+	Info func(string)
+	// Contempt, if non-zero, scores draws below zero for the side to move by the given
+	// pawns, and above zero for the opponent.
+	Contempt eval.Pawns
+	// Analysis, if set, indicates that the search analyzes the position, such as "go infinite",
+	// instead of selecting a move to play. Skill limits, such as blunders, do not apply.
+	Analysis bool
 }
 
 func (o Options) String() string {
 	var ret []string
+	if o.ID != 0 {
+		ret = append(ret, fmt.Sprintf("id=%v", o.ID))
+	}
 	if v, ok := o.DepthLimit.V(); ok {
 		ret = append(ret, fmt.Sprintf("depth=%v", v))
 	}
@@ -28,6 +58,30 @@ func (o Options) String() string {
 	if v, ok := o.TimeControl.V(); ok {
 		ret = append(ret, fmt.Sprintf("time=%v", v))
 	}
+	if len(o.SearchMoves) > 0 {
+		ret = append(ret, fmt.Sprintf("searchmoves=%v", board.PrintMoves(o.SearchMoves)))
+	}
+	if o.MultiPV > 1 {
+		ret = append(ret, fmt.Sprintf("multipv=%v", o.MultiPV))
+	}
+	if o.StartDepth > 1 {
+		ret = append(ret, fmt.Sprintf("start=%v", o.StartDepth))
+	}
+	if len(o.Order) > 0 {
+		ret = append(ret, fmt.Sprintf("order=%v", len(o.Order)))
+	}
+	if o.Progress != nil {
+		ret = append(ret, "progress")
+	}
+	if o.Info != nil {
+		ret = append(ret, "info")
+	}
+	if o.Contempt != 0 {
+		ret = append(ret, fmt.Sprintf("contempt=%v", o.Contempt))
+	}
+	if o.Analysis {
+		ret = append(ret, "analysis")
+	}
 	return fmt.Sprintf("[%v]", strings.Join(ret, ", "))
 }
 
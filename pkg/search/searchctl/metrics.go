@@ -0,0 +1,55 @@
+package searchctl
+
+import (
+	"expvar"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/herohde/morlock/pkg/search/pvfmt"
+)
+
+// Metrics holds process-wide search metrics, exported via expvar as "search". Counters
+// aggregate over all engines in the process, while gauges reflect the latest iteration.
+var Metrics = expvar.NewMap("search")
+
+var (
+	depthGauge = new(expvar.Int) // completed depth of latest iteration
+	npsGauge   = new(expvar.Int) // nodes per second of latest iteration
+	scoreGauge = new(expvar.Int) // score in centipawns of latest iteration, if heuristic
+	hashGauge  = new(expvar.Int) // transposition table usage in permille
+)
+
+func init() {
+	Metrics.Set("depth", depthGauge)
+	Metrics.Set("nps", npsGauge)
+	Metrics.Set("score_cp", scoreGauge)
+	Metrics.Set("hash_permille", hashGauge)
+}
+
+func recordSearch() {
+	Metrics.Add("searches", 1)
+}
+
+func recordStall() {
+	Metrics.Add("stalls", 1)
+}
+
+// recordIteration records a completed iteration. The search statistics are cumulative over
+// the search, so only the increase over the previous iteration is added.
+func recordIteration(pv search.PV, prev search.Stats) {
+	Metrics.Add("iterations", 1)
+	Metrics.Add("nodes", int64(pvfmt.Nodes(pv.Nodes)))
+	Metrics.Add("time_ms", pvfmt.Millis(pv.Time))
+
+	Metrics.Add("quiet_nodes", int64(pv.Stats.QuietNodes-prev.QuietNodes))
+	Metrics.Add("cutoffs", int64(pv.Stats.Cutoffs-prev.Cutoffs))
+	Metrics.Add("probes", int64(pv.Stats.Probes-prev.Probes))
+	Metrics.Add("hits", int64(pv.Stats.Hits-prev.Hits))
+	Metrics.Add("stores", int64(pv.Stats.Stores-prev.Stores))
+	Metrics.Add("researches", int64(pv.Stats.Researches-prev.Researches))
+
+	depthGauge.Set(int64(pv.Depth))
+	npsGauge.Set(int64(pvfmt.NPS(pv.Nodes, pv.Time)))
+	if pv.Score.IsHeuristic() {
+		scoreGauge.Set(int64(pv.Score.Pawns * 100))
+	}
+	hashGauge.Set(int64(pvfmt.Hashfull(pv.Hash)))
+}
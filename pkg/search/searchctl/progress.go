@@ -0,0 +1,63 @@
+package searchctl
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/search"
+	"sync"
+	"time"
+)
+
+// DefaultProgressInterval is the default interval of intermediate progress updates.
+const DefaultProgressInterval = time.Second
+
+// iteration tracks the depth, start time and start node count of the iteration in progress
+// for intermediate progress updates. Thread-safe.
+type iteration struct {
+	depth int
+	start time.Time
+	nodes uint64
+	mu    sync.Mutex
+}
+
+// begin marks the start of a new iteration.
+func (i *iteration) begin(depth int, nodes uint64) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.depth, i.start, i.nodes = depth, time.Now(), nodes
+}
+
+// reportProgress periodically publishes the node count, time and hash utilization of the
+// iteration in progress until the context is done. The updates have no score or moves, so
+// that they are consistent with the PV of the completed iteration.
+func reportProgress(ctx context.Context, opt Options, tt search.TranspositionTable, progress *search.Progress, iter *iteration) {
+	if opt.Progress == nil {
+		return
+	}
+
+	interval := opt.ProgressInterval
+	if interval <= 0 {
+		interval = DefaultProgressInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			iter.mu.Lock()
+			pv := search.PV{ID: opt.ID, Depth: iter.depth, Score: eval.InvalidScore, Nodes: progress.Nodes() - iter.nodes, Time: time.Since(iter.start)}
+			iter.mu.Unlock()
+
+			if tt != nil {
+				pv.Hash = tt.Used()
+			}
+			opt.Progress(pv)
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
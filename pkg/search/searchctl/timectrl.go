@@ -4,57 +4,168 @@ import (
 	"context"
 	"fmt"
 	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/search"
 	"github.com/seekerror/logw"
 	"github.com/seekerror/stdlib/pkg/lang"
+	"github.com/seekerror/stdlib/pkg/util/mathx"
 	"time"
 )
 
+const (
+	// DefaultMovesToGo is the assumed number of moves to the end of the game, if not known.
+	DefaultMovesToGo = 40
+	// MoveOverhead is the time reserved per move for communication and scheduling lag.
+	MoveOverhead = 50 * time.Millisecond
+	// EmergencyTime is the remaining time below which moves are played fast, mostly on the
+	// increment, to not lose on time.
+	EmergencyTime = 5 * time.Second
+	// UnstableExtension is the factor by which the soft limit is extended, if the search is
+	// unstable. The extended limit is capped by the hard limit.
+	UnstableExtension = 2
+	// UnstableScoreDrop is the score drop in pawns between iterations that makes a search
+	// unstable.
+	UnstableScoreDrop eval.Pawns = 0.3
+)
+
 // TimeControl represents time control information.
 type TimeControl struct {
-	White, Black time.Duration
-	Moves        int // 0 == rest of game
+	White, Black       time.Duration
+	WhiteInc, BlackInc time.Duration // increment per move
+	Moves              int           // 0 == rest of game
+	// MoveTime, if positive, is the exact time for the move. It overrides the clock.
+	MoveTime time.Duration
 }
 
-// Limits returns a soft and hard limit for making move with the given color. The
-// interpretation is that after the soft limit, no new search should be conducted.
-func (t TimeControl) Limits(c board.Color) (time.Duration, time.Duration) {
-	remainder := t.White
-	if c == board.Black {
-		remainder = t.Black
+// Budget is the time allocated for making a move.
+type Budget struct {
+	// Soft is the target time. No new iteration is started after the soft limit, unless
+	// extended because the search is unstable.
+	Soft time.Duration
+	// Hard is the maximum time. The search is halted at the hard limit.
+	Hard time.Duration
+	// Emergency is true iff the clock is low and the move is played fast.
+	Emergency bool
+}
+
+// Extended returns the soft limit extended for an unstable search, capped by the hard limit.
+func (b Budget) Extended() time.Duration {
+	if ext := UnstableExtension * b.Soft; ext < b.Hard {
+		return ext
+	}
+	return b.Hard
+}
+
+func (b Budget) String() string {
+	if b.Emergency {
+		return fmt.Sprintf("[%v; %v; emergency]", b.Soft, b.Hard)
 	}
+	return fmt.Sprintf("[%v; %v]", b.Soft, b.Hard)
+}
 
-	// We assume 40 moves to end the game, if nothing else is known.
-	// Let B = T/80 be the soft timeout and the hard timeout be 3B.
+// Budget returns the time budget for making a move with the given color. The allocation is
+// the remaining time split over the moves to go plus most of the increment. The soft limit
+// is half of the allocation and the hard limit 3 times the soft limit, but never more than
+// half the remaining time. If the clock is low, the allocation is mostly the increment.
+func (t TimeControl) Budget(c board.Color) Budget {
+	if t.MoveTime > 0 {
+		return Budget{Soft: t.MoveTime, Hard: t.MoveTime}
+	}
 
-	moves := time.Duration(40)
+	remainder, inc := t.White, t.WhiteInc
+	if c == board.Black {
+		remainder, inc = t.Black, t.BlackInc
+	}
+	remainder -= MoveOverhead
+	if remainder < 0 {
+		remainder = 0
+	}
+
+	moves := time.Duration(DefaultMovesToGo)
 	if t.Moves > 0 {
 		moves = time.Duration(t.Moves) + 1
 	}
 
-	soft := remainder / (2 * moves)
-	hard := 3 * soft
-	return soft, hard
+	if remainder < EmergencyTime {
+		soft := remainder/(4*moves) + inc/4
+		return Budget{Soft: soft, Hard: mathx.Min(2*soft, remainder/4), Emergency: true}
+	}
+
+	soft := (remainder/moves + 3*inc/4) / 2
+	return Budget{Soft: soft, Hard: mathx.Min(3*soft, remainder/2)}
+}
+
+// Remaining returns a time control that allows the given color to use all of its remaining
+// time for the move, less the move overhead, such as for a human player. A move time is
+// unchanged.
+func (t TimeControl) Remaining(c board.Color) TimeControl {
+	if t.MoveTime > 0 {
+		return t
+	}
+
+	remainder := t.White
+	if c == board.Black {
+		remainder = t.Black
+	}
+	return TimeControl{MoveTime: mathx.Max(remainder-MoveOverhead, time.Millisecond)}
+}
+
+// Limits returns a soft and hard limit for making move with the given color. The
+// interpretation is that after the soft limit, no new search should be conducted.
+func (t TimeControl) Limits(c board.Color) (time.Duration, time.Duration) {
+	b := t.Budget(c)
+	return b.Soft, b.Hard
 }
 
 func (t TimeControl) String() string {
-	if t.Moves == 0 {
-		return fmt.Sprintf("%.1f<>%.1f", t.White.Seconds(), t.Black.Seconds())
+	if t.MoveTime > 0 {
+		return fmt.Sprintf("movetime=%.1f", t.MoveTime.Seconds())
+	}
+	ret := fmt.Sprintf("%.1f<>%.1f", t.White.Seconds(), t.Black.Seconds())
+	if t.WhiteInc > 0 || t.BlackInc > 0 {
+		ret += fmt.Sprintf("[inc=%.1f<>%.1f]", t.WhiteInc.Seconds(), t.BlackInc.Seconds())
+	}
+	if t.Moves > 0 {
+		ret += fmt.Sprintf("[moves=%v]", t.Moves)
+	}
+	return ret
+}
+
+// IsUnstable returns true iff the search result changed materially from the previous
+// iteration: the best move changed or the score dropped. More time is then warranted.
+func IsUnstable(prev, cur search.PV) bool {
+	if len(prev.Moves) == 0 || len(cur.Moves) == 0 {
+		return false
+	}
+	if !prev.Moves[0].Equals(cur.Moves[0]) {
+		return true
 	}
-	return fmt.Sprintf("%.1f<>%.1f[moves=%v]", t.White.Seconds(), t.Black.Seconds(), t.Moves)
+	if prev.Score.IsHeuristic() && cur.Score.IsHeuristic() {
+		return prev.Score.Pawns-cur.Score.Pawns > UnstableScoreDrop
+	}
+	return false
 }
 
-// EnforceTimeControl enforces the time control limits, if any. Returns soft limit.
-func EnforceTimeControl(ctx context.Context, h Handle, tc lang.Optional[TimeControl], turn board.Color) (time.Duration, bool) {
+// EnforceTimeControl enforces the time control limits, if any, by halting the search at the
+// hard limit, unless the context is cancelled first. Returns the budget.
+func EnforceTimeControl(ctx context.Context, h Handle, tc lang.Optional[TimeControl], turn board.Color) (Budget, bool) {
 	c, ok := tc.V()
 	if !ok {
-		return 0, false
+		return Budget{}, false
 	}
 
-	soft, hard := c.Limits(turn)
-	time.AfterFunc(hard, func() {
-		h.Halt()
-	})
+	budget := c.Budget(turn)
+	go func() {
+		timer := time.NewTimer(budget.Hard)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+			h.Halt()
+		case <-ctx.Done():
+		}
+	}()
 
-	logw.Debugf(ctx, "Time control limits for %v: [%v; %v]", c, soft, hard)
-	return soft, true
+	logw.Debugf(ctx, "Time control budget for %v: %v", c, budget)
+	return budget, true
 }
@@ -0,0 +1,92 @@
+package searchctl_test
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/herohde/morlock/pkg/search/searchctl"
+	"github.com/seekerror/stdlib/pkg/lang"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestTimeControlBudget(t *testing.T) {
+	tests := []struct {
+		tc         searchctl.TimeControl
+		turn       board.Color
+		soft, hard time.Duration
+		emergency  bool
+	}{
+		{searchctl.TimeControl{White: 80*time.Second + searchctl.MoveOverhead}, board.White, time.Second, 3 * time.Second, false},
+		{searchctl.TimeControl{White: 80*time.Second + searchctl.MoveOverhead, WhiteInc: 4 * time.Second}, board.White, 2500 * time.Millisecond, 7500 * time.Millisecond, false},
+		{searchctl.TimeControl{Black: 20*time.Second + searchctl.MoveOverhead, BlackInc: 20 * time.Second}, board.Black, 7750 * time.Millisecond, 10 * time.Second, false},
+		{searchctl.TimeControl{White: 20*time.Second + searchctl.MoveOverhead, Moves: 1}, board.White, 5 * time.Second, 10 * time.Second, false},
+		{searchctl.TimeControl{White: 4*time.Second + searchctl.MoveOverhead, WhiteInc: time.Second}, board.White, 275 * time.Millisecond, 550 * time.Millisecond, true},
+		{searchctl.TimeControl{White: time.Minute, MoveTime: time.Second}, board.White, time.Second, time.Second, false},
+	}
+
+	for _, tt := range tests {
+		actual := tt.tc.Budget(tt.turn)
+		assert.Equal(t, tt.soft, actual.Soft, "soft: %v", tt.tc)
+		assert.Equal(t, tt.hard, actual.Hard, "hard: %v", tt.tc)
+		assert.Equal(t, tt.emergency, actual.Emergency, "emergency: %v", tt.tc)
+		assert.LessOrEqual(t, actual.Soft, actual.Extended())
+		assert.LessOrEqual(t, actual.Extended(), actual.Hard)
+	}
+}
+
+func TestTimeControlRemaining(t *testing.T) {
+	tc := searchctl.TimeControl{White: time.Minute, Black: 10 * time.Second, WhiteInc: time.Second, Moves: 10}
+	assert.Equal(t, searchctl.TimeControl{MoveTime: time.Minute - searchctl.MoveOverhead}, tc.Remaining(board.White))
+	assert.Equal(t, searchctl.TimeControl{MoveTime: 10*time.Second - searchctl.MoveOverhead}, tc.Remaining(board.Black))
+	assert.Equal(t, searchctl.TimeControl{MoveTime: time.Millisecond}, searchctl.TimeControl{}.Remaining(board.White))
+
+	tc = searchctl.TimeControl{White: time.Minute, MoveTime: time.Second}
+	assert.Equal(t, tc, tc.Remaining(board.White))
+}
+
+func TestIsUnstable(t *testing.T) {
+	e2e4 := board.Move{Type: board.Push, Piece: board.Pawn, From: board.E2, To: board.E4}
+	d2d4 := board.Move{Type: board.Push, Piece: board.Pawn, From: board.D2, To: board.D4}
+
+	tests := []struct {
+		prev, cur search.PV
+		expected  bool
+	}{
+		{search.PV{}, search.PV{Moves: []board.Move{e2e4}, Score: eval.HeuristicScore(0.2)}, false},
+		{search.PV{Moves: []board.Move{e2e4}, Score: eval.HeuristicScore(0.2)}, search.PV{Moves: []board.Move{e2e4}, Score: eval.HeuristicScore(0.1)}, false},
+		{search.PV{Moves: []board.Move{e2e4}, Score: eval.HeuristicScore(0.2)}, search.PV{Moves: []board.Move{e2e4}, Score: eval.HeuristicScore(1.2)}, false},
+		{search.PV{Moves: []board.Move{e2e4}, Score: eval.HeuristicScore(0.2)}, search.PV{Moves: []board.Move{e2e4}, Score: eval.HeuristicScore(-0.5)}, true},
+		{search.PV{Moves: []board.Move{e2e4}, Score: eval.HeuristicScore(0.2)}, search.PV{Moves: []board.Move{d2d4}, Score: eval.HeuristicScore(0.2)}, true},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, searchctl.IsUnstable(tt.prev, tt.cur), "%v -> %v", tt.prev, tt.cur)
+	}
+}
+
+func TestIterativeMoveTime(t *testing.T) {
+	ctx := context.Background()
+	root := &searchctl.Iterative{Root: search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}}
+
+	b, err := fen.NewBoard(fen.Initial)
+	require.NoError(t, err)
+
+	// The hard limit is enforced by the search itself: the search ends without Halt.
+
+	start := time.Now()
+	tc := searchctl.TimeControl{MoveTime: 200 * time.Millisecond}
+	_, out := root.Launch(ctx, b, search.NoTranspositionTable{}, eval.Random{}, searchctl.Options{TimeControl: lang.Some(tc)})
+
+	var last search.PV
+	for pv := range out {
+		last = pv
+	}
+	assert.NotEmpty(t, last.Moves)
+	assert.Less(t, last.Depth, searchctl.MaxDepth)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
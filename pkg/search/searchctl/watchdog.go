@@ -0,0 +1,63 @@
+package searchctl
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/seekerror/logw"
+	"runtime"
+	"time"
+)
+
+// Watchdog detects stalled searches, which make no node progress for the timeout. A stall is
+// logged once with the search ID and position and, if Stacks is set, with the goroutine stacks
+// of the process to diagnose where the search is stuck. Searches that do not report progress,
+// such as adaptors, are reported if they take longer than the timeout.
+type Watchdog struct {
+	// Timeout is the duration without progress after which a search is stalled. Zero means
+	// the watchdog is disabled.
+	Timeout time.Duration
+	// Stacks also logs the goroutine stacks of the process on a stall.
+	Stacks bool
+}
+
+// maxStackSize is the maximum size of the logged goroutine stacks.
+const maxStackSize = 1 << 20
+
+// watch monitors the search progress until the context is done.
+func (w Watchdog) watch(ctx context.Context, id search.ID, pos string, progress *search.Progress) {
+	if w.Timeout <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(w.Timeout)
+	defer ticker.Stop()
+
+	last, stalled := progress.Nodes(), false
+	for {
+		select {
+		case <-ticker.C:
+			nodes := progress.Nodes()
+			switch {
+			case nodes != last:
+				if stalled {
+					logw.Infof(ctx, "Search %v resumed on %v after stall, nodes=%v", id, pos, nodes)
+				}
+				last, stalled = nodes, false
+
+			case !stalled:
+				stalled = true
+				recordStall()
+
+				logw.Warningf(ctx, "Search %v stalled on %v: no progress for %v, nodes=%v", id, pos, w.Timeout, nodes)
+				if w.Stacks {
+					buf := make([]byte, maxStackSize)
+					buf = buf[:runtime.Stack(buf, true)]
+					logw.Warningf(ctx, "Search %v stacks:\n%s", id, buf)
+				}
+			}
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package search
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/eval"
+)
+
+// Stalemate is a root search that, when the position is hopeless, prefers a move that draws
+// immediately, such as by stalemate or by capturing into insufficient material. The simple
+// engines often do not explore such moves, because their evaluation does not consider draws.
+// Draws are detected by the board, so repetitions and the 50-move rule also count.
+type Stalemate struct {
+	// Eval is the underlying search.
+	Eval Search
+	// Threshold is the number of pawns behind for a position to be considered hopeless. If
+	// zero, DefaultStalemateThreshold is used. Forced mates against the side to move are
+	// always hopeless.
+	Threshold eval.Pawns
+}
+
+const DefaultStalemateThreshold eval.Pawns = 5
+
+func (s Stalemate) Capabilities() Capabilities {
+	return CapabilitiesOf(s.Eval)
+}
+
+func (s Stalemate) Search(ctx context.Context, sctx *Context, b *board.Board, depth int) (uint64, eval.Score, []board.Move, error) {
+	nodes, score, moves, err := s.Eval.Search(ctx, sctx, b, depth)
+	if err != nil || len(sctx.Ponder) > 0 || !s.isHopeless(score) {
+		return nodes, score, moves, err
+	}
+
+	for _, m := range b.Position().LegalMoves(b.Turn()) {
+		if !sctx.IsRootMove(m) {
+			continue
+		}
+		nodes++
+		if isDraw(b, m) {
+			return nodes, eval.ZeroScore, []board.Move{m}, nil
+		}
+	}
+	return nodes, score, moves, nil
+}
+
+func (s Stalemate) isHopeless(score eval.Score) bool {
+	switch score.Type {
+	case eval.Heuristic:
+		return score.Pawns < -s.threshold()
+	case eval.MateInX:
+		return score.Mate < 0
+	case eval.NegInf:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s Stalemate) threshold() eval.Pawns {
+	if s.Threshold == 0 {
+		return DefaultStalemateThreshold
+	}
+	return s.Threshold
+}
+
+// isDraw returns true iff the given legal move ends the game in a draw.
+func isDraw(b *board.Board, m board.Move) bool {
+	fork := b.Fork()
+	if !fork.PushMove(m) {
+		return false
+	}
+	if fork.Result().Outcome == board.Draw {
+		return true
+	}
+	if len(fork.Position().LegalMoves(fork.Turn())) > 0 {
+		return false
+	}
+	return fork.AdjudicateNoLegalMoves().Outcome == board.Draw
+}
@@ -0,0 +1,49 @@
+package search_test
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestStalemate(t *testing.T) {
+	ctx := context.Background()
+
+	// A pawn behind, Black can stalemate White with Kc2. A 1-ply search does not see it.
+
+	s := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+	pos := "8/8/6p1/1p4P1/1P4P1/p2k4/P7/K7 b - - 0 1"
+
+	tests := []struct {
+		threshold eval.Pawns
+		score     eval.Score
+		moves     string
+	}{
+		{2, eval.HeuristicScore(-1), ""}, // not hopeless: plain search
+		{0.5, eval.ZeroScore, "Kd3-c2"},
+	}
+
+	for _, tt := range tests {
+		b, err := fen.NewBoard(pos)
+		require.NoError(t, err)
+
+		_, expected, line, err := s.Search(ctx, search.EmptyContext, b, 1)
+		require.NoError(t, err)
+		assert.Equal(t, eval.HeuristicScore(-1), expected)
+		assert.Equal(t, "Kd3-e2", board.PrintMoves(line))
+
+		_, score, moves, err := search.Stalemate{Eval: s, Threshold: tt.threshold}.Search(ctx, search.EmptyContext, b, 1)
+		require.NoError(t, err)
+		assert.Equal(t, tt.score, score)
+		if tt.moves == "" {
+			assert.Equal(t, line, moves)
+		} else {
+			assert.Equal(t, tt.moves, board.PrintMoves(moves))
+		}
+	}
+}
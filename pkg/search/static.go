@@ -0,0 +1,69 @@
+package search
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/seekerror/stdlib/pkg/util/contextx"
+)
+
+// Static is a root search that plays the move with the best static evaluation after the move,
+// regardless of depth. It does no search and is a reference engine for isolating the behavior
+// of an evaluation from the behavior of the search, such as to diagnose odd moves. Moves that
+// checkmate or draw immediately are scored as such.
+type Static struct {
+	Eval Evaluator
+}
+
+func (s Static) Capabilities() Capabilities {
+	return CapabilitiesOf(s.Eval)
+}
+
+func (s Static) Search(ctx context.Context, sctx *Context, b *board.Board, depth int) (uint64, eval.Score, []board.Move, error) {
+	var nodes uint64
+	score := eval.NegInfScore
+	var pv []board.Move
+
+	hasLegalMove := false
+	for _, m := range b.Position().LegalMoves(b.Turn()) {
+		hasLegalMove = true
+		if !sctx.IsRootMove(m) || !b.PushMove(m) {
+			continue
+		}
+		nodes++
+		sctx.Progress.Add(1)
+
+		v := s.evaluate(ctx, sctx, b)
+		b.PopMove()
+
+		if pv == nil || score.Less(v) {
+			score, pv = v, []board.Move{m}
+		}
+	}
+	if contextx.IsCancelled(ctx) {
+		return 0, eval.InvalidScore, nil, ErrHalted
+	}
+
+	if !hasLegalMove {
+		if result := b.AdjudicateNoLegalMoves(); result.Reason == board.Checkmate {
+			return nodes, eval.NegInfScore, nil, nil
+		}
+		return nodes, sctx.Contempt.DrawScore(b.Turn()), nil, nil
+	}
+	return nodes, score, pv, nil
+}
+
+// evaluate returns the score of the position after a move from the point of view of the side
+// that made it.
+func (s Static) evaluate(ctx context.Context, sctx *Context, b *board.Board) eval.Score {
+	if b.Result().Outcome == board.Draw {
+		return sctx.Contempt.DrawScore(b.Turn()).Negate()
+	}
+	if len(b.Position().LegalMoves(b.Turn())) == 0 {
+		if result := b.AdjudicateNoLegalMoves(); result.Reason == board.Checkmate {
+			return eval.IncrementMateDistance(eval.NegInfScore).Negate()
+		}
+		return sctx.Contempt.DrawScore(b.Turn()).Negate()
+	}
+	return eval.HeuristicScore(s.Eval.Evaluate(ctx, sctx, b)).Negate()
+}
@@ -0,0 +1,43 @@
+package search_test
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestStatic(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		fen      string
+		expected string
+		score    eval.Score
+	}{
+		{"4k3/8/8/8/3q4/8/8/3QK3 w - - 0 1", "Qd1*d4", eval.HeuristicScore(9)}, // capture
+		{"k7/7R/6R1/8/8/8/8/7K w - - 0 1", "Rg6-g8", eval.MateInXScore(1)},     // mate
+		{"7k/6Q1/6K1/8/8/8/8/8 b - - 0 1", "", eval.NegInfScore},               // checkmate
+		{"3k4/8/8/8/8/8/8/r2QK3 b - - 0 1", "Ra1*d1", eval.HeuristicScore(5)},  // capture
+	}
+
+	s := search.Static{Eval: search.Leaf{Eval: eval.Material{}}}
+	for _, tt := range tests {
+		b, err := fen.NewBoard(tt.fen)
+		require.NoError(t, err)
+
+		_, score, pv, err := s.Search(ctx, search.EmptyContext, b, 4)
+		require.NoError(t, err)
+
+		if tt.expected == "" {
+			assert.Emptyf(t, pv, "failed: %v", tt.fen)
+		} else {
+			require.Lenf(t, pv, 1, "failed: %v", tt.fen)
+			assert.Equalf(t, tt.expected, pv[0].String(), "failed: %v", tt.fen)
+		}
+		assert.Equalf(t, tt.score, score, "failed: %v", tt.fen)
+	}
+}
@@ -0,0 +1,69 @@
+package search
+
+import (
+	"github.com/seekerror/stdlib/pkg/util/mathx"
+)
+
+// MaxCutoffIndex is the number of move index buckets of the beta cutoff distribution. The
+// last bucket holds cutoffs at that move index or later.
+const MaxCutoffIndex = 8
+
+// Stats holds search statistics to diagnose search efficiency: beta cutoffs and at which move
+// they happened, transposition table usage, quiet search nodes and re-searches. A nil Stats
+// ignores updates. Not thread-safe.
+type Stats struct {
+	Nodes       uint64                 // interior nodes
+	QuietNodes  uint64                 // quiet search nodes
+	Cutoffs     uint64                 // beta cutoffs
+	CutoffIndex [MaxCutoffIndex]uint64 // beta cutoffs by index of the searched move
+	Probes      uint64                 // table reads
+	Hits        uint64                 // table reads that found the position
+	Stores      uint64                 // table writes that were stored
+	Researches  uint64                 // re-searches, such as for a failed aspiration window
+}
+
+// AddNode adds an interior node.
+func (s *Stats) AddNode() {
+	if s != nil {
+		s.Nodes++
+	}
+}
+
+// AddQuiet adds quiet search nodes.
+func (s *Stats) AddQuiet(nodes uint64) {
+	if s != nil {
+		s.QuietNodes += nodes
+	}
+}
+
+// AddCutoff adds a beta cutoff at the given 0-based index of searched moves.
+func (s *Stats) AddCutoff(index int) {
+	if s != nil {
+		s.Cutoffs++
+		s.CutoffIndex[mathx.Min(mathx.Max(index, 0), MaxCutoffIndex-1)]++
+	}
+}
+
+// AddProbe adds a table read.
+func (s *Stats) AddProbe(hit bool) {
+	if s != nil {
+		s.Probes++
+		if hit {
+			s.Hits++
+		}
+	}
+}
+
+// AddStore adds a table write, if stored.
+func (s *Stats) AddStore(stored bool) {
+	if s != nil && stored {
+		s.Stores++
+	}
+}
+
+// AddResearch adds a re-search.
+func (s *Stats) AddResearch() {
+	if s != nil {
+		s.Researches++
+	}
+}
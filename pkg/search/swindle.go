@@ -0,0 +1,142 @@
+package search
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/eval"
+)
+
+// Swindle is a root search that, when losing, prefers "trappy" moves, where a modeled opponent
+// is likely to reply with an error. The opponent is modeled as a search with its own evaluation
+// to a shallow depth, such as the configuration of a historical engine. Each legal move is then
+// scored by the outcome after the reply the opponent model would select. A swindle move is
+// only selected if its true score is within a margin of the best move, so that the search
+// does not blunder into a faster loss against a stronger opponent.
+type Swindle struct {
+	// Eval is the underlying search. It is also used to find the true score of moves.
+	Eval Search
+	// Opponent is the opponent model. It is searched to OpponentDepth, if positive, and
+	// otherwise to a single ply.
+	Opponent      Search
+	OpponentDepth int
+	// Threshold is the number of pawns behind for a position to be considered lost. If zero,
+	// DefaultSwindleThreshold is used. Forced mates against the side to move are always lost.
+	Threshold eval.Pawns
+	// Margin is the number of pawns the true score of a swindle move may be worse than the
+	// score of the best move. If zero, DefaultSwindleMargin is used.
+	Margin eval.Pawns
+}
+
+const (
+	DefaultSwindleThreshold eval.Pawns = 2
+	DefaultSwindleMargin    eval.Pawns = 1
+)
+
+func (s Swindle) Capabilities() Capabilities {
+	return CapabilitiesOf(s.Eval).Merge(CapabilitiesOf(s.Opponent))
+}
+
+func (s Swindle) Search(ctx context.Context, sctx *Context, b *board.Board, depth int) (uint64, eval.Score, []board.Move, error) {
+	nodes, score, moves, err := s.Eval.Search(ctx, sctx, b, depth)
+	if err != nil || len(sctx.Ponder) > 0 || depth < 2 || !s.isLost(score) {
+		return nodes, score, moves, err
+	}
+
+	// (1) The position is lost. Find the move with the best outcome, if the opponent replies
+	// as modeled, among moves that are not much worse than the best move.
+
+	floor := score
+	if score.IsHeuristic() {
+		floor = eval.HeuristicScore(score.Pawns - s.margin())
+	}
+
+	best, bestScore, bestMoves := score, score, moves
+	for _, m := range b.Position().LegalMoves(b.Turn()) {
+		if !sctx.IsRootMove(m) {
+			continue
+		}
+
+		n, actual, _, err := s.Eval.Search(ctx, s.ponder(sctx, m), b, depth)
+		nodes += n
+		if err != nil {
+			return nodes, eval.InvalidScore, nil, err
+		}
+		if actual.Less(floor) {
+			continue // not an acceptable risk
+		}
+
+		reply, n, err := s.reply(ctx, sctx, b, m)
+		nodes += n
+		if err != nil {
+			return nodes, eval.InvalidScore, nil, err
+		}
+		if reply == nil {
+			continue
+		}
+
+		n, expected, line, err := s.Eval.Search(ctx, s.ponder(sctx, m, *reply), b, depth)
+		nodes += n
+		if err != nil {
+			return nodes, eval.InvalidScore, nil, err
+		}
+		if best.Less(expected) || (best == expected && bestScore.Less(actual)) {
+			best, bestScore, bestMoves = expected, actual, line
+		}
+	}
+
+	// (2) Return the true score of the swindle move, with the expected line as the variation.
+
+	return nodes, bestScore, bestMoves, nil
+}
+
+// reply returns the opponent model reply to the given move, if any.
+func (s Swindle) reply(ctx context.Context, sctx *Context, b *board.Board, m board.Move) (*board.Move, uint64, error) {
+	fork := b.Fork()
+	if !fork.PushMove(m) {
+		return nil, 0, nil
+	}
+	if fork.Result().IsTerminal() {
+		return nil, 0, nil
+	}
+
+	depth := s.OpponentDepth
+	if depth < 1 {
+		depth = 1
+	}
+	nodes, _, moves, err := s.Opponent.Search(ctx, &Context{ID: sctx.ID, TT: NoTranspositionTable{}, Progress: sctx.Progress}, fork, depth)
+	if err != nil || len(moves) == 0 {
+		return nil, nodes, err
+	}
+	return &moves[0], nodes, nil
+}
+
+func (s Swindle) ponder(sctx *Context, moves ...board.Move) *Context {
+	return &Context{ID: sctx.ID, TT: NoTranspositionTable{}, Noise: sctx.Noise, Contempt: sctx.Contempt, Ponder: moves, Progress: sctx.Progress}
+}
+
+func (s Swindle) isLost(score eval.Score) bool {
+	switch score.Type {
+	case eval.Heuristic:
+		return score.Pawns < -s.threshold()
+	case eval.MateInX:
+		return score.Mate < 0
+	case eval.NegInf:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s Swindle) threshold() eval.Pawns {
+	if s.Threshold == 0 {
+		return DefaultSwindleThreshold
+	}
+	return s.Threshold
+}
+
+func (s Swindle) margin() eval.Pawns {
+	if s.Margin == 0 {
+		return DefaultSwindleMargin
+	}
+	return s.Margin
+}
@@ -0,0 +1,50 @@
+package search_test
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSwindle(t *testing.T) {
+	ctx := context.Background()
+
+	// The opponent is modeled as a greedy 1-ply material search.
+
+	s := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+	sw := search.Swindle{Eval: s, Opponent: s, OpponentDepth: 1}
+
+	tests := []struct {
+		fen           string
+		depth         int
+		plain, actual string
+		score         eval.Score
+	}{
+		// Not lost: no swindle.
+		{"4k3/8/8/8/3q4/8/8/3QK3 w - - 0 1", 2, "Qd1*d4 Ke8-f7", "Qd1*d4 Ke8-f7", eval.HeuristicScore(9)},
+		// Lost: invite Rxf2, which loses the rook to Kxf2.
+		{"1r4k1/p1p2ppp/8/8/8/2N5/3r1PPP/4R1K1 w - - 0 1", 3, "Re1-e7 Kg8-f8 Re7*f7", "Re1-a1 Rd2*f2 Kg1*f2", eval.HeuristicScore(-3)},
+		// Lost: invite Rxb5, which allows a back-rank mate.
+		{"1r4k1/p1p2ppp/8/8/8/2N5/3r1PPP/4R1K1 w - - 0 1", 4, "Nc3-b5 Kg8-h8 Nb5*c7 Rd2*f2", "Nc3-b5 Rb8*b5 Re1-e8", eval.HeuristicScore(-4)},
+	}
+
+	for _, tt := range tests {
+		b, err := fen.NewBoard(tt.fen)
+		require.NoError(t, err)
+
+		_, score, moves, err := s.Search(ctx, search.EmptyContext, b, tt.depth)
+		require.NoError(t, err)
+		assert.Equal(t, tt.score, score)
+		assert.Equal(t, tt.plain, board.PrintMoves(moves))
+
+		_, score, moves, err = sw.Search(ctx, search.EmptyContext, b, tt.depth)
+		require.NoError(t, err)
+		assert.Equal(t, tt.score, score)
+		assert.Equal(t, tt.actual, board.PrintMoves(moves))
+	}
+}
@@ -0,0 +1,126 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"github.com/herohde/morlock/pkg/board"
+)
+
+// WDL is a tablebase outcome for the side to move, ignoring the 50-move rule.
+type WDL int8
+
+const (
+	TBLoss WDL = iota - 1
+	TBDraw
+	TBWin
+)
+
+func (w WDL) String() string {
+	switch w {
+	case TBLoss:
+		return "loss"
+	case TBDraw:
+		return "draw"
+	case TBWin:
+		return "win"
+	default:
+		return fmt.Sprintf("wdl(%d)", w)
+	}
+}
+
+// Tablebase is an endgame tablebase. No tablebase format is implemented here: probing is
+// provided by the user, such as a Syzygy prober. Thread-safe.
+type Tablebase interface {
+	// Probe returns the outcome for the side to move and the distance to zeroing (DTZ) in
+	// plies, if the position is in the tablebase. The DTZ is the number of plies until the
+	// next capture or pawn move under optimal play.
+	Probe(ctx context.Context, b *board.Board) (WDL, int, bool)
+}
+
+// NoTablebase is a Nop implementation.
+type NoTablebase struct{}
+
+func (n NoTablebase) Probe(ctx context.Context, b *board.Board) (WDL, int, bool) {
+	return TBDraw, 0, false
+}
+
+// TablebaseRootMoves returns the legal root moves that preserve the best tablebase outcome,
+// if every move can be probed. If winning, only the moves with the shortest DTZ are kept so
+// that the win is not lost to the 50-move rule. If losing, only the moves with the longest DTZ
+// are kept. All drawing moves are kept. Also returns the number of probes (tbhits).
+func TablebaseRootMoves(ctx context.Context, tb Tablebase, b *board.Board) ([]board.Move, WDL, uint64, bool) {
+	type probe struct {
+		move board.Move
+		wdl  WDL
+		dtz  int
+	}
+
+	var list []probe
+	var hits uint64
+	for _, m := range b.Position().LegalMoves(b.Turn()) {
+		if !b.PushMove(m) {
+			continue
+		}
+		wdl, dtz, ok := probeChild(ctx, tb, b, &hits)
+		b.PopMove()
+
+		if !ok {
+			return nil, TBDraw, hits, false
+		}
+		list = append(list, probe{move: m, wdl: wdl, dtz: dtz})
+	}
+	if len(list) == 0 {
+		return nil, TBDraw, hits, false // checkmate or stalemate
+	}
+
+	best := list[0]
+	for _, p := range list[1:] {
+		if best.wdl < p.wdl || (best.wdl == p.wdl && isBetterDTZ(p.wdl, p.dtz, best.dtz)) {
+			best = p
+		}
+	}
+
+	var ret []board.Move
+	for _, p := range list {
+		if p.wdl == best.wdl && (best.wdl == TBDraw || p.dtz == best.dtz) {
+			ret = append(ret, p.move)
+		}
+	}
+	return ret, best.wdl, hits, true
+}
+
+// probeChild returns the outcome and DTZ of the move leading to the current position for
+// the side that made it. A game-ending move is exact and needs no probe.
+func probeChild(ctx context.Context, tb Tablebase, b *board.Board, hits *uint64) (WDL, int, bool) {
+	if len(b.Position().LegalMoves(b.Turn())) == 0 {
+		if b.AdjudicateNoLegalMoves().Reason == board.Checkmate {
+			return TBWin, 1, true
+		}
+		return TBDraw, 1, true
+	}
+	if b.Result().Outcome == board.Draw {
+		return TBDraw, 1, true
+	}
+
+	wdl, dtz, ok := tb.Probe(ctx, b)
+	if !ok {
+		return TBDraw, 0, false
+	}
+	*hits++
+
+	if b.NoProgress() == 0 {
+		dtz = 0 // the move itself zeroes
+	}
+	return -wdl, dtz + 1, true
+}
+
+func isBetterDTZ(wdl WDL, a, b int) bool {
+	switch wdl {
+	case TBWin:
+		return a < b
+	case TBLoss:
+		return a > b
+	default:
+		return false
+	}
+}
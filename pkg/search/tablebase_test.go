@@ -0,0 +1,66 @@
+package search_test
+
+import (
+	"context"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// materialTablebase is a fake tablebase for positions with at most 4 pieces, where the side
+// with more material wins and the DTZ is the number of pieces.
+type materialTablebase struct{}
+
+func (materialTablebase) Probe(ctx context.Context, b *board.Board) (search.WDL, int, bool) {
+	pos := b.Position()
+	if pos.All().PopCount() > 4 {
+		return search.TBDraw, 0, false
+	}
+
+	switch balance := (eval.Material{}).Evaluate(ctx, b); {
+	case balance > 0:
+		return search.TBWin, pos.All().PopCount(), true
+	case balance < 0:
+		return search.TBLoss, pos.All().PopCount(), true
+	default:
+		return search.TBDraw, pos.All().PopCount(), true
+	}
+}
+
+func TestTablebaseRootMoves(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		fen      string
+		ok       bool
+		wdl      search.WDL
+		expected string
+	}{
+		{fen.Initial, false, search.TBDraw, ""},
+		{"3r3k/8/8/8/8/8/8/K2Q4 w - - 0 1", true, search.TBWin, "Qd1*d8"},
+		{"3r3k/8/8/8/8/8/8/K2Q4 b - - 0 1", true, search.TBWin, "Rd8*d1"},
+		{"7k/8/8/8/8/8/1q6/K7 w - - 0 1", true, search.TBDraw, "Ka1*b2"},
+	}
+
+	for _, tt := range tests {
+		b, err := fen.NewBoard(tt.fen)
+		require.NoError(t, err)
+
+		moves, wdl, _, ok := search.TablebaseRootMoves(ctx, materialTablebase{}, b)
+		assert.Equal(t, tt.ok, ok, "pos: %v", tt.fen)
+		if !tt.ok {
+			continue
+		}
+		assert.Equal(t, tt.wdl, wdl, "pos: %v", tt.fen)
+		assert.Equal(t, tt.expected, board.PrintMoves(moves), "pos: %v", tt.fen)
+	}
+
+	b, err := fen.NewBoard("3r3k/8/8/8/8/8/8/K2Q4 w - - 0 1")
+	require.NoError(t, err)
+	_, _, _, ok := search.TablebaseRootMoves(ctx, search.NoTablebase{}, b)
+	assert.False(t, ok)
+}
@@ -0,0 +1,178 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/herohde/morlock/pkg/board"
+	"github.com/herohde/morlock/pkg/eval"
+	"io"
+	"strings"
+)
+
+// Cutoff represents the reason a traced node was not fully searched, if any.
+type Cutoff string
+
+const (
+	NoCutoff        Cutoff = ""
+	TableCutoff     Cutoff = "tt"        // transposition table entry determined the score
+	BetaCutoff      Cutoff = "beta"      // a move failed high
+	NullMoveCutoff  Cutoff = "null-move" // passing the turn failed high
+	HorizonCutoff   Cutoff = "horizon"   // depth exhausted: quiet search
+	DrawCutoff      Cutoff = "draw"      // draw by rule, such as repetition
+	MateCutoff      Cutoff = "mate"      // checkmate
+	StalemateCutoff Cutoff = "stalemate"
+	HaltedCutoff    Cutoff = "halted" // search halted
+)
+
+// TraceNode is a visited node in a traced search tree. The alpha/beta window is the window
+// on entry, before any table narrowing. Scores are from the perspective of the side to move.
+type TraceNode struct {
+	Hash        board.ZobristHash
+	Move        string // move leading to the node, if any. "null" for a null move.
+	Depth       int
+	Alpha, Beta eval.Score
+	Score       eval.Score
+	Cutoff      Cutoff
+	Children    []*TraceNode
+}
+
+// Trace records the visited nodes of searches for debugging, bounded by height from the root
+// and total node count. Nodes beyond the bounds are not recorded, but still searched. Each
+// search call adds a root node, such as for each iteration. A nil Trace ignores updates. Not
+// thread-safe.
+type Trace struct {
+	// MaxHeight, if positive, is the maximum height from the root of recorded nodes.
+	MaxHeight int
+	// MaxNodes, if positive, is the maximum number of recorded nodes.
+	MaxNodes int
+
+	roots []*TraceNode
+	stack []*TraceNode // path from the root. Nil for unrecorded nodes.
+	count int
+}
+
+// Enter records entering a node with the given window.
+func (t *Trace) Enter(hash board.ZobristHash, move string, depth int, alpha, beta eval.Score) {
+	if t == nil {
+		return
+	}
+
+	var parent *TraceNode
+	if len(t.stack) > 0 {
+		parent = t.stack[len(t.stack)-1]
+	}
+	if (len(t.stack) > 0 && parent == nil) || (t.MaxHeight > 0 && len(t.stack) > t.MaxHeight) || (t.MaxNodes > 0 && t.count >= t.MaxNodes) {
+		t.stack = append(t.stack, nil) // skip: out of bounds
+		return
+	}
+
+	n := &TraceNode{Hash: hash, Move: move, Depth: depth, Alpha: alpha, Beta: beta, Score: eval.InvalidScore}
+	if parent == nil {
+		t.roots = append(t.roots, n)
+	} else {
+		parent.Children = append(parent.Children, n)
+	}
+	t.stack = append(t.stack, n)
+	t.count++
+}
+
+// Exit records leaving the current node with the given score and cutoff reason.
+func (t *Trace) Exit(score eval.Score, cutoff Cutoff) {
+	if t == nil || len(t.stack) == 0 {
+		return
+	}
+
+	if n := t.stack[len(t.stack)-1]; n != nil {
+		n.Score, n.Cutoff = score, cutoff
+	}
+	t.stack = t.stack[:len(t.stack)-1]
+}
+
+// Roots returns the recorded root nodes in search order.
+func (t *Trace) Roots() []*TraceNode {
+	if t == nil {
+		return nil
+	}
+	return t.roots
+}
+
+// Len returns the number of recorded nodes.
+func (t *Trace) Len() int {
+	if t == nil {
+		return 0
+	}
+	return t.count
+}
+
+type traceNodeJSON struct {
+	Hash     string           `json:"hash"`
+	Move     string           `json:"move,omitempty"`
+	Depth    int              `json:"depth"`
+	Alpha    string           `json:"alpha"`
+	Beta     string           `json:"beta"`
+	Score    string           `json:"score"`
+	Cutoff   Cutoff           `json:"cutoff,omitempty"`
+	Children []*traceNodeJSON `json:"children,omitempty"`
+}
+
+func newTraceNodeJSON(n *TraceNode) *traceNodeJSON {
+	ret := &traceNodeJSON{
+		Hash:   fmt.Sprintf("%016x", uint64(n.Hash)),
+		Move:   n.Move,
+		Depth:  n.Depth,
+		Alpha:  n.Alpha.String(),
+		Beta:   n.Beta.String(),
+		Score:  n.Score.String(),
+		Cutoff: n.Cutoff,
+	}
+	for _, c := range n.Children {
+		ret.Children = append(ret.Children, newTraceNodeJSON(c))
+	}
+	return ret
+}
+
+// WriteJSON writes the recorded nodes as an indented JSON array of root nodes.
+func (t *Trace) WriteJSON(w io.Writer) error {
+	list := []*traceNodeJSON{}
+	for _, r := range t.Roots() {
+		list = append(list, newTraceNodeJSON(r))
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(list)
+}
+
+// WriteDot writes the recorded nodes as a graphviz digraph. Edges are labelled by move and
+// nodes by depth, window, score and cutoff reason, if any.
+func (t *Trace) WriteDot(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("digraph search {\n")
+	sb.WriteString("  node [shape=box, fontname=monospace];\n")
+
+	id := 0
+	var visit func(n *TraceNode) int
+	visit = func(n *TraceNode) int {
+		self := id
+		id++
+
+		label := fmt.Sprintf("d=%v [%v;%v]\\n%v", n.Depth, n.Alpha, n.Beta, n.Score)
+		if n.Cutoff != NoCutoff {
+			label += fmt.Sprintf(" (%v)", n.Cutoff)
+		}
+		fmt.Fprintf(&sb, "  n%v [label=\"%v\"];\n", self, label)
+
+		for _, c := range n.Children {
+			child := visit(c)
+			fmt.Fprintf(&sb, "  n%v -> n%v [label=\"%v\"];\n", self, child, c.Move)
+		}
+		return self
+	}
+	for _, r := range t.Roots() {
+		visit(r)
+	}
+
+	sb.WriteString("}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,64 @@
+package search_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/herohde/morlock/pkg/board/fen"
+	"github.com/herohde/morlock/pkg/eval"
+	"github.com/herohde/morlock/pkg/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	ctx := context.Background()
+
+	b, err := fen.NewBoard("k7/7R/6R1/8/8/8/8/7K w - - 0 1")
+	require.NoError(t, err)
+
+	trace := &search.Trace{MaxHeight: 1}
+	sctx := &search.Context{TT: search.NoTranspositionTable{}, Trace: trace}
+
+	ab := search.AlphaBeta{Eval: search.Leaf{Eval: eval.Material{}}}
+	_, score, _, err := ab.Search(ctx, sctx, b, 2)
+	require.NoError(t, err)
+	assert.Equal(t, eval.MateInXScore(1), score)
+
+	// (1) The root and its children are recorded, but not grandchildren.
+
+	roots := trace.Roots()
+	require.Len(t, roots, 1)
+	assert.Equal(t, "", roots[0].Move)
+	assert.Equal(t, 2, roots[0].Depth)
+	assert.Equal(t, score, roots[0].Score)
+	assert.Equal(t, len(roots[0].Children)+1, trace.Len())
+
+	mate := false
+	for _, c := range roots[0].Children {
+		assert.Empty(t, c.Children)
+		if c.Move == "Rg6-g8" {
+			mate = true
+			assert.Equal(t, eval.NegInfScore, c.Score)
+			assert.Equal(t, search.MateCutoff, c.Cutoff)
+		}
+	}
+	assert.True(t, mate)
+
+	// (2) Dumps.
+
+	var buf bytes.Buffer
+	require.NoError(t, trace.WriteJSON(&buf))
+
+	var list []map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &list))
+	require.Len(t, list, 1)
+	assert.Equal(t, "M1", list[0]["score"])
+
+	buf.Reset()
+	require.NoError(t, trace.WriteDot(&buf))
+	assert.True(t, strings.HasPrefix(buf.String(), "digraph search {"))
+	assert.Contains(t, buf.String(), `[label="Rg6-g8"]`)
+}
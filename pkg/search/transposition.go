@@ -6,9 +6,9 @@ import (
 	"github.com/herohde/morlock/pkg/board"
 	"github.com/herohde/morlock/pkg/eval"
 	"github.com/seekerror/logw"
+	"math"
 	"math/bits"
 	"sync/atomic"
-	"unsafe"
 )
 
 // TODO(herohde) 4/17/2021: consider shared linked list for principal variation.
@@ -19,6 +19,7 @@ type Bound uint8
 const (
 	ExactBound Bound = iota
 	LowerBound
+	UpperBound
 )
 
 func (b Bound) String() string {
@@ -27,11 +28,59 @@ func (b Bound) String() string {
 		return "Exact"
 	case LowerBound:
 		return "Lower"
+	case UpperBound:
+		return "Upper"
 	default:
 		return "?"
 	}
 }
 
+// boundOf returns the bound of a fail-hard search score relative to the original [alpha;beta]
+// search window. A score at or above beta is a lower bound (fail-high) and a score at or below
+// alpha is an upper bound (fail-low).
+func boundOf(score, alpha, beta eval.Score) Bound {
+	switch {
+	case !score.Less(beta):
+		return LowerBound
+	case !alpha.Less(score):
+		return UpperBound
+	default:
+		return ExactBound
+	}
+}
+
+// isCutoff returns true iff a table entry with the given bound and score determines the search
+// result for the [alpha;beta] search window.
+func isCutoff(bound Bound, score, alpha, beta eval.Score) bool {
+	switch bound {
+	case ExactBound:
+		return true
+	case LowerBound:
+		return !score.Less(beta)
+	case UpperBound:
+		return !alpha.Less(score)
+	default:
+		return false
+	}
+}
+
+// narrowWindow returns the [alpha;beta] search window narrowed by a table entry with the given
+// bound and score that does not determine the search result by itself: a lower bound raises
+// alpha and an upper bound lowers beta.
+func narrowWindow(bound Bound, score, alpha, beta eval.Score) (eval.Score, eval.Score) {
+	switch bound {
+	case LowerBound:
+		if alpha.Less(score) {
+			return score, beta
+		}
+	case UpperBound:
+		if score.Less(beta) {
+			return alpha, score
+		}
+	}
+	return alpha, beta
+}
+
 // TranspositionTable represents a transposition table to speed up search performance.
 // Caveat: evaluation heuristics that depend on the game history (notably, hasCastled or
 // last move) may be unsuitable for position-keyed caching. If the recent history is short,
@@ -46,110 +95,226 @@ type TranspositionTable interface {
 	Size() uint64
 	// Used returns the utilization as a fraction [0;1].
 	Used() float64
+	// Age starts a new generation, such as for a new search or game. Entries from previous
+	// generations remain readable, but lose replacement priority.
+	Age()
 }
 
 type TranspositionTableFactory func(ctx context.Context, size uint64) TranspositionTable
 
-// metadata captures node metadata, notably precision and best move. 64bits.
-type metadata struct {
-	bound      Bound        // 1
-	from, to   board.Square // bestmove -- to, from
-	promotion  board.Piece  // bestmove -- promotion
-	ply, depth uint16       //  4
+// maxGenerations is the number of distinct generations. Generations wrap around.
+const maxGenerations = 1 << 6
+
+// bucketSize is the number of entries in a bucket. A bucket fills a 64byte cache line.
+const bucketSize = 4
+
+// entry is a lockless table entry. The key word holds the hash check and score pawns and is
+// sealed with the data word, so that torn reads of concurrently written entries are detected
+// as a check mismatch. An empty entry has zero data. 16bytes.
+//
+// Data word layout, from the least significant byte:
+//
+//	score type + used (8) | score mate (8) | bound + generation (8) | depth (8) | move (16) | ply (16)
+type entry struct {
+	key, data atomic.Uint64
+}
+
+// bucket holds the entries for positions with the same table index. 64bytes.
+type bucket [bucketSize]entry
+
+// usedMask marks the data word of a non-empty entry.
+const usedMask = 0x80
+
+// check returns the 32bit hash check of a position, which is the hash folded in half.
+func check(hash board.ZobristHash) uint32 {
+	return uint32(hash>>32) ^ uint32(hash)
+}
+
+// seal combines the key and data words, such that unseal(seal(key, data), data) = key.
+func seal(key, data uint64) uint64 {
+	return key ^ data ^ data<<32
+}
+
+func unseal(sealed, data uint64) uint64 {
+	return sealed ^ data ^ data<<32
+}
+
+func pack(hash board.ZobristHash, bound Bound, generation uint8, ply, depth int, score eval.Score, move board.Move) (uint64, uint64) {
+	key := uint64(check(hash))<<32 | uint64(math.Float32bits(float32(score.Pawns)))
+
+	flags := uint8(bound)&0x3 | generation<<2
+	mv := uint16(move.From)&0x3f | (uint16(move.To)&0x3f)<<6 | (uint16(move.Promotion)&0xf)<<12
+
+	data := uint64(uint8(score.Type)|usedMask) |
+		uint64(uint8(score.Mate))<<8 |
+		uint64(flags)<<16 |
+		uint64(clamp(depth, math.MaxUint8))<<24 |
+		uint64(mv)<<32 |
+		uint64(clamp(ply, math.MaxUint16))<<48
+	return key, data
+}
+
+// info is a data word. It provides access to the fields used by the replacement logic.
+type info uint64
+
+func unpack(key, data uint64) (Bound, int, eval.Score, board.Move) {
+	score := eval.Score{
+		Type:  eval.ScoreType(uint8(data) &^ usedMask),
+		Mate:  int8(data >> 8),
+		Pawns: eval.Pawns(math.Float32frombits(uint32(key))),
+	}
+	mv := uint16(data >> 32)
+	move := board.Move{
+		From:      board.Square(mv & 0x3f),
+		To:        board.Square((mv >> 6) & 0x3f),
+		Promotion: board.Piece(mv >> 12),
+	}
+	return info(data).bound(), info(data).depth(), score, move
+}
+
+func (d info) bound() Bound {
+	return Bound((d >> 16) & 0x3)
+}
+
+func (d info) generation() uint8 {
+	return uint8(d>>18) & (maxGenerations - 1)
+}
+
+func (d info) depth() int {
+	return int(uint8(d >> 24))
 }
 
-// node represents a search result. 24bytes.
-type node struct {
-	hash  board.ZobristHash // full hash
-	score eval.Score
-	md    metadata
+func (d info) ply() int {
+	return int(uint16(d >> 48))
 }
 
-// table is a transposition table. It uses 32bytes/entry.
+// clamp limits n to [0;max].
+func clamp(n, max int) int {
+	switch {
+	case n < 0:
+		return 0
+	case n > max:
+		return max
+	default:
+		return n
+	}
+}
+
+// table is a transposition table of cache-line sized buckets. It uses 16bytes/entry. Depth is
+// stored up to 255 and the position is identified by a 32bit hash check in addition to the
+// bucket index.
 type table struct {
-	table []*node
-	mask  uint64
-	used  uint64
+	buckets []bucket
+	mask    uint64
+	used    atomic.Uint64 // aligned, unlike a plain uint64 on 32-bit platforms
+	gen     atomic.Uint32 // current generation, modulo maxGenerations
 }
 
 func NewTranspositionTable(ctx context.Context, size uint64) TranspositionTable {
-	n := uint64(1 << (63 - 5 - bits.LeadingZeros64(size)))
+	n := uint64(1)
+	if size >= 1<<6 {
+		n = 1 << (63 - 6 - bits.LeadingZeros64(size))
+	}
 
-	logw.Infof(ctx, "Allocating %vMB TT with %v entries", size>>20, n)
+	logw.Infof(ctx, "Allocating %vMB TT with %v entries", size>>20, n*bucketSize)
 
 	return &table{
-		table: make([]*node, n),
-		mask:  n - 1,
+		buckets: make([]bucket, n), // allocations of 64byte multiples are cache-line aligned
+		mask:    n - 1,
 	}
 }
 
 func (t *table) Size() uint64 {
-	return uint64(len(t.table)) << 5
+	return uint64(len(t.buckets)) << 6
 }
 
 func (t *table) Used() float64 {
-	// https://github.com/census-instrumentation/opencensus-go/issues/587
-	// used := atomic.LoadUint64(&t.used)
-	used := t.used
-	return float64(used) / float64(len(t.table))
+	return float64(t.used.Load()) / float64(len(t.buckets)*bucketSize)
 }
 
 func (t *table) Read(hash board.ZobristHash) (Bound, int, eval.Score, board.Move, bool) {
-	key := uint64(hash) & t.mask
-	addr := (*unsafe.Pointer)(unsafe.Pointer(&t.table[key]))
+	b := &t.buckets[uint64(hash)&t.mask]
+	chk := check(hash)
 
-	ptr := (*node)(atomic.LoadPointer(addr))
-	if ptr != nil && hash == ptr.hash {
-		bestmove := board.Move{From: ptr.md.from, To: ptr.md.to, Promotion: ptr.md.promotion}
-		return ptr.md.bound, int(ptr.md.depth), ptr.score, bestmove, true
+	for i := range b {
+		sealed, data := b[i].key.Load(), b[i].data.Load()
+		if data == 0 {
+			continue
+		}
+		if key := unseal(sealed, data); uint32(key>>32) == chk {
+			bound, depth, score, move := unpack(key, data)
+			return bound, depth, score, move, true
+		}
 	}
 	return 0, 0, eval.Score{}, board.Move{}, false
 }
 
 func (t *table) Write(hash board.ZobristHash, bound Bound, ply, depth int, score eval.Score, move board.Move) bool {
-	key := uint64(hash) & t.mask
-	addr := (*unsafe.Pointer)(unsafe.Pointer(&t.table[key]))
-
-	fresh := &node{
-		hash:  hash,
-		score: score,
-		md: metadata{
-			bound:     bound,
-			from:      move.From,
-			to:        move.To,
-			promotion: move.Promotion,
-			ply:       uint16(ply),
-			depth:     uint16(depth),
-		},
-	}
-
-	ptr := (*node)(atomic.LoadPointer(addr))
-	for {
-		if val(ptr) > val(fresh) {
-			return false // skip: higher value existing node
-		}
-		if atomic.CompareAndSwapPointer(addr, unsafe.Pointer(ptr), unsafe.Pointer(fresh)) {
-			if ptr == nil {
-				// https://github.com/census-instrumentation/opencensus-go/issues/587
-				// atomic.AddUint64(&t.used, 1)
-				t.used++
+	b := &t.buckets[uint64(hash)&t.mask]
+	chk := check(hash)
+
+	gen := t.generation()
+	key, data := pack(hash, bound, gen, ply, depth, score, move)
+
+	// Replace the entry of the same position, if present. Otherwise, replace an empty entry
+	// or the least valuable one, preferring entries from previous generations.
+
+	var victim *entry
+	var low uint32
+	for i := range b {
+		e := &b[i]
+		sealed, old := e.key.Load(), e.data.Load()
+		if old != 0 && uint32(unseal(sealed, old)>>32) == chk {
+			if info(old).generation() == gen && info(old).val() > info(data).val() {
+				return false // skip: higher value existing entry of the current generation
 			}
-			return true // ok: overwrite value
+			victim = e
+			break
+		}
+		if r := info(old).rank(gen); victim == nil || r < low {
+			victim, low = e, r
 		}
-		ptr = (*node)(atomic.LoadPointer(addr))
 	}
+
+	if victim.data.CompareAndSwap(0, data) {
+		t.used.Add(1)
+	} else {
+		victim.data.Store(data)
+	}
+	victim.key.Store(seal(key, data))
+	return true // ok: overwrite value
+}
+
+func (t *table) Age() {
+	t.gen.Add(1)
+}
+
+func (t *table) generation() uint8 {
+	return uint8(t.gen.Load() % maxGenerations)
 }
 
 func (t *table) String() string {
 	return fmt.Sprintf("TT[%v @ %v%%]", t.Size(), int(100*t.Used()))
 }
 
-// val defines node value towards replacement logic.
-func val(n *node) uint16 {
-	if n == nil {
+// val defines entry value towards replacement logic within a generation. Entries from previous
+// generations are always replaced.
+func (d info) val() uint32 {
+	return uint32(d.ply()) + uint32(d.depth())<<1
+}
+
+// rank defines the replacement order of entries for other positions in a bucket: empty entries
+// first, then entries from previous generations and finally entries from the current generation,
+// each by value.
+func (d info) rank(gen uint8) uint32 {
+	switch {
+	case d == 0:
 		return 0
+	case d.generation() != gen:
+		return 1 + d.val()
+	default:
+		return 1<<20 + d.val()
 	}
-	return n.md.ply + (n.md.depth << 1)
 }
 
 // WriteFilter is a predicate on the Write operation.
@@ -181,6 +346,10 @@ func (w WriteLimited) Used() float64 {
 	return w.TT.Used()
 }
 
+func (w WriteLimited) Age() {
+	w.TT.Age()
+}
+
 // NewMinDepthTranspositionTable creates depth-limited TranspositionTables.
 func NewMinDepthTranspositionTable(min int) TranspositionTableFactory {
 	return func(ctx context.Context, size uint64) TranspositionTable {
@@ -211,3 +380,5 @@ func (n NoTranspositionTable) Size() uint64 {
 func (n NoTranspositionTable) Used() float64 {
 	return 0
 }
+
+func (n NoTranspositionTable) Age() {}
@@ -49,3 +49,74 @@ func TestTranspositionTable(t *testing.T) {
 	repl := tt.Write(a, search.ExactBound, 4, 3, eval.HeuristicScore(5), m)
 	assert.True(t, repl)
 }
+
+func TestTranspositionTableAging(t *testing.T) {
+	ctx := context.Background()
+
+	tt := search.NewTranspositionTable(ctx, 0x1000)
+	a := board.ZobristHash(rand.Uint64())
+	m := board.Move{From: board.G4, To: board.G8}
+
+	assert.True(t, tt.Write(a, search.LowerBound, 40, 8, eval.HeuristicScore(1), m))
+	assert.False(t, tt.Write(a, search.UpperBound, 2, 1, eval.HeuristicScore(2), m))
+
+	// An old deep entry loses replacement priority, but is still readable.
+
+	for i := 0; i < 100; i++ {
+		tt.Age()
+	}
+	bound, depth, _, _, ok := tt.Read(a)
+	assert.True(t, ok)
+	assert.Equal(t, search.LowerBound, bound)
+	assert.Equal(t, 8, depth)
+
+	assert.True(t, tt.Write(a, search.UpperBound, 2, 1, eval.HeuristicScore(2), m))
+	bound, depth, score, _, ok := tt.Read(a)
+	assert.True(t, ok)
+	assert.Equal(t, search.UpperBound, bound)
+	assert.Equal(t, 1, depth)
+	assert.Equal(t, eval.HeuristicScore(2), score)
+
+	// Within a generation, the value decides.
+
+	assert.False(t, tt.Write(a, search.ExactBound, 1, 0, eval.HeuristicScore(3), m))
+}
+
+func TestTranspositionTableBuckets(t *testing.T) {
+	ctx := context.Background()
+
+	tt := search.NewTranspositionTable(ctx, 0x1000)
+	m := board.Move{From: board.E7, To: board.E8, Promotion: board.Knight}
+
+	// (1) Positions with the same table index share a bucket of 4 entries.
+
+	var hashes []board.ZobristHash
+	for i := 0; i < 4; i++ {
+		h := board.ZobristHash(uint64(i+1)<<40 | 0x2a)
+		hashes = append(hashes, h)
+		assert.True(t, tt.Write(h, search.ExactBound, 10, 5-i, eval.MateInXScore(int8(-i-1)), m))
+	}
+	for i, h := range hashes {
+		bound, depth, score, move, ok := tt.Read(h)
+		assert.True(t, ok)
+		assert.Equal(t, search.ExactBound, bound)
+		assert.Equal(t, 5-i, depth)
+		assert.Equal(t, eval.MateInXScore(int8(-i-1)), score)
+		assert.Equal(t, m, move)
+	}
+	assert.Equal(t, 4.0/256, tt.Used())
+
+	// (2) A full bucket replaces its least valuable entry.
+
+	h := board.ZobristHash(uint64(5)<<40 | 0x2a)
+	assert.True(t, tt.Write(h, search.LowerBound, 10, 4, eval.HeuristicScore(-1.5), m))
+
+	_, _, _, _, ok := tt.Read(hashes[3])
+	assert.False(t, ok)
+	bound, depth, score, _, ok := tt.Read(h)
+	assert.True(t, ok)
+	assert.Equal(t, search.LowerBound, bound)
+	assert.Equal(t, 4, depth)
+	assert.Equal(t, eval.HeuristicScore(-1.5), score)
+	assert.Equal(t, 4.0/256, tt.Used())
+}
@@ -9,15 +9,20 @@ import (
 
 // PV represents the principal variation for some search depth.
 type PV struct {
+	ID    ID            // search ID, if any
 	Depth int           // depth of search
 	Moves []board.Move  // principal variation
 	Score eval.Score    // evaluation at depth
 	Nodes uint64        // interior/leaf nodes searched
 	Time  time.Duration // time taken by search
 	Hash  float64       // hash table used [0;1]
+	Alt   []PV          // additional lines in order, if MultiPV
+	Root  []RootMove    // root move statistics in order, if available
+	TB    uint64        // tablebase hits
+	Stats Stats         // search statistics, if collected
 }
 
 func (p PV) String() string {
 	pv := board.PrintMoves(p.Moves)
-	return fmt.Sprintf("depth=%v score=%v nodes=%v time=%v hash=%v%% pv=%v", p.Depth, p.Score, p.Nodes, p.Time, int(100*p.Hash), pv)
+	return fmt.Sprintf("id=%v depth=%v score=%v nodes=%v time=%v hash=%v%% pv=%v", p.ID, p.Depth, p.Score, p.Nodes, p.Time, int(100*p.Hash), pv)
 }